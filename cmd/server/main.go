@@ -25,6 +25,7 @@ import (
 	"scriberr/internal/transcription"
 	"scriberr/internal/transcription/adapters"
 	"scriberr/internal/transcription/registry"
+	"scriberr/internal/webhook"
 	"scriberr/pkg/logger"
 )
 
@@ -111,6 +112,23 @@ func main() {
 	speakerMappingRepo := repository.NewSpeakerMappingRepository(database.DB)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(database.DB)
 	watchedFolderRepo := repository.NewWatchedFolderRepository(database.DB)
+	searchRepo := repository.NewSearchRepository(database.DB)
+	embeddingRepo := repository.NewEmbeddingRepository(database.DB)
+	revisionRepo := repository.NewRevisionRepository(database.DB)
+	comparisonRepo := repository.NewComparisonRepository(database.DB)
+	vocabularyRepo := repository.NewVocabularyRepository(database.DB)
+	speakerRepo := repository.NewSpeakerRepository(database.DB)
+	webhookRepo := repository.NewWebhookRepository(database.DB)
+
+	// Index transcripts created before full-text search existed
+	if err := searchRepo.RebuildIfEmpty(context.Background()); err != nil {
+		logger.Warn("Failed to build search index", "error", err)
+	}
+
+	// Model comparisons run in the background and do not survive a restart
+	if err := comparisonRepo.FailInterrupted(context.Background(), "Comparison interrupted by server restart"); err != nil {
+		logger.Warn("Failed to reset interrupted model comparisons", "error", err)
+	}
 
 	// Initialize services
 	logger.Startup("service", "Initializing services")
@@ -121,6 +139,9 @@ func main() {
 	logger.Startup("transcription", "Initializing transcription service")
 	unifiedProcessor := transcription.NewUnifiedJobProcessor(jobRepo, cfg.TempDir, cfg.TranscriptsDir)
 	unifiedProcessor.GetUnifiedService().SetBroadcaster(broadcaster)
+	unifiedProcessor.GetUnifiedService().SetSearchRepository(searchRepo)
+	unifiedProcessor.GetUnifiedService().SetVocabularyRepository(vocabularyRepo)
+	unifiedProcessor.GetUnifiedService().SetSpeakerLibrary(speakerRepo, speakerMappingRepo)
 
 	// Bootstrap embedded Python environment (for all adapters) unless deferred.
 	// Desktop builds can set SCRIBERR_DEFER_MODEL_INIT=true to avoid long first-run startup delays.
@@ -143,14 +164,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Deliver webhook events, including retries left pending by the last run
+	webhookDispatcher := webhook.NewDispatcher(webhookRepo)
+	webhookDispatcher.AllowPrivateTargets = cfg.WebhookAllowPrivateTargets
+	webhookDispatcher.Start()
+	defer webhookDispatcher.Stop()
+
 	// Initialize task queue
 	logger.Startup("queue", "Starting background processing")
 	taskQueue := queue.NewTaskQueue(2, unifiedProcessor, jobRepo) // 2 workers
+	taskQueue.SetWebhookDispatcher(webhookDispatcher)
+	taskQueue.SetBroadcaster(broadcaster)
+	// Completion webhooks held back for an auto summary the last run did not finish
+	if err := unifiedProcessor.GetUnifiedService().ResumeDeferredCompletionWebhooks(context.Background()); err != nil {
+		logger.Warn("Failed to resume deferred completion webhooks", "error", err)
+	}
 	taskQueue.Start()
 	defer taskQueue.Stop()
 
 	// Initialize desktop auto-import folder watcher service
 	folderWatchService := folderwatch.NewService(cfg, watchedFolderRepo, jobRepo, userRepo, profileRepo, taskQueue)
+	folderWatchService.SetWebhookDispatcher(webhookDispatcher)
+	folderWatchService.SetBroadcaster(broadcaster)
 	if err := folderWatchService.Start(context.Background()); err != nil {
 		logger.Warn("Some auto-import folders failed to initialize", "error", err)
 	}
@@ -175,6 +210,14 @@ func main() {
 		noteRepo,
 		speakerMappingRepo,
 		refreshTokenRepo,
+		searchRepo,
+		embeddingRepo,
+		revisionRepo,
+		comparisonRepo,
+		vocabularyRepo,
+		speakerRepo,
+		webhookRepo,
+		webhookDispatcher,
 		taskQueue,
 		unifiedProcessor,
 		quickTranscriptionService,
@@ -182,13 +225,42 @@ func main() {
 		broadcaster,
 	)
 	handler.SetFolderWatchService(folderWatchService)
+	// Jobs that get summarized send their completion webhook once the summary exists
+	unifiedProcessor.GetUnifiedService().SetCompletionWebhookDeferral(handler.WillAutoSummarize)
 	taskQueue.SetOnJobCompleted(func(jobID string) {
+		// The summary can take far longer than the title and embedding, which
+		// do not depend on it
+		go func() {
+			if handler.WillAutoSummarize(context.Background(), jobID) {
+				// Summaries of long recordings can take a while on local models
+				summaryCtx, cancelSummary := context.WithTimeout(context.Background(), 30*time.Minute)
+				if _, err := handler.AutoSummarizeJob(summaryCtx, jobID); err != nil {
+					logger.Warn("Auto summary after transcription completion failed", "job_id", jobID, "error", err)
+				}
+				cancelSummary()
+			}
+
+			// Sent whenever it was held back on completion, even if auto-summary was turned off since
+			webhookCtx, cancelWebhook := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancelWebhook()
+			if err := unifiedProcessor.GetUnifiedService().SendDeferredCompletionWebhook(webhookCtx, jobID); err != nil {
+				logger.Error("Failed to send webhook", "job_id", jobID, "error", err)
+			}
+		}()
+
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 		defer cancel()
 
 		if err := handler.AutoGenerateTranscriptionTitleForJob(ctx, jobID); err != nil {
 			logger.Warn("Auto title generation after transcription completion failed", "job_id", jobID, "error", err)
 		}
+
+		// Embed the transcript up front so semantic search and library chat need not wait for it
+		embedCtx, cancelEmbed := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancelEmbed()
+		if err := handler.EmbedTranscription(embedCtx, jobID); err != nil {
+			logger.Warn("Embedding transcript after transcription completion failed", "job_id", jobID, "error", err)
+		}
 	})
 
 	// Set up router
@@ -0,0 +1,281 @@
+// Package analytics computes meeting metrics such as talk time, turns,
+// overlaps and interruptions from the speakers of a diarized transcript.
+package analytics
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// Version changes whenever Compute derives different metrics from the same
+// transcript, so that cached results can be told apart
+const Version = 2
+
+// SpeakerStats are the metrics of one speaker. Times are in seconds.
+type SpeakerStats struct {
+	Speaker           string  `json:"speaker"`        // Label in the transcript, e.g. "SPEAKER_00"
+	Name              string  `json:"name,omitempty"` // Custom name from the speaker mappings
+	TalkTime          float64 `json:"talk_time"`
+	TalkTimePercent   float64 `json:"talk_time_percent"`
+	Turns             int     `json:"turns"`
+	AverageTurnLength float64 `json:"average_turn_length"`
+	LongestMonologue  float64 `json:"longest_monologue"`
+	Words             int     `json:"words"`
+	WordsPerMinute    float64 `json:"words_per_minute"`
+	Overlaps          int     `json:"overlaps"`      // Segments started while someone else was talking
+	OverlapTime       float64 `json:"overlap_time"`  // Time spent talking over someone else
+	Interruptions     int     `json:"interruptions"` // Turns that took the floor from another speaker
+	Interrupted       int     `json:"interrupted"`   // Turns of this speaker cut short by another
+}
+
+// Analytics are the speaker metrics of a transcript. Times are in seconds.
+type Analytics struct {
+	Duration      float64        `json:"duration"`    // From the first to the last utterance with a speaker
+	SpeechTime    float64        `json:"speech_time"` // Time during which anyone speaks
+	Overlaps      int            `json:"overlaps"`
+	OverlapTime   float64        `json:"overlap_time"`
+	SpeakerCount  int            `json:"speaker_count"`
+	Turns         int            `json:"turns"`
+	Interruptions int            `json:"interruptions"`
+	Speakers      []SpeakerStats `json:"speakers"` // Most talk time first
+}
+
+// ApplyNames sets the custom name of each speaker that has one
+func (a *Analytics) ApplyNames(names map[string]string) {
+	for i := range a.Speakers {
+		a.Speakers[i].Name = names[a.Speakers[i].Speaker]
+	}
+}
+
+// utterance is a stretch of speech attributed to a speaker
+type utterance struct {
+	speaker    string
+	start, end float64
+	words      int
+}
+
+// turn is a stretch in which one speaker holds the floor
+type turn struct {
+	speaker    string
+	start, end float64
+}
+
+// wordPause is the longest silence between two words of a speaker that still
+// counts as talk time
+const wordPause = 1.0
+
+// Compute derives speaker metrics from the utterances of a transcript that
+// have a speaker. Utterances are built from the word timings when the words
+// have speakers, and from the segments otherwise. Consecutive utterances of
+// one speaker form a turn; an utterance of another speaker that ends before
+// the current turn does, such as a brief "yeah", counts as a turn of its own
+// but does not end the current one. A turn that starts while another is in
+// progress and outlasts it interrupts it.
+func Compute(t *interfaces.TranscriptResult) *Analytics {
+	utterances := wordUtterances(t.WordSegments)
+	if len(utterances) == 0 {
+		utterances = segmentUtterances(t.Segments)
+	}
+	sort.SliceStable(utterances, func(i, j int) bool { return utterances[i].start < utterances[j].start })
+
+	result := &Analytics{Speakers: []SpeakerStats{}}
+	if len(utterances) == 0 {
+		return result
+	}
+
+	stats := map[string]*SpeakerStats{}
+	statsFor := func(speaker string) *SpeakerStats {
+		s, ok := stats[speaker]
+		if !ok {
+			s = &SpeakerStats{Speaker: speaker}
+			stats[speaker] = s
+		}
+		return s
+	}
+
+	var turns []turn
+	var current *turn
+	closeTurn := func(tr turn) {
+		turns = append(turns, tr)
+		s := statsFor(tr.speaker)
+		s.LongestMonologue = math.Max(s.LongestMonologue, tr.end-tr.start)
+	}
+	first, last := utterances[0].start, utterances[0].end
+	for _, u := range utterances {
+		s := statsFor(u.speaker)
+		s.TalkTime += u.end - u.start
+		s.Words += u.words
+		last = math.Max(last, u.end)
+
+		switch {
+		case current == nil:
+			current = &turn{speaker: u.speaker, start: u.start, end: u.end}
+		case u.speaker == current.speaker:
+			current.end = math.Max(current.end, u.end)
+		case u.end <= current.end:
+			// Said while the current speaker keeps the floor
+			closeTurn(turn{speaker: u.speaker, start: u.start, end: u.end})
+		default:
+			if u.start < current.end {
+				s.Interruptions++
+				statsFor(current.speaker).Interrupted++
+				result.Interruptions++
+			}
+			closeTurn(*current)
+			current = &turn{speaker: u.speaker, start: u.start, end: u.end}
+		}
+	}
+	closeTurn(*current)
+
+	for _, tr := range turns {
+		statsFor(tr.speaker).Turns++
+	}
+	result.Turns = len(turns)
+	result.Duration = last - first
+	result.SpeechTime, result.OverlapTime, result.Overlaps = sweep(utterances, stats)
+
+	var totalTalk float64
+	for _, s := range stats {
+		totalTalk += s.TalkTime
+	}
+	for _, s := range stats {
+		if totalTalk > 0 {
+			s.TalkTimePercent = round(100 * s.TalkTime / totalTalk)
+		}
+		if s.Turns > 0 {
+			s.AverageTurnLength = round(s.TalkTime / float64(s.Turns))
+		}
+		if s.TalkTime > 0 {
+			s.WordsPerMinute = round(float64(s.Words) / (s.TalkTime / 60))
+		}
+		s.TalkTime = round(s.TalkTime)
+		s.LongestMonologue = round(s.LongestMonologue)
+		s.OverlapTime = round(s.OverlapTime)
+		result.Speakers = append(result.Speakers, *s)
+	}
+	sort.Slice(result.Speakers, func(i, j int) bool {
+		if result.Speakers[i].TalkTime != result.Speakers[j].TalkTime {
+			return result.Speakers[i].TalkTime > result.Speakers[j].TalkTime
+		}
+		return result.Speakers[i].Speaker < result.Speakers[j].Speaker
+	})
+	result.SpeakerCount = len(result.Speakers)
+	result.Duration = round(result.Duration)
+	result.SpeechTime = round(result.SpeechTime)
+	result.OverlapTime = round(result.OverlapTime)
+	return result
+}
+
+// segmentUtterances turns each segment with a speaker into an utterance
+func segmentUtterances(segments []interfaces.TranscriptSegment) []utterance {
+	utterances := make([]utterance, 0, len(segments))
+	for _, seg := range segments {
+		if seg.Speaker == nil || *seg.Speaker == "" || seg.End <= seg.Start {
+			continue
+		}
+		utterances = append(utterances, utterance{
+			speaker: *seg.Speaker,
+			start:   seg.Start,
+			end:     seg.End,
+			words:   len(strings.Fields(seg.Text)),
+		})
+	}
+	return utterances
+}
+
+// wordUtterances joins the words of each speaker into utterances, starting a
+// new one after a pause longer than wordPause. Words of other speakers in
+// between, such as a backchannel, do not split an utterance. It returns nil
+// when no word has a speaker.
+func wordUtterances(words []interfaces.TranscriptWord) []utterance {
+	bySpeaker := map[string][]interfaces.TranscriptWord{}
+	var speakers []string
+	for _, w := range words {
+		if w.Speaker == nil || *w.Speaker == "" || w.End <= w.Start || strings.TrimSpace(w.Word) == "" {
+			continue
+		}
+		if _, ok := bySpeaker[*w.Speaker]; !ok {
+			speakers = append(speakers, *w.Speaker)
+		}
+		bySpeaker[*w.Speaker] = append(bySpeaker[*w.Speaker], w)
+	}
+
+	var utterances []utterance
+	for _, speaker := range speakers {
+		words := bySpeaker[speaker]
+		sort.SliceStable(words, func(i, j int) bool { return words[i].Start < words[j].Start })
+		var current *utterance
+		for _, w := range words {
+			if current != nil && w.Start-current.end <= wordPause {
+				current.end = math.Max(current.end, w.End)
+				current.words++
+				continue
+			}
+			if current != nil {
+				utterances = append(utterances, *current)
+			}
+			current = &utterance{speaker: speaker, start: w.Start, end: w.End, words: 1}
+		}
+		utterances = append(utterances, *current)
+	}
+	return utterances
+}
+
+// sweep walks the utterance boundaries in time order, returning the time
+// anyone speaks, the time two or more speakers do and how often someone
+// started talking over another. Each speaker's share is added to their stats.
+func sweep(utterances []utterance, stats map[string]*SpeakerStats) (speech, overlap float64, overlaps int) {
+	type event struct {
+		at      float64
+		speaker string
+		delta   int
+	}
+	events := make([]event, 0, 2*len(utterances))
+	for _, u := range utterances {
+		events = append(events, event{u.start, u.speaker, 1}, event{u.end, u.speaker, -1})
+	}
+	// Ends before starts at the same instant, so touching segments do not overlap
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at != events[j].at {
+			return events[i].at < events[j].at
+		}
+		return events[i].delta < events[j].delta
+	})
+
+	active := map[string]int{}
+	prev := events[0].at
+	for _, e := range events {
+		if span := e.at - prev; span > 0 && len(active) > 0 {
+			speech += span
+			if len(active) > 1 {
+				overlap += span
+				for speaker := range active {
+					stats[speaker].OverlapTime += span
+				}
+			}
+		}
+		prev = e.at
+		if e.delta > 0 {
+			others := len(active)
+			if active[e.speaker] > 0 {
+				others--
+			}
+			if others > 0 {
+				stats[e.speaker].Overlaps++
+				overlaps++
+			}
+		}
+		active[e.speaker] += e.delta
+		if active[e.speaker] == 0 {
+			delete(active, e.speaker)
+		}
+	}
+	return speech, overlap, overlaps
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,129 @@
+package analytics
+
+import (
+	"testing"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func segment(speaker string, start, end float64, text string) interfaces.TranscriptSegment {
+	return interfaces.TranscriptSegment{Start: start, End: end, Text: text, Speaker: &speaker}
+}
+
+func TestCompute(t *testing.T) {
+	result := Compute(&interfaces.TranscriptResult{Segments: []interfaces.TranscriptSegment{
+		segment("A", 0, 10, "one two three four five six seven eight nine ten"),
+		segment("B", 4, 5, "yeah"), // Backchannel while A keeps talking
+		segment("A", 10, 20, "one two three four five"),
+		segment("B", 19, 30, "sorry to cut in but"), // Takes the floor from A
+		{Start: 30, End: 31, Text: "unattributed"},
+		segment("A", 32, 35, "right"),
+	}})
+
+	assert.Equal(t, 35.0, result.Duration)
+	assert.Equal(t, 33.0, result.SpeechTime)
+	assert.Equal(t, 2, result.Overlaps)
+	assert.Equal(t, 2.0, result.OverlapTime)
+	assert.Equal(t, 2, result.SpeakerCount)
+	assert.Equal(t, 4, result.Turns)
+	assert.Equal(t, 1, result.Interruptions)
+
+	if assert.Len(t, result.Speakers, 2) {
+		a, b := result.Speakers[0], result.Speakers[1]
+		assert.Equal(t, "A", a.Speaker)
+		assert.Equal(t, 23.0, a.TalkTime)
+		assert.Equal(t, 65.71, a.TalkTimePercent)
+		assert.Equal(t, 2, a.Turns)
+		assert.Equal(t, 11.5, a.AverageTurnLength)
+		assert.Equal(t, 20.0, a.LongestMonologue)
+		assert.Equal(t, 16, a.Words)
+		assert.Equal(t, 41.74, a.WordsPerMinute)
+		assert.Equal(t, 0, a.Overlaps)
+		assert.Equal(t, 2.0, a.OverlapTime)
+		assert.Equal(t, 1, a.Interrupted)
+
+		assert.Equal(t, "B", b.Speaker)
+		assert.Equal(t, 12.0, b.TalkTime)
+		assert.Equal(t, 2, b.Turns)
+		assert.Equal(t, 11.0, b.LongestMonologue)
+		assert.Equal(t, 2, b.Overlaps)
+		assert.Equal(t, 1, b.Interruptions)
+		assert.Equal(t, 30.0, b.WordsPerMinute)
+	}
+
+	result.ApplyNames(map[string]string{"B": "Bob"})
+	assert.Equal(t, "", result.Speakers[0].Name)
+	assert.Equal(t, "Bob", result.Speakers[1].Name)
+}
+
+func TestComputeWithoutSpeakers(t *testing.T) {
+	result := Compute(&interfaces.TranscriptResult{Segments: []interfaces.TranscriptSegment{{Start: 0, End: 5, Text: "hello"}}})
+	assert.Equal(t, 0, result.SpeakerCount)
+	assert.NotNil(t, result.Speakers)
+}
+
+func word(speaker string, start, end float64, text string) interfaces.TranscriptWord {
+	return interfaces.TranscriptWord{Start: start, End: end, Word: text, Speaker: &speaker}
+}
+
+func TestComputeFromWords(t *testing.T) {
+	// The segments attribute B's "yeah" to A and hide the pauses; the words
+	// tell who spoke when
+	result := Compute(&interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			segment("A", 0, 6, "one two three yeah four five"),
+			segment("B", 6, 7.5, "but wait"),
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			word("A", 0, 0.5, "one"),
+			word("A", 0.6, 1, "two"),
+			word("A", 1.2, 2, "three"),
+			word("B", 2.5, 3, "yeah"),
+			word("A", 5, 5.5, "four"), // After a pause of three seconds
+			word("A", 5.6, 6, "five"),
+			word("B", 5.8, 6.5, "but"), // Takes the floor from A
+			word("B", 6.6, 7.5, "wait"),
+			{Start: 7.5, End: 8, Word: "unattributed"},
+		},
+	})
+
+	assert.Equal(t, 7.5, result.Duration)
+	assert.Equal(t, 5.0, result.SpeechTime)
+	assert.Equal(t, 1, result.Overlaps)
+	assert.Equal(t, 0.2, result.OverlapTime)
+	assert.Equal(t, 4, result.Turns)
+	assert.Equal(t, 1, result.Interruptions)
+
+	if assert.Len(t, result.Speakers, 2) {
+		a, b := result.Speakers[0], result.Speakers[1]
+		assert.Equal(t, "A", a.Speaker)
+		assert.Equal(t, 3.0, a.TalkTime)
+		assert.Equal(t, 2, a.Turns)
+		assert.Equal(t, 2.0, a.LongestMonologue)
+		assert.Equal(t, 5, a.Words)
+		assert.Equal(t, 100.0, a.WordsPerMinute)
+		assert.Equal(t, 1, a.Interrupted)
+
+		assert.Equal(t, "B", b.Speaker)
+		assert.Equal(t, 2.2, b.TalkTime)
+		assert.Equal(t, 2, b.Turns)
+		assert.Equal(t, 3, b.Words)
+		assert.Equal(t, 81.82, b.WordsPerMinute)
+		assert.Equal(t, 1, b.Overlaps)
+		assert.Equal(t, 1, b.Interruptions)
+	}
+}
+
+func TestComputeFallsBackToSegments(t *testing.T) {
+	// Words without speakers leave the segments in charge
+	result := Compute(&interfaces.TranscriptResult{
+		Segments:     []interfaces.TranscriptSegment{segment("A", 0, 6, "one two three")},
+		WordSegments: []interfaces.TranscriptWord{{Start: 0, End: 1, Word: "one"}},
+	})
+	if assert.Len(t, result.Speakers, 1) {
+		assert.Equal(t, 6.0, result.Speakers[0].TalkTime)
+		assert.Equal(t, 3, result.Speakers[0].Words)
+	}
+}
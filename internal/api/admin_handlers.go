@@ -0,0 +1,255 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"scriberr/internal/auth"
+	"scriberr/internal/models"
+	"scriberr/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// InviteUserRequest represents the payload for inviting a new user
+type InviteUserRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Role     string `json:"role,omitempty"`
+}
+
+// InviteUserResponse returns the new account along with its one-time password
+type InviteUserResponse struct {
+	User              models.User `json:"user"`
+	TemporaryPassword string      `json:"temporary_password"`
+}
+
+// @Summary List users
+// @Description List all user accounts (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/admin/users [get]
+func (h *Handler) ListUsers(c *gin.Context) {
+	users, _, err := h.userRepo.List(c.Request.Context(), 0, -1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// @Summary Invite a user
+// @Description Create a user account with a generated temporary password that is returned only once (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body InviteUserRequest true "User details"
+// @Success 201 {object} InviteUserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/admin/users [post]
+func (h *Handler) InviteUser(c *gin.Context) {
+	var req InviteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleMember
+	}
+	if role != models.RoleAdmin && role != models.RoleMember {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'admin' or 'member'"})
+		return
+	}
+
+	password := generateSecureAPIKey(16)
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to secure password"})
+		return
+	}
+
+	user := models.User{
+		Username: req.Username,
+		Password: hashedPassword,
+		Role:     role,
+	}
+	if err := h.userRepo.Create(c.Request.Context(), &user); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	logger.Info("User invited", "user_id", user.ID, "username", user.Username, "role", user.Role)
+	c.JSON(http.StatusCreated, InviteUserResponse{User: user, TemporaryPassword: password})
+}
+
+// @Summary Disable a user
+// @Description Disable a user account and revoke its sessions (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/disable [post]
+func (h *Handler) DisableUser(c *gin.Context) {
+	h.setUserDisabled(c, true)
+}
+
+// @Summary Enable a user
+// @Description Re-enable a previously disabled user account (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/enable [post]
+func (h *Handler) EnableUser(c *gin.Context) {
+	h.setUserDisabled(c, false)
+}
+
+func (h *Handler) setUserDisabled(c *gin.Context, disabled bool) {
+	callerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	user, ok := h.findTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if disabled {
+		if user.ID == callerID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot disable your own account"})
+			return
+		}
+		if !h.ensureOtherAdminRemains(c, user) {
+			return
+		}
+	}
+
+	user.Disabled = disabled
+	if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	if disabled {
+		if err := h.refreshTokenRepo.RevokeAllForUser(c.Request.Context(), user.ID); err != nil {
+			logger.Warn("Failed to revoke sessions of disabled user", "user_id", user.ID, "error", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Delete a user
+// @Description Delete a user account, its API keys and watch folders. Its transcriptions are transferred to the calling admin (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id} [delete]
+func (h *Handler) DeleteUser(c *gin.Context) {
+	callerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	user, ok := h.findTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if user.ID == callerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot delete your own account"})
+		return
+	}
+	if !h.ensureOtherAdminRemains(c, user) {
+		return
+	}
+
+	// Stop any folder watchers before their rows disappear
+	if h.folderWatchService != nil {
+		if folders, err := h.folderWatchService.ListUserFolders(c.Request.Context(), user.ID); err == nil {
+			for _, folder := range folders {
+				_ = h.folderWatchService.DeleteUserFolder(c.Request.Context(), user.ID, folder.Folder.ID)
+			}
+		}
+	}
+
+	if err := h.userRepo.DeleteAndReassign(c.Request.Context(), user.ID, callerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+
+	logger.Info("User deleted", "user_id", user.ID, "username", user.Username, "reassigned_to", callerID)
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+// findTargetUser loads the user addressed by the :id path parameter
+func (h *Handler) findTargetUser(c *gin.Context) (*models.User, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil, false
+	}
+
+	user, err := h.userRepo.FindByID(c.Request.Context(), uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return nil, false
+	}
+	return user, true
+}
+
+// ensureOtherAdminRemains refuses to remove the last active admin
+func (h *Handler) ensureOtherAdminRemains(c *gin.Context, user *models.User) bool {
+	if !user.IsAdmin() || user.Disabled {
+		return true
+	}
+	admins, err := h.userRepo.CountActiveAdmins(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check admin accounts"})
+		return false
+	}
+	if admins <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last active admin"})
+		return false
+	}
+	return true
+}
+
+// callerIsAdmin reports whether the authenticated caller holds the admin role
+func (h *Handler) callerIsAdmin(c *gin.Context) bool {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return false
+	}
+	user, err := h.userRepo.FindByID(c.Request.Context(), userID.(uint))
+	return err == nil && user.IsAdmin()
+}
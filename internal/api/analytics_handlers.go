@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"scriberr/internal/analytics"
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetTranscriptAnalytics reports speaker metrics for a completed transcript
+// @Summary Get speaker analytics
+// @Description Per-speaker talk time and share, turns, average turn length, longest monologue, words per minute, overlaps and interruptions, computed from the speakers of the current transcript, using word timings when the words have speakers. Speaker mappings are applied as names. Results are cached with the execution that produced the transcript until it is edited.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} analytics.Analytics
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/v1/transcription/{id}/analytics [get]
+func (h *Handler) GetTranscriptAnalytics(c *gin.Context) {
+	ctx := c.Request.Context()
+	job, err := h.jobRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+	if job.Status != models.StatusCompleted || job.Transcript == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Transcript not ready, current status: %s", job.Status)})
+		return
+	}
+
+	result, err := h.transcriptAnalytics(ctx, job)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse transcript"})
+		return
+	}
+
+	mappings, err := h.speakerMappingRepo.ListByJob(ctx, job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speaker mappings"})
+		return
+	}
+	names := make(map[string]string, len(mappings))
+	for _, m := range mappings {
+		names[m.OriginalSpeaker] = m.CustomName
+	}
+	result.ApplyNames(names)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// transcriptAnalytics returns the analytics of a job's transcript, from the
+// cache of the execution that produced it when the transcript is unchanged
+// since they were computed by the current version of the analytics
+func (h *Handler) transcriptAnalytics(ctx context.Context, job *models.TranscriptionJob) (*analytics.Analytics, error) {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", analytics.Version, *job.Transcript)))
+	digest := hex.EncodeToString(sum[:])
+
+	var execution *models.TranscriptionJobExecution
+	var err error
+	if job.ActiveExecutionID != nil {
+		execution, err = h.jobRepo.FindExecution(ctx, job.ID, *job.ActiveExecutionID)
+	} else {
+		execution, err = h.jobRepo.FindLatestCompletedExecution(ctx, job.ID)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Warn("Failed to load execution for analytics cache", "job_id", job.ID, "error", err)
+	}
+
+	if execution != nil && execution.Analytics != nil && execution.AnalyticsDigest != nil && *execution.AnalyticsDigest == digest {
+		var cached analytics.Analytics
+		if err := json.Unmarshal([]byte(*execution.Analytics), &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	var transcript interfaces.TranscriptResult
+	if err := json.Unmarshal([]byte(*job.Transcript), &transcript); err != nil {
+		return nil, err
+	}
+	result := analytics.Compute(&transcript)
+
+	if execution != nil {
+		if data, err := json.Marshal(result); err == nil {
+			encoded := string(data)
+			execution.Analytics = &encoded
+			execution.AnalyticsDigest = &digest
+			if err := h.jobRepo.UpdateExecution(ctx, execution); err != nil {
+				logger.Warn("Failed to cache transcript analytics", "job_id", job.ID, "error", err)
+			}
+		}
+	}
+	return result, nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"scriberr/internal/llm"
 	"scriberr/internal/models"
+	"scriberr/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -23,9 +24,14 @@ const (
 	RoleUser    = "user"
 )
 
-// ChatCreateRequest represents a request to create a new chat session
+var errNoLLMProvider = errors.New("no configured LLM provider found")
+
+// ChatCreateRequest represents a request to create a new chat session.
+// Library sessions answer from all of the user's transcriptions and take no
+// transcription ID.
 type ChatCreateRequest struct {
-	TranscriptionID string `json:"transcription_id" binding:"required"`
+	TranscriptionID string `json:"transcription_id,omitempty"`
+	Mode            string `json:"mode,omitempty" binding:"omitempty,oneof=transcript library"`
 	Model           string `json:"model" binding:"required"`
 	Provider        string `json:"provider,omitempty" binding:"omitempty,oneof=ollama openai"`
 	Title           string `json:"title,omitempty"`
@@ -39,7 +45,8 @@ type ChatMessageRequest struct {
 // ChatSessionResponse represents a chat session response
 type ChatSessionResponse struct {
 	ID              string               `json:"id"`
-	TranscriptionID string               `json:"transcription_id"`
+	Mode            string               `json:"mode"`
+	TranscriptionID string               `json:"transcription_id,omitempty"`
 	Title           string               `json:"title"`
 	Model           string               `json:"model"`
 	Provider        string               `json:"provider"`
@@ -53,10 +60,11 @@ type ChatSessionResponse struct {
 
 // ChatMessageResponse represents a chat message response
 type ChatMessageResponse struct {
-	ID        uint      `json:"id"`
-	Role      string    `json:"role"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint                  `json:"id"`
+	Role      string                `json:"role"`
+	Content   string                `json:"content"`
+	Citations []models.ChatCitation `json:"citations,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
 }
 
 // ChatModelsResponse represents the available chat models
@@ -92,10 +100,11 @@ type Segment struct {
 }
 
 type configuredLLMService struct {
-	Provider string
-	IsActive bool
-	Updated  time.Time
-	Service  llm.Service
+	Provider       string
+	IsActive       bool
+	Updated        time.Time
+	Service        llm.Service
+	EmbeddingModel string
 }
 
 func (h *Handler) getImplicitLLMServices(requestedProvider string) []configuredLLMService {
@@ -104,20 +113,22 @@ func (h *Handler) getImplicitLLMServices(requestedProvider string) []configuredL
 
 	if requestedProvider == "" || requestedProvider == "ollama" {
 		services = append(services, configuredLLMService{
-			Provider: "ollama",
-			IsActive: false,
-			Updated:  time.Time{},
-			Service:  llm.NewOllamaService("http://localhost:11434"),
+			Provider:       "ollama",
+			IsActive:       false,
+			Updated:        time.Time{},
+			Service:        llm.NewOllamaService("http://localhost:11434"),
+			EmbeddingModel: defaultEmbeddingModels["ollama"],
 		})
 	}
 
 	openAIKey := strings.TrimSpace(h.config.OpenAIAPIKey)
 	if openAIKey != "" && (requestedProvider == "" || requestedProvider == "openai") {
 		services = append(services, configuredLLMService{
-			Provider: "openai",
-			IsActive: false,
-			Updated:  time.Time{},
-			Service:  llm.NewOpenAIService(openAIKey, nil),
+			Provider:       "openai",
+			IsActive:       false,
+			Updated:        time.Time{},
+			Service:        llm.NewOpenAIService(openAIKey, nil),
+			EmbeddingModel: defaultEmbeddingModels["openai"],
 		})
 	}
 
@@ -201,7 +212,7 @@ func (h *Handler) getConfiguredLLMServices(ctx context.Context, requestedProvide
 
 	if len(latestByProvider) == 0 {
 		if requestedProvider == "" {
-			return nil, fmt.Errorf("no configured LLM provider found")
+			return nil, errNoLLMProvider
 		}
 		return nil, fmt.Errorf("no %s configuration found", requestedProvider)
 	}
@@ -215,10 +226,11 @@ func (h *Handler) getConfiguredLLMServices(ctx context.Context, requestedProvide
 			continue
 		}
 		entries = append(entries, configuredLLMService{
-			Provider: provider,
-			IsActive: cfg.IsActive,
-			Updated:  cfg.UpdatedAt,
-			Service:  svc,
+			Provider:       provider,
+			IsActive:       cfg.IsActive,
+			Updated:        cfg.UpdatedAt,
+			Service:        svc,
+			EmbeddingModel: embeddingModelFor(cfg),
 		})
 	}
 
@@ -257,8 +269,7 @@ func (h *Handler) getConfiguredLLMServices(ctx context.Context, requestedProvide
 func (h *Handler) getLLMService(ctx context.Context) (llm.Service, string, error) {
 	services, err := h.getConfiguredLLMServices(ctx, "")
 	if err != nil {
-		if strings.Contains(strings.ToLower(err.Error()), "no llm configuration") ||
-			strings.Contains(strings.ToLower(err.Error()), "no configured llm provider") {
+		if errors.Is(err, errNoLLMProvider) || strings.Contains(strings.ToLower(err.Error()), "no llm configuration") {
 			return nil, "", fmt.Errorf("no active LLM configuration found")
 		}
 		return nil, "", err
@@ -389,16 +400,35 @@ func (h *Handler) CreateChatSession(c *gin.Context) {
 		return
 	}
 
-	// Verify transcription exists and has completed transcript
-	transcription, err := h.jobRepo.FindByID(c.Request.Context(), req.TranscriptionID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Transcription not found"})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	if transcription.Status != models.StatusCompleted || transcription.Transcript == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Transcription must be completed to create a chat session"})
-		return
+	mode := req.Mode
+	if mode == "" {
+		mode = models.ChatModeTranscript
+	}
+
+	var transcriptionID *string
+	if mode == models.ChatModeTranscript {
+		if req.TranscriptionID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "transcription_id is required"})
+			return
+		}
+
+		// Verify transcription exists, belongs to the caller and has completed transcript
+		transcription, err := h.jobRepo.FindByIDForUser(c.Request.Context(), req.TranscriptionID, userID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transcription not found"})
+			return
+		}
+
+		if transcription.Status != models.StatusCompleted || transcription.Transcript == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Transcription must be completed to create a chat session"})
+			return
+		}
+		transcriptionID = &transcription.ID
 	}
 
 	// Resolve provider-specific LLM service (or active provider if omitted).
@@ -416,8 +446,10 @@ func (h *Handler) CreateChatSession(c *gin.Context) {
 
 	now := time.Now()
 	chatSession := &models.ChatSession{
-		JobID:           req.TranscriptionID, // Use same ID for JobID as TranscriptionID
-		TranscriptionID: req.TranscriptionID,
+		UserID:          &userID,
+		Mode:            mode,
+		JobID:           transcriptionID, // Use same ID for JobID as TranscriptionID
+		TranscriptionID: transcriptionID,
 		Title:           title,
 		Model:           strings.TrimSpace(req.Model),
 		Provider:        provider,
@@ -431,20 +463,7 @@ func (h *Handler) CreateChatSession(c *gin.Context) {
 		return
 	}
 
-	response := ChatSessionResponse{
-		ID:              chatSession.ID,
-		TranscriptionID: chatSession.TranscriptionID,
-		Title:           chatSession.Title,
-		Model:           chatSession.Model,
-		Provider:        chatSession.Provider,
-		IsActive:        chatSession.IsActive,
-		CreatedAt:       chatSession.CreatedAt,
-		UpdatedAt:       chatSession.UpdatedAt,
-		MessageCount:    chatSession.MessageCount,
-		LastActivityAt:  chatSession.LastActivityAt,
-	}
-
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusCreated, newChatSessionResponse(chatSession))
 }
 
 // @Summary Get chat sessions for a transcription
@@ -471,6 +490,35 @@ func (h *Handler) GetChatSessions(c *gin.Context) {
 		return
 	}
 
+	c.JSON(http.StatusOK, h.chatSessionListResponse(c.Request.Context(), sessions))
+}
+
+// @Summary Get library chat sessions
+// @Description Get the caller's chat sessions that span their whole library of transcriptions
+// @Tags chat
+// @Produce json
+// @Success 200 {array} ChatSessionResponse
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/chat/library/sessions [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetLibraryChatSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.chatRepo.ListLibraryForUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get chat sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.chatSessionListResponse(c.Request.Context(), sessions))
+}
+
+// chatSessionListResponse adds message counts and last messages to sessions
+func (h *Handler) chatSessionListResponse(ctx context.Context, sessions []models.ChatSession) []ChatSessionResponse {
 	// Extract session IDs for batch queries
 	sessionIDs := make([]string, len(sessions))
 	for i, session := range sessions {
@@ -478,40 +526,26 @@ func (h *Handler) GetChatSessions(c *gin.Context) {
 	}
 
 	// Batch query for message counts - eliminates N+1 problem
-	messageCountMap, _ := h.chatRepo.GetMessageCountsBySessionIDs(c.Request.Context(), sessionIDs)
+	messageCountMap, _ := h.chatRepo.GetMessageCountsBySessionIDs(ctx, sessionIDs)
 
 	// Batch query for last messages - eliminates N+1 problem
-	lastMsgsMap, _ := h.chatRepo.GetLastMessagesBySessionIDs(c.Request.Context(), sessionIDs)
+	lastMsgsMap, _ := h.chatRepo.GetLastMessagesBySessionIDs(ctx, sessionIDs)
 
 	// Create last message response lookup map
 	lastMessageMap := make(map[string]*ChatMessageResponse)
 	for sessionID, msg := range lastMsgsMap {
-		lastMessageMap[sessionID] = &ChatMessageResponse{
-			ID:        msg.ID,
-			Role:      msg.Role,
-			Content:   msg.Content,
-			CreatedAt: msg.CreatedAt,
-		}
+		response := newChatMessageResponse(*msg)
+		lastMessageMap[sessionID] = &response
 	}
 
 	var responses []ChatSessionResponse
-	for _, session := range sessions {
-		responses = append(responses, ChatSessionResponse{
-			ID:              session.ID,
-			TranscriptionID: session.TranscriptionID,
-			Title:           session.Title,
-			Model:           session.Model,
-			Provider:        session.Provider,
-			IsActive:        session.IsActive,
-			CreatedAt:       session.CreatedAt,
-			UpdatedAt:       session.UpdatedAt,
-			MessageCount:    int(messageCountMap[session.ID]), // Use batch-loaded count
-			LastActivityAt:  session.LastActivityAt,
-			LastMessage:     lastMessageMap[session.ID], // Use batch-loaded last message
-		})
+	for i := range sessions {
+		response := newChatSessionResponse(&sessions[i])
+		response.MessageCount = int(messageCountMap[sessions[i].ID]) // Use batch-loaded count
+		response.LastMessage = lastMessageMap[sessions[i].ID]        // Use batch-loaded last message
+		responses = append(responses, response)
 	}
-
-	c.JSON(http.StatusOK, responses)
+	return responses
 }
 
 // @Summary Get a chat session with messages
@@ -544,29 +578,14 @@ func (h *Handler) GetChatSession(c *gin.Context) {
 
 	var messageResponses []ChatMessageResponse
 	for _, msg := range session.Messages {
-		messageResponses = append(messageResponses, ChatMessageResponse{
-			ID:        msg.ID,
-			Role:      msg.Role,
-			Content:   msg.Content,
-			CreatedAt: msg.CreatedAt,
-		})
+		messageResponses = append(messageResponses, newChatMessageResponse(msg))
 	}
 
 	response := ChatSessionWithMessages{
-		ChatSessionResponse: ChatSessionResponse{
-			ID:              session.ID,
-			TranscriptionID: session.TranscriptionID,
-			Title:           session.Title,
-			Model:           session.Model,
-			Provider:        session.Provider,
-			IsActive:        session.IsActive,
-			CreatedAt:       session.CreatedAt,
-			UpdatedAt:       session.UpdatedAt,
-			MessageCount:    len(messageResponses),
-			LastActivityAt:  session.LastActivityAt,
-		},
-		Messages: messageResponses,
+		ChatSessionResponse: newChatSessionResponse(session),
+		Messages:            messageResponses,
 	}
+	response.MessageCount = len(messageResponses)
 
 	c.JSON(http.StatusOK, response)
 }
@@ -673,10 +692,20 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 	var currentTokenCount int
 	var transcriptContext string
 
+	// Library sessions, and transcripts too long for the context window, are
+	// answered from the passages most relevant to the question
+	useRetrieval := session.Mode == models.ChatModeLibrary
+	var transcriptTooLong string
+	var citations []models.ChatCitation
+	var transcriptionID string
+	if session.TranscriptionID != nil {
+		transcriptionID = *session.TranscriptionID
+	}
+
 	// Fallback: If transcript wasn't loaded via Preload, fetch it directly from the job repository
-	if session.Transcription.Transcript == nil || *session.Transcription.Transcript == "" {
-		fmt.Printf("Debug: Transcript not loaded via Preload for session %s (TranscriptionID: %s), fetching directly...\n", sessionID, session.TranscriptionID)
-		job, jobErr := h.jobRepo.FindByID(c.Request.Context(), session.TranscriptionID)
+	if !useRetrieval && (session.Transcription.Transcript == nil || *session.Transcription.Transcript == "") {
+		fmt.Printf("Debug: Transcript not loaded via Preload for session %s (TranscriptionID: %s), fetching directly...\n", sessionID, transcriptionID)
+		job, jobErr := h.jobRepo.FindByID(c.Request.Context(), transcriptionID)
 		if jobErr == nil && job != nil && job.Transcript != nil && *job.Transcript != "" {
 			session.Transcription.Transcript = job.Transcript
 			fmt.Printf("Debug: Direct fetch succeeded, transcript length: %d\n", len(*job.Transcript))
@@ -686,7 +715,7 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 	}
 
 	// Add system message with transcript context
-	if session.Transcription.Transcript != nil && *session.Transcription.Transcript != "" {
+	if !useRetrieval && session.Transcription.Transcript != nil && *session.Transcription.Transcript != "" {
 		transcript := *session.Transcription.Transcript
 		fmt.Printf("Debug: Transcript found for session %s. Length: %d\n", sessionID, len(transcript))
 
@@ -703,14 +732,14 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 		var sb strings.Builder
 
 		// Get speaker mappings
-		mappings, err := h.speakerMappingRepo.ListByJob(c.Request.Context(), session.TranscriptionID)
+		mappings, err := h.speakerMappingRepo.ListByJob(c.Request.Context(), transcriptionID)
 		speakerMap := make(map[string]string)
 		if err == nil {
 			for _, m := range mappings {
 				speakerMap[m.OriginalSpeaker] = m.CustomName
 			}
 		} else {
-			fmt.Printf("Failed to get speaker mappings for job %s: %v\n", session.TranscriptionID, err)
+			fmt.Printf("Failed to get speaker mappings for job %s: %v\n", transcriptionID, err)
 		}
 
 		for _, seg := range t.Segments {
@@ -742,25 +771,59 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 		// Estimate 1 token ~= 4 chars
 		transcriptTokens := len(transcriptContext) / 4
 		if transcriptTokens > contextWindow-500 { // Leave 500 tokens for response/history
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Transcript is too long for this model's context window (estimated %d tokens, limit %d). Please use a model with a larger context window.", transcriptTokens, contextWindow)})
-			return
+			transcriptTooLong = fmt.Sprintf("Transcript is too long for this model's context window (estimated %d tokens, limit %d). Please use a model with a larger context window.", transcriptTokens, contextWindow)
+			logger.Debug("Transcript exceeds the model's context window, falling back to retrieval", "session_id", sessionID, "estimated_tokens", transcriptTokens, "context_window", contextWindow)
+			useRetrieval = true
+			transcriptContext = ""
+		} else {
+			currentTokenCount += transcriptTokens
 		}
-		currentTokenCount += transcriptTokens
-	} else {
-		fmt.Printf("Warning: Transcript is nil or empty for chat session %s. Transcription ID: %s\n", sessionID, session.TranscriptionID)
+	} else if !useRetrieval {
+		fmt.Printf("Warning: Transcript is nil or empty for chat session %s. Transcription ID: %s\n", sessionID, transcriptionID)
 		if session.Transcription.ID == "" {
 			fmt.Println("Warning: Session Transcription relation seems missing or empty")
 		}
 	}
 
+	if useRetrieval {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return
+		}
+		var jobIDs []string
+		if session.Mode != models.ChatModeLibrary {
+			jobIDs = []string{transcriptionID}
+		}
+
+		passages, _, err := h.retrievePassages(c.Request.Context(), userID, jobIDs, req.Content, retrievalTopK)
+		if err != nil {
+			if transcriptTooLong != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": transcriptTooLong})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to retrieve relevant passages: " + err.Error()})
+			return
+		}
+		// Retrieved passages may use up to half of the context window
+		transcriptContext, citations = formatRetrievedContext(passages, session.Mode == models.ChatModeLibrary, contextWindow*4/2)
+		currentTokenCount += len(transcriptContext) / 4
+	}
+
+	// Full transcripts are prepended to the first user message, retrieved
+	// passages to the question they were retrieved for
+	contextIndex := 0
+	if useRetrieval {
+		contextIndex = len(messages) - 1
+	}
+
 	// Add conversation history with transcript context prepended to first user message
 	for i, msg := range messages {
 		msgContent := msg.Content
 		// Prepend transcript context to the first user message for better model compatibility
 		// (Some models like Qwen3 don't properly handle system messages)
-		if i == 0 && msg.Role == RoleUser && transcriptContext != "" {
+		if i == contextIndex && msg.Role == RoleUser && transcriptContext != "" {
 			msgContent = transcriptContext + "User question: " + msg.Content
-			fmt.Printf("Debug: Prepended transcript to first user message\n")
+			fmt.Printf("Debug: Prepended context to user message %d\n", i)
 		}
 		msgTokens := len(msgContent) / 4
 
@@ -778,13 +841,18 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 	}
 
 	// Intelligent context trimming: if context exceeds limit, remove oldest messages
-	// Keep the first message (with transcript context) and trim from the middle
+	// Keep the first message (with transcript context) and trim from the middle.
+	// With retrieval the context rides on the latest message, so the oldest go first.
+	keep, drop := 2, 1
+	if useRetrieval {
+		keep, drop = 1, 0
+	}
 	trimmedCount := 0
-	for currentTokenCount > contextWindow && len(openaiMessages) > 2 {
-		// Remove the second message (oldest after the context-bearing first message)
-		removed := openaiMessages[1]
+	for currentTokenCount > contextWindow && len(openaiMessages) > keep {
+		// Remove the oldest message that does not carry the context
+		removed := openaiMessages[drop]
 		removedTokens := len(removed.Content) / 4
-		openaiMessages = append(openaiMessages[:1], openaiMessages[2:]...)
+		openaiMessages = append(openaiMessages[:drop], openaiMessages[drop+1:]...)
 		currentTokenCount -= removedTokens
 		trimmedCount++
 		fmt.Printf("Debug: Trimmed message to fit context. Removed %d tokens, new count: %d/%d\n", removedTokens, currentTokenCount, contextWindow)
@@ -850,6 +918,7 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 						ChatSessionID: sessionID,
 						Role:          "assistant",
 						Content:       assistantResponse.String(),
+						Citations:     marshalCitations(citations),
 					}
 					_ = h.chatRepo.AddMessage(context.Background(), assistantMessage)
 
@@ -890,6 +959,7 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 							ChatSessionID: sessionID,
 							Role:          "assistant",
 							Content:       assistantResponse.String(),
+							Citations:     marshalCitations(citations),
 						}
 						_ = h.chatRepo.AddMessage(context.Background(), assistantMessage)
 
@@ -962,20 +1032,7 @@ func (h *Handler) UpdateChatSessionTitle(c *gin.Context) {
 		return
 	}
 
-	response := ChatSessionResponse{
-		ID:              session.ID,
-		TranscriptionID: session.TranscriptionID,
-		Title:           session.Title,
-		Model:           session.Model,
-		Provider:        session.Provider,
-		IsActive:        session.IsActive,
-		CreatedAt:       session.CreatedAt,
-		UpdatedAt:       session.UpdatedAt,
-		MessageCount:    session.MessageCount,
-		LastActivityAt:  session.LastActivityAt,
-	}
-
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, newChatSessionResponse(session))
 }
 
 // @Summary Delete a chat session
@@ -1008,6 +1065,29 @@ func (h *Handler) DeleteChatSession(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// RequireChatSessionOwner returns middleware that rejects requests for a chat
+// session, identified by the given path parameter, whose transcription the
+// authenticated user does not own
+func (h *Handler) RequireChatSessionOwner(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := currentUserID(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+		if _, err := h.chatRepo.FindSessionForUser(c.Request.Context(), c.Param(param), userID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get chat session"})
+			}
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // generateChatTitle generates a title based on the first user message
 func generateChatTitle(message string) string {
 	// Truncate to reasonable length and clean up
@@ -1234,16 +1314,37 @@ Return only the title, nothing else.`
 }
 
 func (h *Handler) respondWithSession(c *gin.Context, session *models.ChatSession) {
-	c.JSON(http.StatusOK, ChatSessionResponse{
-		ID:              session.ID,
-		TranscriptionID: session.TranscriptionID,
-		Title:           session.Title,
-		Model:           session.Model,
-		Provider:        session.Provider,
-		IsActive:        session.IsActive,
-		CreatedAt:       session.CreatedAt,
-		UpdatedAt:       session.UpdatedAt,
-		MessageCount:    session.MessageCount,
-		LastActivityAt:  session.LastActivityAt,
-	})
+	c.JSON(http.StatusOK, newChatSessionResponse(session))
+}
+
+func newChatSessionResponse(session *models.ChatSession) ChatSessionResponse {
+	response := ChatSessionResponse{
+		ID:             session.ID,
+		Mode:           session.Mode,
+		Title:          session.Title,
+		Model:          session.Model,
+		Provider:       session.Provider,
+		IsActive:       session.IsActive,
+		CreatedAt:      session.CreatedAt,
+		UpdatedAt:      session.UpdatedAt,
+		MessageCount:   session.MessageCount,
+		LastActivityAt: session.LastActivityAt,
+	}
+	if session.TranscriptionID != nil {
+		response.TranscriptionID = *session.TranscriptionID
+	}
+	return response
+}
+
+func newChatMessageResponse(msg models.ChatMessage) ChatMessageResponse {
+	response := ChatMessageResponse{
+		ID:        msg.ID,
+		Role:      msg.Role,
+		Content:   msg.Content,
+		CreatedAt: msg.CreatedAt,
+	}
+	if msg.Citations != nil {
+		_ = json.Unmarshal([]byte(*msg.Citations), &response.Citations)
+	}
+	return response
 }
@@ -0,0 +1,487 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"scriberr/internal/evaluation"
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxReferenceSize limits uploaded reference transcripts
+	maxReferenceSize = 10 << 20
+	// comparisonTimeout bounds a whole comparison, from waiting for a worker to its last model
+	comparisonTimeout = 6 * time.Hour
+)
+
+// CreateComparisonRequest selects the models to compare. It is sent as JSON,
+// or as a multipart form where the reference can be uploaded as a text file.
+type CreateComparisonRequest struct {
+	ModelIDs  []string `json:"model_ids" form:"model_ids" binding:"required,min=1"`
+	Reference *string  `json:"reference,omitempty" form:"reference"` // Correct transcript to score against; models are scored against each other without one
+}
+
+// ComparisonModelResult is the output of one model in a comparison
+type ComparisonModelResult struct {
+	ModelID        string                   `json:"model_id"`
+	Status         models.JobStatus         `json:"status"`
+	ErrorMessage   *string                  `json:"error_message,omitempty"`
+	ProcessingTime float64                  `json:"processing_time"` // Seconds, as reported by the model
+	Language       string                   `json:"language,omitempty"`
+	Text           string                   `json:"text,omitempty"`
+	WER            *evaluation.Score        `json:"wer,omitempty"` // Against the reference
+	CER            *evaluation.Score        `json:"cer,omitempty"`
+	Segments       []evaluation.SegmentDiff `json:"segments,omitempty"`
+}
+
+// ComparisonPairResult scores the output of one model against another's when
+// no reference transcript was given
+type ComparisonPairResult struct {
+	ReferenceModelID  string                   `json:"reference_model_id"`
+	HypothesisModelID string                   `json:"hypothesis_model_id"`
+	WER               evaluation.Score         `json:"wer"`
+	CER               evaluation.Score         `json:"cer"`
+	Segments          []evaluation.SegmentDiff `json:"segments"`
+}
+
+// ComparisonResponse describes a model comparison and, once it has run, its results
+type ComparisonResponse struct {
+	ID                 string                  `json:"id"`
+	TranscriptionJobID string                  `json:"transcription_job_id"`
+	Status             models.JobStatus        `json:"status"`
+	ModelIDs           []string                `json:"model_ids"`
+	HasReference       bool                    `json:"has_reference"`
+	ErrorMessage       *string                 `json:"error_message,omitempty"`
+	CreatedAt          time.Time               `json:"created_at"`
+	CompletedAt        *time.Time              `json:"completed_at,omitempty"`
+	Results            []ComparisonModelResult `json:"results,omitempty"`
+	Pairs              []ComparisonPairResult  `json:"pairs,omitempty"`
+}
+
+// ComparisonProgress is broadcast as a "comparison_progress" event on the
+// event stream of a transcription while its audio is compared across models
+type ComparisonProgress struct {
+	ComparisonID string           `json:"comparison_id"`
+	Status       models.JobStatus `json:"status"`
+	ModelID      string           `json:"model_id,omitempty"` // Model being run
+	Completed    int              `json:"completed"`
+	Total        int              `json:"total"`
+}
+
+// comparisonResults is what is stored in ModelComparison.Results
+type comparisonResults struct {
+	Models []ComparisonModelResult `json:"models"`
+	Pairs  []ComparisonPairResult  `json:"pairs,omitempty"`
+}
+
+// @Summary Compare transcription models
+// @Description Run the audio of a job through several transcription models and compute word and character error rates against a reference transcript, or between the models when no reference is given. The comparison runs in the background on a queue worker, so it waits while the queue is paused or all workers are busy; the job's own transcript is not changed. A user can have one comparison queued or running at a time. The reference can also be uploaded as a text file in the "reference" field of a multipart form.
+// @Tags transcription
+// @Accept json,mpfd
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param request body CreateComparisonRequest true "Models and reference transcript"
+// @Success 202 {object} ComparisonResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/{id}/comparisons [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateModelComparison(c *gin.Context) {
+	var req CreateComparisonRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if file, err := c.FormFile("reference"); err == nil {
+		if file.Size > maxReferenceSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reference transcript is too large"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read reference transcript"})
+			return
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read reference transcript"})
+			return
+		}
+		reference := string(data)
+		req.Reference = &reference
+	}
+	if req.Reference != nil && strings.TrimSpace(*req.Reference) == "" {
+		req.Reference = nil
+	}
+
+	available := h.unifiedProcessor.GetUnifiedService().GetTranscriptionModels()
+	for i, modelID := range req.ModelIDs {
+		if !slices.Contains(available, modelID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown transcription model %q, available: %s", modelID, strings.Join(available, ", "))})
+			return
+		}
+		if slices.Contains(req.ModelIDs[:i], modelID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Model %q is listed twice", modelID)})
+			return
+		}
+	}
+	if req.Reference == nil && len(req.ModelIDs) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either a reference transcript or at least two models are required"})
+		return
+	}
+
+	job, ok := h.findOwnedJob(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if job.AudioPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Job has no audio"})
+		return
+	}
+	// The models would share the converted audio file with the running job
+	if job.Status == models.StatusPending || job.Status == models.StatusProcessing {
+		c.JSON(http.StatusConflict, gin.H{"error": "Job is being transcribed"})
+		return
+	}
+
+	// Every model takes a worker slot for as long as it runs
+	unfinished, err := h.comparisonRepo.CountUnfinishedByUser(c.Request.Context(), job.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comparison"})
+		return
+	}
+	if unfinished > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Another model comparison is still queued or running"})
+		return
+	}
+
+	modelIDs, err := json.Marshal(req.ModelIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comparison"})
+		return
+	}
+	comparison := &models.ModelComparison{
+		TranscriptionJobID: job.ID,
+		UserID:             job.UserID,
+		ModelIDs:           string(modelIDs),
+		Reference:          req.Reference,
+		Status:             models.StatusPending,
+	}
+	if err := h.comparisonRepo.Create(c.Request.Context(), comparison); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comparison"})
+		return
+	}
+
+	// Comparisons are not part of the job, but share the queue's workers
+	params := job.Parameters
+	params.Diarize = false
+	go h.runModelComparison(*comparison, job.AudioPath, params, req.ModelIDs)
+
+	logger.Info("Model comparison queued", "job_id", job.ID, "comparison_id", comparison.ID, "models", req.ModelIDs)
+	c.JSON(http.StatusAccepted, newComparisonResponse(comparison, nil))
+}
+
+// @Summary List model comparisons of a job
+// @Description List the model comparisons of a job, newest first, without their results
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {array} ComparisonResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/{id}/comparisons [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListModelComparisons(c *gin.Context) {
+	job, ok := h.findOwnedJob(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	comparisons, err := h.comparisonRepo.ListByJob(c.Request.Context(), job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comparisons"})
+		return
+	}
+
+	response := make([]ComparisonResponse, 0, len(comparisons))
+	for i := range comparisons {
+		response = append(response, newComparisonResponse(&comparisons[i], nil))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get a model comparison
+// @Description Get a model comparison with the error rates, processing times and per-segment differences of each model
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param comparisonId path string true "Comparison ID"
+// @Success 200 {object} ComparisonResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/{id}/comparisons/{comparisonId} [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetModelComparison(c *gin.Context) {
+	comparison, ok := h.findJobComparison(c)
+	if !ok {
+		return
+	}
+
+	var results *comparisonResults
+	if comparison.Results != nil {
+		results = &comparisonResults{}
+		if err := json.Unmarshal([]byte(*comparison.Results), results); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse comparison results"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, newComparisonResponse(comparison, results))
+}
+
+// @Summary Delete a model comparison
+// @Description Delete a finished model comparison
+// @Tags transcription
+// @Param id path string true "Job ID"
+// @Param comparisonId path string true "Comparison ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/{id}/comparisons/{comparisonId} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteModelComparison(c *gin.Context) {
+	comparison, ok := h.findJobComparison(c)
+	if !ok {
+		return
+	}
+	if comparison.Status == models.StatusPending || comparison.Status == models.StatusProcessing {
+		c.JSON(http.StatusConflict, gin.H{"error": "Comparison is still running"})
+		return
+	}
+
+	if err := h.comparisonRepo.Delete(c.Request.Context(), comparison.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comparison"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Cancel a model comparison
+// @Description Cancel a model comparison that is queued or running. The models that already finished keep their results.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param comparisonId path string true "Comparison ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/transcription/{id}/comparisons/{comparisonId}/cancel [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CancelModelComparison(c *gin.Context) {
+	comparison, ok := h.findJobComparison(c)
+	if !ok {
+		return
+	}
+	if comparison.Status != models.StatusPending && comparison.Status != models.StatusProcessing {
+		c.JSON(http.StatusConflict, gin.H{"error": "Comparison is not running"})
+		return
+	}
+
+	if err := h.taskQueue.CancelTask(comparison.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Comparison is not running"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comparison cancellation requested"})
+}
+
+// findJobComparison loads the comparison in the path, which must belong to the job in the path
+func (h *Handler) findJobComparison(c *gin.Context) (*models.ModelComparison, bool) {
+	job, ok := h.findOwnedJob(c, c.Param("id"))
+	if !ok {
+		return nil, false
+	}
+
+	comparison, err := h.comparisonRepo.FindByID(c.Request.Context(), c.Param("comparisonId"))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comparison"})
+		return nil, false
+	}
+	if err != nil || comparison.TranscriptionJobID != job.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comparison not found"})
+		return nil, false
+	}
+	return comparison, true
+}
+
+// runModelComparison waits for a queue worker slot, then transcribes the
+// audio with each model in turn and stores the scores
+func (h *Handler) runModelComparison(comparison models.ModelComparison, audioPath string, params models.WhisperXParams, modelIDs []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), comparisonTimeout)
+	defer cancel()
+
+	var results comparisonResults
+	completed := 0
+	err := h.taskQueue.RunTask(ctx, comparison.ID, func(ctx context.Context) error {
+		results, completed = h.compareModels(ctx, &comparison, audioPath, params, modelIDs)
+		return ctx.Err()
+	})
+
+	now := time.Now()
+	comparison.CompletedAt = &now
+	comparison.Status = models.StatusCompleted
+	switch {
+	case errors.Is(err, context.Canceled):
+		message := "Comparison was cancelled"
+		comparison.Status = models.StatusFailed
+		comparison.ErrorMessage = &message
+	case errors.Is(err, context.DeadlineExceeded):
+		message := "Comparison timed out"
+		comparison.Status = models.StatusFailed
+		comparison.ErrorMessage = &message
+	case completed == 0:
+		message := "All models failed"
+		comparison.Status = models.StatusFailed
+		comparison.ErrorMessage = &message
+	}
+	if results.Models != nil {
+		if data, err := json.Marshal(results); err != nil {
+			logger.Error("Failed to encode model comparison results", "comparison_id", comparison.ID, "error", err)
+		} else {
+			encoded := string(data)
+			comparison.Results = &encoded
+		}
+	}
+
+	// The run may have used up the timeout; saving the outcome must not depend on it
+	if err := h.comparisonRepo.Update(context.Background(), &comparison); err != nil {
+		logger.Error("Failed to save model comparison", "comparison_id", comparison.ID, "error", err)
+	}
+
+	h.broadcastComparisonProgress(comparison.TranscriptionJobID, ComparisonProgress{
+		ComparisonID: comparison.ID,
+		Status:       comparison.Status,
+		Completed:    len(modelIDs),
+		Total:        len(modelIDs),
+	})
+	logger.Info("Model comparison finished", "comparison_id", comparison.ID, "status", comparison.Status, "completed_models", completed)
+}
+
+// compareModels runs the models once the comparison holds a worker slot. It
+// stops at the first model that would start after ctx is done.
+func (h *Handler) compareModels(ctx context.Context, comparison *models.ModelComparison, audioPath string, params models.WhisperXParams, modelIDs []string) (comparisonResults, int) {
+	comparison.Status = models.StatusProcessing
+	if err := h.comparisonRepo.Update(ctx, comparison); err != nil {
+		logger.Error("Failed to update model comparison", "comparison_id", comparison.ID, "error", err)
+	}
+
+	service := h.unifiedProcessor.GetUnifiedService()
+	results := comparisonResults{Models: make([]ComparisonModelResult, len(modelIDs))}
+	transcripts := make([]*interfaces.TranscriptResult, len(modelIDs))
+	completed := 0
+	for i, modelID := range modelIDs {
+		if ctx.Err() != nil {
+			// Models that never ran have no result
+			results.Models = results.Models[:i]
+			break
+		}
+		h.broadcastComparisonProgress(comparison.TranscriptionJobID, ComparisonProgress{
+			ComparisonID: comparison.ID,
+			Status:       models.StatusProcessing,
+			ModelID:      modelID,
+			Completed:    i,
+			Total:        len(modelIDs),
+		})
+
+		result := &results.Models[i]
+		result.ModelID = modelID
+		transcript, err := service.TranscribeWithModel(ctx, comparison.ID, comparison.UserID, audioPath, modelID, params)
+		if err != nil {
+			logger.Warn("Model comparison run failed", "comparison_id", comparison.ID, "model_id", modelID, "error", err)
+			message := err.Error()
+			result.Status = models.StatusFailed
+			result.ErrorMessage = &message
+			continue
+		}
+
+		transcripts[i] = transcript
+		completed++
+		result.Status = models.StatusCompleted
+		result.ProcessingTime = transcript.ProcessingTime.Seconds()
+		result.Language = transcript.Language
+		result.Text = evaluation.TranscriptText(transcript)
+		if comparison.Reference != nil {
+			scores := evaluation.Compare(*comparison.Reference, transcript)
+			result.WER = &scores.WER
+			result.CER = &scores.CER
+			result.Segments = scores.Segments
+		}
+	}
+
+	if comparison.Reference == nil {
+		for i := range transcripts {
+			for j := i + 1; j < len(transcripts); j++ {
+				if transcripts[i] == nil || transcripts[j] == nil {
+					continue
+				}
+				scores := evaluation.Compare(evaluation.TranscriptText(transcripts[i]), transcripts[j])
+				results.Pairs = append(results.Pairs, ComparisonPairResult{
+					ReferenceModelID:  modelIDs[i],
+					HypothesisModelID: modelIDs[j],
+					WER:               scores.WER,
+					CER:               scores.CER,
+					Segments:          scores.Segments,
+				})
+			}
+		}
+	}
+
+	return results, completed
+}
+
+func (h *Handler) broadcastComparisonProgress(jobID string, progress ComparisonProgress) {
+	if h.broadcaster != nil {
+		h.broadcaster.Broadcast(jobID, "comparison_progress", progress)
+	}
+}
+
+func newComparisonResponse(comparison *models.ModelComparison, results *comparisonResults) ComparisonResponse {
+	response := ComparisonResponse{
+		ID:                 comparison.ID,
+		TranscriptionJobID: comparison.TranscriptionJobID,
+		Status:             comparison.Status,
+		ModelIDs:           []string{},
+		HasReference:       comparison.Reference != nil,
+		ErrorMessage:       comparison.ErrorMessage,
+		CreatedAt:          comparison.CreatedAt,
+		CompletedAt:        comparison.CompletedAt,
+	}
+	if err := json.Unmarshal([]byte(comparison.ModelIDs), &response.ModelIDs); err != nil {
+		logger.Warn("Failed to parse comparison models", "comparison_id", comparison.ID, "error", err)
+	}
+	if results != nil {
+		response.Results = results.Models
+		response.Pairs = results.Pairs
+	}
+	return response
+}
@@ -0,0 +1,373 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"scriberr/internal/llm"
+	"scriberr/internal/models"
+	"scriberr/internal/rag"
+	"scriberr/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultEmbeddingModels are used when an LLM configuration names no embedding model
+var defaultEmbeddingModels = map[string]string{
+	"openai": "text-embedding-3-small",
+	"ollama": "nomic-embed-text",
+}
+
+const (
+	// embeddingBatchSize is the number of passages sent per embeddings request
+	embeddingBatchSize = 32
+	// retrievalTopK is the number of passages put in front of the model
+	retrievalTopK = 8
+	// libraryBackfillLimit bounds how many not yet embedded transcriptions a
+	// single library question queues for embedding
+	libraryBackfillLimit = 10
+)
+
+var errNoEmbeddingProvider = errors.New("no configured LLM provider supports embeddings")
+
+// SemanticSearchResponse is the result of a semantic search
+type SemanticSearchResponse struct {
+	Query   string               `json:"query"`
+	Model   string               `json:"model"`
+	Results []models.SemanticHit `json:"results"`
+}
+
+// retrievedPassage is a transcript passage ranked against a question
+type retrievedPassage struct {
+	Chunk    models.TranscriptChunk
+	JobTitle *string
+	Score    float64
+}
+
+func embeddingModelFor(cfg models.LLMConfig) string {
+	if cfg.EmbeddingModel != nil && strings.TrimSpace(*cfg.EmbeddingModel) != "" {
+		return strings.TrimSpace(*cfg.EmbeddingModel)
+	}
+	return defaultEmbeddingModels[normalizeProvider(cfg.Provider)]
+}
+
+// getEmbedder returns the embedding backend and model of the preferred LLM provider
+func (h *Handler) getEmbedder(ctx context.Context) (llm.Embedder, string, error) {
+	services, err := h.getConfiguredLLMServices(ctx, "")
+	if err != nil {
+		return nil, "", err
+	}
+	for _, svc := range services {
+		if embedder, ok := svc.Service.(llm.Embedder); ok && svc.EmbeddingModel != "" {
+			return embedder, svc.EmbeddingModel, nil
+		}
+	}
+	return nil, "", errNoEmbeddingProvider
+}
+
+// EmbedTranscription rebuilds the embedded passages of a job from its stored
+// transcript. It does nothing when no configured LLM provider can embed.
+func (h *Handler) EmbedTranscription(ctx context.Context, jobID string) error {
+	embedder, model, err := h.getEmbedder(ctx)
+	if err != nil {
+		if errors.Is(err, errNoLLMProvider) || errors.Is(err, errNoEmbeddingProvider) {
+			return nil
+		}
+		return err
+	}
+	return h.embedTranscription(ctx, embedder, model, jobID)
+}
+
+func (h *Handler) embedTranscription(ctx context.Context, embedder llm.Embedder, model, jobID string) error {
+	job, err := h.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to load job: %w", err)
+	}
+	if job.Transcript == nil || *job.Transcript == "" {
+		return h.embeddingRepo.DeleteByJob(ctx, jobID)
+	}
+
+	speakers := make(map[string]string)
+	if mappings, err := h.speakerMappingRepo.ListByJob(ctx, jobID); err == nil {
+		for _, m := range mappings {
+			speakers[m.OriginalSpeaker] = m.CustomName
+		}
+	}
+
+	chunks, err := rag.ChunkTranscript(*job.Transcript, speakers, rag.DefaultChunkSize)
+	if err != nil {
+		return err
+	}
+
+	records := make([]models.TranscriptChunk, 0, len(chunks))
+	for start := 0; start < len(chunks); start += embeddingBatchSize {
+		batch := chunks[start:min(start+embeddingBatchSize, len(chunks))]
+		texts := make([]string, len(batch))
+		for i, chunk := range batch {
+			texts[i] = chunk.Text
+		}
+
+		vectors, err := embedder.Embed(ctx, model, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed transcript: %w", err)
+		}
+		for i, chunk := range batch {
+			records = append(records, models.TranscriptChunk{
+				TranscriptionJobID: jobID,
+				ChunkIndex:         chunk.Index,
+				Content:            chunk.Text,
+				StartTime:          chunk.Start,
+				EndTime:            chunk.End,
+				Model:              model,
+				Embedding:          rag.EncodeVector(vectors[i]),
+			})
+		}
+	}
+
+	if err := h.embeddingRepo.ReplaceChunks(ctx, jobID, records); err != nil {
+		return err
+	}
+	logger.Debug("Embedded transcript", "job_id", jobID, "model", model, "chunks", len(records))
+	return nil
+}
+
+// retrievePassages returns the k passages most similar to query from the
+// user's transcriptions, limited to jobIDs when given. Transcriptions that
+// were not embedded with the current model yet are embedded in the background;
+// until then the passages are found by keyword instead.
+func (h *Handler) retrievePassages(ctx context.Context, userID uint, jobIDs []string, query string, k int) ([]retrievedPassage, string, error) {
+	embedder, model, err := h.getEmbedder(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var missing []string
+	if len(jobIDs) > 0 {
+		for _, jobID := range jobIDs {
+			count, err := h.embeddingRepo.CountByJob(ctx, jobID, model)
+			if err != nil {
+				return nil, model, err
+			}
+			if count == 0 {
+				missing = append(missing, jobID)
+			}
+		}
+	} else {
+		missing, err = h.embeddingRepo.JobsWithoutChunks(ctx, userID, model, libraryBackfillLimit)
+		if err != nil {
+			return nil, model, err
+		}
+	}
+	h.backfillEmbeddings(embedder, model, missing)
+
+	// A transcript being embedded would go unanswered; the library is answered
+	// from what is embedded already, unless that is nothing yet
+	if len(jobIDs) > 0 && len(missing) > 0 {
+		passages, err := h.keywordPassages(ctx, userID, jobIDs, query, k)
+		return passages, model, err
+	}
+
+	chunks, err := h.embeddingRepo.ListForUser(ctx, userID, jobIDs, model)
+	if err != nil {
+		return nil, model, err
+	}
+	if len(chunks) == 0 {
+		if len(missing) > 0 {
+			passages, err := h.keywordPassages(ctx, userID, nil, query, k)
+			return passages, model, err
+		}
+		return []retrievedPassage{}, model, nil
+	}
+
+	queryVectors, err := embedder.Embed(ctx, model, []string{query})
+	if err != nil {
+		return nil, model, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	candidates := make([][]float32, len(chunks))
+	for i, chunk := range chunks {
+		candidates[i] = rag.DecodeVector(chunk.Embedding)
+	}
+
+	titles := make(map[string]*string)
+	passages := []retrievedPassage{}
+	for _, hit := range rag.TopK(queryVectors[0], candidates, k) {
+		chunk := chunks[hit.Index]
+		title, ok := titles[chunk.TranscriptionJobID]
+		if !ok {
+			if job, err := h.jobRepo.FindByID(ctx, chunk.TranscriptionJobID); err == nil {
+				title = job.Title
+			}
+			titles[chunk.TranscriptionJobID] = title
+		}
+		passages = append(passages, retrievedPassage{Chunk: chunk, JobTitle: title, Score: hit.Score})
+	}
+	return passages, model, nil
+}
+
+// backfillEmbeddings embeds jobs in the background, so questions are not held
+// up by embedding whole transcripts. Jobs already being embedded are skipped.
+func (h *Handler) backfillEmbeddings(embedder llm.Embedder, model string, jobIDs []string) {
+	var queued []string
+	for _, jobID := range jobIDs {
+		if _, running := h.backfilling.LoadOrStore(jobID, struct{}{}); !running {
+			queued = append(queued, jobID)
+		}
+	}
+	if len(queued) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		for _, jobID := range queued {
+			if err := h.embedTranscription(ctx, embedder, model, jobID); err != nil {
+				logger.Warn("Failed to embed transcript", "job_id", jobID, "error", err)
+			}
+			h.backfilling.Delete(jobID)
+		}
+	}()
+}
+
+// keywordPassages finds passages with the full-text index, for transcriptions
+// that have no embedded passages yet. They carry no similarity score.
+func (h *Handler) keywordPassages(ctx context.Context, userID uint, jobIDs []string, query string, k int) ([]retrievedPassage, error) {
+	found, err := h.searchRepo.SearchPassages(ctx, userID, jobIDs, query, k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transcripts: %w", err)
+	}
+	passages := make([]retrievedPassage, 0, len(found))
+	for _, p := range found {
+		content := p.Content
+		if p.Speaker != nil && *p.Speaker != "" {
+			content = *p.Speaker + ": " + content
+		}
+		chunk := models.TranscriptChunk{TranscriptionJobID: p.JobID, Content: content}
+		if p.StartTime != nil {
+			chunk.StartTime = *p.StartTime
+		}
+		if p.EndTime != nil {
+			chunk.EndTime = *p.EndTime
+		}
+		passages = append(passages, retrievedPassage{Chunk: chunk, JobTitle: p.JobTitle})
+	}
+	logger.Debug("Answering from keyword search until transcripts are embedded", "user_id", userID, "passages", len(passages))
+	return passages, nil
+}
+
+// formatRetrievedContext renders passages as numbered excerpts for the model
+// to cite, stopping before maxChars is exceeded
+func formatRetrievedContext(passages []retrievedPassage, library bool, maxChars int) (string, []models.ChatCitation) {
+	var sb strings.Builder
+	citations := []models.ChatCitation{}
+	for _, p := range passages {
+		title := "Untitled transcription"
+		if p.JobTitle != nil && *p.JobTitle != "" {
+			title = *p.JobTitle
+		}
+		excerpt := fmt.Sprintf("[%d] %q (%s - %s)\n%s\n\n", len(citations)+1, title,
+			formatTime(p.Chunk.StartTime), formatTime(p.Chunk.EndTime), p.Chunk.Content)
+		if len(citations) > 0 && sb.Len()+len(excerpt) > maxChars {
+			break
+		}
+		sb.WriteString(excerpt)
+		citations = append(citations, models.ChatCitation{
+			Index:     len(citations) + 1,
+			JobID:     p.Chunk.TranscriptionJobID,
+			JobTitle:  p.JobTitle,
+			StartTime: p.Chunk.StartTime,
+			EndTime:   p.Chunk.EndTime,
+			Score:     p.Score,
+		})
+	}
+
+	source := "a transcript"
+	if library {
+		source = "the user's transcripts"
+	}
+	if len(citations) == 0 {
+		return fmt.Sprintf("You are answering questions about %s, but no relevant excerpts were found. Say that you could not find the answer.\n\n", source), citations
+	}
+	return fmt.Sprintf("You are answering questions using the most relevant excerpts from %s. "+
+		"Each excerpt is numbered and labelled with its transcription and time range. "+
+		"Answer only from these excerpts and cite them inline by number, e.g. [1]. "+
+		"If they do not contain the answer, say so.\n\n---EXCERPTS START---\n%s---EXCERPTS END---\n\n", source, sb.String()), citations
+}
+
+// @Summary Semantic search across transcriptions
+// @Description Find the transcript passages closest in meaning to a query using embeddings from the configured LLM provider
+// @Tags search
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Maximum number of results (default 10, max 50)"
+// @Success 200 {object} SemanticSearchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/search/semantic [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) SemanticSearch(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter q is required"})
+		return
+	}
+
+	limit := 10
+	if v := c.Query("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(parsed, 50)
+	}
+
+	passages, model, err := h.retrievePassages(c.Request.Context(), userID, nil, query, limit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errNoEmbeddingProvider) || errors.Is(err, errNoLLMProvider) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := make([]models.SemanticHit, 0, len(passages))
+	for _, p := range passages {
+		results = append(results, models.SemanticHit{
+			JobID:      p.Chunk.TranscriptionJobID,
+			JobTitle:   p.JobTitle,
+			ChunkIndex: p.Chunk.ChunkIndex,
+			Content:    p.Chunk.Content,
+			StartTime:  p.Chunk.StartTime,
+			EndTime:    p.Chunk.EndTime,
+			Score:      p.Score,
+		})
+	}
+
+	c.JSON(http.StatusOK, SemanticSearchResponse{Query: query, Model: model, Results: results})
+}
+
+func marshalCitations(citations []models.ChatCitation) *string {
+	if len(citations) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(citations)
+	if err != nil {
+		return nil
+	}
+	encoded := string(data)
+	return &encoded
+}
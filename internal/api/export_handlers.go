@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"scriberr/internal/export"
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ExportTranscript renders a completed transcript in a subtitle or document format
+// @Summary Export transcript
+// @Description Render the transcript of a completed job as SRT, WebVTT, plain text, Markdown, JSON or TSV. Speaker mappings are applied and max_line_width/max_line_count from the job parameters control subtitle wrapping.
+// @Tags transcription
+// @Produce plain
+// @Param id path string true "Job ID"
+// @Param format query string false "Export format" Enums(srt, vtt, txt, md, json, tsv) default(srt)
+// @Param download query bool false "Send as attachment"
+// @Success 200 {string} string "Rendered transcript"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/v1/transcription/{id}/export [get]
+func (h *Handler) ExportTranscript(c *gin.Context) {
+	jobID := c.Param("id")
+
+	format, err := export.ParseFormat(c.DefaultQuery("format", string(export.FormatSRT)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "supported_formats": export.SupportedFormats})
+		return
+	}
+
+	job, err := h.jobRepo.FindByID(c.Request.Context(), jobID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+
+	if job.Status != models.StatusCompleted || job.Transcript == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Transcript not ready, current status: %s", job.Status)})
+		return
+	}
+
+	var result interfaces.TranscriptResult
+	if err := json.Unmarshal([]byte(*job.Transcript), &result); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse transcript"})
+		return
+	}
+
+	opts := export.Options{SpeakerNames: map[string]string{}}
+	if job.Title != nil {
+		opts.Title = *job.Title
+	}
+	if job.Parameters.MaxLineWidth != nil {
+		opts.MaxLineWidth = *job.Parameters.MaxLineWidth
+	}
+	if job.Parameters.MaxLineCount != nil {
+		opts.MaxLineCount = *job.Parameters.MaxLineCount
+	}
+
+	mappings, err := h.speakerMappingRepo.ListByJob(c.Request.Context(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speaker mappings"})
+		return
+	}
+	for _, m := range mappings {
+		opts.SpeakerNames[m.OriginalSpeaker] = m.CustomName
+	}
+
+	body, err := export.Render(&result, format, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render transcript"})
+		return
+	}
+
+	if c.Query("download") == "true" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFilename(job, format)))
+	}
+	c.Data(http.StatusOK, format.ContentType(), body)
+}
+
+// exportFilename builds a filesystem-safe download name from the job title
+func exportFilename(job *models.TranscriptionJob, format export.Format) string {
+	base := job.ID
+	if job.Title != nil && strings.TrimSpace(*job.Title) != "" {
+		base = strings.Trim(unsafeFilenameChars.ReplaceAllString(*job.Title, "_"), "_")
+		if base == "" {
+			base = job.ID
+		}
+	}
+	return base + "." + string(format)
+}
@@ -16,6 +16,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -30,6 +31,8 @@ import (
 	"scriberr/internal/service"
 	"scriberr/internal/sse"
 	"scriberr/internal/transcription"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/internal/webhook"
 	"scriberr/pkg/binaries"
 	"scriberr/pkg/logger"
 
@@ -54,12 +57,21 @@ type Handler struct {
 	noteRepo            repository.NoteRepository
 	speakerMappingRepo  repository.SpeakerMappingRepository
 	refreshTokenRepo    repository.RefreshTokenRepository
+	searchRepo          repository.SearchRepository
+	embeddingRepo       repository.EmbeddingRepository
+	revisionRepo        repository.RevisionRepository
+	comparisonRepo      repository.ComparisonRepository
+	vocabularyRepo      repository.VocabularyRepository
+	speakerRepo         repository.SpeakerRepository
+	webhookRepo         repository.WebhookRepository
+	webhookDispatcher   *webhook.Dispatcher
 	taskQueue           *queue.TaskQueue
 	unifiedProcessor    *transcription.UnifiedJobProcessor
 	quickTranscription  *transcription.QuickTranscriptionService
 	multiTrackProcessor *processing.MultiTrackProcessor
 	folderWatchService  *folderwatch.Service
 	broadcaster         *sse.Broadcaster
+	backfilling         sync.Map // IDs of jobs being embedded in the background
 }
 
 // NewHandler creates a new handler
@@ -78,6 +90,14 @@ func NewHandler(
 	noteRepo repository.NoteRepository,
 	speakerMappingRepo repository.SpeakerMappingRepository,
 	refreshTokenRepo repository.RefreshTokenRepository,
+	searchRepo repository.SearchRepository,
+	embeddingRepo repository.EmbeddingRepository,
+	revisionRepo repository.RevisionRepository,
+	comparisonRepo repository.ComparisonRepository,
+	vocabularyRepo repository.VocabularyRepository,
+	speakerRepo repository.SpeakerRepository,
+	webhookRepo repository.WebhookRepository,
+	webhookDispatcher *webhook.Dispatcher,
 	taskQueue *queue.TaskQueue,
 	unifiedProcessor *transcription.UnifiedJobProcessor,
 	quickTranscription *transcription.QuickTranscriptionService,
@@ -99,6 +119,14 @@ func NewHandler(
 		noteRepo:            noteRepo,
 		speakerMappingRepo:  speakerMappingRepo,
 		refreshTokenRepo:    refreshTokenRepo,
+		searchRepo:          searchRepo,
+		embeddingRepo:       embeddingRepo,
+		revisionRepo:        revisionRepo,
+		comparisonRepo:      comparisonRepo,
+		vocabularyRepo:      vocabularyRepo,
+		speakerRepo:         speakerRepo,
+		webhookRepo:         webhookRepo,
+		webhookDispatcher:   webhookDispatcher,
 		taskQueue:           taskQueue,
 		unifiedProcessor:    unifiedProcessor,
 		quickTranscription:  quickTranscription,
@@ -131,6 +159,7 @@ type LoginResponse struct {
 	User  struct {
 		ID       uint   `json:"id"`
 		Username string `json:"username"`
+		Role     string `json:"role"`
 	} `json:"user"`
 }
 
@@ -162,8 +191,10 @@ type ChangeUsernameRequest struct {
 
 // CreateAPIKeyRequest represents the create API key request
 type CreateAPIKeyRequest struct {
-	Name        string `json:"name" binding:"required,min=1,max=100"`
-	Description string `json:"description,omitempty"`
+	Name        string     `json:"name" binding:"required,min=1,max=100"`
+	Description string     `json:"description,omitempty"`
+	Scopes      []string   `json:"scopes,omitempty"`     // Defaults to transcription:read, transcription:write and chat
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Omit for a key that never expires
 }
 
 // CreateAPIKeyResponse represents the create API key response
@@ -191,35 +222,39 @@ type YouTubeDownloadResponse struct {
 
 // LLMConfigRequest represents the LLM configuration request
 type LLMConfigRequest struct {
-	Provider      string  `json:"provider" binding:"required,oneof=ollama openai"`
-	BaseURL       *string `json:"base_url,omitempty"`
-	OpenAIBaseURL *string `json:"openai_base_url,omitempty"`
-	APIKey        *string `json:"api_key,omitempty"`
-	IsActive      bool    `json:"is_active"`
+	Provider       string  `json:"provider" binding:"required,oneof=ollama openai"`
+	BaseURL        *string `json:"base_url,omitempty"`
+	OpenAIBaseURL  *string `json:"openai_base_url,omitempty"`
+	APIKey         *string `json:"api_key,omitempty"`
+	EmbeddingModel *string `json:"embedding_model,omitempty"` // For semantic search and chat retrieval; empty uses the provider default
+	IsActive       bool    `json:"is_active"`
 }
 
 // LLMConfigResponse represents the LLM configuration response
 type LLMConfigResponse struct {
-	ID            uint    `json:"id"`
-	Provider      string  `json:"provider"`
-	BaseURL       *string `json:"base_url,omitempty"`
-	OpenAIBaseURL *string `json:"openai_base_url,omitempty"`
-	HasAPIKey     bool    `json:"has_api_key"` // Don't return actual API key
-	IsActive      bool    `json:"is_active"`
-	CreatedAt     string  `json:"created_at"`
-	UpdatedAt     string  `json:"updated_at"`
+	ID             uint    `json:"id"`
+	Provider       string  `json:"provider"`
+	BaseURL        *string `json:"base_url,omitempty"`
+	OpenAIBaseURL  *string `json:"openai_base_url,omitempty"`
+	HasAPIKey      bool    `json:"has_api_key"` // Don't return actual API key
+	IsActive       bool    `json:"is_active"`
+	EmbeddingModel string  `json:"embedding_model"` // Effective model, including the provider default
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
 }
 
 // APIKeyListResponse represents an API key in the list (without the actual key)
 type APIKeyListResponse struct {
-	ID          uint   `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	KeyPreview  string `json:"key_preview"`
-	IsActive    bool   `json:"is_active"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
-	LastUsed    string `json:"last_used,omitempty"`
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	KeyPreview  string   `json:"key_preview"`
+	Scopes      []string `json:"scopes"`
+	IsActive    bool     `json:"is_active"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+	LastUsed    string   `json:"last_used,omitempty"`
+	ExpiresAt   string   `json:"expires_at,omitempty"`
 }
 
 // APIKeysWrapper wraps the API keys list response
@@ -230,10 +265,8 @@ type APIKeysWrapper struct {
 // transformAPIKeyForList converts a models.APIKey to APIKeyListResponse
 func transformAPIKeyForList(apiKey models.APIKey) APIKeyListResponse {
 	keyPreview := ""
-	if len(apiKey.Key) > 8 {
-		keyPreview = apiKey.Key[:8] + "..."
-	} else if apiKey.Key != "" {
-		keyPreview = apiKey.Key + "..."
+	if apiKey.KeyPrefix != "" {
+		keyPreview = apiKey.KeyPrefix + "..."
 	}
 
 	lastUsed := ""
@@ -241,6 +274,11 @@ func transformAPIKeyForList(apiKey models.APIKey) APIKeyListResponse {
 		lastUsed = apiKey.LastUsed.Format(time.RFC3339)
 	}
 
+	expiresAt := ""
+	if apiKey.ExpiresAt != nil {
+		expiresAt = apiKey.ExpiresAt.Format(time.RFC3339)
+	}
+
 	description := ""
 	if apiKey.Description != nil {
 		description = *apiKey.Description
@@ -251,10 +289,12 @@ func transformAPIKeyForList(apiKey models.APIKey) APIKeyListResponse {
 		Name:        apiKey.Name,
 		Description: description,
 		KeyPreview:  keyPreview,
+		Scopes:      apiKey.Scopes,
 		IsActive:    apiKey.IsActive,
 		CreatedAt:   apiKey.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   apiKey.UpdatedAt.Format(time.RFC3339),
 		LastUsed:    lastUsed,
+		ExpiresAt:   expiresAt,
 	}
 }
 
@@ -272,6 +312,11 @@ func transformAPIKeyForList(apiKey models.APIKey) APIKeyListResponse {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) UploadAudio(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	// Note: This endpoint is also used by the CLI watcher to upload files.
 	// The CLI authenticates using a long-lived JWT token.
 
@@ -323,6 +368,7 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 
 	job := models.TranscriptionJob{
 		ID:        jobID,
+		UserID:    userID,
 		AudioPath: filePath,
 		Status:    models.StatusUploaded,
 	}
@@ -337,11 +383,12 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job"})
 		return
 	}
+	h.announceJobCreated(c.Request.Context(), &job)
 
-	// Check for auto-transcription if user is authenticated via JWT
-	if userID, exists := c.Get("user_id"); exists {
+	// Check for auto-transcription for the owning user
+	{
 		// Use UserService to get user
-		user, err := h.userService.GetUser(c.Request.Context(), userID.(uint))
+		user, err := h.userService.GetUser(c.Request.Context(), userID)
 		if err == nil && user.AutoTranscriptionEnabled {
 			// Get user's default profile or use system default
 			var profile *models.TranscriptionProfile
@@ -367,6 +414,9 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 			if profile != nil {
 				job.Parameters = profile.Parameters
 				job.Diarization = profile.Parameters.Diarize
+				if job.Priority == 0 {
+					job.Priority = profile.Priority
+				}
 				job.Status = models.StatusPending
 
 				// Update the job in database
@@ -399,6 +449,11 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) UploadVideo(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	// Parse multipart form
 	header, err := c.FormFile("video")
 	if err != nil {
@@ -430,6 +485,7 @@ func (h *Handler) UploadVideo(c *gin.Context) {
 	// Create job record
 	job := models.TranscriptionJob{
 		ID:        jobID,
+		UserID:    userID,
 		AudioPath: audioPath, // Use the extracted audio path
 		Status:    models.StatusUploaded,
 	}
@@ -445,14 +501,15 @@ func (h *Handler) UploadVideo(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job"})
 		return
 	}
+	h.announceJobCreated(c.Request.Context(), &job)
 
 	// Clean up video file as we only need audio
 	// TODO: Make this configurable? Some users might want to keep the video.
 	_ = h.fileService.RemoveFile(videoPath)
 
 	// Check for auto-transcription (same logic as UploadAudio)
-	if userID, exists := c.Get("user_id"); exists {
-		user, err := h.userService.GetUser(c.Request.Context(), userID.(uint))
+	{
+		user, err := h.userService.GetUser(c.Request.Context(), userID)
 		if err == nil && user.AutoTranscriptionEnabled {
 			var profile *models.TranscriptionProfile
 			if user.DefaultProfileID != nil {
@@ -471,6 +528,9 @@ func (h *Handler) UploadVideo(c *gin.Context) {
 			if profile != nil {
 				job.Parameters = profile.Parameters
 				job.Diarization = profile.Parameters.Diarize
+				if job.Priority == 0 {
+					job.Priority = profile.Priority
+				}
 				job.Status = models.StatusPending
 				if err := h.jobRepo.Update(c.Request.Context(), &job); err == nil {
 					if err := h.taskQueue.EnqueueJob(jobID); err != nil {
@@ -499,6 +559,11 @@ func (h *Handler) UploadVideo(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) UploadMultiTrack(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	// Parse multipart form
 	form, err := c.MultipartForm()
 	if err != nil {
@@ -548,6 +613,7 @@ func (h *Handler) UploadMultiTrack(c *gin.Context) {
 	// Create job record
 	job := models.TranscriptionJob{
 		ID:              jobID,
+		UserID:          userID,
 		Status:          models.StatusUploaded,
 		IsMultiTrack:    true,
 		MultiTrackFiles: trackFiles,
@@ -566,6 +632,7 @@ func (h *Handler) UploadMultiTrack(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job"})
 		return
 	}
+	h.announceJobCreated(c.Request.Context(), &job)
 }
 
 // @Summary Get multi-track merge status
@@ -710,6 +777,7 @@ func (h *Handler) GetTrackProgress(c *gin.Context) {
 // @Param vad_offset formData number false "VAD offset" default(0.363)
 // @Param min_speakers formData int false "Minimum speakers for diarization"
 // @Param max_speakers formData int false "Maximum speakers for diarization"
+// @Param priority formData int false "Queue priority, higher runs first; only admins may raise it above 0" default(0)
 // @Success 200 {object} models.TranscriptionJob
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -717,6 +785,15 @@ func (h *Handler) GetTrackProgress(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) SubmitJob(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	priority := getFormIntWithDefault(c, "priority", 0)
+	if !h.checkPriorityAllowed(c, priority) {
+		return
+	}
+
 	// Parse multipart form
 	header, err := c.FormFile(paramAudio)
 	if err != nil {
@@ -788,8 +865,10 @@ func (h *Handler) SubmitJob(c *gin.Context) {
 	// Create job
 	job := models.TranscriptionJob{
 		ID:          jobID,
+		UserID:      userID,
 		AudioPath:   filePath,
 		Status:      models.StatusPending,
+		Priority:    priority,
 		Diarization: diarize,
 		Parameters:  params,
 	}
@@ -804,6 +883,7 @@ func (h *Handler) SubmitJob(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job"})
 		return
 	}
+	h.announceJobCreated(c.Request.Context(), &job)
 
 	// Enqueue job
 	if err := h.taskQueue.EnqueueJob(jobID); err != nil {
@@ -814,12 +894,21 @@ func (h *Handler) SubmitJob(c *gin.Context) {
 	c.JSON(http.StatusOK, job)
 }
 
+// JobStatusResponse is a job together with its retry budget, its place in
+// the queue and, while it is processed, the latest progress reported by the model
+type JobStatusResponse struct {
+	models.TranscriptionJob
+	MaxAttempts int                  `json:"max_attempts"`
+	Queue       *queue.QueuedJob     `json:"queue,omitempty"`
+	Progress    *interfaces.Progress `json:"progress,omitempty"`
+}
+
 // @Summary Get job status
-// @Description Get the current status of a transcription job
+// @Description Get the current status of a transcription job, including how many processing attempts it has used. A pending or processing job has a queue entry with its position and, when it can be estimated, when it is expected to start and finish. While the job is processed, progress holds the model's current stage (loading_model, vad, transcribing, aligning or diarizing) with its percentage and estimated time left when known.
 // @Tags transcription
 // @Produce json
 // @Param id path string true "Job ID"
-// @Success 200 {object} models.TranscriptionJob
+// @Success 200 {object} JobStatusResponse
 // @Failure 404 {object} map[string]string
 // @Router /api/v1/transcription/{id}/status [get]
 // @Security ApiKeyAuth
@@ -837,7 +926,19 @@ func (h *Handler) GetJobStatus(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, job)
+	response := JobStatusResponse{
+		TranscriptionJob: *job,
+		MaxAttempts:      h.taskQueue.RetryPolicy().MaxAttempts,
+	}
+	if entries := h.queueEntries(c.Request.Context(), []models.TranscriptionJob{*job}); entries != nil {
+		response.Queue = entries[job.ID]
+	}
+	if job.Status == models.StatusProcessing {
+		if progress, ok := h.unifiedProcessor.GetUnifiedService().JobProgress(job.ID); ok {
+			response.Progress = &progress
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // @Summary Get transcript
@@ -911,8 +1012,14 @@ func (h *Handler) GetTranscript(c *gin.Context) {
 	})
 }
 
+// JobListItem is a job in a job list, with its place in the queue while it is pending or processing
+type JobListItem struct {
+	models.TranscriptionJob
+	Queue *queue.QueuedJob `json:"queue,omitempty"`
+}
+
 // @Summary List all transcription records
-// @Description Get a list of all transcription jobs with optional search and filtering
+// @Description Get a list of all transcription jobs with optional search and filtering. Pending and processing jobs have a queue entry with their position and estimated start and completion.
 // @Tags transcription
 // @Produce json
 // @Param page query int false "Page number" default(1)
@@ -928,6 +1035,11 @@ func (h *Handler) GetTranscript(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) ListTranscriptionJobs(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset := (page - 1) * limit
@@ -944,14 +1056,20 @@ func (h *Handler) ListTranscriptionJobs(c *gin.Context) {
 		}
 	}
 
-	jobs, total, err := h.jobRepo.ListWithParams(c.Request.Context(), offset, limit, sortBy, sortOrder, searchQuery, updatedAfter)
+	jobs, total, err := h.jobRepo.ListWithParams(c.Request.Context(), userID, offset, limit, sortBy, sortOrder, searchQuery, updatedAfter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list jobs"})
 		return
 	}
 
+	entries := h.queueEntries(c.Request.Context(), jobs)
+	items := make([]JobListItem, len(jobs))
+	for i, job := range jobs {
+		items[i] = JobListItem{TranscriptionJob: job, Queue: entries[job.ID]}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"jobs": jobs,
+		"jobs": items,
 		"pagination": gin.H{
 			"page":  page,
 			"limit": limit,
@@ -990,9 +1108,11 @@ func (h *Handler) GetTranscriptionJob(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Job ID"
 // @Param parameters body models.WhisperXParams true "Transcription parameters"
+// @Param priority query int false "Queue priority, higher runs first; only admins may raise it above 0"
 // @Success 200 {object} models.TranscriptionJob
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /api/v1/transcription/{id}/start [post]
 // @Security ApiKeyAuth
 // @Security BearerAuth
@@ -1009,29 +1129,43 @@ func (h *Handler) StartTranscription(c *gin.Context) {
 		return
 	}
 
+	if value := c.Query("priority"); value != "" {
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "priority must be an integer"})
+			return
+		}
+		if !h.checkPriorityAllowed(c, priority) {
+			return
+		}
+		job.Priority = priority
+	}
+
 	// Update job with parameters
 	job.Parameters = *requestParams
 	job.Diarization = requestParams.Diarize
-	job.Status = models.StatusPending
 
 	// Clear previous results for re-transcription
 	job.Transcript = nil
 	job.Summary = nil
 	job.ErrorMessage = nil
 
-	// Save updated job
-	if err := h.jobRepo.Update(c.Request.Context(), job); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update job"})
-		return
-	}
-
-	// Enqueue job for transcription
-	if err := h.taskQueue.EnqueueJob(jobID); err != nil {
+	// Save and enqueue in one step, unless a worker or another request got to the job first
+	if err := h.taskQueue.RequeueJob(job, models.StatusUploaded, models.StatusCompleted, models.StatusFailed); err != nil {
+		if err == queue.ErrJobNotQueueable {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot start transcription: job is currently processing or pending"})
+			return
+		}
 		logger.Error("Failed to enqueue job", "job_id", jobID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enqueue job"})
 		return
 	}
 
+	// Edits were made against the previous transcript; the new one starts a fresh history
+	if err := h.revisionRepo.DeleteByJob(c.Request.Context(), jobID); err != nil {
+		logger.Warn("Failed to clear transcript revisions", "job_id", jobID, "error", err)
+	}
+
 	// Log job started
 	params := make(map[string]any)
 	params["model"] = requestParams.Model
@@ -1049,6 +1183,38 @@ func (h *Handler) StartTranscription(c *gin.Context) {
 	c.JSON(http.StatusOK, job)
 }
 
+// findOwnedJob loads a job belonging to the authenticated user. Jobs owned by
+// other users are reported as not found so their existence is not disclosed.
+func (h *Handler) findOwnedJob(c *gin.Context, jobID string) (*models.TranscriptionJob, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+
+	job, err := h.jobRepo.FindByIDForUser(c.Request.Context(), jobID, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return nil, false
+	}
+	return job, true
+}
+
+// RequireJobOwner returns middleware that rejects requests for a job, identified
+// by the given path parameter, that the authenticated user does not own
+func (h *Handler) RequireJobOwner(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := h.findOwnedJob(c, c.Param(param)); !ok {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 func (h *Handler) getJobForTranscription(c *gin.Context, jobID string) (*models.TranscriptionJob, error) {
 	job, err := h.jobRepo.FindByID(c.Request.Context(), jobID)
 	if err != nil {
@@ -1127,32 +1293,44 @@ func (h *Handler) getValidatedTranscriptionParams(c *gin.Context, job *models.Tr
 		"diarize_model", requestParams.DiarizeModel,
 		"language", requestParams.Language)
 
-	normalizedDiarizeModel, validDiarizeModel := normalizeDiarizeModel(requestParams.DiarizeModel)
+	if err := h.validateTranscriptionParams(c, job, &requestParams, "start_transcription"); err != nil {
+		return nil, err
+	}
+	return &requestParams, nil
+}
+
+// validateTranscriptionParams normalizes params for job and checks that they
+// fit its audio, writing a 400 response when they do not
+func (h *Handler) validateTranscriptionParams(c *gin.Context, job *models.TranscriptionJob, params *models.WhisperXParams, action string) error {
+	normalizedDiarizeModel, validDiarizeModel := normalizeDiarizeModel(params.DiarizeModel)
 	if !validDiarizeModel {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid diarize_model. Must be 'pyannote' or 'nvidia_sortformer'"})
-		return nil, fmt.Errorf("invalid diarize_model")
+		return fmt.Errorf("invalid diarize_model")
+	}
+	params.DiarizeModel = normalizedDiarizeModel
+	if err := h.validateVocabularyIDs(c, params); err != nil {
+		return err
 	}
-	requestParams.DiarizeModel = normalizedDiarizeModel
-	fallbackDiarizationModelIfTokenMissing(&requestParams, fmt.Sprintf("start_transcription job=%s", jobID), h.config.HFToken)
+	fallbackDiarizationModelIfTokenMissing(params, fmt.Sprintf("%s job=%s", action, job.ID), h.config.HFToken)
 
 	// Validate multi-track compatibility
-	if job.IsMultiTrack && !requestParams.IsMultiTrackEnabled {
+	if job.IsMultiTrack && !params.IsMultiTrackEnabled {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Multi-track audio requires multi-track transcription to be enabled in the parameters"})
-		return nil, fmt.Errorf("multi-track mismatch")
+		return fmt.Errorf("multi-track mismatch")
 	}
 
-	if !job.IsMultiTrack && requestParams.IsMultiTrackEnabled {
+	if !job.IsMultiTrack && params.IsMultiTrackEnabled {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Multi-track transcription cannot be used with single-track audio files"})
-		return nil, fmt.Errorf("single-track mismatch")
+		return fmt.Errorf("single-track mismatch")
 	}
 
 	// Multi-track transcription should automatically disable diarization
-	if requestParams.IsMultiTrackEnabled && requestParams.Diarize {
+	if params.IsMultiTrackEnabled && params.Diarize {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Diarization must be disabled when using multi-track transcription"})
-		return nil, fmt.Errorf("diarization conflict")
+		return fmt.Errorf("diarization conflict")
 	}
 
-	return &requestParams, nil
+	return nil
 }
 
 // @Summary Kill running transcription job
@@ -1235,6 +1413,7 @@ func (h *Handler) UpdateTranscriptionTitle(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update title"})
 		return
 	}
+	h.announceTitle(job)
 
 	c.JSON(http.StatusOK, gin.H{
 		"id":         job.ID,
@@ -1598,6 +1777,8 @@ func (h *Handler) generateAndPersistTranscriptionTitle(
 		return "", "", err
 	}
 
+	h.announceTitle(job)
+
 	if originalAudioPath != renamedAudioPath {
 		logger.Info("Renamed audio file after transcription auto title",
 			"job_id", job.ID,
@@ -1823,16 +2004,53 @@ func (h *Handler) DeleteTranscriptionJob(c *gin.Context) {
 		fmt.Printf("Failed to delete summaries for job %s: %v\n", jobID, err)
 	}
 
+	// Delete Search Index Entries
+	if err := h.searchRepo.DeleteByJob(ctx, jobID); err != nil {
+		fmt.Printf("Failed to delete search index entries for job %s: %v\n", jobID, err)
+	}
+
+	// Delete Embedded Passages
+	if err := h.embeddingRepo.DeleteByJob(ctx, jobID); err != nil {
+		fmt.Printf("Failed to delete embedded passages for job %s: %v\n", jobID, err)
+	}
+
+	// Delete Transcript Revisions
+	if err := h.revisionRepo.DeleteByJob(ctx, jobID); err != nil {
+		fmt.Printf("Failed to delete transcript revisions for job %s: %v\n", jobID, err)
+	}
+
+	// Delete Model Comparisons, stopping the ones still queued or running
+	if comparisons, err := h.comparisonRepo.ListByJob(ctx, jobID); err == nil {
+		for _, comparison := range comparisons {
+			if comparison.Status == models.StatusPending || comparison.Status == models.StatusProcessing {
+				_ = h.taskQueue.CancelTask(comparison.ID)
+			}
+		}
+	}
+	if err := h.comparisonRepo.DeleteByJob(ctx, jobID); err != nil {
+		fmt.Printf("Failed to delete model comparisons for job %s: %v\n", jobID, err)
+	}
+
 	// Delete Speaker Mappings
 	if err := h.speakerMappingRepo.DeleteByJobID(ctx, jobID); err != nil {
 		fmt.Printf("Failed to delete speaker mappings for job %s: %v\n", jobID, err)
 	}
 
+	// Delete Speaker Embeddings
+	if err := h.speakerRepo.DeleteJobSpeakers(ctx, jobID); err != nil {
+		fmt.Printf("Failed to delete speaker embeddings for job %s: %v\n", jobID, err)
+	}
+
 	// Delete Job Executions
 	if err := h.jobRepo.DeleteExecutionsByJobID(ctx, jobID); err != nil {
 		fmt.Printf("Failed to delete job executions for job %s: %v\n", jobID, err)
 	}
 
+	// Delete Chunk Checkpoints
+	if err := h.jobRepo.DeleteChunksByJobID(ctx, jobID); err != nil {
+		fmt.Printf("Failed to delete chunk checkpoints for job %s: %v\n", jobID, err)
+	}
+
 	// Delete MultiTrack Files (DB records)
 	if err := h.jobRepo.DeleteMultiTrackFilesByJobID(ctx, jobID); err != nil {
 		fmt.Printf("Failed to delete multi-track file records for job %s: %v\n", jobID, err)
@@ -2067,6 +2285,12 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
+	if user.Disabled {
+		logger.AuthEvent("login", req.Username, c.ClientIP(), false, "account_disabled")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		return
+	}
+
 	token, err := h.authService.GenerateToken(user)
 
 	if err != nil {
@@ -2095,6 +2319,7 @@ func (h *Handler) Login(c *gin.Context) {
 	response := LoginResponse{Token: token}
 	response.User.ID = user.ID
 	response.User.Username = user.Username
+	response.User.Role = user.Role
 
 	logger.AuthEvent("login", req.Username, c.ClientIP(), true)
 	c.JSON(http.StatusOK, response)
@@ -2199,13 +2424,17 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	// Create user
+	// The initial user administers the instance; of concurrent registrations only one succeeds
 	user := models.User{
 		Username: req.Username,
 		Password: hashedPassword,
 	}
 
-	if err := h.userRepo.Create(c.Request.Context(), &user); err != nil {
+	if err := h.userRepo.CreateInitialAdmin(c.Request.Context(), &user); err != nil {
+		if err == repository.ErrAdminExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "Registration is not allowed. Admin user already exists"})
+			return
+		}
 		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
 			c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
 			return
@@ -2240,6 +2469,7 @@ func (h *Handler) Register(c *gin.Context) {
 	response := LoginResponse{Token: token}
 	response.User.ID = user.ID
 	response.User.Username = user.Username
+	response.User.Role = user.Role
 
 	c.JSON(http.StatusCreated, response)
 }
@@ -2272,6 +2502,10 @@ func (h *Handler) Refresh(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 		return
 	}
+	if user.Disabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is disabled"})
+		return
+	}
 	token, err := h.authService.GenerateToken(user)
 
 	if err != nil {
@@ -2442,7 +2676,12 @@ func (h *Handler) ChangeUsername(c *gin.Context) {
 // @Security BearerAuth
 // @Router /api/v1/api-keys [get]
 func (h *Handler) ListAPIKeys(c *gin.Context) {
-	apiKeys, err := h.apiKeyRepo.ListActive(c.Request.Context())
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	apiKeys, err := h.apiKeyRepo.ListActiveByUser(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
 		return
@@ -2458,7 +2697,7 @@ func (h *Handler) ListAPIKeys(c *gin.Context) {
 }
 
 // @Summary Create API key
-// @Description Create a new API key for external API access
+// @Description Create a new API key for external API access. The key is only returned once; the server keeps a hash of it.
 // @Tags api-keys
 // @Accept json
 // @Produce json
@@ -2468,20 +2707,56 @@ func (h *Handler) ListAPIKeys(c *gin.Context) {
 // @Security BearerAuth
 // @Router /api/v1/api-keys [post]
 func (h *Handler) CreateAPIKey(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	var req CreateAPIKeyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
 
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = models.DefaultAPIKeyScopes
+	}
+	for _, scope := range scopes {
+		if !models.IsValidAPIKeyScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+			return
+		}
+	}
+
+	// Only admins can delegate admin access to a key
+	for _, scope := range scopes {
+		if scope != models.ScopeAdmin {
+			continue
+		}
+		user, err := h.userRepo.FindByID(c.Request.Context(), userID)
+		if err != nil || !user.IsAdmin() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can create keys with the admin scope"})
+			return
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
 	// Generate a secure API key
 	apiKey := generateSecureAPIKey(32)
 
-	// Create the API key record
+	// Create the API key record; only its hash is stored
 	newKey := models.APIKey{
+		UserID:      userID,
 		Key:         apiKey,
 		Name:        req.Name,
 		Description: &req.Description,
+		Scopes:      scopes,
+		ExpiresAt:   req.ExpiresAt,
 		IsActive:    true,
 	}
 
@@ -2512,9 +2787,14 @@ func (h *Handler) DeleteAPIKey(c *gin.Context) {
 		return
 	}
 
-	// Check if the API key exists
-	_, err = h.apiKeyRepo.FindByID(c.Request.Context(), uint(id))
-	if err != nil {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Check if the API key exists and belongs to the caller
+	key, err := h.apiKeyRepo.FindByID(c.Request.Context(), uint(id))
+	if err != nil || key.UserID != userID {
 		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
 		return
 	}
@@ -2548,14 +2828,15 @@ func (h *Handler) GetLLMConfig(c *gin.Context) {
 	}
 
 	response := LLMConfigResponse{
-		ID:            config.ID,
-		Provider:      config.Provider,
-		BaseURL:       config.BaseURL,
-		OpenAIBaseURL: config.OpenAIBaseURL,
-		HasAPIKey:     config.APIKey != nil && *config.APIKey != "",
-		IsActive:      config.IsActive,
-		CreatedAt:     config.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:     config.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:             config.ID,
+		Provider:       config.Provider,
+		BaseURL:        config.BaseURL,
+		OpenAIBaseURL:  config.OpenAIBaseURL,
+		HasAPIKey:      config.APIKey != nil && *config.APIKey != "",
+		IsActive:       config.IsActive,
+		EmbeddingModel: embeddingModelFor(*config),
+		CreatedAt:      config.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:      config.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -2592,6 +2873,10 @@ func (h *Handler) SaveLLMConfig(c *gin.Context) {
 		req.BaseURL = &normalizedBaseURL
 	}
 
+	if req.EmbeddingModel != nil && strings.TrimSpace(*req.EmbeddingModel) == "" {
+		req.EmbeddingModel = nil
+	}
+
 	if req.OpenAIBaseURL != nil {
 		trimmed := strings.TrimSpace(*req.OpenAIBaseURL)
 		if trimmed == "" {
@@ -2634,11 +2919,12 @@ func (h *Handler) SaveLLMConfig(c *gin.Context) {
 	if err == gorm.ErrRecordNotFound {
 		// No existing active config, create new one
 		config = &models.LLMConfig{
-			Provider:      req.Provider,
-			BaseURL:       req.BaseURL,
-			OpenAIBaseURL: req.OpenAIBaseURL,
-			APIKey:        apiKeyToSave,
-			IsActive:      req.IsActive,
+			Provider:       req.Provider,
+			BaseURL:        req.BaseURL,
+			OpenAIBaseURL:  req.OpenAIBaseURL,
+			APIKey:         apiKeyToSave,
+			EmbeddingModel: req.EmbeddingModel,
+			IsActive:       req.IsActive,
 		}
 
 		if err := h.llmConfigRepo.Create(c.Request.Context(), config); err != nil {
@@ -2651,6 +2937,7 @@ func (h *Handler) SaveLLMConfig(c *gin.Context) {
 		existingConfig.BaseURL = req.BaseURL
 		existingConfig.OpenAIBaseURL = req.OpenAIBaseURL
 		existingConfig.APIKey = apiKeyToSave
+		existingConfig.EmbeddingModel = req.EmbeddingModel
 		existingConfig.IsActive = req.IsActive
 
 		if err := h.llmConfigRepo.Update(c.Request.Context(), existingConfig); err != nil {
@@ -2661,14 +2948,15 @@ func (h *Handler) SaveLLMConfig(c *gin.Context) {
 	}
 
 	response := LLMConfigResponse{
-		ID:            config.ID,
-		Provider:      config.Provider,
-		BaseURL:       config.BaseURL,
-		OpenAIBaseURL: config.OpenAIBaseURL,
-		HasAPIKey:     config.APIKey != nil && *config.APIKey != "",
-		IsActive:      config.IsActive,
-		CreatedAt:     config.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:     config.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:             config.ID,
+		Provider:       config.Provider,
+		BaseURL:        config.BaseURL,
+		OpenAIBaseURL:  config.OpenAIBaseURL,
+		HasAPIKey:      config.APIKey != nil && *config.APIKey != "",
+		IsActive:       config.IsActive,
+		EmbeddingModel: embeddingModelFor(*config),
+		CreatedAt:      config.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:      config.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -2704,6 +2992,160 @@ func (h *Handler) GetQueueStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// @Summary List the queue
+// @Description List the running jobs and the pending jobs in the order workers pick them up, with each pending job's position and the estimated start and completion of every job. Estimates scale the audio length by the processing speed of recent jobs with the same model and device.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} queue.QueueSnapshot
+// @Router /api/v1/admin/queue [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetQueue(c *gin.Context) {
+	snapshot, err := h.taskQueue.Snapshot(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list queue"})
+		return
+	}
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// @Summary Pause the queue
+// @Description Stop workers from starting queued jobs until the queue is resumed. Running jobs finish normally. The queue stays paused across restarts.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]bool
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/queue/pause [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) PauseQueue(c *gin.Context) {
+	if err := h.taskQueue.Pause(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pause queue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"paused": true})
+}
+
+// @Summary Resume the queue
+// @Description Let workers pick up queued jobs again
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]bool
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/queue/resume [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ResumeQueue(c *gin.Context) {
+	if err := h.taskQueue.Resume(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resume queue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"paused": false})
+}
+
+// @Summary Pause a queued job
+// @Description Hold a pending job in the queue. Workers skip it until it is resumed, and it keeps its place in line. The pause survives restarts.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.TranscriptionJob
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/admin/queue/jobs/{id}/pause [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) PauseQueuedJob(c *gin.Context) {
+	h.changeQueuedJob(c, h.taskQueue.PauseJob)
+}
+
+// @Summary Resume a queued job
+// @Description Release a paused job back to its place in the queue
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.TranscriptionJob
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/admin/queue/jobs/{id}/resume [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ResumeQueuedJob(c *gin.Context) {
+	h.changeQueuedJob(c, h.taskQueue.ResumeJob)
+}
+
+// @Summary Move a queued job to the front
+// @Description Make a pending job the next one a worker picks up. It takes the highest priority among the pending jobs and skips the rest of any retry backoff.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.TranscriptionJob
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/admin/queue/jobs/{id}/move-to-front [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) MoveQueuedJobToFront(c *gin.Context) {
+	h.changeQueuedJob(c, h.taskQueue.MoveJobToFront)
+}
+
+// changeQueuedJob applies a queue operation to the job in the path and
+// responds with the updated job
+func (h *Handler) changeQueuedJob(c *gin.Context, change func(jobID string) error) {
+	jobID := c.Param("id")
+	if _, err := h.jobRepo.FindByID(c.Request.Context(), jobID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+
+	if err := change(jobID); err != nil {
+		if err == queue.ErrJobNotPending {
+			c.JSON(http.StatusConflict, gin.H{"error": "Job is not waiting in the queue"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update queued job"})
+		return
+	}
+
+	job, err := h.jobRepo.FindByID(c.Request.Context(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// queueEntries returns the queue entries of those jobs that are pending or
+// processing, keyed by job ID. It is nil if none are, or the queue cannot be read.
+func (h *Handler) queueEntries(ctx context.Context, jobs []models.TranscriptionJob) map[string]*queue.QueuedJob {
+	queued := false
+	for _, job := range jobs {
+		if job.Status == models.StatusPending || job.Status == models.StatusProcessing {
+			queued = true
+			break
+		}
+	}
+	if !queued {
+		return nil
+	}
+
+	snapshot, err := h.taskQueue.Snapshot(ctx)
+	if err != nil {
+		logger.Warn("Failed to load queue", "error", err)
+		return nil
+	}
+	entries := make(map[string]*queue.QueuedJob)
+	for _, job := range jobs {
+		if entry := snapshot.Find(job.ID); entry != nil {
+			entries[job.ID] = entry
+		}
+	}
+	return entries
+}
+
 // @Summary Get supported models
 // @Description Get list of supported WhisperX models
 // @Tags transcription
@@ -2787,6 +3229,17 @@ func getFormValueWithDefault(c *gin.Context, key, defaultValue string) string {
 	return defaultValue
 }
 
+// checkPriorityAllowed refuses a raised queue priority from anyone but an
+// admin, writing a 403 response. The queue is shared, so members may only
+// lower their jobs' priority; profiles set by admins can still raise it.
+func (h *Handler) checkPriorityAllowed(c *gin.Context, priority int) bool {
+	if priority <= 0 || h.callerIsAdmin(c) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can raise a job's queue priority"})
+	return false
+}
+
 func getFormIntWithDefault(c *gin.Context, key string, defaultValue int) int {
 	if value := c.PostForm(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -2858,6 +3311,10 @@ func (h *Handler) CreateProfile(c *gin.Context) {
 		return
 	}
 
+	if err := h.validateVocabularyIDs(c, &profile.Parameters); err != nil {
+		return
+	}
+
 	// Check if profile name already exists
 	// TODO: Add FindByName to ProfileRepository if needed, or rely on unique constraint error
 	// For now, we'll skip explicit check or implement it in repository.
@@ -2928,6 +3385,10 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	if err := h.validateVocabularyIDs(c, &updatedProfile.Parameters); err != nil {
+		return
+	}
+
 	// Check if profile name already exists (excluding current profile)
 	// TODO: Add check to repository
 
@@ -3124,10 +3585,18 @@ func (h *Handler) SubmitQuickTranscription(c *gin.Context) {
 		return
 	}
 	params.DiarizeModel = normalizedDiarizeModel
+	if err := h.validateVocabularyIDs(c, &params); err != nil {
+		return
+	}
 	fallbackDiarizationModelIfTokenMissing(&params, "quick_transcription", h.config.HFToken)
 
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	// Submit quick transcription job
-	job, err := h.quickTranscription.SubmitQuickJob(file, header.Filename, params)
+	job, err := h.quickTranscription.SubmitQuickJob(userID, file, header.Filename, params)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to submit quick transcription: %v", err)})
 		return
@@ -3148,6 +3617,10 @@ func (h *Handler) SubmitQuickTranscription(c *gin.Context) {
 // @Security BearerAuth
 func (h *Handler) GetQuickTranscriptionStatus(c *gin.Context) {
 	jobID := c.Param("id")
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
 
 	job, err := h.quickTranscription.GetQuickJob(jobID)
 	if err != nil {
@@ -3159,6 +3632,12 @@ func (h *Handler) GetQuickTranscriptionStatus(c *gin.Context) {
 		return
 	}
 
+	// Quick jobs of other users are reported as missing, like regular jobs
+	if job.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+
 	c.JSON(http.StatusOK, job)
 }
 
@@ -3175,6 +3654,11 @@ func (h *Handler) GetQuickTranscriptionStatus(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) DownloadFromYouTube(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	var req YouTubeDownloadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -3278,6 +3762,7 @@ func (h *Handler) DownloadFromYouTube(c *gin.Context) {
 	// Create transcription record
 	job := models.TranscriptionJob{
 		ID:        jobID,
+		UserID:    userID,
 		AudioPath: actualFilePath,
 		Status:    models.StatusUploaded,
 	}
@@ -3294,6 +3779,7 @@ func (h *Handler) DownloadFromYouTube(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save transcription record"})
 		return
 	}
+	h.announceJobCreated(c.Request.Context(), &job)
 
 	c.JSON(http.StatusOK, job)
 }
@@ -3415,6 +3901,7 @@ type UserSettingsResponse struct {
 	AutoTranscriptionTitleEnabled bool    `json:"auto_transcription_title_enabled"`
 	AutoChatTitleEnabled          bool    `json:"auto_chat_title_enabled"`
 	DefaultProfileID              *string `json:"default_profile_id,omitempty"`
+	DefaultSummaryTemplateID      *string `json:"default_summary_template_id,omitempty"`
 }
 
 // UpdateUserSettingsRequest represents the request to update user settings
@@ -3423,6 +3910,8 @@ type UpdateUserSettingsRequest struct {
 	AutoSummaryEnabled            *bool `json:"auto_summary_enabled,omitempty"`
 	AutoTranscriptionTitleEnabled *bool `json:"auto_transcription_title_enabled,omitempty"`
 	AutoChatTitleEnabled          *bool `json:"auto_chat_title_enabled,omitempty"`
+	// DefaultSummaryTemplateID selects the template used for automatic summaries; empty clears it
+	DefaultSummaryTemplateID *string `json:"default_summary_template_id,omitempty"`
 }
 
 // @Summary Get user settings
@@ -3453,6 +3942,7 @@ func (h *Handler) GetUserSettings(c *gin.Context) {
 		AutoTranscriptionTitleEnabled: user.AutoTranscriptionTitleEnabled,
 		AutoChatTitleEnabled:          user.AutoChatTitleEnabled,
 		DefaultProfileID:              user.DefaultProfileID,
+		DefaultSummaryTemplateID:      user.DefaultSummaryTemplateID,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -3502,6 +3992,17 @@ func (h *Handler) UpdateUserSettings(c *gin.Context) {
 	if req.AutoChatTitleEnabled != nil {
 		user.AutoChatTitleEnabled = *req.AutoChatTitleEnabled
 	}
+	if req.DefaultSummaryTemplateID != nil {
+		if *req.DefaultSummaryTemplateID == "" {
+			user.DefaultSummaryTemplateID = nil
+		} else {
+			if _, err := h.summaryRepo.FindByID(c.Request.Context(), *req.DefaultSummaryTemplateID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Summary template not found"})
+				return
+			}
+			user.DefaultSummaryTemplateID = req.DefaultSummaryTemplateID
+		}
+	}
 
 	// Save updated user
 	if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
@@ -3515,17 +4016,68 @@ func (h *Handler) UpdateUserSettings(c *gin.Context) {
 		AutoTranscriptionTitleEnabled: user.AutoTranscriptionTitleEnabled,
 		AutoChatTitleEnabled:          user.AutoChatTitleEnabled,
 		DefaultProfileID:              user.DefaultProfileID,
+		DefaultSummaryTemplateID:      user.DefaultSummaryTemplateID,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
 // @Summary SSE Events
-// @Description Subscribe to server-sent events
+// @Description Subscribe to server-sent events. With job_id, streams the progress of one job. Without it, streams the current user's events across all jobs: job_created, job_imported, job_update, queue_update, summary_created and title_updated. User events carry IDs; reconnect with the Last-Event-ID header (or last_event_id) to receive missed events, or a resync event when they are no longer buffered.
 // @Tags events
 // @Produce text/event-stream
+// @Param job_id query string false "Job ID"
+// @Param last_event_id query string false "Resume the user stream after this event ID"
 // @Success 200 {string} string "stream"
 // @Router /api/v1/events [get]
 func (h *Handler) Events(c *gin.Context) {
+	jobID := c.Query("job_id")
+	if jobID == "" {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return
+		}
+		h.broadcaster.ServeUser(c.Writer, c.Request, userID)
+		return
+	}
+	if _, ok := h.findOwnedJob(c, jobID); !ok {
+		return
+	}
 	h.broadcaster.ServeHTTP(c.Writer, c.Request)
 }
+
+// announceJobCreated tells the owner of a new job about it on their event
+// stream and webhook subscriptions
+func (h *Handler) announceJobCreated(ctx context.Context, job *models.TranscriptionJob) {
+	h.broadcaster.BroadcastToUser(job.UserID, "job_created", map[string]interface{}{
+		"job_id": job.ID,
+		"title":  job.Title,
+		"status": job.Status,
+	})
+	h.webhookDispatcher.Publish(ctx, job.UserID, webhook.EventJobCreated, webhook.NewJobEventData(job))
+}
+
+// announceSummaryCreated tells the owner of a job about its new summary on
+// their event stream and webhook subscriptions
+func (h *Handler) announceSummaryCreated(ctx context.Context, summary *models.Summary) {
+	job, err := h.jobRepo.FindByID(ctx, summary.TranscriptionID)
+	if err != nil {
+		logger.Warn("Failed to load job for summary event", "job_id", summary.TranscriptionID, "error", err)
+		return
+	}
+	h.broadcaster.BroadcastToUser(job.UserID, "summary_created", map[string]interface{}{
+		"job_id":      job.ID,
+		"summary_id":  summary.ID,
+		"model":       summary.Model,
+		"template_id": summary.TemplateID,
+	})
+	h.webhookDispatcher.Publish(ctx, job.UserID, webhook.EventSummaryCreated, webhook.NewSummaryEventData(summary))
+}
+
+// announceTitle tells the owner of a job about its new title on their event stream
+func (h *Handler) announceTitle(job *models.TranscriptionJob) {
+	h.broadcaster.BroadcastToUser(job.UserID, "title_updated", map[string]interface{}{
+		"job_id": job.ID,
+		"title":  job.Title,
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"gorm.io/gorm"
 
 	"scriberr/internal/models"
+	"scriberr/internal/webhook"
 )
 
 // NoteCreateRequest is the payload for creating a note
@@ -108,7 +109,7 @@ func (h *Handler) CreateNote(c *gin.Context) {
 	}
 
 	// Ensure transcription exists
-	_, err := h.jobRepo.FindByID(c.Request.Context(), transcriptionID)
+	job, err := h.jobRepo.FindByID(c.Request.Context(), transcriptionID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			log.Printf("notes.CreateNote: transcription %s not found", transcriptionID)
@@ -139,6 +140,11 @@ func (h *Handler) CreateNote(c *gin.Context) {
 		return
 	}
 
+	if err := h.searchRepo.IndexNote(c.Request.Context(), n); err != nil {
+		log.Printf("notes.CreateNote: failed to index note %s for search: %v", n.ID, err)
+	}
+	h.webhookDispatcher.Publish(c.Request.Context(), job.UserID, webhook.EventNoteCreated, webhook.NewNoteEventData(n))
+
 	log.Printf("notes.CreateNote: created note %s for transcription %s (start=%d end=%d startTime=%.3f endTime=%.3f quoteLen=%d)", n.ID, transcriptionID, n.StartWordIndex, n.EndWordIndex, n.StartTime, n.EndTime, len(n.Quote))
 	// Tests expect 200 on creation
 	c.JSON(http.StatusOK, n)
@@ -157,7 +163,11 @@ func (h *Handler) CreateNote(c *gin.Context) {
 // @Router /api/v1/notes/{note_id} [get]
 func (h *Handler) GetNote(c *gin.Context) {
 	noteID := c.Param("note_id")
-	n, err := h.noteRepo.FindByID(c.Request.Context(), noteID)
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	n, err := h.noteRepo.FindByIDForUser(c.Request.Context(), noteID, userID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
@@ -191,7 +201,11 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 		return
 	}
 
-	n, err := h.noteRepo.FindByID(c.Request.Context(), noteID)
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	n, err := h.noteRepo.FindByIDForUser(c.Request.Context(), noteID, userID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
@@ -208,6 +222,9 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update note"})
 		return
 	}
+	if err := h.searchRepo.IndexNote(c.Request.Context(), n); err != nil {
+		log.Printf("notes.UpdateNote: failed to index note %s for search: %v", n.ID, err)
+	}
 
 	c.JSON(http.StatusOK, n)
 }
@@ -224,10 +241,25 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 // @Router /api/v1/notes/{note_id} [delete]
 func (h *Handler) DeleteNote(c *gin.Context) {
 	noteID := c.Param("note_id")
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if _, err := h.noteRepo.FindByIDForUser(c.Request.Context(), noteID, userID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch note"})
+		return
+	}
 	if err := h.noteRepo.Delete(c.Request.Context(), noteID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete note"})
 		return
 	}
+	if err := h.searchRepo.RemoveNote(c.Request.Context(), noteID); err != nil {
+		log.Printf("notes.DeleteNote: failed to remove note %s from search: %v", noteID, err)
+	}
 	// Tests expect 200 on deletion
 	c.JSON(http.StatusOK, gin.H{"message": "Note deleted"})
 }
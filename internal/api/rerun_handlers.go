@@ -0,0 +1,329 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"scriberr/internal/models"
+	"scriberr/internal/queue"
+	"scriberr/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RerunRequest starts another transcription of an existing job. The
+// parameters of the profile, or the job's current parameters when no profile
+// is given, are used with the overrides in Parameters applied on top.
+type RerunRequest struct {
+	ProfileID  *string         `json:"profile_id,omitempty"`
+	Parameters json.RawMessage `json:"parameters,omitempty" swaggertype:"object"` // Partial models.WhisperXParams
+}
+
+// ExecutionResultResponse describes one transcription run of a job
+type ExecutionResultResponse struct {
+	ID                 string                `json:"id"`
+	Attempt            int                   `json:"attempt"`
+	Status             models.JobStatus      `json:"status"`
+	ErrorMessage       *string               `json:"error_message,omitempty"`
+	StartedAt          time.Time             `json:"started_at"`
+	CompletedAt        *time.Time            `json:"completed_at,omitempty"`
+	ProcessingDuration *int64                `json:"processing_duration,omitempty"`
+	ActualParameters   models.WhisperXParams `json:"actual_parameters"`
+	HasTranscript      bool                  `json:"has_transcript"`
+	Active             bool                  `json:"active"` // Whether this result is the job's current transcript
+}
+
+// @Summary Re-transcribe a job
+// @Description Transcribe a completed or failed job again, e.g. with another model or profile. The current result is kept as an alternate and the new result becomes active when it completes.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param request body RerunRequest false "Profile and parameter overrides"
+// @Success 200 {object} models.TranscriptionJob
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/{id}/rerun [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) RerunTranscription(c *gin.Context) {
+	// The body is optional; without one the job runs again with its current parameters
+	var req RerunRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	job, ok := h.findOwnedJob(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if job.Status != models.StatusCompleted && job.Status != models.StatusFailed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only completed or failed jobs can be transcribed again"})
+		return
+	}
+
+	params := job.Parameters
+	var profile *models.TranscriptionProfile
+	if req.ProfileID != nil && *req.ProfileID != "" {
+		var err error
+		profile, err = h.profileRepo.FindByID(c.Request.Context(), *req.ProfileID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get profile"})
+			return
+		}
+		params = profile.Parameters
+	}
+	if len(req.Parameters) > 0 && string(req.Parameters) != "null" {
+		if err := json.Unmarshal(req.Parameters, &params); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parameters: " + err.Error()})
+			return
+		}
+	}
+	if err := h.validateTranscriptionParams(c, job, &params, "rerun"); err != nil {
+		return
+	}
+
+	if err := h.preserveActiveResult(c.Request.Context(), job); err != nil {
+		logger.Error("Failed to keep current transcription result", "job_id", job.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to keep current transcription result"})
+		return
+	}
+
+	job.Parameters = params
+	job.Diarization = params.Diarize
+	if profile != nil && job.Priority == 0 {
+		job.Priority = profile.Priority
+	}
+	job.ErrorMessage = nil
+
+	// Save and enqueue in one step, unless the job was started again meanwhile
+	if err := h.taskQueue.RequeueJob(job, models.StatusCompleted, models.StatusFailed); err != nil {
+		if errors.Is(err, queue.ErrJobNotQueueable) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Only completed or failed jobs can be transcribed again"})
+			return
+		}
+		logger.Error("Failed to enqueue job", "job_id", job.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enqueue job"})
+		return
+	}
+
+	// Edits were made against the current transcript; the new one starts a fresh history
+	if err := h.revisionRepo.DeleteByJob(c.Request.Context(), job.ID); err != nil {
+		logger.Warn("Failed to clear transcript revisions", "job_id", job.ID, "error", err)
+	}
+
+	logger.Info("Re-transcription queued", "job_id", job.ID, "model_family", params.ModelFamily, "model", params.Model)
+	c.JSON(http.StatusOK, job)
+}
+
+// @Summary List transcription results of a job
+// @Description List every transcription run of a job, oldest first, and which result is the active transcript
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {array} ExecutionResultResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/{id}/executions [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListJobExecutions(c *gin.Context) {
+	job, ok := h.findOwnedJob(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	executions, err := h.jobRepo.ListExecutions(c.Request.Context(), job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get executions"})
+		return
+	}
+
+	response := make([]ExecutionResultResponse, 0, len(executions))
+	for _, execution := range executions {
+		response = append(response, ExecutionResultResponse{
+			ID:                 execution.ID,
+			Attempt:            execution.Attempt,
+			Status:             execution.Status,
+			ErrorMessage:       execution.ErrorMessage,
+			StartedAt:          execution.StartedAt,
+			CompletedAt:        execution.CompletedAt,
+			ProcessingDuration: execution.ProcessingDuration,
+			ActualParameters:   execution.ActualParameters,
+			HasTranscript:      execution.Transcript != nil,
+			Active:             job.ActiveExecutionID != nil && *job.ActiveExecutionID == execution.ID,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get the transcript of a transcription result
+// @Description Get the transcript produced by one run of a job, active or not
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param executionId path string true "Execution ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/{id}/executions/{executionId}/transcript [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetExecutionTranscript(c *gin.Context) {
+	execution, ok := h.findJobExecution(c)
+	if !ok {
+		return
+	}
+	if execution.Transcript == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Execution has no transcript"})
+		return
+	}
+
+	var transcript interface{}
+	if err := json.Unmarshal([]byte(*execution.Transcript), &transcript); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse transcript"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job_id":            execution.TranscriptionJobID,
+		"execution_id":      execution.ID,
+		"actual_parameters": execution.ActualParameters,
+		"transcript":        transcript,
+	})
+}
+
+// @Summary Make a transcription result the active transcript
+// @Description Switch the job's transcript to the result of another run. The current transcript, including manual edits, is kept with its own run.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param executionId path string true "Execution ID"
+// @Success 200 {object} models.TranscriptionJob
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/{id}/executions/{executionId}/activate [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ActivateExecution(c *gin.Context) {
+	job, ok := h.findOwnedJob(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if job.Status != models.StatusCompleted && job.Status != models.StatusFailed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot switch transcripts while the job is being transcribed"})
+		return
+	}
+
+	execution, ok := h.findJobExecution(c)
+	if !ok {
+		return
+	}
+	if execution.Status != models.StatusCompleted || execution.Transcript == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Execution has no transcript"})
+		return
+	}
+	if job.Status == models.StatusCompleted && job.ActiveExecutionID != nil && *job.ActiveExecutionID == execution.ID {
+		c.JSON(http.StatusOK, job)
+		return
+	}
+
+	if err := h.preserveActiveResult(c.Request.Context(), job); err != nil {
+		logger.Error("Failed to keep current transcription result", "job_id", job.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to keep current transcription result"})
+		return
+	}
+
+	job.Transcript = execution.Transcript
+	job.ActiveExecutionID = &execution.ID
+	job.Parameters = execution.ActualParameters
+	job.Diarization = execution.ActualParameters.Diarize
+	job.Status = models.StatusCompleted
+	job.ErrorMessage = nil
+	if err := h.jobRepo.Update(c.Request.Context(), job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update job"})
+		return
+	}
+
+	if err := h.revisionRepo.DeleteByJob(c.Request.Context(), job.ID); err != nil {
+		logger.Warn("Failed to clear transcript revisions", "job_id", job.ID, "error", err)
+	}
+	h.refreshTranscriptDerivatives(job.ID)
+
+	c.JSON(http.StatusOK, job)
+}
+
+// findJobExecution loads the execution in the path, which must belong to the job in the path
+func (h *Handler) findJobExecution(c *gin.Context) (*models.TranscriptionJobExecution, bool) {
+	execution, err := h.jobRepo.FindExecution(c.Request.Context(), c.Param("id"), c.Param("executionId"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Execution not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get execution"})
+		return nil, false
+	}
+	return execution, true
+}
+
+// preserveActiveResult stores the current transcript of a job, including any
+// manual edits, with the execution that produced it so it survives as an
+// alternate. Jobs transcribed before results were kept per execution get it
+// attached to their latest completed execution, or to a new one.
+func (h *Handler) preserveActiveResult(ctx context.Context, job *models.TranscriptionJob) error {
+	if job.Transcript == nil {
+		return nil
+	}
+
+	var execution *models.TranscriptionJobExecution
+	var err error
+	if job.ActiveExecutionID != nil {
+		execution, err = h.jobRepo.FindExecution(ctx, job.ID, *job.ActiveExecutionID)
+	} else {
+		execution, err = h.jobRepo.FindLatestCompletedExecution(ctx, job.ID)
+		if err == nil && execution.Transcript != nil {
+			// Already kept; the job's transcript is from a run without an execution record
+			execution, err = nil, gorm.ErrRecordNotFound
+		}
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if execution == nil {
+		completedAt := job.UpdatedAt
+		execution = &models.TranscriptionJobExecution{
+			TranscriptionJobID: job.ID,
+			StartedAt:          job.UpdatedAt,
+			CompletedAt:        &completedAt,
+			ActualParameters:   job.Parameters,
+			Status:             models.StatusCompleted,
+			Transcript:         job.Transcript,
+		}
+		if err := h.jobRepo.CreateExecution(ctx, execution); err != nil {
+			return err
+		}
+	} else {
+		execution.Transcript = job.Transcript
+		if err := h.jobRepo.UpdateExecution(ctx, execution); err != nil {
+			return err
+		}
+	}
+
+	job.ActiveExecutionID = &execution.ID
+	return nil
+}
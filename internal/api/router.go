@@ -2,6 +2,7 @@ package api
 
 import (
 	"scriberr/internal/auth"
+	"scriberr/internal/models"
 	"scriberr/internal/web"
 	"scriberr/pkg/logger"
 	"scriberr/pkg/middleware"
@@ -69,6 +70,13 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 	router.GET("/install.sh", handler.GetInstallScript)
 	router.GET("/install-cli.sh", handler.GetInstallScript)
 
+	// Restricts a route to admin accounts; must follow an auth middleware
+	adminOnly := middleware.RequireAdmin()
+
+	// API key scopes; reads and writes of a group may need different scopes
+	transcriptionScope := middleware.RequireMethodScope(models.ScopeTranscriptionRead, models.ScopeTranscriptionWrite)
+	sharedConfigScope := middleware.RequireMethodScope(models.ScopeTranscriptionRead, models.ScopeAdmin)
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
@@ -116,7 +124,9 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 
 		// Transcription routes (require authentication)
 		transcription := v1.Group("/transcription")
-		transcription.Use(middleware.AuthMiddleware(authService))
+		transcription.Use(middleware.AuthMiddleware(authService), transcriptionScope)
+		// Routes addressing a single job are restricted to the job's owner
+		jobOwner := handler.RequireJobOwner("id")
 		{
 			// File upload routes - disable compression for these
 			uploadRoutes := transcription.Group("")
@@ -125,50 +135,106 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 				uploadRoutes.POST("/upload", handler.UploadAudio)
 				uploadRoutes.POST("/upload-video", handler.UploadVideo)
 				uploadRoutes.POST("/upload-multitrack", handler.UploadMultiTrack)
-				uploadRoutes.GET("/:id/audio", handler.GetAudioFile) // Audio streaming shouldn't be compressed
+				uploadRoutes.GET("/:id/audio", jobOwner, handler.GetAudioFile) // Audio streaming shouldn't be compressed
 			}
 
 			// Regular API routes with compression
 			transcription.POST("/youtube", handler.DownloadFromYouTube)
 			transcription.POST("/submit", handler.SubmitJob)
-			transcription.POST("/:id/start", handler.StartTranscription)
-			transcription.POST("/:id/kill", handler.KillJob)
-			transcription.GET("/:id/logs", handler.GetJobLogs)
-			transcription.GET("/:id/status", handler.GetJobStatus)
-			transcription.GET("/:id/transcript", handler.GetTranscript)
-			transcription.GET("/:id/execution", handler.GetJobExecutionData)
-			transcription.GET("/:id/merge-status", handler.GetMergeStatus)
-			transcription.GET("/:id/track-progress", handler.GetTrackProgress)
-			transcription.PUT("/:id/title", handler.UpdateTranscriptionTitle)
-			transcription.POST("/:id/title/auto", handler.AutoGenerateTranscriptionTitle)
-			transcription.GET("/:id/summary", handler.GetSummaryForTranscription)
-			transcription.GET("/:id", handler.GetTranscriptionJob)
-			transcription.DELETE("/:id", handler.DeleteTranscriptionJob)
+			transcription.POST("/:id/start", jobOwner, handler.StartTranscription)
+			transcription.POST("/:id/kill", jobOwner, handler.KillJob)
+			transcription.POST("/:id/rerun", jobOwner, handler.RerunTranscription)
+			transcription.GET("/:id/executions", jobOwner, handler.ListJobExecutions)
+			transcription.GET("/:id/executions/:executionId/transcript", jobOwner, handler.GetExecutionTranscript)
+			transcription.POST("/:id/executions/:executionId/activate", jobOwner, handler.ActivateExecution)
+			transcription.POST("/:id/comparisons", jobOwner, handler.CreateModelComparison)
+			transcription.GET("/:id/comparisons", jobOwner, handler.ListModelComparisons)
+			transcription.GET("/:id/comparisons/:comparisonId", jobOwner, handler.GetModelComparison)
+			transcription.DELETE("/:id/comparisons/:comparisonId", jobOwner, handler.DeleteModelComparison)
+			transcription.POST("/:id/comparisons/:comparisonId/cancel", jobOwner, handler.CancelModelComparison)
+			transcription.GET("/:id/logs", jobOwner, handler.GetJobLogs)
+			transcription.GET("/:id/status", jobOwner, handler.GetJobStatus)
+			transcription.GET("/:id/transcript", jobOwner, handler.GetTranscript)
+			transcription.GET("/:id/transcript/revisions", jobOwner, handler.ListTranscriptRevisions)
+			transcription.POST("/:id/transcript/revert", jobOwner, handler.RevertTranscript)
+			transcription.PUT("/:id/transcript/segments/:index/text", jobOwner, handler.EditTranscriptSegmentText)
+			transcription.PUT("/:id/transcript/segments/:index/speaker", jobOwner, handler.SetTranscriptSegmentSpeaker)
+			transcription.POST("/:id/transcript/segments/:index/split", jobOwner, handler.SplitTranscriptSegment)
+			transcription.POST("/:id/transcript/segments/:index/merge", jobOwner, handler.MergeTranscriptSegments)
+			transcription.GET("/:id/export", jobOwner, handler.ExportTranscript)
+			transcription.GET("/:id/analytics", jobOwner, handler.GetTranscriptAnalytics)
+			transcription.GET("/:id/execution", jobOwner, handler.GetJobExecutionData)
+			transcription.GET("/:id/merge-status", jobOwner, handler.GetMergeStatus)
+			transcription.GET("/:id/track-progress", jobOwner, handler.GetTrackProgress)
+			transcription.PUT("/:id/title", jobOwner, handler.UpdateTranscriptionTitle)
+			transcription.POST("/:id/title/auto", jobOwner, handler.AutoGenerateTranscriptionTitle)
+			transcription.GET("/:id/summary", jobOwner, handler.GetSummaryForTranscription)
+			transcription.GET("/:id", jobOwner, handler.GetTranscriptionJob)
+			transcription.DELETE("/:id", jobOwner, handler.DeleteTranscriptionJob)
 			transcription.GET("/list", handler.ListTranscriptionJobs)
 			transcription.GET("/models", handler.GetSupportedModels)
 			// Notes for a transcription
-			transcription.GET("/:id/notes", handler.ListNotes)
-			transcription.POST("/:id/notes", handler.CreateNote)
+			transcription.GET("/:id/notes", jobOwner, handler.ListNotes)
+			transcription.POST("/:id/notes", jobOwner, handler.CreateNote)
 
 			// Speaker mappings for a transcription
-			transcription.GET("/:id/speakers", handler.GetSpeakerMappings)
-			transcription.POST("/:id/speakers", handler.UpdateSpeakerMappings)
+			transcription.GET("/:id/speakers", jobOwner, handler.GetSpeakerMappings)
+			transcription.POST("/:id/speakers", jobOwner, handler.UpdateSpeakerMappings)
+			transcription.GET("/:id/speaker-matches", jobOwner, handler.GetSpeakerMatches)
+			transcription.POST("/:id/speaker-matches/apply", jobOwner, handler.ApplySpeakerMatches)
 
 			// Quick transcription endpoints
 			transcription.POST("/quick", handler.SubmitQuickTranscription)
 			transcription.GET("/quick/:id", handler.GetQuickTranscriptionStatus)
 		}
 
-		// Profile routes (require authentication)
+		// Profiles are shared by all users; only admins may change them
 		profiles := v1.Group("/profiles")
-		profiles.Use(middleware.AuthMiddleware(authService))
+		profiles.Use(middleware.AuthMiddleware(authService), sharedConfigScope)
 		{
 			profiles.GET("/", handler.ListProfiles)
-			profiles.POST("/", handler.CreateProfile)
+			profiles.POST("/", adminOnly, handler.CreateProfile)
 			profiles.GET("/:id", handler.GetProfile)
-			profiles.PUT("/:id", handler.UpdateProfile)
-			profiles.DELETE("/:id", handler.DeleteProfile)
-			profiles.POST("/:id/set-default", handler.SetDefaultProfile)
+			profiles.PUT("/:id", adminOnly, handler.UpdateProfile)
+			profiles.DELETE("/:id", adminOnly, handler.DeleteProfile)
+			profiles.POST("/:id/set-default", adminOnly, handler.SetDefaultProfile)
+		}
+
+		// Custom vocabularies, owned by the user that created them
+		vocabularies := v1.Group("/vocabularies")
+		vocabularies.Use(middleware.AuthMiddleware(authService), transcriptionScope)
+		{
+			vocabularies.GET("", handler.ListVocabularies)
+			vocabularies.POST("", handler.CreateVocabulary)
+			vocabularies.GET("/:id", handler.GetVocabulary)
+			vocabularies.PUT("/:id", handler.UpdateVocabulary)
+			vocabularies.DELETE("/:id", handler.DeleteVocabulary)
+		}
+
+		// Speaker library, owned by the user that enrolled the speakers
+		speakerLibrary := v1.Group("/speakers")
+		speakerLibrary.Use(middleware.AuthMiddleware(authService), transcriptionScope)
+		{
+			speakerLibrary.GET("", handler.ListSpeakers)
+			speakerLibrary.POST("", handler.CreateSpeaker)
+			speakerLibrary.GET("/:id", handler.GetSpeaker)
+			speakerLibrary.PUT("/:id", handler.UpdateSpeaker)
+			speakerLibrary.DELETE("/:id", handler.DeleteSpeaker)
+			speakerLibrary.POST("/:id/samples", handler.AddSpeakerSample)
+			speakerLibrary.POST("/:id/merge", handler.MergeSpeakers)
+		}
+
+		// Webhook subscriptions, owned by the user that created them
+		webhooks := v1.Group("/webhooks")
+		webhooks.Use(middleware.AuthMiddleware(authService), transcriptionScope)
+		{
+			webhooks.GET("", handler.ListWebhookSubscriptions)
+			webhooks.POST("", handler.CreateWebhookSubscription)
+			webhooks.GET("/:id", handler.GetWebhookSubscription)
+			webhooks.PUT("/:id", handler.UpdateWebhookSubscription)
+			webhooks.DELETE("/:id", handler.DeleteWebhookSubscription)
+			webhooks.GET("/:id/deliveries", handler.ListWebhookDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", handler.RedeliverWebhook)
 		}
 
 		// User routes (require authentication)
@@ -191,77 +257,102 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 			watchFolders.DELETE("/:id", handler.DeleteWatchFolder)
 		}
 
-		// Admin routes (require authentication)
+		// Admin routes (require an admin account)
 		admin := v1.Group("/admin")
-		admin.Use(middleware.AuthMiddleware(authService))
+		admin.Use(middleware.AuthMiddleware(authService), middleware.RequireScope(models.ScopeAdmin), adminOnly)
 		{
 			queue := admin.Group("/queue")
 			{
+				queue.GET("", handler.GetQueue)
 				queue.GET("/stats", handler.GetQueueStats)
+				queue.POST("/pause", handler.PauseQueue)
+				queue.POST("/resume", handler.ResumeQueue)
+				queue.POST("/jobs/:id/pause", handler.PauseQueuedJob)
+				queue.POST("/jobs/:id/resume", handler.ResumeQueuedJob)
+				queue.POST("/jobs/:id/move-to-front", handler.MoveQueuedJobToFront)
+			}
+
+			users := admin.Group("/users")
+			{
+				users.GET("", handler.ListUsers)
+				users.POST("", handler.InviteUser)
+				users.POST("/:id/disable", handler.DisableUser)
+				users.POST("/:id/enable", handler.EnableUser)
+				users.DELETE("/:id", handler.DeleteUser)
 			}
 		}
 
-		// LLM configuration routes (require authentication)
+		// LLM configuration routes (global config, changes are admin only)
 		llm := v1.Group("/llm")
-		llm.Use(middleware.AuthMiddleware(authService))
+		llm.Use(middleware.AuthMiddleware(authService), middleware.RequireMethodScope(models.ScopeChat, models.ScopeAdmin))
 		{
 			llm.GET("/config", handler.GetLLMConfig)
-			llm.POST("/config", handler.SaveLLMConfig)
+			llm.POST("/config", adminOnly, handler.SaveLLMConfig)
 		}
 
-		// Summarization templates routes (require authentication)
+		// Summarization templates routes (shared templates, changes are admin only)
 		summaries := v1.Group("/summaries")
-		summaries.Use(middleware.AuthMiddleware(authService))
+		summaries.Use(middleware.AuthMiddleware(authService), sharedConfigScope)
 		{
 			summaries.GET("/", handler.ListSummaryTemplates)
-			summaries.POST("/", handler.CreateSummaryTemplate)
+			summaries.POST("/", adminOnly, handler.CreateSummaryTemplate)
 			summaries.GET("/:id", handler.GetSummaryTemplate)
-			summaries.PUT("/:id", handler.UpdateSummaryTemplate)
-			summaries.DELETE("/:id", handler.DeleteSummaryTemplate)
+			summaries.PUT("/:id", adminOnly, handler.UpdateSummaryTemplate)
+			summaries.DELETE("/:id", adminOnly, handler.DeleteSummaryTemplate)
 			summaries.GET("/settings", handler.GetSummarySettings)
-			summaries.POST("/settings", handler.SaveSummarySettings)
+			summaries.POST("/settings", adminOnly, handler.SaveSummarySettings)
 		}
 
 		// Chat routes (require authentication)
 		chat := v1.Group("/chat")
-		chat.Use(middleware.AuthMiddleware(authService))
+		chat.Use(middleware.AuthMiddleware(authService), middleware.RequireScope(models.ScopeChat))
 		{
 			chat.GET("/models", handler.GetChatModels)
 			chat.POST("/sessions", handler.CreateChatSession)
-			chat.GET("/transcriptions/:transcription_id/sessions", handler.GetChatSessions)
-			chat.GET("/sessions/:session_id", handler.GetChatSession)
-			chat.POST("/sessions/:session_id/messages", handler.SendChatMessage)
-			chat.PUT("/sessions/:session_id/title", handler.UpdateChatSessionTitle)
-			chat.POST("/sessions/:session_id/title/auto", handler.AutoGenerateChatTitle)
-			chat.DELETE("/sessions/:session_id", handler.DeleteChatSession)
+			chat.GET("/transcriptions/:transcription_id/sessions", handler.RequireJobOwner("transcription_id"), handler.GetChatSessions)
+			chat.GET("/library/sessions", handler.GetLibraryChatSessions)
+			sessionOwner := handler.RequireChatSessionOwner("session_id")
+			chat.GET("/sessions/:session_id", sessionOwner, handler.GetChatSession)
+			chat.POST("/sessions/:session_id/messages", sessionOwner, handler.SendChatMessage)
+			chat.PUT("/sessions/:session_id/title", sessionOwner, handler.UpdateChatSessionTitle)
+			chat.POST("/sessions/:session_id/title/auto", sessionOwner, handler.AutoGenerateChatTitle)
+			chat.DELETE("/sessions/:session_id", sessionOwner, handler.DeleteChatSession)
 		}
 
 		// Notes routes (require authentication)
 		notes := v1.Group("/notes")
-		notes.Use(middleware.AuthMiddleware(authService))
+		notes.Use(middleware.AuthMiddleware(authService), transcriptionScope)
 		{
 			notes.GET("/:note_id", handler.GetNote)
 			notes.PUT("/:note_id", handler.UpdateNote)
 			notes.DELETE("/:note_id", handler.DeleteNote)
 		}
 
+		// Full-text search (require authentication)
+		search := v1.Group("/search")
+		search.Use(middleware.AuthMiddleware(authService), middleware.RequireScope(models.ScopeTranscriptionRead))
+		{
+			search.GET("", handler.Search)
+			search.GET("/semantic", handler.SemanticSearch)
+		}
+
 		// Summarization route (require authentication)
 		summarize := v1.Group("/summarize")
-		summarize.Use(middleware.AuthMiddleware(authService))
+		summarize.Use(middleware.AuthMiddleware(authService), middleware.RequireScope(models.ScopeChat))
 		{
 			summarize.POST("/", handler.Summarize)
 		}
 
 		// Config routes (require authentication)
 		config := v1.Group("/config")
-		config.Use(middleware.AuthMiddleware(authService))
+		config.Use(middleware.AuthMiddleware(authService), middleware.RequireScope(models.ScopeAdmin))
 		{
 			config.POST("/openai/validate", handler.ValidateOpenAIKey)
 		}
 
 		// SSE Events (require authentication)
 		events := v1.Group("/events")
-		events.Use(middleware.AuthMiddleware(authService))
+		events.Use(middleware.AuthMiddleware(authService), middleware.RequireScope(models.ScopeTranscriptionRead))
 		{
 			events.GET("/", handler.Events)
 		}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"scriberr/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchResponse is a page of full-text search hits
+type SearchResponse struct {
+	Query   string             `json:"query"`
+	Results []models.SearchHit `json:"results"`
+	Total   int64              `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+}
+
+// @Summary Search transcripts
+// @Description Full-text search across transcript segments, notes and summaries of the caller's transcriptions. Results carry segment timestamps and speaker so clients can seek to the hit; the snippet is HTML-escaped with matches wrapped in <mark> tags.
+// @Tags search
+// @Produce json
+// @Param q query string true "Search text; use double quotes for exact phrases"
+// @Param type query string false "Restrict to segment, note or summary"
+// @Param limit query int false "Maximum results (default 20, max 100)"
+// @Param offset query int false "Results to skip"
+// @Success 200 {object} SearchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/search [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) Search(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		return
+	}
+
+	kind := c.Query("type")
+	switch kind {
+	case "", models.SearchKindSegment, models.SearchKindNote, models.SearchKindSummary:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of segment, note or summary"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+		return
+	}
+
+	hits, total, err := h.searchRepo.Search(c.Request.Context(), userID, query, kind, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search transcripts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SearchResponse{
+		Query:   query,
+		Results: hits,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
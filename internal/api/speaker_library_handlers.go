@@ -0,0 +1,465 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"scriberr/internal/models"
+	"scriberr/internal/rag"
+	"scriberr/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnrollSpeakerRequest creates a library speaker, optionally enrolling the
+// voice of a speaker label in one of the user's jobs
+type EnrollSpeakerRequest struct {
+	Name         string `json:"name" binding:"required"`
+	JobID        string `json:"job_id,omitempty"`
+	SpeakerLabel string `json:"speaker_label,omitempty"`
+}
+
+// UpdateSpeakerRequest renames a library speaker
+type UpdateSpeakerRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// SpeakerSampleRequest identifies a speaker label of a job whose voice is
+// added to a library speaker
+type SpeakerSampleRequest struct {
+	JobID        string `json:"job_id" binding:"required"`
+	SpeakerLabel string `json:"speaker_label" binding:"required"`
+}
+
+// MergeSpeakersRequest lists the duplicate speakers to merge into another
+type MergeSpeakersRequest struct {
+	SpeakerIDs []string `json:"speaker_ids" binding:"required,min=1"`
+}
+
+// SpeakerMatchResponse is a speaker label of a job with the library speaker
+// its voice matched, if any, and the name it currently has in the job
+type SpeakerMatchResponse struct {
+	Label       string   `json:"label"`
+	Model       string   `json:"model"`
+	SpeakerID   *string  `json:"speaker_id,omitempty"`
+	SpeakerName *string  `json:"speaker_name,omitempty"`
+	Similarity  *float64 `json:"similarity,omitempty"` // Omitted when the user enrolled the label themselves
+	CurrentName *string  `json:"current_name,omitempty"`
+}
+
+// @Summary List speakers
+// @Description List the speakers enrolled in the current user's speaker library
+// @Tags speakers
+// @Produce json
+// @Success 200 {array} models.Speaker
+// @Router /api/v1/speakers [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListSpeakers(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.speakerRepo.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list speakers"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary Enroll speaker
+// @Description Add a speaker to the library. When job_id and speaker_label are given, the voice of that label is enrolled and the label is named in the job. Jobs transcribed with speaker_embeddings enabled are then matched against the speaker.
+// @Tags speakers
+// @Accept json
+// @Produce json
+// @Param speaker body EnrollSpeakerRequest true "Speaker"
+// @Success 201 {object} models.Speaker
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/speakers [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateSpeaker(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req EnrollSpeakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Speaker name is required"})
+		return
+	}
+	if (req.JobID == "") != (req.SpeakerLabel == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "job_id and speaker_label must be given together"})
+		return
+	}
+
+	// Check the sample before creating the speaker so a bad one leaves nothing behind
+	var sample *models.JobSpeaker
+	if req.JobID != "" {
+		if sample, ok = h.findJobVoice(c, req.JobID, req.SpeakerLabel); !ok {
+			return
+		}
+	}
+
+	speaker := models.Speaker{UserID: userID, Name: name}
+	if err := h.speakerRepo.Create(c.Request.Context(), &speaker); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create speaker"})
+		return
+	}
+	if sample != nil && !h.enrollJobVoice(c, &speaker, sample) {
+		// A failed enrollment leaves nothing behind either
+		if err := h.speakerRepo.Delete(context.WithoutCancel(c.Request.Context()), speaker.ID); err != nil {
+			logger.Warn("Failed to remove speaker after failed enrollment", "speaker_id", speaker.ID, "error", err)
+		}
+		return
+	}
+	h.respondWithSpeaker(c, http.StatusCreated, userID, speaker.ID)
+}
+
+// @Summary Get speaker
+// @Description Get a speaker of the current user's speaker library
+// @Tags speakers
+// @Produce json
+// @Param id path string true "Speaker ID"
+// @Success 200 {object} models.Speaker
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/speakers/{id} [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetSpeaker(c *gin.Context) {
+	speaker, ok := h.findOwnedSpeaker(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, speaker)
+}
+
+// @Summary Rename speaker
+// @Description Rename a library speaker. Jobs already named after the speaker keep the old name.
+// @Tags speakers
+// @Accept json
+// @Produce json
+// @Param id path string true "Speaker ID"
+// @Param speaker body UpdateSpeakerRequest true "Speaker"
+// @Success 200 {object} models.Speaker
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/speakers/{id} [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateSpeaker(c *gin.Context) {
+	speaker, ok := h.findOwnedSpeaker(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req UpdateSpeakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if speaker.Name = strings.TrimSpace(req.Name); speaker.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Speaker name is required"})
+		return
+	}
+	if err := h.speakerRepo.Update(c.Request.Context(), speaker); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update speaker"})
+		return
+	}
+	c.JSON(http.StatusOK, speaker)
+}
+
+// @Summary Delete speaker
+// @Description Delete a library speaker and its voice prints. Jobs keep the names they were given.
+// @Tags speakers
+// @Param id path string true "Speaker ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/speakers/{id} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteSpeaker(c *gin.Context) {
+	speaker, ok := h.findOwnedSpeaker(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := h.speakerRepo.Delete(c.Request.Context(), speaker.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete speaker"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Add speaker sample
+// @Description Average the voice of a speaker label in a job into a library speaker's voice print and name the label after the speaker
+// @Tags speakers
+// @Accept json
+// @Produce json
+// @Param id path string true "Speaker ID"
+// @Param sample body SpeakerSampleRequest true "Sample"
+// @Success 200 {object} models.Speaker
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/speakers/{id}/samples [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) AddSpeakerSample(c *gin.Context) {
+	speaker, ok := h.findOwnedSpeaker(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req SpeakerSampleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	sample, ok := h.findJobVoice(c, req.JobID, req.SpeakerLabel)
+	if !ok {
+		return
+	}
+	if !h.enrollJobVoice(c, speaker, sample) {
+		return
+	}
+	h.respondWithSpeaker(c, http.StatusOK, speaker.UserID, speaker.ID)
+}
+
+// @Summary Merge speakers
+// @Description Merge duplicate speakers into this one. Their voice prints are averaged into this speaker's and their job matches point to it; the duplicates are deleted.
+// @Tags speakers
+// @Accept json
+// @Produce json
+// @Param id path string true "Speaker ID to keep"
+// @Param request body MergeSpeakersRequest true "Speakers to merge"
+// @Success 200 {object} models.Speaker
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/speakers/{id}/merge [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) MergeSpeakers(c *gin.Context) {
+	target, ok := h.findOwnedSpeaker(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req MergeSpeakersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	seen := map[string]bool{}
+	var sourceIDs []string
+	for _, id := range req.SpeakerIDs {
+		if id == target.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A speaker cannot be merged into itself"})
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			sourceIDs = append(sourceIDs, id)
+		}
+	}
+
+	found, err := h.speakerRepo.FindByIDs(c.Request.Context(), sourceIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speakers"})
+		return
+	}
+	owned := map[string]bool{}
+	for _, s := range found {
+		if s.UserID == target.UserID {
+			owned[s.ID] = true
+		}
+	}
+	for _, id := range sourceIDs {
+		if !owned[id] {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found: " + id})
+			return
+		}
+	}
+
+	if err := h.speakerRepo.Merge(c.Request.Context(), target.ID, sourceIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge speakers"})
+		return
+	}
+	h.respondWithSpeaker(c, http.StatusOK, target.UserID, target.ID)
+}
+
+// @Summary Get speaker matches
+// @Description List the speaker labels of a job that has voice embeddings, with the library speakers they matched
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Transcription Job ID"
+// @Success 200 {array} SpeakerMatchResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/speaker-matches [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetSpeakerMatches(c *gin.Context) {
+	matches, ok := h.speakerMatches(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, matches)
+}
+
+// @Summary Apply speaker matches
+// @Description Name the speaker labels of a job after the library speakers they matched, replacing names they already have
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Transcription Job ID"
+// @Success 200 {array} SpeakerMatchResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/speaker-matches/apply [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ApplySpeakerMatches(c *gin.Context) {
+	jobID := c.Param("id")
+	matches, ok := h.speakerMatches(c, jobID)
+	if !ok {
+		return
+	}
+
+	names := make(map[string]string)
+	for _, m := range matches {
+		if m.SpeakerName != nil {
+			names[m.Label] = *m.SpeakerName
+		}
+	}
+	if err := h.speakerMappingRepo.SetNames(c.Request.Context(), jobID, names, true); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply speaker names"})
+		return
+	}
+
+	if matches, ok = h.speakerMatches(c, jobID); ok {
+		c.JSON(http.StatusOK, matches)
+	}
+}
+
+// speakerMatches lists the speaker labels of a job with their matches and current names
+func (h *Handler) speakerMatches(c *gin.Context, jobID string) ([]SpeakerMatchResponse, bool) {
+	ctx := c.Request.Context()
+	jobSpeakers, err := h.speakerRepo.ListJobSpeakers(ctx, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speaker matches"})
+		return nil, false
+	}
+	mappings, err := h.speakerMappingRepo.ListByJob(ctx, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speaker mappings"})
+		return nil, false
+	}
+	var speakerIDs []string
+	for _, js := range jobSpeakers {
+		if js.SpeakerID != nil {
+			speakerIDs = append(speakerIDs, *js.SpeakerID)
+		}
+	}
+	matched, err := h.speakerRepo.FindByIDs(ctx, speakerIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speakers"})
+		return nil, false
+	}
+
+	nameByID := make(map[string]string, len(matched))
+	for _, s := range matched {
+		nameByID[s.ID] = s.Name
+	}
+	currentNames := make(map[string]string, len(mappings))
+	for _, m := range mappings {
+		currentNames[m.OriginalSpeaker] = m.CustomName
+	}
+
+	response := make([]SpeakerMatchResponse, len(jobSpeakers))
+	for i, js := range jobSpeakers {
+		response[i] = SpeakerMatchResponse{Label: js.Label, Model: js.Model, SpeakerID: js.SpeakerID, Similarity: js.Similarity}
+		if js.SpeakerID != nil {
+			if name, ok := nameByID[*js.SpeakerID]; ok {
+				response[i].SpeakerName = &name
+			}
+		}
+		if name, ok := currentNames[js.Label]; ok {
+			response[i].CurrentName = &name
+		}
+	}
+	return response, true
+}
+
+// findOwnedSpeaker loads a speaker of the current user, writing a 404
+// response when there is no such speaker
+func (h *Handler) findOwnedSpeaker(c *gin.Context, id string) (*models.Speaker, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+
+	speaker, err := h.speakerRepo.FindByUserAndID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speaker"})
+		return nil, false
+	}
+	if speaker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+		return nil, false
+	}
+	return speaker, true
+}
+
+// findJobVoice loads the voice embedding of a speaker label in one of the
+// current user's jobs, writing an error response when there is none
+func (h *Handler) findJobVoice(c *gin.Context, jobID, label string) (*models.JobSpeaker, bool) {
+	if _, ok := h.findOwnedJob(c, jobID); !ok {
+		return nil, false
+	}
+	jobSpeaker, err := h.speakerRepo.FindJobSpeaker(c.Request.Context(), jobID, label)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speaker embedding"})
+		return nil, false
+	}
+	if jobSpeaker == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The job has no voice embedding for " + label + "; transcribe it with speaker_embeddings enabled"})
+		return nil, false
+	}
+	return jobSpeaker, true
+}
+
+// enrollJobVoice adds a job's voice to a speaker, marks the label as that
+// speaker and names it in the job, writing a 500 response on failure
+func (h *Handler) enrollJobVoice(c *gin.Context, speaker *models.Speaker, sample *models.JobSpeaker) bool {
+	ctx := c.Request.Context()
+	if err := h.speakerRepo.AddSample(ctx, speaker.ID, sample.Model, rag.DecodeVector(sample.Embedding)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add speaker sample"})
+		return false
+	}
+	if err := h.speakerRepo.SetJobSpeakerMatch(ctx, sample.ID, &speaker.ID, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update speaker match"})
+		return false
+	}
+	names := map[string]string{sample.Label: speaker.Name}
+	if err := h.speakerMappingRepo.SetNames(ctx, sample.TranscriptionJobID, names, true); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to name speaker"})
+		return false
+	}
+	return true
+}
+
+// respondWithSpeaker writes a speaker as it is now stored, with its voice prints
+func (h *Handler) respondWithSpeaker(c *gin.Context, status int, userID uint, id string) {
+	speaker, err := h.speakerRepo.FindByUserAndID(c.Request.Context(), userID, id)
+	if err != nil || speaker == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speaker"})
+		return
+	}
+	c.JSON(status, speaker)
+}
@@ -3,13 +3,19 @@ package api
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	"scriberr/internal/export"
 	"scriberr/internal/llm"
 	"scriberr/internal/models"
+	"scriberr/internal/rag"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -42,29 +48,95 @@ func (h *Handler) Summarize(c *gin.Context) {
 		return
 	}
 
+	// Summaries are persisted against the transcription, so it must belong to the caller
+	job, ok := h.findOwnedJob(c, req.TranscriptionID)
+	if !ok {
+		return
+	}
+
 	svc, provider, err := h.getLLMService(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Prepare chat messages: simple single-user message with full content
-	messages := []llm.ChatMessage{{Role: "user", Content: req.Content}}
-
 	start := time.Now()
 	log.Printf("[summarize] start transcription_id=%s provider=%s model=%s content_len=%d", req.TranscriptionID, provider, req.Model, len(req.Content))
 
+	contextWindow, err := svc.GetContextWindow(c.Request.Context(), req.Model)
+	if err != nil || contextWindow <= 0 {
+		contextWindow = 4096
+	}
+
 	// Stream response with proper headers for real-time delivery
 	c.Header("Content-Type", "text/plain; charset=utf-8")
 	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
 	c.Header("Connection", "keep-alive")
 	c.Header("Transfer-Encoding", "chunked")
 	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering
-	c.Status(http.StatusOK)             // Start response immediately
+	c.Header("Trailer", summaryErrorTrailer)
+	c.Status(http.StatusOK) // Start response immediately
+	flusher, _ := c.Writer.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	// Prepare chat messages: simple single-user message with full content, or
+	// notes on each part of the transcript when it does not fit the model
+	prompt := req.Content
+	if len(req.Content)/4+summaryResponseTokens > contextWindow {
+		transcriptText, instructions := splitSummaryContent(req.Content)
+		if instructions == "" {
+			instructions = h.summaryInstructions(c.Request.Context(), req.TemplateID)
+		}
+		log.Printf("[summarize] content exceeds context, summarizing in parts transcription_id=%s model=%s context_window=%d", req.TranscriptionID, req.Model, contextWindow)
+
+		// Part progress goes out as summary_progress events; the response
+		// only gets blank lines so proxies do not time it out meanwhile.
+		// The transcript the client sent is summarized, edits included.
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Minute)
+		chunks := rag.ChunkText(transcriptText, summaryPartBudget(instructions, contextWindow))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			prompt, err = h.summarizeInParts(ctx, svc, req.Model, job.ID, chunks, instructions, contextWindow)
+		}()
+		keepSummaryStreamAlive(c, done)
+		cancel()
+		if err != nil {
+			log.Printf("[summarize] error transcription_id=%s model=%s err=%v duration_ms=%d", req.TranscriptionID, req.Model, err, time.Since(start).Milliseconds())
+			// The response is already under way, so the failure goes out of band
+			// rather than being mistaken for the summary
+			msg := fmt.Sprintf("Failed to summarize transcript: %v", err)
+			h.broadcastSummaryProgress(job.ID, SummaryProgress{Stage: "error", Error: msg})
+			c.Writer.Header().Set(summaryErrorTrailer, msg)
+			return
+		}
+	}
+	messages := []llm.ChatMessage{{Role: "user", Content: prompt}}
 
 	h.processSummarization(c, req, svc, messages, start)
 }
 
+// keepSummaryStreamAlive writes a newline to the summary stream every
+// summaryKeepAliveInterval until done is closed
+func keepSummaryStreamAlive(c *gin.Context, done <-chan struct{}) {
+	flusher, _ := c.Writer.(http.Flusher)
+	ticker := time.NewTicker(summaryKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			_, _ = c.Writer.WriteString("\n")
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
 func (h *Handler) processSummarization(c *gin.Context, req SummarizeRequest, svc llm.Service, messages []llm.ChatMessage, start time.Time) {
 	// Allow longer generation time for large transcripts and smaller models
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Minute)
@@ -162,6 +234,7 @@ func (h *Handler) handleSummarizeError(c *gin.Context, req SummarizeRequest, svc
 	if flusher != nil {
 		flusher.Flush()
 	}
+	c.Writer.Header().Set(summaryErrorTrailer, fmt.Sprintf("Failed to summarize transcript: %v", err))
 	log.Printf("[summarize] error transcription_id=%s model=%s err=%v duration_ms=%d", req.TranscriptionID, req.Model, err, time.Since(start).Milliseconds())
 }
 
@@ -181,6 +254,339 @@ func (h *Handler) persistSummary(req SummarizeRequest, content string) {
 	} else {
 		// Also cache on the transcription job for quick access
 		_ = h.jobRepo.UpdateSummary(context.Background(), req.TranscriptionID, content)
+		if err := h.searchRepo.IndexSummary(context.Background(), sum); err != nil {
+			log.Printf("[summarize] failed to index summary transcription_id=%s err=%v", req.TranscriptionID, err)
+		}
+		h.announceSummaryCreated(context.Background(), sum)
+	}
+}
+
+// defaultAutoSummaryPrompt is used when the owner has not picked a default template
+const defaultAutoSummaryPrompt = "Summarize the transcript. Start with a short overview, then list the key points, decisions and action items as bullet points."
+
+// WillAutoSummarize reports whether the owner of a job wants it summarized on completion
+func (h *Handler) WillAutoSummarize(ctx context.Context, jobID string) bool {
+	job, err := h.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		return false
+	}
+	user, err := h.userRepo.FindByID(ctx, job.UserID)
+	return err == nil && user.AutoSummaryEnabled
+}
+
+// AutoSummarizeJob summarizes a completed transcription when its owner has auto-summary
+// enabled, using their default template and the configured default model.
+// It is designed for background execution (e.g. queue completion hooks) and returns
+// a nil summary when there is nothing to do.
+func (h *Handler) AutoSummarizeJob(ctx context.Context, jobID string) (*models.Summary, error) {
+	job, err := h.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.StatusCompleted || job.Transcript == nil || strings.TrimSpace(*job.Transcript) == "" {
+		return nil, nil
+	}
+
+	user, err := h.userRepo.FindByID(ctx, job.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.AutoSummaryEnabled {
+		return nil, nil
+	}
+
+	var template *models.SummaryTemplate
+	if user.DefaultSummaryTemplateID != nil {
+		template, err = h.summaryRepo.FindByID(ctx, *user.DefaultSummaryTemplateID)
+		if err != nil {
+			logger.Warn("Default summary template unavailable, using built-in prompt",
+				"user_id", user.ID, "template_id", *user.DefaultSummaryTemplateID, "error", err)
+			template = nil
+		}
+	}
+
+	svc, _, err := h.getLLMServiceForAutoTitle(ctx)
+	if err != nil {
+		// No active LLM is a normal state; skip without surfacing as hard error.
+		if strings.Contains(strings.ToLower(err.Error()), "no active llm configuration") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// The global default model wins; the template's own model is the fallback
+	preferredModel := ""
+	if settings, err := h.summaryRepo.GetSettings(ctx); (err != nil || strings.TrimSpace(settings.DefaultModel) == "") && template != nil {
+		preferredModel = template.Model
+	}
+	model, err := h.resolveAutoTitleModel(ctx, svc, preferredModel)
+	if err != nil {
+		return nil, err
+	}
+
+	content := h.buildSummaryContent(ctx, job, template)
+	if contextWindow, err := svc.GetContextWindow(ctx, model); err == nil && contextWindow > 0 && len(content)/4+summaryResponseTokens > contextWindow {
+		transcriptText, instructions := splitSummaryContent(content)
+		chunks := h.summaryChunks(ctx, job, transcriptText, summaryPartBudget(instructions, contextWindow))
+		content, err = h.summarizeInParts(ctx, svc, model, job.ID, chunks, instructions, contextWindow)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp, err := svc.ChatCompletion(ctx, model, []llm.ChatMessage{{Role: "user", Content: content}}, 0.0)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return nil, fmt.Errorf("model returned an empty summary")
+	}
+	text := strings.TrimSpace(resp.Choices[0].Message.Content)
+
+	summary := &models.Summary{
+		TranscriptionID: job.ID,
+		Model:           model,
+		Content:         text,
+	}
+	if template != nil {
+		summary.TemplateID = &template.ID
+	}
+	if err := h.summaryRepo.SaveSummary(ctx, summary); err != nil {
+		return nil, err
+	}
+	// Also cache on the transcription job for quick access
+	_ = h.jobRepo.UpdateSummary(ctx, job.ID, text)
+	if err := h.searchRepo.IndexSummary(ctx, summary); err != nil {
+		logger.Warn("Failed to index summary for search", "job_id", job.ID, "error", err)
+	}
+	h.announceSummaryCreated(ctx, summary)
+
+	logger.Info("Auto-generated transcription summary", "job_id", job.ID, "model", model, "template_id", summary.TemplateID)
+	return summary, nil
+}
+
+// buildSummaryContent combines the transcript and template instructions the same
+// way the web client does for manually requested summaries
+func (h *Handler) buildSummaryContent(ctx context.Context, job *models.TranscriptionJob, template *models.SummaryTemplate) string {
+	prompt := defaultAutoSummaryPrompt
+	includeSpeakers := false
+	if template != nil {
+		prompt = template.Prompt
+		includeSpeakers = template.IncludeSpeakerInfo
+	}
+
+	transcriptText := *job.Transcript
+	var result interfaces.TranscriptResult
+	if err := json.Unmarshal([]byte(*job.Transcript), &result); err == nil {
+		if includeSpeakers {
+			opts := export.Options{SpeakerNames: map[string]string{}}
+			if mappings, err := h.speakerMappingRepo.ListByJob(ctx, job.ID); err == nil {
+				for _, m := range mappings {
+					opts.SpeakerNames[m.OriginalSpeaker] = m.CustomName
+				}
+			}
+			if body, err := export.Render(&result, export.FormatTXT, opts); err == nil {
+				transcriptText = string(body)
+			}
+		} else if strings.TrimSpace(result.Text) != "" {
+			transcriptText = result.Text
+		}
+	}
+
+	label := "Transcript:"
+	if includeSpeakers {
+		label = "Transcript (each line is prefixed with a timestamp and the speaker name):"
+	}
+	return fmt.Sprintf("%s\n%s%s%s", label, strings.TrimSpace(transcriptText), summaryInstructionsMarker, prompt)
+}
+
+const (
+	// summaryResponseTokens is kept free in the context window for the model's answer
+	summaryResponseTokens = 1024
+	// summaryPromptTokens covers the wording wrapped around each part
+	summaryPromptTokens = 256
+	// summaryMinPartChars stops tiny context windows from producing hundreds of parts
+	summaryMinPartChars = 2000
+	// summaryMaxMergeRounds bounds how often notes are merged before the final summary
+	summaryMaxMergeRounds = 5
+	// summaryKeepAliveInterval is how often the stream gets a blank line while
+	// a transcript is summarized in parts
+	summaryKeepAliveInterval = 15 * time.Second
+	// summaryErrorTrailer is the response trailer that reports a failed summary,
+	// as the status has already been sent by then
+	summaryErrorTrailer = "X-Summary-Error"
+)
+
+// summaryInstructionsMarker separates the transcript from the instructions in
+// summarize prompts built by the web client and buildSummaryContent
+const summaryInstructionsMarker = "\n\nInstructions:\n"
+
+// SummaryProgress is broadcast as a "summary_progress" event on the event
+// stream of a transcription while it is summarized in parts
+type SummaryProgress struct {
+	Stage     string `json:"stage"` // "map" while parts are summarized, "reduce" while notes are merged, "final" for the summary itself, "error" when it failed
+	Completed int    `json:"completed"`
+	Total     int    `json:"total"`
+	Error     string `json:"error,omitempty"`
+}
+
+// summaryPart is a summarized stretch of a transcript
+type summaryPart struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+func (p summaryPart) header() string {
+	if p.End <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s - %s)", formatTime(p.Start), formatTime(p.End))
+}
+
+// splitSummaryContent separates a summarize prompt into its transcript and
+// instructions. The instructions are empty when the prompt has none.
+func splitSummaryContent(content string) (string, string) {
+	i := strings.LastIndex(content, summaryInstructionsMarker)
+	if i < 0 {
+		return content, ""
+	}
+	return content[:i], strings.TrimSpace(content[i+len(summaryInstructionsMarker):])
+}
+
+// summaryInstructions returns the prompt of a summary template, or the
+// built-in prompt when there is none
+func (h *Handler) summaryInstructions(ctx context.Context, templateID *string) string {
+	if templateID != nil && *templateID != "" {
+		if template, err := h.summaryRepo.FindByID(ctx, *templateID); err == nil && strings.TrimSpace(template.Prompt) != "" {
+			return template.Prompt
+		}
+	}
+	return defaultAutoSummaryPrompt
+}
+
+// summaryPartBudget is how many characters of transcript go into each part
+// when summarizing in parts
+func summaryPartBudget(instructions string, contextWindow int) int {
+	return max((contextWindow-summaryResponseTokens-summaryPromptTokens-len(instructions)/4)*4, summaryMinPartChars)
+}
+
+// summaryChunks splits the stored transcript of a job on segment boundaries,
+// with time ranges and speaker names, or transcriptText when the transcript
+// has no usable segments
+func (h *Handler) summaryChunks(ctx context.Context, job *models.TranscriptionJob, transcriptText string, budget int) []rag.Chunk {
+	var chunks []rag.Chunk
+	if job.Transcript != nil {
+		speakers := make(map[string]string)
+		if mappings, err := h.speakerMappingRepo.ListByJob(ctx, job.ID); err == nil {
+			for _, m := range mappings {
+				speakers[m.OriginalSpeaker] = m.CustomName
+			}
+		}
+		chunks, _ = rag.ChunkTranscript(*job.Transcript, speakers, budget)
+	}
+	if len(chunks) == 0 {
+		chunks = rag.ChunkText(transcriptText, budget)
+	}
+	return chunks
+}
+
+// summarizeInParts condenses a transcript that does not fit the context window
+// of the model. Each of the chunks, cut with summaryPartBudget, is summarized
+// on its own and the notes are merged until they fit. It returns the prompt
+// for the final summary.
+func (h *Handler) summarizeInParts(ctx context.Context, svc llm.Service, model string, jobID string, chunks []rag.Chunk, instructions string, contextWindow int) (string, error) {
+	budget := summaryPartBudget(instructions, contextWindow)
+	if len(chunks) == 0 {
+		return "", fmt.Errorf("transcript is empty")
+	}
+
+	parts := make([]summaryPart, len(chunks))
+	for i, chunk := range chunks {
+		part := summaryPart{Start: chunk.Start, End: chunk.End}
+		prompt := fmt.Sprintf("You are summarizing a long transcript one part at a time. "+
+			"This is part %d of %d%s. Lines start with the speaker where it is known.\n\n%s\n\n"+
+			"Write concise notes on this part only: the topics discussed, key points, decisions, action items with their owners, "+
+			"and any names, figures or dates mentioned. Say who said what where it matters. "+
+			"The notes will later be combined with the notes on the other parts to follow these instructions:\n%s",
+			i+1, len(chunks), part.header(), chunk.Text, instructions)
+		notes, err := summaryCompletion(ctx, svc, model, prompt)
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize part %d of %d: %w", i+1, len(chunks), err)
+		}
+		part.Text = notes
+		parts[i] = part
+		h.broadcastSummaryProgress(jobID, SummaryProgress{Stage: "map", Completed: i + 1, Total: len(chunks)})
+	}
+
+	// Merge neighbouring notes until all of them fit next to the instructions
+	for round := 0; len(parts) > 1 && len(formatSummaryParts(parts)) > budget && round < summaryMaxMergeRounds; round++ {
+		var groups [][]summaryPart
+		size := 0
+		for _, part := range parts {
+			n := len(part.Text) + 64
+			if len(groups) == 0 || (size+n > budget && len(groups[len(groups)-1]) > 0) {
+				groups = append(groups, nil)
+				size = 0
+			}
+			groups[len(groups)-1] = append(groups[len(groups)-1], part)
+			size += n
+		}
+		if len(groups) == len(parts) {
+			// Every note fills a part on its own; merge them in pairs instead
+			groups = groups[:0]
+			for i := 0; i < len(parts); i += 2 {
+				groups = append(groups, parts[i:min(i+2, len(parts))])
+			}
+		}
+
+		merged := make([]summaryPart, len(groups))
+		for i, group := range groups {
+			part := summaryPart{Start: group[0].Start, End: group[len(group)-1].End}
+			if len(group) == 1 {
+				merged[i] = group[0]
+			} else {
+				prompt := fmt.Sprintf("Below are notes on consecutive parts of a transcript, in order.\n\n%s\n\n"+
+					"Merge them into a single set of concise notes covering the same stretch of the transcript. "+
+					"Keep every key point, decision and action item, and who they came from.", formatSummaryParts(group))
+				notes, err := summaryCompletion(ctx, svc, model, prompt)
+				if err != nil {
+					return "", fmt.Errorf("failed to merge notes: %w", err)
+				}
+				part.Text = notes
+				merged[i] = part
+			}
+			h.broadcastSummaryProgress(jobID, SummaryProgress{Stage: "reduce", Completed: i + 1, Total: len(groups)})
+		}
+		parts = merged
+	}
+
+	h.broadcastSummaryProgress(jobID, SummaryProgress{Stage: "final", Completed: 0, Total: 1})
+	return fmt.Sprintf("Below are notes on consecutive parts of one transcript, in order. Together they cover the whole recording.\n\n%s"+
+		"%sWrite the summary as if you had read the full transcript.\n%s", formatSummaryParts(parts), summaryInstructionsMarker, instructions), nil
+}
+
+func formatSummaryParts(parts []summaryPart) string {
+	var sb strings.Builder
+	for i, part := range parts {
+		fmt.Fprintf(&sb, "Notes on part %d of %d%s:\n%s\n\n", i+1, len(parts), part.header(), part.Text)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func summaryCompletion(ctx context.Context, svc llm.Service, model, prompt string) (string, error) {
+	resp, err := svc.ChatCompletion(ctx, model, []llm.ChatMessage{{Role: "user", Content: prompt}}, 0.0)
+	if err != nil {
+		return "", err
+	}
+	if resp == nil || len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("model returned an empty response")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
+
+func (h *Handler) broadcastSummaryProgress(jobID string, progress SummaryProgress) {
+	if h.broadcaster != nil {
+		h.broadcaster.Broadcast(jobID, "summary_progress", progress)
 	}
 }
 
@@ -0,0 +1,349 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"scriberr/internal/models"
+	"scriberr/internal/repository"
+	"scriberr/internal/transcriptedit"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// EditSegmentTextRequest replaces the text of a transcript segment
+type EditSegmentTextRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// SetSegmentSpeakerRequest assigns a transcript segment to a speaker
+type SetSegmentSpeakerRequest struct {
+	Speaker string `json:"speaker" binding:"required"` // Speaker label, e.g. SPEAKER_01
+}
+
+// SplitSegmentRequest splits a transcript segment in two
+type SplitSegmentRequest struct {
+	WordIndex int `json:"word_index" binding:"required,min=1"` // The second segment starts with this word
+}
+
+// TranscriptRevisionResponse describes one change in the edit history of a transcript
+type TranscriptRevisionResponse struct {
+	Number    int                    `json:"number"`
+	Action    string                 `json:"action"`
+	AuthorID  *uint                  `json:"author_id,omitempty"`
+	Author    string                 `json:"author,omitempty"`
+	Diff      *transcriptedit.Change `json:"diff,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// TranscriptEditResponse is the transcript after an edit and the revision that recorded it
+type TranscriptEditResponse struct {
+	Revision   TranscriptRevisionResponse  `json:"revision"`
+	Transcript interfaces.TranscriptResult `json:"transcript"`
+}
+
+// @Summary List transcript revisions
+// @Description List the edit history of a transcript, oldest first. Revision 0 is the original model output.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Transcription Job ID"
+// @Success 200 {array} TranscriptRevisionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/transcription/{id}/transcript/revisions [get]
+func (h *Handler) ListTranscriptRevisions(c *gin.Context) {
+	revisions, err := h.revisionRepo.ListByJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get transcript revisions"})
+		return
+	}
+
+	authors := make(map[uint]string)
+	response := make([]TranscriptRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		response = append(response, h.newTranscriptRevisionResponse(c.Request.Context(), revision, authors))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Edit the text of a transcript segment
+// @Description Replace the text of a segment. Word timings are kept when the word count is unchanged and estimated otherwise.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Transcription Job ID"
+// @Param index path int true "Segment index"
+// @Param request body EditSegmentTextRequest true "New segment text"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/transcription/{id}/transcript/segments/{index}/text [put]
+func (h *Handler) EditTranscriptSegmentText(c *gin.Context) {
+	var req EditSegmentTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	index, ok := parseSegmentIndex(c)
+	if !ok {
+		return
+	}
+	h.applyTranscriptEdit(c, models.RevisionActionEditText, func(t *interfaces.TranscriptResult) (*transcriptedit.Change, error) {
+		return transcriptedit.EditText(t, index, req.Text)
+	})
+}
+
+// @Summary Reassign a transcript segment to another speaker
+// @Description Set the speaker label of a segment and its words
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Transcription Job ID"
+// @Param index path int true "Segment index"
+// @Param request body SetSegmentSpeakerRequest true "Speaker label"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/transcription/{id}/transcript/segments/{index}/speaker [put]
+func (h *Handler) SetTranscriptSegmentSpeaker(c *gin.Context) {
+	var req SetSegmentSpeakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	index, ok := parseSegmentIndex(c)
+	if !ok {
+		return
+	}
+	h.applyTranscriptEdit(c, models.RevisionActionSpeaker, func(t *interfaces.TranscriptResult) (*transcriptedit.Change, error) {
+		return transcriptedit.SetSpeaker(t, index, req.Speaker)
+	})
+}
+
+// @Summary Split a transcript segment
+// @Description Split a segment in two before the given word. The split time comes from the word timings when available.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Transcription Job ID"
+// @Param index path int true "Segment index"
+// @Param request body SplitSegmentRequest true "Split position"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/transcription/{id}/transcript/segments/{index}/split [post]
+func (h *Handler) SplitTranscriptSegment(c *gin.Context) {
+	var req SplitSegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	index, ok := parseSegmentIndex(c)
+	if !ok {
+		return
+	}
+	h.applyTranscriptEdit(c, models.RevisionActionSplit, func(t *interfaces.TranscriptResult) (*transcriptedit.Change, error) {
+		return transcriptedit.Split(t, index, req.WordIndex)
+	})
+}
+
+// @Summary Merge a transcript segment with the next one
+// @Description Join a segment with the segment after it. The merged segment keeps the speaker of the first.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Transcription Job ID"
+// @Param index path int true "Segment index"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/transcription/{id}/transcript/segments/{index}/merge [post]
+func (h *Handler) MergeTranscriptSegments(c *gin.Context) {
+	index, ok := parseSegmentIndex(c)
+	if !ok {
+		return
+	}
+	h.applyTranscriptEdit(c, models.RevisionActionMerge, func(t *interfaces.TranscriptResult) (*transcriptedit.Change, error) {
+		return transcriptedit.Merge(t, index)
+	})
+}
+
+// @Summary Revert a transcript to the original model output
+// @Description Undo all edits by restoring the transcript produced by the model. The revert is recorded as a new revision.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Transcription Job ID"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/transcription/{id}/transcript/revert [post]
+func (h *Handler) RevertTranscript(c *gin.Context) {
+	original, err := h.revisionRepo.FindOriginal(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Transcript has not been edited"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get original transcript"})
+		return
+	}
+
+	var originalResult interfaces.TranscriptResult
+	if original.Transcript == nil || json.Unmarshal([]byte(*original.Transcript), &originalResult) != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse original transcript"})
+		return
+	}
+
+	h.applyTranscriptEdit(c, models.RevisionActionRevert, func(t *interfaces.TranscriptResult) (*transcriptedit.Change, error) {
+		return transcriptedit.Replace(t, originalResult), nil
+	})
+}
+
+func parseSegmentIndex(c *gin.Context) (int, bool) {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid segment index"})
+		return 0, false
+	}
+	return index, true
+}
+
+// applyTranscriptEdit runs edit against the stored transcript of the job in
+// the path and saves the result as a new revision authored by the caller
+func (h *Handler) applyTranscriptEdit(c *gin.Context, action string, edit func(t *interfaces.TranscriptResult) (*transcriptedit.Change, error)) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	job, ok := h.findOwnedJob(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if job.Status != models.StatusCompleted || job.Transcript == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transcript not available"})
+		return
+	}
+
+	var result interfaces.TranscriptResult
+	if err := json.Unmarshal([]byte(*job.Transcript), &result); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse transcript"})
+		return
+	}
+
+	change, err := edit(&result)
+	if err != nil {
+		switch {
+		case errors.Is(err, transcriptedit.ErrSegmentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Segment not found"})
+		case errors.Is(err, transcriptedit.ErrInvalidEdit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to edit transcript"})
+		}
+		return
+	}
+
+	updated, err := json.Marshal(result)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode transcript"})
+		return
+	}
+	diff, err := json.Marshal(change)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode transcript change"})
+		return
+	}
+	diffText := string(diff)
+
+	revision := &models.TranscriptRevision{
+		TranscriptionJobID: job.ID,
+		Action:             action,
+		AuthorID:           &userID,
+		Diff:               &diffText,
+	}
+	if err := h.revisionRepo.Apply(c.Request.Context(), revision, *job.Transcript, string(updated)); err != nil {
+		if errors.Is(err, repository.ErrTranscriptChanged) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Transcript was changed by another edit, reload it and try again"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save transcript"})
+		return
+	}
+
+	h.refreshTranscriptDerivatives(job.ID)
+
+	c.JSON(http.StatusOK, TranscriptEditResponse{
+		Revision:   h.newTranscriptRevisionResponse(c.Request.Context(), *revision, map[uint]string{}),
+		Transcript: result,
+	})
+}
+
+// refreshTranscriptDerivatives updates the search index and embedded passages
+// of a job after its transcript changed. Embedding talks to the LLM provider,
+// so it runs in the background.
+func (h *Handler) refreshTranscriptDerivatives(jobID string) {
+	if err := h.searchRepo.IndexTranscript(context.Background(), jobID); err != nil {
+		logger.Warn("Failed to index transcript for search", "job_id", jobID, "error", err)
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		if err := h.EmbedTranscription(ctx, jobID); err != nil {
+			logger.Warn("Failed to embed transcript", "job_id", jobID, "error", err)
+		}
+	}()
+}
+
+func (h *Handler) newTranscriptRevisionResponse(ctx context.Context, revision models.TranscriptRevision, authors map[uint]string) TranscriptRevisionResponse {
+	response := TranscriptRevisionResponse{
+		Number:    revision.Number,
+		Action:    revision.Action,
+		AuthorID:  revision.AuthorID,
+		CreatedAt: revision.CreatedAt,
+	}
+	if revision.AuthorID != nil {
+		name, ok := authors[*revision.AuthorID]
+		if !ok {
+			if user, err := h.userRepo.FindByID(ctx, *revision.AuthorID); err == nil {
+				name = user.Username
+			}
+			authors[*revision.AuthorID] = name
+		}
+		response.Author = name
+	}
+	if revision.Diff != nil {
+		var change transcriptedit.Change
+		if err := json.Unmarshal([]byte(*revision.Diff), &change); err == nil {
+			response.Diff = &change
+		}
+	}
+	return response
+}
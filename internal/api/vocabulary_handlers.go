@@ -0,0 +1,235 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/pipeline"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits on the size of a vocabulary
+const (
+	maxVocabularyTerms = 1000
+	maxVocabularyRules = 500
+)
+
+// VocabularyRequest is the body for creating or replacing a vocabulary
+type VocabularyRequest struct {
+	Name        string                   `json:"name" binding:"required,min=1"`
+	Description *string                  `json:"description,omitempty"`
+	Terms       []string                 `json:"terms"`
+	Rules       []models.ReplacementRule `json:"rules"`
+}
+
+// @Summary List vocabularies
+// @Description List the custom vocabularies of the current user
+// @Tags vocabularies
+// @Produce json
+// @Success 200 {array} models.Vocabulary
+// @Router /api/v1/vocabularies [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListVocabularies(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	vocabularies, err := h.vocabularyRepo.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list vocabularies"})
+		return
+	}
+	c.JSON(http.StatusOK, vocabularies)
+}
+
+// @Summary Create vocabulary
+// @Description Create a custom vocabulary of terms to boost and find-and-replace rules. Reference it from a profile or job through the vocabulary_ids parameter.
+// @Tags vocabularies
+// @Accept json
+// @Produce json
+// @Param vocabulary body VocabularyRequest true "Vocabulary"
+// @Success 201 {object} models.Vocabulary
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/vocabularies [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateVocabulary(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	vocabulary := models.Vocabulary{UserID: userID}
+	if !bindVocabulary(c, &vocabulary) {
+		return
+	}
+	if err := h.vocabularyRepo.Create(c.Request.Context(), &vocabulary); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create vocabulary"})
+		return
+	}
+	c.JSON(http.StatusCreated, vocabulary)
+}
+
+// @Summary Get vocabulary
+// @Description Get a custom vocabulary of the current user
+// @Tags vocabularies
+// @Produce json
+// @Param id path string true "Vocabulary ID"
+// @Success 200 {object} models.Vocabulary
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/vocabularies/{id} [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetVocabulary(c *gin.Context) {
+	vocabulary, ok := h.findOwnedVocabulary(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, vocabulary)
+}
+
+// @Summary Update vocabulary
+// @Description Replace the name, description, terms and rules of a custom vocabulary
+// @Tags vocabularies
+// @Accept json
+// @Produce json
+// @Param id path string true "Vocabulary ID"
+// @Param vocabulary body VocabularyRequest true "Vocabulary"
+// @Success 200 {object} models.Vocabulary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/vocabularies/{id} [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateVocabulary(c *gin.Context) {
+	vocabulary, ok := h.findOwnedVocabulary(c)
+	if !ok {
+		return
+	}
+	if !bindVocabulary(c, vocabulary) {
+		return
+	}
+	if err := h.vocabularyRepo.Update(c.Request.Context(), vocabulary); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update vocabulary"})
+		return
+	}
+	c.JSON(http.StatusOK, vocabulary)
+}
+
+// @Summary Delete vocabulary
+// @Description Delete a custom vocabulary. Profiles and jobs that still refer to it transcribe without it.
+// @Tags vocabularies
+// @Param id path string true "Vocabulary ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/vocabularies/{id} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteVocabulary(c *gin.Context) {
+	vocabulary, ok := h.findOwnedVocabulary(c)
+	if !ok {
+		return
+	}
+	if err := h.vocabularyRepo.Delete(c.Request.Context(), vocabulary.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete vocabulary"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// findOwnedVocabulary loads the vocabulary in the id path parameter, writing
+// a 404 response when the current user has no such vocabulary
+func (h *Handler) findOwnedVocabulary(c *gin.Context) (*models.Vocabulary, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+
+	vocabulary, err := h.vocabularyRepo.FindByUserAndID(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get vocabulary"})
+		return nil, false
+	}
+	if vocabulary == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vocabulary not found"})
+		return nil, false
+	}
+	return vocabulary, true
+}
+
+// bindVocabulary reads and validates a VocabularyRequest into vocabulary,
+// writing a 400 response when it is invalid
+func bindVocabulary(c *gin.Context, vocabulary *models.Vocabulary) bool {
+	var req VocabularyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return false
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vocabulary name is required"})
+		return false
+	}
+
+	terms := make([]string, 0, len(req.Terms))
+	for _, term := range req.Terms {
+		if term = strings.TrimSpace(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) > maxVocabularyTerms {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A vocabulary can have at most %d terms", maxVocabularyTerms)})
+		return false
+	}
+	if len(req.Rules) > maxVocabularyRules {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A vocabulary can have at most %d rules", maxVocabularyRules)})
+		return false
+	}
+	for i, rule := range req.Rules {
+		if err := pipeline.ValidateReplacementRule(rule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid rule %d: %v", i+1, err)})
+			return false
+		}
+	}
+
+	vocabulary.Name = strings.TrimSpace(req.Name)
+	vocabulary.Description = req.Description
+	vocabulary.Terms = terms
+	vocabulary.Rules = req.Rules
+	if vocabulary.Rules == nil {
+		vocabulary.Rules = []models.ReplacementRule{}
+	}
+	return true
+}
+
+// validateVocabularyIDs checks that the caller may apply every vocabulary
+// referenced by params, writing a 400 response when they may not
+func (h *Handler) validateVocabularyIDs(c *gin.Context, params *models.WhisperXParams) error {
+	if len(params.VocabularyIDs) == 0 {
+		return nil
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return fmt.Errorf("user not authenticated")
+	}
+	found, err := h.vocabularyRepo.FindUsableByIDs(c.Request.Context(), userID, params.VocabularyIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check vocabularies"})
+		return err
+	}
+	existing := make(map[string]bool, len(found))
+	for _, v := range found {
+		existing[v.ID] = true
+	}
+	for _, id := range params.VocabularyIDs {
+		if !existing[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown vocabulary: " + id})
+			return fmt.Errorf("unknown vocabulary %s", id)
+		}
+	}
+	return nil
+}
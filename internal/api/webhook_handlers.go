@@ -0,0 +1,311 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"scriberr/internal/models"
+	"scriberr/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page sizes of a subscription's delivery log
+const (
+	defaultWebhookDeliveries = 50
+	maxWebhookDeliveries     = 200
+)
+
+// WebhookSubscriptionRequest is the body for creating or replacing a webhook subscription
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description *string  `json:"description,omitempty"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Active      *bool    `json:"active,omitempty"` // Defaults to true
+}
+
+// WebhookSubscriptionCreatedResponse is a new subscription together with its
+// signing secret, which is only shown once
+type WebhookSubscriptionCreatedResponse struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// @Summary List webhook subscriptions
+// @Description List the webhook subscriptions of the current user
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} models.WebhookSubscription
+// @Router /api/v1/webhooks [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListWebhookSubscriptions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := h.webhookRepo.ListSubscriptions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhook subscriptions"})
+		return
+	}
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// @Summary Create webhook subscription
+// @Description Subscribe a URL to events: job.created, job.started, job.completed, job.failed, summary.created and note.created. Deliveries are POSTed as JSON and signed in the X-Scriberr-Signature header with "sha256=" and the hex HMAC-SHA256 of "<X-Scriberr-Timestamp>.<body>", keyed with the secret returned here. The secret is not shown again. Each subscription receives its events in order; a delivery that is being retried holds back later ones until it succeeds or runs out of attempts.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body WebhookSubscriptionRequest true "Subscription"
+// @Success 201 {object} WebhookSubscriptionCreatedResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/webhooks [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateWebhookSubscription(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	subscription := models.WebhookSubscription{UserID: userID}
+	if !h.bindWebhookSubscription(c, &subscription) {
+		return
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+		return
+	}
+	subscription.Secret = secret
+
+	if err := h.webhookRepo.CreateSubscription(c.Request.Context(), &subscription); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook subscription"})
+		return
+	}
+	c.JSON(http.StatusCreated, WebhookSubscriptionCreatedResponse{WebhookSubscription: subscription, Secret: secret})
+}
+
+// @Summary Get webhook subscription
+// @Description Get a webhook subscription of the current user
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/webhooks/{id} [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetWebhookSubscription(c *gin.Context) {
+	subscription, ok := h.findOwnedWebhookSubscription(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+// @Summary Update webhook subscription
+// @Description Replace the URL, description, events and active flag of a webhook subscription. The secret is kept.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param subscription body WebhookSubscriptionRequest true "Subscription"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/webhooks/{id} [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateWebhookSubscription(c *gin.Context) {
+	subscription, ok := h.findOwnedWebhookSubscription(c)
+	if !ok {
+		return
+	}
+	if !h.bindWebhookSubscription(c, subscription) {
+		return
+	}
+	if err := h.webhookRepo.UpdateSubscription(c.Request.Context(), subscription); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook subscription"})
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+// @Summary Delete webhook subscription
+// @Description Delete a webhook subscription and its delivery log
+// @Tags webhooks
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/webhooks/{id} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteWebhookSubscription(c *gin.Context) {
+	subscription, ok := h.findOwnedWebhookSubscription(c)
+	if !ok {
+		return
+	}
+	if err := h.webhookRepo.DeleteSubscription(c.Request.Context(), subscription.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook subscription"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List webhook deliveries
+// @Description List the most recent deliveries to a webhook subscription, newest first, with their attempts and the receiver's last response status. The response body is only included for admins.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param limit query int false "Maximum number of deliveries (default 50, max 200)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListWebhookDeliveries(c *gin.Context) {
+	subscription, ok := h.findOwnedWebhookSubscription(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultWebhookDeliveries
+	if v := c.Query("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(parsed, maxWebhookDeliveries)
+	}
+
+	deliveries, err := h.webhookRepo.ListDeliveries(c.Request.Context(), subscription.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhook deliveries"})
+		return
+	}
+	// Response bodies can echo internal services; only admins may read them
+	if !h.callerIsAdmin(c) {
+		for i := range deliveries {
+			deliveries[i].ResponseBody = nil
+		}
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// @Summary Redeliver webhook event
+// @Description Queue a new delivery of the event of an earlier delivery, with the same event ID and payload
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) RedeliverWebhook(c *gin.Context) {
+	subscription, ok := h.findOwnedWebhookSubscription(c)
+	if !ok {
+		return
+	}
+	if !subscription.Active {
+		c.JSON(http.StatusConflict, gin.H{"error": "Webhook subscription is inactive"})
+		return
+	}
+
+	delivery, err := h.webhookRepo.FindDelivery(c.Request.Context(), subscription.ID, c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook delivery"})
+		return
+	}
+	if delivery == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+		return
+	}
+
+	redelivery, err := h.webhookDispatcher.Redeliver(c.Request.Context(), delivery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue redelivery"})
+		return
+	}
+	c.JSON(http.StatusAccepted, redelivery)
+}
+
+// findOwnedWebhookSubscription loads the subscription in the id path
+// parameter, writing a 404 response when the current user has no such subscription
+func (h *Handler) findOwnedWebhookSubscription(c *gin.Context) (*models.WebhookSubscription, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+
+	subscription, err := h.webhookRepo.FindSubscription(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook subscription"})
+		return nil, false
+	}
+	if subscription == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+		return nil, false
+	}
+	return subscription, true
+}
+
+// isPrivateWebhookHost reports whether host is localhost or a private IP address
+func isPrivateWebhookHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && webhook.IsPrivateAddress(ip)
+}
+
+// bindWebhookSubscription reads and validates a WebhookSubscriptionRequest
+// into subscription, writing a 400 response when it is invalid
+func (h *Handler) bindWebhookSubscription(c *gin.Context, subscription *models.WebhookSubscription) bool {
+	var req WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return false
+	}
+
+	target, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an absolute http or https URL"})
+		return false
+	}
+	// Hostnames are checked again when delivering, once they are resolved
+	if !h.config.WebhookAllowPrivateTargets && isPrivateWebhookHost(target.Hostname()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must not point at a loopback or private network address"})
+		return false
+	}
+
+	events := make([]string, 0, len(req.Events))
+	seen := make(map[string]bool, len(req.Events))
+	for _, event := range req.Events {
+		if !webhook.IsEvent(event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown event %q; supported events are %s", event, strings.Join(webhook.Events, ", "))})
+			return false
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+
+	subscription.URL = target.String()
+	subscription.Description = req.Description
+	subscription.Events = events
+	subscription.Active = req.Active == nil || *req.Active
+	return true
+}
@@ -41,6 +41,9 @@ type Config struct {
 
 	// Hugging Face configuration
 	HFToken string
+
+	// Webhook configuration
+	WebhookAllowPrivateTargets bool // Let webhooks reach loopback and private network addresses
 }
 
 // Load loads configuration from environment variables and .env file
@@ -70,6 +73,8 @@ func Load() *Config {
 		SecureCookies:  getEnv("SECURE_COOKIES", defaultSecure) == "true",
 		OpenAIAPIKey:   getEnv("OPENAI_API_KEY", ""),
 		HFToken:        getEnv("HF_TOKEN", ""),
+
+		WebhookAllowPrivateTargets: getEnv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "false") == "true",
 	}
 }
 
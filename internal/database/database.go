@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -63,6 +64,8 @@ func Initialize(dbPath string) error {
 	if err := DB.AutoMigrate(
 		&models.TranscriptionJob{},
 		&models.TranscriptionJobExecution{},
+		&models.QueueSetting{},
+		&models.AudioChunk{},
 		&models.SpeakerMapping{},
 		&models.MultiTrackFile{},
 		&models.User{},
@@ -77,6 +80,15 @@ func Initialize(dbPath string) error {
 		&models.Note{},
 		&models.RefreshToken{},
 		&models.WatchedFolder{},
+		&models.TranscriptChunk{},
+		&models.TranscriptRevision{},
+		&models.ModelComparison{},
+		&models.Vocabulary{},
+		&models.Speaker{},
+		&models.SpeakerVoicePrint{},
+		&models.JobSpeaker{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
 	); err != nil {
 		return fmt.Errorf("failed to auto migrate: %v", err)
 	}
@@ -102,9 +114,108 @@ func Initialize(dbPath string) error {
 		return fmt.Errorf("failed to create unique constraint for speaker mappings: %v", err)
 	}
 
+	// Full-text index over transcript segments, notes and summaries. Only the
+	// content column is searchable; the rest locates the hit.
+	createSearchIndex := `CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+		content,
+		job_id UNINDEXED,
+		kind UNINDEXED,
+		source_id UNINDEXED,
+		start_time UNINDEXED,
+		end_time UNINDEXED,
+		speaker UNINDEXED,
+		tokenize = 'porter unicode61'
+	)`
+	if err := DB.Exec(createSearchIndex).Error; err != nil {
+		return fmt.Errorf("failed to create search index: %v", err)
+	}
+
+	// Jobs and API keys created before per-user ownership existed have no owner.
+	// Hand them to the first registered account so they remain accessible.
+	for _, table := range []string{"transcription_jobs", "api_keys"} {
+		assignOrphans := fmt.Sprintf("UPDATE %s SET user_id = (SELECT MIN(id) FROM users) WHERE (user_id IS NULL OR user_id = 0) AND EXISTS (SELECT 1 FROM users)", table)
+		if err := DB.Exec(assignOrphans).Error; err != nil {
+			fmt.Printf("Warning: Failed to assign owner to existing %s: %v\n", table, err)
+		}
+	}
+
+	// Installations that predate roles have no admin. Promote the first
+	// registered account so somebody can still reach the admin endpoints.
+	promoteFirstUser := "UPDATE users SET role = ? WHERE id = (SELECT MIN(id) FROM users) AND NOT EXISTS (SELECT 1 FROM users WHERE role = ?)"
+	if err := DB.Exec(promoteFirstUser, models.RoleAdmin, models.RoleAdmin).Error; err != nil {
+		fmt.Printf("Warning: Failed to promote first user to admin: %v\n", err)
+	}
+
+	// API keys used to be stored in plaintext
+	if err := migrateLegacyAPIKeys(DB); err != nil {
+		return fmt.Errorf("failed to migrate API keys: %v", err)
+	}
+
 	return nil
 }
 
+// migrateLegacyAPIKeys replaces plaintext API keys with their hash and drops
+// the plaintext column. Existing keys keep the access their owner has so
+// automations that already use them continue to work.
+func migrateLegacyAPIKeys(db *gorm.DB) error {
+	// Migrator().HasColumn pattern-matches the table DDL, where "key" also
+	// matches PRIMARY KEY, so ask SQLite for the real column list instead
+	var plaintextColumns int64
+	if err := db.Raw("SELECT COUNT(*) FROM pragma_table_info('api_keys') WHERE name = 'key'").Scan(&plaintextColumns).Error; err != nil {
+		return err
+	}
+	if plaintextColumns == 0 {
+		return nil
+	}
+
+	adminScopes, err := json.Marshal(models.AllAPIKeyScopes)
+	if err != nil {
+		return err
+	}
+	memberScopes, err := json.Marshal(models.DefaultAPIKeyScopes)
+	if err != nil {
+		return err
+	}
+
+	// Hash every key and drop the plaintext column together, so an
+	// interrupted migration never leaves keys that cannot be looked up
+	return db.Transaction(func(tx *gorm.DB) error {
+		var legacy []struct {
+			ID   uint
+			Key  string
+			Role string
+		}
+		if err := tx.Table("api_keys").
+			Select("api_keys.id, api_keys.key, users.role").
+			Joins("LEFT JOIN users ON users.id = api_keys.user_id").
+			Where("api_keys.key_hash IS NULL OR api_keys.key_hash = ''").
+			Scan(&legacy).Error; err != nil {
+			return err
+		}
+
+		for _, k := range legacy {
+			scopes := memberScopes
+			if k.Role == models.RoleAdmin {
+				scopes = adminScopes
+			}
+			if err := tx.Table("api_keys").Where("id = ?", k.ID).Updates(map[string]interface{}{
+				"key_hash":   models.HashAPIKey(k.Key),
+				"key_prefix": models.APIKeyPrefix(k.Key),
+				"scopes":     string(scopes),
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if tx.Migrator().HasIndex(&models.APIKey{}, "idx_api_keys_key") {
+			if err := tx.Migrator().DropIndex(&models.APIKey{}, "idx_api_keys_key"); err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().DropColumn(&models.APIKey{}, "key")
+	})
+}
+
 // Close closes the database connection gracefully
 func Close() error {
 	if DB == nil {
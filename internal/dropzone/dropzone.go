@@ -259,6 +259,11 @@ func (s *Service) uploadFile(sourcePath, originalFilename string) error {
 		Title:     &originalFilename, // Use original filename as title
 	}
 
+	// The dropzone is a server-local folder, so imports belong to the instance owner
+	if owner, err := s.userRepo.FindFirst(context.Background()); err == nil {
+		job.UserID = owner.ID
+	}
+
 	// Save to database
 	if err := s.jobRepo.Create(context.Background(), &job); err != nil {
 		os.Remove(destPath) // Clean up file on database error
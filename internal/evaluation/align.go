@@ -0,0 +1,137 @@
+package evaluation
+
+// edit is one step of an alignment; ref and hyp are token indexes, -1 when
+// the step has no token on that side
+type edit struct {
+	kind string
+	ref  int
+	hyp  int
+}
+
+// smallAlignment is the largest problem aligned with a full edit distance
+// matrix; larger ones are divided with Hirschberg's algorithm so memory stays
+// linear in the transcript length
+const smallAlignment = 1 << 16
+
+// align returns a minimal edit script turning ref into hyp. Among scripts
+// with the fewest edits it picks one with the most matches, so a word that
+// appears in both is shown as equal rather than as part of a substitution.
+func align[T comparable](ref, hyp []T) []edit {
+	// Each edit costs more than all matches together can save
+	c := costs{edit: len(ref) + len(hyp) + 1, match: -1}
+	return hirschberg(ref, hyp, 0, 0, c, make([]edit, 0, max(len(ref), len(hyp))))
+}
+
+// costs are the step weights of an alignment
+type costs struct {
+	edit  int
+	match int
+}
+
+func (c costs) step(equal bool) int {
+	if equal {
+		return c.match
+	}
+	return c.edit
+}
+
+func hirschberg[T comparable](ref, hyp []T, refOffset, hypOffset int, c costs, out []edit) []edit {
+	switch {
+	case len(ref) == 0:
+		for j := range hyp {
+			out = append(out, edit{kind: OpInsert, ref: -1, hyp: hypOffset + j})
+		}
+		return out
+	case len(hyp) == 0:
+		for i := range ref {
+			out = append(out, edit{kind: OpDelete, ref: refOffset + i, hyp: -1})
+		}
+		return out
+	case len(ref) == 1 || len(ref)*len(hyp) <= smallAlignment:
+		return alignFull(ref, hyp, refOffset, hypOffset, c, out)
+	}
+
+	// Split the reference in half and find where the hypothesis is best cut
+	// by combining the costs of aligning each half from its own end
+	mid := len(ref) / 2
+	forward := lastRow(ref[:mid], hyp, c)
+	backward := lastRow(reversed(ref[mid:]), reversed(hyp), c)
+	split := 0
+	for j := 1; j <= len(hyp); j++ {
+		if forward[j]+backward[len(hyp)-j] < forward[split]+backward[len(hyp)-split] {
+			split = j
+		}
+	}
+
+	out = hirschberg(ref[:mid], hyp[:split], refOffset, hypOffset, c, out)
+	return hirschberg(ref[mid:], hyp[split:], refOffset+mid, hypOffset+split, c, out)
+}
+
+// lastRow returns the alignment costs between ref and every prefix of hyp
+func lastRow[T comparable](ref, hyp []T, c costs) []int {
+	prev := make([]int, len(hyp)+1)
+	cur := make([]int, len(hyp)+1)
+	for j := range prev {
+		prev[j] = j * c.edit
+	}
+	for i := range ref {
+		cur[0] = (i + 1) * c.edit
+		for j := range hyp {
+			cur[j+1] = min(prev[j]+c.step(ref[i] == hyp[j]), prev[j+1]+c.edit, cur[j]+c.edit)
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func alignFull[T comparable](ref, hyp []T, refOffset, hypOffset int, c costs, out []edit) []edit {
+	width := len(hyp) + 1
+	dist := make([]int, (len(ref)+1)*width)
+	for j := 0; j <= len(hyp); j++ {
+		dist[j] = j * c.edit
+	}
+	for i := 1; i <= len(ref); i++ {
+		dist[i*width] = i * c.edit
+		for j := 1; j <= len(hyp); j++ {
+			dist[i*width+j] = min(
+				dist[(i-1)*width+j-1]+c.step(ref[i-1] == hyp[j-1]),
+				dist[(i-1)*width+j]+c.edit,
+				dist[i*width+j-1]+c.edit,
+			)
+		}
+	}
+
+	start := len(out)
+	i, j := len(ref), len(hyp)
+	for i > 0 || j > 0 {
+		d := dist[i*width+j]
+		switch {
+		case i > 0 && j > 0 && ref[i-1] == hyp[j-1] && d == dist[(i-1)*width+j-1]+c.match:
+			i, j = i-1, j-1
+			out = append(out, edit{kind: OpEqual, ref: refOffset + i, hyp: hypOffset + j})
+		case i > 0 && j > 0 && ref[i-1] != hyp[j-1] && d == dist[(i-1)*width+j-1]+c.edit:
+			i, j = i-1, j-1
+			out = append(out, edit{kind: OpSubstitute, ref: refOffset + i, hyp: hypOffset + j})
+		case i > 0 && d == dist[(i-1)*width+j]+c.edit:
+			i--
+			out = append(out, edit{kind: OpDelete, ref: refOffset + i, hyp: -1})
+		default:
+			j--
+			out = append(out, edit{kind: OpInsert, ref: -1, hyp: hypOffset + j})
+		}
+	}
+
+	// The trace was collected from the end
+	for a, b := start, len(out)-1; a < b; a, b = a+1, b-1 {
+		out[a], out[b] = out[b], out[a]
+	}
+	return out
+}
+
+func reversed[T any](s []T) []T {
+	r := make([]T, len(s))
+	for i, v := range s {
+		r[len(s)-1-i] = v
+	}
+	return r
+}
@@ -0,0 +1,244 @@
+// Package evaluation measures transcription accuracy with word and character
+// error rates and aligns transcripts against each other for review.
+package evaluation
+
+import (
+	"strings"
+	"unicode"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// Alignment operation types
+const (
+	OpEqual      = "equal"
+	OpSubstitute = "substitute"
+	OpInsert     = "insert" // Word only in the hypothesis
+	OpDelete     = "delete" // Word only in the reference
+)
+
+// Op is a run of aligned words of the same type
+type Op struct {
+	Type string `json:"type"`
+	Ref  string `json:"ref,omitempty"`
+	Hyp  string `json:"hyp,omitempty"`
+}
+
+// Score is an error rate with the edit counts it was computed from
+type Score struct {
+	Rate            float64 `json:"rate"`
+	Substitutions   int     `json:"substitutions"`
+	Deletions       int     `json:"deletions"`
+	Insertions      int     `json:"insertions"`
+	Hits            int     `json:"hits"`
+	ReferenceLength int     `json:"reference_length"`
+}
+
+// SegmentDiff is the alignment of the reference against one hypothesis segment
+type SegmentDiff struct {
+	Start  float64 `json:"start"`
+	End    float64 `json:"end"`
+	Text   string  `json:"text"`
+	Errors int     `json:"errors"`
+	Ops    []Op    `json:"ops"`
+}
+
+// Result is the comparison of a transcript against a reference
+type Result struct {
+	WER      Score         `json:"wer"`
+	CER      Score         `json:"cer"`
+	Segments []SegmentDiff `json:"segments"`
+}
+
+// Words splits text into lower-cased words, dropping punctuation so that
+// formatting differences between models are not counted as errors
+func Words(text string) []string {
+	text = strings.NewReplacer("’", "'", "‘", "'").Replace(strings.ToLower(text))
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	result := words[:0]
+	for _, w := range words {
+		if w = strings.Trim(w, "'"); w != "" {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+// TranscriptText returns the full text of a transcript, joining the segments
+// when the model did not provide one
+func TranscriptText(t *interfaces.TranscriptResult) string {
+	if strings.TrimSpace(t.Text) != "" || len(t.Segments) == 0 {
+		return t.Text
+	}
+	texts := make([]string, len(t.Segments))
+	for i, s := range t.Segments {
+		texts[i] = strings.TrimSpace(s.Text)
+	}
+	return strings.Join(texts, " ")
+}
+
+// Compare aligns hypothesis against the reference text and computes the word
+// and character error rates. The alignment is split along the hypothesis
+// segments so differences can be shown next to the audio they belong to.
+func Compare(reference string, hypothesis *interfaces.TranscriptResult) Result {
+	ref := Words(reference)
+
+	segments := hypothesis.Segments
+	if len(segments) == 0 {
+		segments = []interfaces.TranscriptSegment{{Text: hypothesis.Text}}
+	}
+	var hyp []string
+	var hypSegment []int
+	for i, s := range segments {
+		for _, w := range Words(s.Text) {
+			hyp = append(hyp, w)
+			hypSegment = append(hypSegment, i)
+		}
+	}
+
+	edits := align(ref, hyp)
+
+	diffs := make([]SegmentDiff, len(segments))
+	for i, s := range segments {
+		diffs[i] = SegmentDiff{Start: s.Start, End: s.End, Text: strings.TrimSpace(s.Text), Ops: []Op{}}
+	}
+	// Reference words missing from the hypothesis belong to the segment of
+	// the word before them, or the first segment at the start
+	current := 0
+	for _, e := range edits {
+		if e.hyp >= 0 {
+			current = hypSegment[e.hyp]
+		}
+		diff := &diffs[current]
+		if e.kind != OpEqual {
+			diff.Errors++
+		}
+		var refWord, hypWord string
+		if e.ref >= 0 {
+			refWord = ref[e.ref]
+		}
+		if e.hyp >= 0 {
+			hypWord = hyp[e.hyp]
+		}
+		if n := len(diff.Ops); n > 0 && diff.Ops[n-1].Type == e.kind {
+			diff.Ops[n-1].Ref = joinWord(diff.Ops[n-1].Ref, refWord)
+			diff.Ops[n-1].Hyp = joinWord(diff.Ops[n-1].Hyp, hypWord)
+		} else {
+			diff.Ops = append(diff.Ops, Op{Type: e.kind, Ref: refWord, Hyp: hypWord})
+		}
+	}
+
+	return Result{
+		WER:      wordScore(edits, len(ref)),
+		CER:      charScore(edits, ref, hyp),
+		Segments: diffs,
+	}
+}
+
+// WER returns the word error rate of hypothesis against reference
+func WER(reference, hypothesis string) Score {
+	ref := Words(reference)
+	return wordScore(align(ref, Words(hypothesis)), len(ref))
+}
+
+// CER returns the character error rate of hypothesis against reference
+func CER(reference, hypothesis string) Score {
+	ref, hyp := Words(reference), Words(hypothesis)
+	return charScore(align(ref, hyp), ref, hyp)
+}
+
+func joinWord(run, word string) string {
+	if run == "" {
+		return word
+	}
+	if word == "" {
+		return run
+	}
+	return run + " " + word
+}
+
+func wordScore(edits []edit, refLength int) Score {
+	score := Score{ReferenceLength: refLength}
+	for _, e := range edits {
+		score.count(e.kind, 1)
+	}
+	score.rate()
+	return score
+}
+
+// maxCharAlignment caps the size of a mismatched region that is aligned
+// character by character; larger regions are counted without alignment
+const maxCharAlignment = 1 << 22
+
+// charScore computes the character error rate over the word alignment.
+// Matching words only contribute hits, and each run of mismatched words is
+// aligned character by character, which keeps the cost proportional to the
+// differences rather than the square of the transcript length. Whitespace is
+// not counted, so words split or joined differently only cost their
+// characters.
+func charScore(edits []edit, ref, hyp []string) Score {
+	var score Score
+	var refRun, hypRun []rune
+	flush := func() {
+		if len(refRun) == 0 && len(hypRun) == 0 {
+			return
+		}
+		if len(refRun)*len(hypRun) > maxCharAlignment {
+			common := min(len(refRun), len(hypRun))
+			score.count(OpSubstitute, common)
+			score.count(OpDelete, len(refRun)-common)
+			score.count(OpInsert, len(hypRun)-common)
+		} else {
+			for _, e := range align(refRun, hypRun) {
+				score.count(e.kind, 1)
+			}
+		}
+		refRun, hypRun = refRun[:0], hypRun[:0]
+	}
+
+	for _, e := range edits {
+		if e.kind == OpEqual {
+			flush()
+			score.count(OpEqual, len([]rune(ref[e.ref])))
+			continue
+		}
+		if e.ref >= 0 {
+			refRun = append(refRun, []rune(ref[e.ref])...)
+		}
+		if e.hyp >= 0 {
+			hypRun = append(hypRun, []rune(hyp[e.hyp])...)
+		}
+	}
+	flush()
+
+	score.ReferenceLength = score.Hits + score.Substitutions + score.Deletions
+	score.rate()
+	return score
+}
+
+func (s *Score) count(kind string, n int) {
+	switch kind {
+	case OpEqual:
+		s.Hits += n
+	case OpSubstitute:
+		s.Substitutions += n
+	case OpInsert:
+		s.Insertions += n
+	case OpDelete:
+		s.Deletions += n
+	}
+}
+
+func (s *Score) rate() {
+	errors := s.Substitutions + s.Deletions + s.Insertions
+	switch {
+	case s.ReferenceLength > 0:
+		s.Rate = float64(errors) / float64(s.ReferenceLength)
+	case errors > 0:
+		s.Rate = 1
+	default:
+		s.Rate = 0
+	}
+}
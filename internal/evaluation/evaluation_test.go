@@ -0,0 +1,135 @@
+package evaluation
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWords(t *testing.T) {
+	assert.Equal(t, []string{"hello", "world", "don't", "well", "known", "42"},
+		Words("Hello, WORLD! Don’t -- 'well-known' 42."))
+	assert.Empty(t, Words(" ... "))
+}
+
+func TestWER(t *testing.T) {
+	score := WER("the cat sat on the mat", "cat sit on the mat today")
+	assert.Equal(t, 1, score.Substitutions)
+	assert.Equal(t, 1, score.Deletions)
+	assert.Equal(t, 1, score.Insertions)
+	assert.Equal(t, 4, score.Hits)
+	assert.Equal(t, 6, score.ReferenceLength)
+	assert.InDelta(t, 0.5, score.Rate, 1e-9)
+
+	// Of the alignments with four errors the one keeping "let's" as a match wins
+	score = WER("good morning everyone let's begin", "good mourning every one let's")
+	assert.Equal(t, 2, score.Hits)
+	assert.Equal(t, 4, score.Substitutions+score.Deletions+score.Insertions)
+
+	assert.Equal(t, 0.0, WER("Hello, world.", "hello world").Rate)
+	assert.Equal(t, 1.0, WER("", "noise").Rate)
+	assert.Equal(t, 0.0, WER("", "").Rate)
+}
+
+func TestCER(t *testing.T) {
+	score := CER("the cat sat", "the cat sit")
+	assert.Equal(t, 1, score.Substitutions)
+	assert.Equal(t, 9, score.ReferenceLength)
+	assert.InDelta(t, 1.0/9, score.Rate, 1e-9)
+
+	// Words joined differently only cost their characters, not whitespace
+	assert.Equal(t, 0.0, CER("ice cream", "icecream").Rate)
+}
+
+func TestAlignLargeInputs(t *testing.T) {
+	// Inputs above smallAlignment go through Hirschberg and must give the
+	// same distance as the full matrix
+	rng := rand.New(rand.NewSource(1))
+	vocabulary := []string{"a", "b", "c", "d", "e"}
+	ref := make([]string, 600)
+	hyp := make([]string, 550)
+	for i := range ref {
+		ref[i] = vocabulary[rng.Intn(len(vocabulary))]
+	}
+	for i := range hyp {
+		hyp[i] = vocabulary[rng.Intn(len(vocabulary))]
+	}
+
+	split := align(ref, hyp)
+	full := alignFull(ref, hyp, 0, 0, costs{edit: len(ref) + len(hyp) + 1, match: -1}, nil)
+	errors := func(edits []edit) int {
+		n := 0
+		for _, e := range edits {
+			if e.kind != OpEqual {
+				n++
+			}
+		}
+		return n
+	}
+	assert.Equal(t, errors(full), errors(split))
+
+	// The script must consume both sides in order
+	nextRef, nextHyp := 0, 0
+	for _, e := range split {
+		if e.ref >= 0 {
+			assert.Equal(t, nextRef, e.ref)
+			nextRef++
+		}
+		if e.hyp >= 0 {
+			assert.Equal(t, nextHyp, e.hyp)
+			nextHyp++
+		}
+	}
+	assert.Equal(t, len(ref), nextRef)
+	assert.Equal(t, len(hyp), nextHyp)
+}
+
+func TestCompareSegments(t *testing.T) {
+	hypothesis := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 2, Text: "Good morning every one."},
+			{Start: 2, End: 4, Text: "Let's begin."},
+		},
+	}
+
+	result := Compare("Good morning everyone. Let's begin the meeting.", hypothesis)
+	assert.Equal(t, 1, result.WER.Substitutions)
+	assert.Equal(t, 1, result.WER.Insertions)
+	assert.Equal(t, 2, result.WER.Deletions)
+	if assert.Len(t, result.Segments, 2) {
+		first := result.Segments[0]
+		assert.Equal(t, "Good morning every one.", first.Text)
+		assert.Equal(t, 2, first.Errors)
+		assert.Equal(t, []Op{
+			{Type: OpEqual, Ref: "good morning", Hyp: "good morning"},
+			{Type: OpInsert, Hyp: "every"},
+			{Type: OpSubstitute, Ref: "everyone", Hyp: "one"},
+		}, first.Ops)
+
+		// Trailing words missing from the hypothesis go to the last segment
+		second := result.Segments[1]
+		assert.Equal(t, 2.0, second.Start)
+		assert.Equal(t, 2, second.Errors)
+		assert.Equal(t, Op{Type: OpDelete, Ref: "the meeting"}, second.Ops[len(second.Ops)-1])
+	}
+
+	// "every one" and "everyone" differ in whitespace only
+	assert.Equal(t, len("themeeting"), result.CER.Deletions)
+	assert.Equal(t, 0, result.CER.Substitutions)
+
+	noSegments := Compare("one two", &interfaces.TranscriptResult{Text: "one two"})
+	assert.Equal(t, 0.0, noSegments.WER.Rate)
+	assert.Len(t, noSegments.Segments, 1)
+}
+
+func TestTranscriptText(t *testing.T) {
+	assert.Equal(t, "full", TranscriptText(&interfaces.TranscriptResult{Text: "full"}))
+	joined := TranscriptText(&interfaces.TranscriptResult{Segments: []interfaces.TranscriptSegment{
+		{Text: " first "}, {Text: "second"},
+	}})
+	assert.Equal(t, "first second", strings.TrimSpace(joined))
+}
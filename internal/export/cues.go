@@ -0,0 +1,164 @@
+package export
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// cue is a single timed subtitle block
+type cue struct {
+	Start   float64
+	End     float64
+	Speaker string
+	Lines   []string
+}
+
+// timedToken is a word with its (possibly estimated) timing
+type timedToken struct {
+	Text  string
+	Start float64
+	End   float64
+}
+
+// buildCues turns transcript segments into subtitle cues, wrapping text to
+// MaxLineWidth characters per line and splitting cues after MaxLineCount lines.
+func buildCues(result *interfaces.TranscriptResult, opts Options) []cue {
+	segments := result.Segments
+	if len(segments) == 0 && strings.TrimSpace(result.Text) != "" {
+		segments = []interfaces.TranscriptSegment{{Text: result.Text}}
+	}
+
+	var cues []cue
+	wordIdx := 0
+	for _, seg := range segments {
+		text := strings.Join(strings.Fields(seg.Text), " ")
+		if text == "" {
+			continue
+		}
+		speaker := speakerName(seg.Speaker, opts)
+
+		// Word timings are consumed even when no wrapping is requested so that
+		// the cursor stays aligned with the segment list.
+		var words []timedToken
+		words, wordIdx = segmentWords(result.WordSegments, wordIdx, seg)
+
+		if opts.MaxLineWidth <= 0 && opts.MaxLineCount <= 0 {
+			cues = append(cues, cue{Start: seg.Start, End: seg.End, Speaker: speaker, Lines: []string{text}})
+			continue
+		}
+
+		// Words the alignment missed would vanish from the cue, so the
+		// segment text is used whenever the word timings do not cover it
+		if len(words) != len(strings.Fields(text)) {
+			words = estimateWordTimings(text, seg.Start, seg.End)
+		}
+		cues = append(cues, wrapTokens(words, seg, speaker, opts)...)
+	}
+	return cues
+}
+
+// segmentWords collects the word timings whose midpoint falls inside seg,
+// starting at cursor. It returns the words and the advanced cursor.
+func segmentWords(all []interfaces.TranscriptWord, cursor int, seg interfaces.TranscriptSegment) ([]timedToken, int) {
+	var words []timedToken
+	for cursor < len(all) {
+		w := all[cursor]
+		mid := (w.Start + w.End) / 2
+		if mid < seg.Start {
+			cursor++
+			continue
+		}
+		if mid > seg.End {
+			break
+		}
+		if t := strings.TrimSpace(w.Word); t != "" {
+			words = append(words, timedToken{Text: t, Start: w.Start, End: w.End})
+		}
+		cursor++
+	}
+	return words, cursor
+}
+
+// estimateWordTimings spreads the segment duration across its words in
+// proportion to their length in characters when no word-level timing is available.
+func estimateWordTimings(text string, start, end float64) []timedToken {
+	fields := strings.Fields(text)
+	totalChars := 0
+	for _, f := range fields {
+		totalChars += utf8.RuneCountInString(f)
+	}
+	if totalChars == 0 {
+		return nil
+	}
+
+	duration := end - start
+	tokens := make([]timedToken, 0, len(fields))
+	pos := start
+	for _, f := range fields {
+		d := duration * float64(utf8.RuneCountInString(f)) / float64(totalChars)
+		tokens = append(tokens, timedToken{Text: f, Start: pos, End: pos + d})
+		pos += d
+	}
+	return tokens
+}
+
+// wrapTokens greedily packs tokens into lines and lines into cues
+func wrapTokens(tokens []timedToken, seg interfaces.TranscriptSegment, speaker string, opts Options) []cue {
+	var cues []cue
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0 // In characters, as MaxLineWidth is
+	cueStart := -1.0
+	cueEnd := 0.0
+
+	flushLine := func() {
+		if line.Len() > 0 {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+	}
+	flushCue := func() {
+		flushLine()
+		if len(lines) == 0 {
+			return
+		}
+		cues = append(cues, cue{Start: cueStart, End: cueEnd, Speaker: speaker, Lines: lines})
+		lines = nil
+		cueStart = -1
+	}
+
+	for _, tok := range tokens {
+		width := utf8.RuneCountInString(tok.Text)
+		if opts.MaxLineWidth > 0 && lineWidth > 0 && lineWidth+1+width > opts.MaxLineWidth {
+			flushLine()
+		}
+		if opts.MaxLineCount > 0 && len(lines) >= opts.MaxLineCount && line.Len() == 0 {
+			flushCue()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+			lineWidth++
+		}
+		line.WriteString(tok.Text)
+		lineWidth += width
+		if cueStart < 0 {
+			cueStart = tok.Start
+		}
+		cueEnd = tok.End
+	}
+	flushCue()
+
+	// Keep the outer cue boundaries aligned with the segment itself
+	if len(cues) > 0 {
+		if seg.Start < cues[0].Start {
+			cues[0].Start = seg.Start
+		}
+		if seg.End > cues[len(cues)-1].End {
+			cues[len(cues)-1].End = seg.End
+		}
+	}
+	return cues
+}
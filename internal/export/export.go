@@ -0,0 +1,255 @@
+// Package export renders stored transcripts into subtitle and document formats.
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// Format identifies an export output format
+type Format string
+
+const (
+	FormatSRT      Format = "srt"
+	FormatVTT      Format = "vtt"
+	FormatTXT      Format = "txt"
+	FormatMarkdown Format = "md"
+	FormatJSON     Format = "json"
+	FormatTSV      Format = "tsv"
+)
+
+// SupportedFormats lists every format accepted by Render
+var SupportedFormats = []Format{FormatSRT, FormatVTT, FormatTXT, FormatMarkdown, FormatJSON, FormatTSV}
+
+// ParseFormat validates a user supplied format string
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	for _, supported := range SupportedFormats {
+		if f == supported {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported export format %q", s)
+}
+
+// ContentType returns the HTTP content type for the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatSRT:
+		return "application/x-subrip; charset=utf-8"
+	case FormatVTT:
+		return "text/vtt; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	case FormatTSV:
+		return "text/tab-separated-values; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Options controls how a transcript is rendered
+type Options struct {
+	// Title is used as the document heading for markdown output
+	Title string
+	// SpeakerNames maps original speaker labels (e.g. SPEAKER_00) to display names
+	SpeakerNames map[string]string
+	// MaxLineWidth is the maximum number of characters per subtitle line (0 = unlimited)
+	MaxLineWidth int
+	// MaxLineCount is the maximum number of lines per subtitle cue (0 = unlimited)
+	MaxLineCount int
+}
+
+// Render converts a transcript into the requested format
+func Render(result *interfaces.TranscriptResult, format Format, opts Options) ([]byte, error) {
+	if result == nil {
+		return nil, fmt.Errorf("transcript is empty")
+	}
+
+	switch format {
+	case FormatSRT:
+		return renderSRT(result, opts), nil
+	case FormatVTT:
+		return renderVTT(result, opts), nil
+	case FormatTXT:
+		return renderTXT(result, opts), nil
+	case FormatMarkdown:
+		return renderMarkdown(result, opts), nil
+	case FormatJSON:
+		return renderJSON(result, opts)
+	case FormatTSV:
+		return renderTSV(result, opts), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+func renderSRT(result *interfaces.TranscriptResult, opts Options) []byte {
+	var buf bytes.Buffer
+	for i, cue := range buildCues(result, opts) {
+		text := strings.Join(cue.Lines, "\n")
+		if cue.Speaker != "" {
+			text = fmt.Sprintf("[%s] %s", cue.Speaker, text)
+		}
+		fmt.Fprintf(&buf, "%d\n%s --> %s\n%s\n\n", i+1, formatTimestamp(cue.Start, ","), formatTimestamp(cue.End, ","), text)
+	}
+	return buf.Bytes()
+}
+
+// vttEscaper escapes the characters WebVTT treats as markup in cue text and
+// voice names, which also keeps "-->" out of the cue payload
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func renderVTT(result *interfaces.TranscriptResult, opts Options) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("WEBVTT\n\n")
+	for _, cue := range buildCues(result, opts) {
+		text := vttEscaper.Replace(strings.Join(cue.Lines, "\n"))
+		if cue.Speaker != "" {
+			text = fmt.Sprintf("<v %s>%s", vttEscaper.Replace(cue.Speaker), text)
+		}
+		fmt.Fprintf(&buf, "%s --> %s\n%s\n\n", formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."), text)
+	}
+	return buf.Bytes()
+}
+
+func renderTXT(result *interfaces.TranscriptResult, opts Options) []byte {
+	if len(result.Segments) == 0 {
+		return []byte(strings.TrimSpace(result.Text) + "\n")
+	}
+
+	var buf bytes.Buffer
+	for _, seg := range result.Segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		if speaker := speakerName(seg.Speaker, opts); speaker != "" {
+			fmt.Fprintf(&buf, "[%s] %s: %s\n", formatClock(seg.Start), speaker, text)
+		} else {
+			fmt.Fprintf(&buf, "[%s] %s\n", formatClock(seg.Start), text)
+		}
+	}
+	return buf.Bytes()
+}
+
+func renderMarkdown(result *interfaces.TranscriptResult, opts Options) []byte {
+	var buf bytes.Buffer
+	title := opts.Title
+	if title == "" {
+		title = "Transcript"
+	}
+	fmt.Fprintf(&buf, "# %s\n\n", title)
+
+	if len(result.Segments) == 0 {
+		buf.WriteString(strings.TrimSpace(result.Text))
+		buf.WriteString("\n")
+		return buf.Bytes()
+	}
+
+	// Consecutive segments from the same speaker are grouped into one paragraph
+	lastSpeaker := ""
+	first := true
+	for _, seg := range result.Segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		speaker := speakerName(seg.Speaker, opts)
+		if first || speaker != lastSpeaker {
+			if !first {
+				buf.WriteString("\n\n")
+			}
+			if speaker != "" {
+				fmt.Fprintf(&buf, "**%s** _(%s)_\n\n", speaker, formatClock(seg.Start))
+			} else {
+				fmt.Fprintf(&buf, "_(%s)_\n\n", formatClock(seg.Start))
+			}
+			buf.WriteString(text)
+		} else {
+			buf.WriteString(" ")
+			buf.WriteString(text)
+		}
+		lastSpeaker = speaker
+		first = false
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+func renderJSON(result *interfaces.TranscriptResult, opts Options) ([]byte, error) {
+	// Copy so the caller's result is not mutated by speaker renaming
+	out := *result
+	out.Segments = make([]interfaces.TranscriptSegment, len(result.Segments))
+	for i, seg := range result.Segments {
+		seg.Speaker = mappedSpeaker(seg.Speaker, opts)
+		out.Segments[i] = seg
+	}
+	if len(result.WordSegments) > 0 {
+		out.WordSegments = make([]interfaces.TranscriptWord, len(result.WordSegments))
+		for i, w := range result.WordSegments {
+			w.Speaker = mappedSpeaker(w.Speaker, opts)
+			out.WordSegments[i] = w
+		}
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+func renderTSV(result *interfaces.TranscriptResult, opts Options) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("start\tend\tspeaker\ttext\n")
+	for _, seg := range result.Segments {
+		text := strings.Join(strings.Fields(seg.Text), " ")
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, "%d\t%d\t%s\t%s\n", int64(seg.Start*1000+0.5), int64(seg.End*1000+0.5), speakerName(seg.Speaker, opts), text)
+	}
+	return buf.Bytes()
+}
+
+// speakerName returns the display name for a speaker label, or "" if none
+func speakerName(speaker *string, opts Options) string {
+	if speaker == nil || *speaker == "" {
+		return ""
+	}
+	if name, ok := opts.SpeakerNames[*speaker]; ok && name != "" {
+		return name
+	}
+	return *speaker
+}
+
+func mappedSpeaker(speaker *string, opts Options) *string {
+	name := speakerName(speaker, opts)
+	if name == "" {
+		return speaker
+	}
+	return &name
+}
+
+// formatTimestamp renders seconds as HH:MM:SS<sep>mmm
+func formatTimestamp(seconds float64, sep string) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	ms := int64(seconds*1000 + 0.5)
+	h := ms / 3600000
+	m := (ms % 3600000) / 60000
+	s := (ms % 60000) / 1000
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", h, m, s, sep, ms%1000)
+}
+
+// formatClock renders seconds as HH:MM:SS for human readable formats
+func formatClock(seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	total := int64(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, (total%3600)/60, total%60)
+}
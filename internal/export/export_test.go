@@ -0,0 +1,192 @@
+package export
+
+import (
+	"encoding/json"
+	"testing"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func speaker(s string) *string { return &s }
+
+// testTranscript has a renamed speaker with markup characters in the name,
+// markup in the text and timestamps past the first hour
+func testTranscript() *interfaces.TranscriptResult {
+	return &interfaces.TranscriptResult{
+		Text: "Hello there. Use a <b> tag & 1 --> 2 Bye.",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0.5, End: 3, Text: " Hello there. ", Speaker: speaker("SPEAKER_00")},
+			{Start: 3723.25, End: 3725.5, Text: "Use a <b> tag & 1 --> 2", Speaker: speaker("SPEAKER_01")},
+			{Start: 3725.5, End: 3726, Text: "Bye."},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0.5, End: 1, Word: "Hello", Speaker: speaker("SPEAKER_00")},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	opts := Options{Title: "Meeting", SpeakerNames: map[string]string{"SPEAKER_00": "Alice & <Bob>"}}
+
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{
+			name:   "srt",
+			format: FormatSRT,
+			want: "1\n00:00:00,500 --> 00:00:03,000\n[Alice & <Bob>] Hello there.\n\n" +
+				"2\n01:02:03,250 --> 01:02:05,500\n[SPEAKER_01] Use a <b> tag & 1 --> 2\n\n" +
+				"3\n01:02:05,500 --> 01:02:06,000\nBye.\n\n",
+		},
+		{
+			name:   "vtt escapes speaker names and cue text",
+			format: FormatVTT,
+			want: "WEBVTT\n\n" +
+				"00:00:00.500 --> 00:00:03.000\n<v Alice &amp; &lt;Bob&gt;>Hello there.\n\n" +
+				"01:02:03.250 --> 01:02:05.500\n<v SPEAKER_01>Use a &lt;b&gt; tag &amp; 1 --&gt; 2\n\n" +
+				"01:02:05.500 --> 01:02:06.000\nBye.\n\n",
+		},
+		{
+			name:   "txt",
+			format: FormatTXT,
+			want: "[00:00:00] Alice & <Bob>: Hello there.\n" +
+				"[01:02:03] SPEAKER_01: Use a <b> tag & 1 --> 2\n" +
+				"[01:02:05] Bye.\n",
+		},
+		{
+			name:   "markdown",
+			format: FormatMarkdown,
+			want: "# Meeting\n\n" +
+				"**Alice & <Bob>** _(00:00:00)_\n\nHello there.\n\n" +
+				"**SPEAKER_01** _(01:02:03)_\n\nUse a <b> tag & 1 --> 2\n\n" +
+				"_(01:02:05)_\n\nBye.\n",
+		},
+		{
+			name:   "tsv",
+			format: FormatTSV,
+			want: "start\tend\tspeaker\ttext\n" +
+				"500\t3000\tAlice & <Bob>\tHello there.\n" +
+				"3723250\t3725500\tSPEAKER_01\tUse a <b> tag & 1 --> 2\n" +
+				"3725500\t3726000\t\tBye.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Render(testTranscript(), tt.format, opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(out))
+		})
+	}
+}
+
+func TestRenderJSONRenamesSpeakers(t *testing.T) {
+	result := testTranscript()
+	out, err := Render(result, FormatJSON, Options{SpeakerNames: map[string]string{"SPEAKER_00": "Alice"}})
+	assert.NoError(t, err)
+
+	var exported interfaces.TranscriptResult
+	if !assert.NoError(t, json.Unmarshal(out, &exported)) {
+		return
+	}
+	assert.Equal(t, "Alice", *exported.Segments[0].Speaker)
+	assert.Equal(t, "SPEAKER_01", *exported.Segments[1].Speaker)
+	assert.Nil(t, exported.Segments[2].Speaker)
+	assert.Equal(t, "Alice", *exported.WordSegments[0].Speaker)
+	assert.Equal(t, 3723.25, exported.Segments[1].Start)
+
+	// The caller's transcript keeps its original labels
+	assert.Equal(t, "SPEAKER_00", *result.Segments[0].Speaker)
+}
+
+func TestRenderWithoutSegments(t *testing.T) {
+	result := &interfaces.TranscriptResult{Text: " Just text. "}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatSRT, "1\n00:00:00,000 --> 00:00:00,000\nJust text.\n\n"},
+		{FormatVTT, "WEBVTT\n\n00:00:00.000 --> 00:00:00.000\nJust text.\n\n"},
+		{FormatTXT, "Just text.\n"},
+		{FormatMarkdown, "# Transcript\n\nJust text.\n"},
+		{FormatTSV, "start\tend\tspeaker\ttext\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			out, err := Render(result, tt.format, Options{})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(out))
+		})
+	}
+
+	_, err := Render(nil, FormatSRT, Options{})
+	assert.Error(t, err)
+}
+
+func TestBuildCuesWrapsByCharacters(t *testing.T) {
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{{Start: 0, End: 8, Text: "Привет мир как дела"}},
+	}
+	cues := buildCues(result, Options{MaxLineWidth: 10, MaxLineCount: 1})
+	if assert.Len(t, cues, 2) {
+		assert.Equal(t, []string{"Привет мир"}, cues[0].Lines)
+		assert.Equal(t, []string{"как дела"}, cues[1].Lines)
+		// 9 of the 16 letters are in the first cue
+		assert.InDelta(t, 4.5, cues[0].End, 0.001)
+		assert.InDelta(t, 4.5, cues[1].Start, 0.001)
+	}
+}
+
+func TestBuildCuesKeepsWordsMissingFromAlignment(t *testing.T) {
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{{Start: 0, End: 3, Text: "Hello there friend"}},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0, End: 1, Word: "Hello"},
+			{Start: 2, End: 3, Word: "friend"},
+		},
+	}
+	cues := buildCues(result, Options{MaxLineWidth: 42})
+	if assert.Len(t, cues, 1) {
+		assert.Equal(t, []string{"Hello there friend"}, cues[0].Lines)
+	}
+
+	// Word timings covering every word are used as they are
+	result.WordSegments = append(result.WordSegments[:1], interfaces.TranscriptWord{Start: 1, End: 1.2, Word: "there"}, result.WordSegments[1])
+	cues = buildCues(result, Options{MaxLineWidth: 11})
+	if assert.Len(t, cues, 1) {
+		assert.Equal(t, []string{"Hello there", "friend"}, cues[0].Lines)
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		sep     string
+		want    string
+	}{
+		{0, ",", "00:00:00,000"},
+		{-1, ",", "00:00:00,000"},
+		{59.9996, ".", "00:01:00.000"},
+		{3599.999, ",", "00:59:59,999"},
+		{3723.25, ".", "01:02:03.250"},
+		{36000, ",", "10:00:00,000"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatTimestamp(tt.seconds, tt.sep), "%v seconds", tt.seconds)
+	}
+	assert.Equal(t, "01:02:03", formatClock(3723.9))
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat(" VTT ")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatVTT, f)
+
+	_, err = ParseFormat("docx")
+	assert.Error(t, err)
+}
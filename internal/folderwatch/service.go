@@ -14,6 +14,8 @@ import (
 	"scriberr/internal/config"
 	"scriberr/internal/models"
 	"scriberr/internal/repository"
+	"scriberr/internal/sse"
+	"scriberr/internal/webhook"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/google/uuid"
@@ -66,6 +68,8 @@ type Service struct {
 	userRepo    repository.UserRepository
 	profileRepo repository.ProfileRepository
 	taskQueue   TaskQueue
+	webhooks    *webhook.Dispatcher
+	broadcaster *sse.Broadcaster
 
 	mu       sync.RWMutex
 	runners  map[uint]*folderRunner
@@ -111,6 +115,16 @@ func NewService(
 	}
 }
 
+// SetWebhookDispatcher publishes job.created events for imported files.
+func (s *Service) SetWebhookDispatcher(dispatcher *webhook.Dispatcher) {
+	s.webhooks = dispatcher
+}
+
+// SetBroadcaster reports imported files on the owner's user-wide event stream.
+func (s *Service) SetBroadcaster(broadcaster *sse.Broadcaster) {
+	s.broadcaster = broadcaster
+}
+
 // Start restores all enabled folder watchers.
 func (s *Service) Start(ctx context.Context) error {
 	folders, err := s.folderRepo.FindEnabled(ctx)
@@ -644,6 +658,7 @@ func (s *Service) importFile(ctx context.Context, userID uint, sourcePath string
 	title := filepath.Base(sourcePath)
 	job := models.TranscriptionJob{
 		ID:        jobID,
+		UserID:    userID,
 		AudioPath: destPath,
 		Status:    models.StatusUploaded,
 		Title:     &title,
@@ -654,6 +669,13 @@ func (s *Service) importFile(ctx context.Context, userID uint, sourcePath string
 		return fmt.Errorf("failed to create transcription job: %w", err)
 	}
 
+	s.broadcaster.BroadcastToUser(userID, "job_imported", map[string]interface{}{
+		"job_id":      job.ID,
+		"title":       job.Title,
+		"status":      job.Status,
+		"source_path": sourcePath,
+	})
+	s.webhooks.Publish(ctx, userID, webhook.EventJobCreated, webhook.NewJobEventData(&job))
 	s.maybeQueueAutoTranscription(ctx, userID, &job)
 	return nil
 }
@@ -683,6 +705,7 @@ func (s *Service) maybeQueueAutoTranscription(ctx context.Context, userID uint,
 
 	job.Parameters = profile.Parameters
 	job.Diarization = profile.Parameters.Diarize
+	job.Priority = profile.Priority
 	job.Status = models.StatusPending
 
 	if err := s.jobRepo.Update(ctx, job); err != nil {
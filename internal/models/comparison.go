@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ModelComparison runs the audio of a job through several transcription
+// models and scores their output against a reference transcript, or against
+// each other when no reference is given. The job itself is not changed.
+type ModelComparison struct {
+	ID                 string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TranscriptionJobID string     `json:"transcription_job_id" gorm:"type:varchar(36);not null;index"`
+	UserID             uint       `json:"user_id" gorm:"index"`
+	ModelIDs           string     `json:"-" gorm:"type:text;not null"` // JSON-encoded []string, in the order they are run
+	Reference          *string    `json:"-" gorm:"type:text"`
+	Status             JobStatus  `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	ErrorMessage       *string    `json:"error_message,omitempty" gorm:"type:text"`
+	Results            *string    `json:"-" gorm:"type:text"` // JSON-encoded per-model results and scores
+	CreatedAt          time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+
+	// Relationships
+	TranscriptionJob TranscriptionJob `json:"-" gorm:"foreignKey:TranscriptionJobID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate ensures ModelComparison has a UUID primary key
+func (mc *ModelComparison) BeforeCreate(tx *gorm.DB) error {
+	if mc.ID == "" {
+		mc.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// TranscriptChunk is a passage of a transcript with its embedding, used for
+// semantic search and retrieval-augmented chat
+type TranscriptChunk struct {
+	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TranscriptionJobID string    `json:"transcription_job_id" gorm:"type:varchar(36);not null;index"`
+	ChunkIndex         int       `json:"chunk_index" gorm:"not null"`
+	Content            string    `json:"content" gorm:"type:text;not null"`
+	StartTime          float64   `json:"start_time"`
+	EndTime            float64   `json:"end_time"`
+	Model              string    `json:"model" gorm:"type:varchar(255);not null;index"` // Embedding model that produced the vector
+	Embedding          []byte    `json:"-" gorm:"type:blob;not null"`                   // Little-endian float32s
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	TranscriptionJob TranscriptionJob `json:"-" gorm:"foreignKey:TranscriptionJobID;constraint:OnDelete:CASCADE"`
+}
+
+// ChatCitation points an answer in library chat back to the passage it used
+type ChatCitation struct {
+	Index     int     `json:"index"` // The [n] marker used in the answer
+	JobID     string  `json:"job_id"`
+	JobTitle  *string `json:"job_title,omitempty"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Score     float64 `json:"score"` // Cosine similarity to the question; 0 for passages found by keyword
+}
+
+// SemanticHit is a passage returned by semantic search
+type SemanticHit struct {
+	JobID      string  `json:"job_id"`
+	JobTitle   *string `json:"job_title,omitempty"`
+	ChunkIndex int     `json:"chunk_index"`
+	Content    string  `json:"content"`
+	StartTime  float64 `json:"start_time"`
+	EndTime    float64 `json:"end_time"`
+	Score      float64 `json:"score"`
+}
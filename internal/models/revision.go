@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Transcript revision actions
+const (
+	RevisionActionOriginal = "original" // The transcript as produced by the model
+	RevisionActionEditText = "edit_text"
+	RevisionActionSplit    = "split"
+	RevisionActionMerge    = "merge"
+	RevisionActionSpeaker  = "speaker"
+	RevisionActionRevert   = "revert"
+)
+
+// TranscriptRevision records one manual change to a transcript. Revision 0 is
+// created with the first edit and keeps the original model output, so the
+// transcript can always be reverted to it.
+type TranscriptRevision struct {
+	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TranscriptionJobID string    `json:"transcription_job_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_transcript_revision_number"`
+	Number             int       `json:"number" gorm:"not null;uniqueIndex:idx_transcript_revision_number"`
+	Action             string    `json:"action" gorm:"type:varchar(20);not null"`
+	AuthorID           *uint     `json:"author_id,omitempty"` // Nil for the original model output
+	Diff               *string   `json:"-" gorm:"type:text"`  // JSON-encoded segment change
+	Transcript         *string   `json:"-" gorm:"type:text"`  // Full transcript, only kept for the original
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	TranscriptionJob TranscriptionJob `json:"-" gorm:"foreignKey:TranscriptionJobID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// Kinds of text held in the full-text search index
+const (
+	SearchKindSegment = "segment"
+	SearchKindNote    = "note"
+	SearchKindSummary = "summary"
+)
+
+// SearchHit is a single full-text match inside a transcription
+type SearchHit struct {
+	JobID     string   `json:"job_id"`
+	JobTitle  *string  `json:"job_title,omitempty"`
+	Kind      string   `json:"kind"`                 // segment, note or summary
+	SourceID  string   `json:"source_id"`            // Segment index, note ID or summary ID
+	StartTime *float64 `json:"start_time,omitempty"` // Seconds into the recording, when the hit has a position
+	EndTime   *float64 `json:"end_time,omitempty"`
+	Speaker   *string  `json:"speaker,omitempty"` // Display name of the segment's speaker
+	Snippet   string   `json:"snippet"`           // HTML-escaped matched text with hits wrapped in <mark> tags
+	Rank      float64  `json:"rank"`              // bm25 score; lower is more relevant
+}
+
+// SearchPassage is a transcript segment found by keyword, used to answer
+// questions about transcriptions that have no embedded passages yet
+type SearchPassage struct {
+	JobID     string
+	JobTitle  *string
+	Content   string
+	StartTime *float64
+	EndTime   *float64
+	Speaker   *string // Display name of the segment's speaker
+	Rank      float64 // bm25 score; lower is more relevant
+}
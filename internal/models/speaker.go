@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Speaker is a person enrolled in a user's speaker library. New jobs that
+// compute speaker embeddings are matched against the speaker's voice prints
+// so the same colleague need not be renamed in every recording.
+type Speaker struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	VoicePrints []SpeakerVoicePrint `json:"voice_prints" gorm:"foreignKey:SpeakerID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate ensures Speaker has a UUID primary key
+func (s *Speaker) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// SpeakerVoicePrint is the average voice embedding of a speaker for one
+// embedding model. Embeddings of different models cannot be compared, so a
+// speaker has a voice print per model it was enrolled with.
+type SpeakerVoicePrint struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	SpeakerID string    `json:"speaker_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_voice_print_speaker_model"`
+	Model     string    `json:"model" gorm:"type:varchar(255);not null;uniqueIndex:idx_voice_print_speaker_model"`
+	Embedding []byte    `json:"-" gorm:"type:blob;not null"`       // Little-endian float32s, unit length
+	Samples   int       `json:"samples" gorm:"not null;default:1"` // Recordings averaged into the embedding
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// JobSpeaker is the voice embedding of a speaker label in a job, with the
+// library speaker it was recognized as when the match reached the threshold
+type JobSpeaker struct {
+	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TranscriptionJobID string    `json:"transcription_job_id" gorm:"type:varchar(36);not null;index"`
+	Label              string    `json:"label" gorm:"type:varchar(50);not null"` // e.g., "SPEAKER_00"
+	Model              string    `json:"model" gorm:"type:varchar(255);not null"`
+	Embedding          []byte    `json:"-" gorm:"type:blob;not null"`
+	SpeakerID          *string   `json:"speaker_id,omitempty" gorm:"type:varchar(36);index"`
+	Similarity         *float64  `json:"similarity,omitempty"`
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	TranscriptionJob TranscriptionJob `json:"-" gorm:"foreignKey:TranscriptionJobID;constraint:OnDelete:CASCADE"`
+}
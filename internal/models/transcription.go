@@ -1,6 +1,8 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,9 +12,17 @@ import (
 // TranscriptionJob represents a transcription job record
 type TranscriptionJob struct {
 	ID                    string         `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID                uint           `json:"user_id" gorm:"index"` // Owning user; jobs are only visible to their owner
 	Title                 *string        `json:"title,omitempty" gorm:"type:text"`
 	Status                JobStatus      `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Priority              int            `json:"priority" gorm:"not null;default:0;index"` // Higher priority jobs are picked up first
+	QueuedAt              *time.Time     `json:"queued_at,omitempty" gorm:"index"`         // When the job last entered the queue
+	Attempts              int            `json:"attempts" gorm:"not null;default:0"`       // Processing attempts made since the job was last queued
+	NextAttemptAt         *time.Time     `json:"next_attempt_at,omitempty"`                // Earliest time a retry may be picked up
+	Paused                bool           `json:"paused" gorm:"not null;default:false"`     // Held in the queue until resumed
+	WebhookDeferred       bool           `json:"-" gorm:"not null;default:false"`          // Completion webhook held back until follow-up work such as summarizing is done
 	AudioPath             string         `json:"audio_path" gorm:"type:text;not null"`
+	AudioDuration         *float64       `json:"audio_duration,omitempty"` // Length of the audio in seconds, once probed
 	Transcript            *string        `json:"transcript,omitempty" gorm:"type:text"`
 	Diarization           bool           `json:"diarization" gorm:"type:boolean;default:false"`
 	Summary               *string        `json:"summary,omitempty" gorm:"type:text"`
@@ -23,7 +33,8 @@ type TranscriptionJob struct {
 	MergedAudioPath       *string        `json:"merged_audio_path,omitempty" gorm:"type:text"`
 	MergeStatus           string         `json:"merge_status" gorm:"type:varchar(20);default:'none'"` // none, pending, processing, completed, failed
 	MergeError            *string        `json:"merge_error,omitempty" gorm:"type:text"`
-	IndividualTranscripts *string        `json:"individual_transcripts,omitempty" gorm:"type:text"` // JSON-serialized map[string]*string
+	IndividualTranscripts *string        `json:"individual_transcripts,omitempty" gorm:"type:text"`     // JSON-serialized map[string]*string
+	ActiveExecutionID     *string        `json:"active_execution_id,omitempty" gorm:"type:varchar(36)"` // Execution whose result is the current transcript
 	CreatedAt             time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt             time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt             gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
@@ -46,6 +57,13 @@ const (
 	StatusFailed     JobStatus = "failed"
 )
 
+// QueueSetting stores the state of the job queue that outlives restarts (single row)
+type QueueSetting struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Paused    bool      `json:"paused" gorm:"not null;default:false"` // Workers start no new jobs while paused
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
 // WhisperXParams contains parameters for WhisperX transcription
 type WhisperXParams struct {
 	// Model family (whisper or nvidia)
@@ -90,6 +108,10 @@ type WhisperXParams struct {
 	DiarizeModel      string `json:"diarize_model" gorm:"type:varchar(50);default:'nvidia_sortformer'"` // Options: 'pyannote', 'nvidia_sortformer'
 	SpeakerEmbeddings bool   `json:"speaker_embeddings" gorm:"type:boolean;default:false"`
 
+	// Speaker library matching, used when speaker embeddings are enabled
+	AutoApplySpeakerNames bool    `json:"auto_apply_speaker_names" gorm:"type:boolean;default:false"`
+	SpeakerMatchThreshold float64 `json:"speaker_match_threshold" gorm:"type:real;default:0"` // 0 uses the default threshold
+
 	// Transcription quality settings
 	Temperature                    float64 `json:"temperature" gorm:"type:real;default:0"`
 	BestOf                         int     `json:"best_of" gorm:"type:int;default:5"`
@@ -120,6 +142,37 @@ type WhisperXParams struct {
 	AttentionContextLeft  int `json:"attention_context_left" gorm:"type:int;default:256"`
 	AttentionContextRight int `json:"attention_context_right" gorm:"type:int;default:256"`
 
+	// Audio preprocessing: cut silences of at least MinSilenceDuration seconds
+	// quieter than SilenceThreshold dBFS before any model runs. Timestamps are
+	// mapped back to the original recording.
+	TrimSilence        bool    `json:"trim_silence" gorm:"type:boolean;default:false"`
+	SilenceThreshold   float64 `json:"silence_threshold" gorm:"type:real;default:0"`    // 0 uses -40 dBFS
+	MinSilenceDuration float64 `json:"min_silence_duration" gorm:"type:real;default:0"` // 0 uses 2 seconds
+
+	// Audio filters for quiet or noisy recordings such as phone calls and
+	// far-field meeting microphones, applied with ffmpeg before any model runs
+	HighpassFrequency int     `json:"highpass_frequency" gorm:"type:int;default:0"` // Hz, 0 disables
+	LowpassFrequency  int     `json:"lowpass_frequency" gorm:"type:int;default:0"`  // Hz, 0 disables
+	Denoise           bool    `json:"denoise" gorm:"type:boolean;default:false"`
+	DenoiseStrength   float64 `json:"denoise_strength" gorm:"type:real;default:0"` // dB of noise reduction, 0 uses 12
+	NormalizeLoudness bool    `json:"normalize_loudness" gorm:"type:boolean;default:false"`
+	LoudnessTarget    float64 `json:"loudness_target" gorm:"type:real;default:0"` // Integrated LUFS, 0 uses -16
+
+	// Long-audio chunking: recordings longer than ChunkMinutes are split at
+	// silences into pieces of about that length, transcribed by up to
+	// ChunkWorkers at a time and checkpointed so an interrupted job resumes
+	ChunkMinutes int `json:"chunk_minutes" gorm:"type:int;default:0"` // 0 disables chunking
+	ChunkWorkers int `json:"chunk_workers" gorm:"type:int;default:0"` // 0 or 1 transcribes chunks sequentially
+
+	// Transcript post-processing
+	RemoveFillerWords    bool `json:"remove_filler_words" gorm:"type:boolean;default:false"`
+	NormalizeNumbers     bool `json:"normalize_numbers" gorm:"type:boolean;default:false"`
+	RepairCapitalization bool `json:"repair_capitalization" gorm:"type:boolean;default:false"`
+
+	// Custom vocabularies: terms are passed to models that accept a prompt or
+	// hotwords, and replacement rules are applied to the transcript
+	VocabularyIDs []string `json:"vocabulary_ids,omitempty" gorm:"serializer:json;type:text"`
+
 	// Multi-track transcription settings
 	IsMultiTrackEnabled bool `json:"is_multi_track_enabled" gorm:"type:boolean;default:false"`
 
@@ -146,21 +199,60 @@ type User struct {
 	ID                            uint      `json:"id" gorm:"primaryKey"`
 	Username                      string    `json:"username" gorm:"uniqueIndex;not null;type:varchar(50)"`
 	Password                      string    `json:"-" gorm:"not null;type:varchar(255)"`
+	Role                          string    `json:"role" gorm:"not null;type:varchar(20);default:'member'"`
+	Disabled                      bool      `json:"disabled" gorm:"not null;default:false"`
 	DefaultProfileID              *string   `json:"default_profile_id,omitempty" gorm:"type:varchar(36)"`
 	AutoTranscriptionEnabled      bool      `json:"auto_transcription_enabled" gorm:"not null;default:false"`
 	AutoSummaryEnabled            bool      `json:"auto_summary_enabled" gorm:"not null;default:false"`
+	DefaultSummaryTemplateID      *string   `json:"default_summary_template_id,omitempty" gorm:"type:varchar(36)"` // Template used for automatic summaries
 	AutoTranscriptionTitleEnabled bool      `json:"auto_transcription_title_enabled" gorm:"not null;default:true"`
 	AutoChatTitleEnabled          bool      `json:"auto_chat_title_enabled" gorm:"not null;default:true"`
 	CreatedAt                     time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt                     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
-// APIKey represents an API key for external authentication
+// User roles
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// IsAdmin reports whether the user holds the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// API key scopes. A key can only reach route groups covered by its scopes.
+const (
+	ScopeTranscriptionRead  = "transcription:read"
+	ScopeTranscriptionWrite = "transcription:write"
+	ScopeChat               = "chat"
+	ScopeAdmin              = "admin"
+)
+
+// AllAPIKeyScopes lists every scope a key can be granted
+var AllAPIKeyScopes = []string{ScopeTranscriptionRead, ScopeTranscriptionWrite, ScopeChat, ScopeAdmin}
+
+// DefaultAPIKeyScopes are granted when a key is created without explicit scopes
+var DefaultAPIKeyScopes = []string{ScopeTranscriptionRead, ScopeTranscriptionWrite, ScopeChat}
+
+// apiKeyPrefixLength is the number of leading characters kept in clear text
+// so users can tell their keys apart
+const apiKeyPrefixLength = 8
+
+// APIKey represents an API key for external authentication.
+// Only a SHA-256 hash of the key is stored; the plaintext is available in
+// Key right after creation and is never persisted.
 type APIKey struct {
-	ID          uint    `json:"id" gorm:"primaryKey"`
-	Key         string  `json:"key" gorm:"uniqueIndex;not null;type:varchar(255)"`
-	Name        string  `json:"name" gorm:"not null;type:varchar(100)"`
-	Description *string `json:"description,omitempty" gorm:"type:text"`
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"index"` // User the key acts on behalf of
+	Key         string     `json:"key,omitempty" gorm:"-"`
+	KeyHash     string     `json:"-" gorm:"uniqueIndex;type:varchar(64)"`
+	KeyPrefix   string     `json:"key_prefix" gorm:"type:varchar(16)"`
+	Name        string     `json:"name" gorm:"not null;type:varchar(100)"`
+	Description *string    `json:"description,omitempty" gorm:"type:text"`
+	Scopes      []string   `json:"scopes" gorm:"serializer:json;type:text"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" gorm:"index"`
 	// IsActive should persist explicit false values; avoid default tag to prevent
 	// GORM from overriding false with DB defaults during inserts.
 	IsActive  bool       `json:"is_active" gorm:"type:boolean;not null"`
@@ -169,20 +261,67 @@ type APIKey struct {
 	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
-// BeforeCreate sets the API key if not already set
+// BeforeCreate generates the API key if not already set and replaces it with its hash
 func (ak *APIKey) BeforeCreate(tx *gorm.DB) error {
-	if ak.Key == "" {
-		ak.Key = uuid.New().String()
+	if ak.KeyHash == "" {
+		if ak.Key == "" {
+			ak.Key = uuid.New().String()
+		}
+		ak.KeyHash = HashAPIKey(ak.Key)
+		ak.KeyPrefix = APIKeyPrefix(ak.Key)
+	}
+	if len(ak.Scopes) == 0 {
+		ak.Scopes = append([]string(nil), DefaultAPIKeyScopes...)
 	}
 	return nil
 }
 
+// HasScope reports whether the key was granted scope
+func (ak *APIKey) HasScope(scope string) bool {
+	for _, s := range ak.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired reports whether the key is past its expiry time
+func (ak *APIKey) IsExpired(now time.Time) bool {
+	return ak.ExpiresAt != nil && !now.Before(*ak.ExpiresAt)
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 digest stored for an API key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix returns the part of an API key that is safe to display
+func APIKeyPrefix(key string) string {
+	if len(key) > apiKeyPrefixLength {
+		return key[:apiKeyPrefixLength]
+	}
+	return key
+}
+
+// IsValidAPIKeyScope reports whether scope is a known API key scope
+func IsValidAPIKeyScope(scope string) bool {
+	for _, s := range AllAPIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // TranscriptionProfile represents a saved transcription configuration profile
 type TranscriptionProfile struct {
 	ID          string         `json:"id" gorm:"primaryKey;type:varchar(36)"`
 	Name        string         `json:"name" gorm:"type:varchar(255);not null"`
 	Description *string        `json:"description,omitempty" gorm:"type:text"`
 	IsDefault   bool           `json:"is_default" gorm:"type:boolean;default:false"`
+	Priority    int            `json:"priority" gorm:"not null;default:0"` // Queue priority for jobs started with this profile
 	Parameters  WhisperXParams `json:"parameters" gorm:"embedded"`
 	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
@@ -209,14 +348,15 @@ func (tp *TranscriptionProfile) BeforeSave(tx *gorm.DB) error {
 
 // LLMConfig represents LLM configuration settings
 type LLMConfig struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	Provider      string    `json:"provider" gorm:"not null;type:varchar(50)"`  // "ollama" or "openai"
-	BaseURL       *string   `json:"base_url,omitempty" gorm:"type:text"`        // For Ollama
-	OpenAIBaseURL *string   `json:"openai_base_url,omitempty" gorm:"type:text"` // For OpenAI custom endpoint
-	APIKey        *string   `json:"api_key,omitempty" gorm:"type:text"`         // For OpenAI (encrypted)
-	IsActive      bool      `json:"is_active" gorm:"type:boolean;default:false"`
-	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	Provider       string    `json:"provider" gorm:"not null;type:varchar(50)"`          // "ollama" or "openai"
+	BaseURL        *string   `json:"base_url,omitempty" gorm:"type:text"`                // For Ollama
+	OpenAIBaseURL  *string   `json:"openai_base_url,omitempty" gorm:"type:text"`         // For OpenAI custom endpoint
+	APIKey         *string   `json:"api_key,omitempty" gorm:"type:text"`                 // For OpenAI (encrypted)
+	EmbeddingModel *string   `json:"embedding_model,omitempty" gorm:"type:varchar(255)"` // Model used for semantic search; provider default when unset
+	IsActive       bool      `json:"is_active" gorm:"type:boolean;default:false"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // BeforeSave ensures only one LLM config can be active
@@ -230,11 +370,20 @@ func (lc *LLMConfig) BeforeSave(tx *gorm.DB) error {
 	return nil
 }
 
-// ChatSession represents a chat session with a transcript
+// Chat session modes
+const (
+	ChatModeTranscript = "transcript" // Questions about a single transcription
+	ChatModeLibrary    = "library"    // Questions answered from all of a user's transcriptions
+)
+
+// ChatSession represents a chat session with a transcript, or with the
+// user's whole library in library mode
 type ChatSession struct {
 	ID              string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
-	JobID           string     `json:"job_id" gorm:"type:varchar(36);not null"`
-	TranscriptionID string     `json:"transcription_id" gorm:"type:varchar(36);not null;index"`
+	UserID          *uint      `json:"user_id,omitempty" gorm:"index"`
+	Mode            string     `json:"mode" gorm:"type:varchar(20);not null;default:'transcript'"`
+	JobID           *string    `json:"job_id,omitempty" gorm:"type:varchar(36)"`                 // Unset in library mode
+	TranscriptionID *string    `json:"transcription_id,omitempty" gorm:"type:varchar(36);index"` // Unset in library mode
 	Title           string     `json:"title" gorm:"type:varchar(255);not null"`
 	Model           string     `json:"model" gorm:"type:varchar(100);not null"`
 	Provider        string     `json:"provider" gorm:"type:varchar(50);not null;default:'openai'"`
@@ -259,6 +408,9 @@ func (cs *ChatSession) BeforeCreate(tx *gorm.DB) error {
 	if cs.Title == "" {
 		cs.Title = "New Chat Session"
 	}
+	if cs.Mode == "" {
+		cs.Mode = ChatModeTranscript
+	}
 	return nil
 }
 
@@ -270,6 +422,7 @@ type ChatMessage struct {
 	Role          string    `json:"role" gorm:"type:varchar(20);not null"` // "user" or "assistant"
 	Content       string    `json:"content" gorm:"type:text;not null"`
 	TokensUsed    *int      `json:"tokens_used,omitempty" gorm:"type:integer"`
+	Citations     *string   `json:"citations,omitempty" gorm:"type:text"` // JSON array of ChatCitation for retrieved passages
 	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
 
 	// Relationships
@@ -299,6 +452,7 @@ type MultiTrackTiming struct {
 type TranscriptionJobExecution struct {
 	ID                 string `json:"id" gorm:"primaryKey;type:varchar(36)"`
 	TranscriptionJobID string `json:"transcription_job_id" gorm:"type:varchar(36);not null;index"`
+	Attempt            int    `json:"attempt" gorm:"not null;default:1"` // Which processing attempt this execution records
 
 	// Execution timing
 	StartedAt          time.Time  `json:"started_at" gorm:"not null"`
@@ -317,6 +471,11 @@ type TranscriptionJobExecution struct {
 	// Execution results
 	Status       JobStatus `json:"status" gorm:"type:varchar(20);not null"`
 	ErrorMessage *string   `json:"error_message,omitempty" gorm:"type:text"`
+	Transcript   *string   `json:"-" gorm:"type:text"` // Result of this execution, kept as an alternate once a later run replaces it
+
+	// Speaker analytics cache, valid while the transcript hashes to AnalyticsDigest
+	Analytics       *string `json:"-" gorm:"type:text"`        // JSON-serialized analytics.Analytics
+	AnalyticsDigest *string `json:"-" gorm:"type:varchar(64)"` // SHA-256 of the transcript the analytics were computed from
 
 	// Metadata
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
@@ -343,6 +502,25 @@ func (tje *TranscriptionJobExecution) CalculateProcessingDuration() {
 	}
 }
 
+// AudioChunk is a checkpoint of a long recording transcribed in pieces. A
+// finished chunk keeps its transcript, so a job interrupted by a crash or
+// restart resumes with the first unfinished chunk.
+type AudioChunk struct {
+	ID                 uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TranscriptionJobID string     `json:"transcription_job_id" gorm:"type:varchar(36);not null;index"`
+	Index              int        `json:"index" gorm:"not null"`
+	Start              float64    `json:"start" gorm:"type:real;not null"` // Seconds into the preprocessed audio
+	End                float64    `json:"end" gorm:"type:real;not null"`
+	PlanDigest         string     `json:"plan_digest" gorm:"type:varchar(64);not null"` // Audio and parameters the chunks were planned for
+	Status             JobStatus  `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Result             *string    `json:"-" gorm:"type:text"` // JSON-serialized transcript, timed from the chunk's start
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationship
+	TranscriptionJob TranscriptionJob `json:"-" gorm:"foreignKey:TranscriptionJobID;constraint:OnDelete:CASCADE"`
+}
+
 // SpeakerMapping represents custom speaker names for a transcription job
 type SpeakerMapping struct {
 	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Vocabulary is a user-managed list of domain terms and find-and-replace
+// rules. Profiles and jobs reference vocabularies through the vocabulary_ids
+// transcription parameter: the terms are passed to models that accept a
+// prompt or hotwords, and the rules are applied to every transcript.
+type Vocabulary struct {
+	ID          string            `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID      uint              `json:"user_id" gorm:"not null;index"`
+	Name        string            `json:"name" gorm:"type:varchar(255);not null"`
+	Description *string           `json:"description,omitempty" gorm:"type:text"`
+	Terms       []string          `json:"terms" gorm:"serializer:json;type:text"`
+	Rules       []ReplacementRule `json:"rules" gorm:"serializer:json;type:text"`
+	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ReplacementRule rewrites matches of Find in a transcript to Replace. Literal
+// rules match whole words; regex rules use Go regular expressions and may
+// refer to capture groups as $1 or ${name} in Replace.
+type ReplacementRule struct {
+	Find          string `json:"find"`
+	Replace       string `json:"replace"`
+	Regex         bool   `json:"regex"`
+	CaseSensitive bool   `json:"case_sensitive"`
+}
+
+// BeforeCreate ensures Vocabulary has a UUID primary key
+func (v *Vocabulary) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookSubscription sends the events a user subscribed to to a URL. Each
+// delivery is signed with the subscription's secret.
+type WebhookSubscription struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	URL         string    `json:"url" gorm:"type:text;not null"`
+	Description *string   `json:"description,omitempty" gorm:"type:text"`
+	Events      []string  `json:"events" gorm:"serializer:json;type:text"`
+	Secret      string    `json:"-" gorm:"type:varchar(100);not null"`
+	Active      bool      `json:"active" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate ensures WebhookSubscription has a UUID primary key
+func (w *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Subscribes reports whether the subscription is active and wants event
+func (w *WebhookSubscription) Subscribes(event string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is the state of one delivery of an event
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery records an event sent, or to be sent, to a subscription.
+// Pending deliveries are retried at NextAttemptAt until they succeed or run
+// out of attempts, so they survive a restart.
+type WebhookDelivery struct {
+	ID             string                `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	SubscriptionID string                `json:"subscription_id" gorm:"type:varchar(36);not null;index"`
+	EventID        string                `json:"event_id" gorm:"type:varchar(36);not null;index"`
+	Event          string                `json:"event" gorm:"type:varchar(50);not null"`
+	Payload        string                `json:"payload" gorm:"type:text;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int                   `json:"attempts" gorm:"not null"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty" gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	ResponseStatus *int                  `json:"response_status,omitempty"`
+	ResponseBody   *string               `json:"response_body,omitempty" gorm:"type:text"`
+	Error          *string               `json:"error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	RedeliveryOf   *string               `json:"redelivery_of,omitempty" gorm:"type:varchar(36)"`
+	CreatedAt      time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Subscription WebhookSubscription `json:"-" gorm:"foreignKey:SubscriptionID"`
+}
+
+// BeforeCreate ensures WebhookDelivery has a UUID primary key
+func (w *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"errors"
+
+	"scriberr/pkg/logger"
+)
+
+// ErrJobNotPending is returned when pausing, resuming or moving a job that is not waiting in the queue
+var ErrJobNotPending = errors.New("job is not pending")
+
+// Pause stops workers from starting jobs, and RunTask from starting tasks,
+// until Resume is called. Running jobs and tasks finish normally. The queue
+// stays paused across restarts.
+func (tq *TaskQueue) Pause() error {
+	if err := tq.jobRepo.SetQueuePaused(context.Background(), true); err != nil {
+		return err
+	}
+	tq.paused.Store(true)
+	logger.Info("Task queue paused")
+	tq.announceQueue()
+	return nil
+}
+
+// Resume lets workers pick up pending jobs again
+func (tq *TaskQueue) Resume() error {
+	if err := tq.jobRepo.SetQueuePaused(context.Background(), false); err != nil {
+		return err
+	}
+	tq.paused.Store(false)
+	logger.Info("Task queue resumed")
+	tq.announceQueue()
+	tq.notifyWorkers()
+	tq.slotMutex.Lock()
+	tq.signalSlots()
+	tq.slotMutex.Unlock()
+	return nil
+}
+
+// IsPaused reports whether the whole queue is paused
+func (tq *TaskQueue) IsPaused() bool {
+	return tq.paused.Load()
+}
+
+// PauseJob holds a pending job in the queue: workers skip it, and the jobs
+// behind it move up, until it is resumed. It keeps its place in line.
+func (tq *TaskQueue) PauseJob(jobID string) error {
+	return tq.setJobPaused(jobID, true)
+}
+
+// ResumeJob releases a paused job back to its place in the queue
+func (tq *TaskQueue) ResumeJob(jobID string) error {
+	if err := tq.setJobPaused(jobID, false); err != nil {
+		return err
+	}
+	tq.notifyWorkers()
+	return nil
+}
+
+func (tq *TaskQueue) setJobPaused(jobID string, paused bool) error {
+	updated, err := tq.jobRepo.SetPaused(context.Background(), jobID, paused)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrJobNotPending
+	}
+	logger.Info("Job pause changed", "job_id", jobID, "paused", paused)
+	tq.announceJob(jobID, "")
+	tq.announceQueue()
+	return nil
+}
+
+// MoveJobToFront makes a pending job the next one a worker picks up
+func (tq *TaskQueue) MoveJobToFront(jobID string) error {
+	moved, err := tq.jobRepo.MoveToFront(context.Background(), jobID)
+	if err != nil {
+		return err
+	}
+	if !moved {
+		return ErrJobNotPending
+	}
+	logger.Info("Job moved to the front of the queue", "job_id", jobID)
+	tq.announceQueue()
+	tq.notifyWorkers()
+	return nil
+}
@@ -0,0 +1,239 @@
+package queue
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"scriberr/internal/models"
+	"scriberr/pkg/logger"
+)
+
+// DurationEstimator is implemented by job processors that can estimate how
+// long processing a job takes
+type DurationEstimator interface {
+	EstimateProcessingTime(ctx context.Context, job *models.TranscriptionJob) (time.Duration, bool)
+}
+
+// AudioDurationRecorder is implemented by job processors that record the
+// length of a job's audio, which their DurationEstimator scales with
+type AudioDurationRecorder interface {
+	RecordAudioDuration(ctx context.Context, jobID string) (bool, error)
+}
+
+// QueuedJob is a running or pending job with its place in the queue and, when
+// the processor can estimate it, its expected timing
+type QueuedJob struct {
+	JobID                    string           `json:"job_id"`
+	UserID                   uint             `json:"user_id"`
+	Title                    *string          `json:"title,omitempty"`
+	Status                   models.JobStatus `json:"status"`
+	Priority                 int              `json:"priority"`
+	Position                 int              `json:"position,omitempty"` // Place among the pending jobs that are not paused, from 1
+	Paused                   bool             `json:"paused,omitempty"`
+	StartedAt                *time.Time       `json:"started_at,omitempty"`
+	EstimatedDurationSeconds *float64         `json:"estimated_duration_seconds,omitempty"`
+	EstimatedStartAt         *time.Time       `json:"estimated_start_at,omitempty"`
+	EstimatedCompletionAt    *time.Time       `json:"estimated_completion_at,omitempty"`
+}
+
+// QueueSnapshot is the running jobs and the pending jobs in the order workers pick them up
+type QueueSnapshot struct {
+	Paused  bool        `json:"paused"` // Whether the whole queue is paused
+	Workers int         `json:"workers"`
+	Running []QueuedJob `json:"running"`
+	Pending []QueuedJob `json:"pending"`
+}
+
+// Find returns a job of the snapshot, or nil if it is neither running nor pending
+func (s *QueueSnapshot) Find(jobID string) *QueuedJob {
+	for _, jobs := range [][]QueuedJob{s.Running, s.Pending} {
+		for i := range jobs {
+			if jobs[i].JobID == jobID {
+				return &jobs[i]
+			}
+		}
+	}
+	return nil
+}
+
+// recordAudioDuration has the processor record the audio length of a newly
+// queued job, so Snapshot never has to probe it, and then announces the
+// estimates it makes possible
+func (tq *TaskQueue) recordAudioDuration(jobID string) {
+	recorder, ok := tq.processor.(AudioDurationRecorder)
+	if !ok {
+		return
+	}
+	recorded, err := recorder.RecordAudioDuration(tq.ctx, jobID)
+	if err != nil {
+		if tq.ctx.Err() == nil {
+			logger.Warn("Failed to record audio duration", "job_id", jobID, "error", err)
+		}
+		return
+	}
+	if recorded {
+		tq.announceQueue()
+	}
+}
+
+// workerSlot is when a worker is expected to be free again; known is false
+// once a job of unknown length is assigned to it
+type workerSlot struct {
+	at    time.Time
+	known bool
+}
+
+// Snapshot lists the running and pending jobs. Estimates come from the
+// processor if it is a DurationEstimator: pending jobs are handed to the
+// worker expected to be free first, as the workers will pick them up, with
+// jobs in retry backoff waiting for their next attempt while the jobs behind
+// them go ahead. Paused jobs, and every pending job while the queue is paused,
+// have no expected start, and workers running a task take no job until it ends.
+func (tq *TaskQueue) Snapshot(ctx context.Context) (*QueueSnapshot, error) {
+	now := time.Now()
+	estimator, _ := tq.processor.(DurationEstimator)
+	estimate := func(job *models.TranscriptionJob) (time.Duration, bool) {
+		if estimator == nil {
+			return 0, false
+		}
+		return estimator.EstimateProcessingTime(ctx, job)
+	}
+
+	tq.jobsMutex.RLock()
+	started := make(map[string]time.Time, len(tq.runningJobs))
+	for jobID, running := range tq.runningJobs {
+		started[jobID] = running.StartedAt
+	}
+	activeTasks := tq.activeTasks
+	tq.jobsMutex.RUnlock()
+
+	runningIDs := make([]string, 0, len(started))
+	for jobID := range started {
+		runningIDs = append(runningIDs, jobID)
+	}
+	sort.Slice(runningIDs, func(i, j int) bool { return started[runningIDs[i]].Before(started[runningIDs[j]]) })
+
+	snapshot := &QueueSnapshot{
+		Paused:  tq.IsPaused(),
+		Workers: int(atomic.LoadInt64(&tq.currentWorkers)),
+		Running: []QueuedJob{},
+		Pending: []QueuedJob{},
+	}
+	var slots []workerSlot
+	for _, jobID := range runningIDs {
+		job, err := tq.jobRepo.FindByID(ctx, jobID)
+		if err != nil {
+			logger.Warn("Failed to load running job", "job_id", jobID, "error", err)
+			continue
+		}
+		startedAt := started[jobID]
+		entry := newQueuedJob(job)
+		entry.StartedAt = &startedAt
+		slot := workerSlot{}
+		if duration, ok := estimate(job); ok {
+			completion := startedAt.Add(duration)
+			if completion.Before(now) {
+				// Running longer than expected; it may finish any moment
+				completion = now
+			}
+			entry.setEstimate(duration, startedAt, completion)
+			slot = workerSlot{at: completion, known: true}
+		}
+		slots = append(slots, slot)
+		snapshot.Running = append(snapshot.Running, entry)
+	}
+	// Tasks such as model comparisons hold their worker for an unknown time
+	for range activeTasks {
+		slots = append(slots, workerSlot{})
+	}
+	for len(slots) < max(snapshot.Workers, 1) {
+		slots = append(slots, workerSlot{at: now, known: true})
+	}
+
+	pending, err := tq.jobRepo.ListQueued(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var waiting []int // Pending jobs that workers will pick up, in order
+	position := 0
+	for i := range pending {
+		job := &pending[i]
+		entry := newQueuedJob(job)
+		if !job.Paused {
+			position++
+			entry.Position = position
+			waiting = append(waiting, i)
+		}
+		snapshot.Pending = append(snapshot.Pending, entry)
+	}
+	if !snapshot.Paused {
+		schedulePending(slots, pending, snapshot.Pending, waiting, estimate)
+	}
+	return snapshot, nil
+}
+
+// schedulePending estimates the waiting jobs the way the workers claim them:
+// the worker that is free first takes the first job whose retry backoff is
+// over, or idles until the earliest backoff ends when every job is backing off.
+// A job of unknown length leaves its worker without an estimate.
+func schedulePending(slots []workerSlot, jobs []models.TranscriptionJob, entries []QueuedJob, waiting []int, estimate func(*models.TranscriptionJob) (time.Duration, bool)) {
+	for len(waiting) > 0 {
+		next := -1
+		for j, slot := range slots {
+			if slot.known && (next < 0 || slot.at.Before(slots[next].at)) {
+				next = j
+			}
+		}
+		if next < 0 {
+			return
+		}
+
+		start := slots[next].at
+		pick := -1
+		var retryAt time.Time
+		for k, i := range waiting {
+			due := jobs[i].NextAttemptAt
+			if due == nil || !due.After(start) {
+				pick = k
+				break
+			}
+			if retryAt.IsZero() || due.Before(retryAt) {
+				retryAt = *due
+			}
+		}
+		if pick < 0 {
+			slots[next].at = retryAt
+			continue
+		}
+
+		i := waiting[pick]
+		waiting = append(waiting[:pick], waiting[pick+1:]...)
+		if duration, ok := estimate(&jobs[i]); ok {
+			completion := start.Add(duration)
+			entries[i].setEstimate(duration, start, completion)
+			slots[next].at = completion
+		} else {
+			slots[next].known = false
+		}
+	}
+}
+
+func newQueuedJob(job *models.TranscriptionJob) QueuedJob {
+	return QueuedJob{
+		JobID:    job.ID,
+		UserID:   job.UserID,
+		Title:    job.Title,
+		Status:   job.Status,
+		Priority: job.Priority,
+		Paused:   job.Paused,
+	}
+}
+
+func (q *QueuedJob) setEstimate(duration time.Duration, start, completion time.Time) {
+	seconds := duration.Seconds()
+	q.EstimatedDurationSeconds = &seconds
+	q.EstimatedStartAt = &start
+	q.EstimatedCompletionAt = &completion
+}
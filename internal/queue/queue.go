@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -14,21 +15,30 @@ import (
 
 	"scriberr/internal/models"
 	"scriberr/internal/repository"
+	"scriberr/internal/sse"
+	"scriberr/internal/webhook"
 	"scriberr/pkg/logger"
 )
 
 // RunningJob tracks both context cancellation and OS process
 type RunningJob struct {
-	Cancel  context.CancelFunc
-	Process *exec.Cmd
+	Cancel    context.CancelFunc
+	Process   *exec.Cmd
+	StartedAt time.Time
 }
 
-// TaskQueue manages transcription job processing
+// pollInterval is how often idle workers look for pending jobs that were
+// queued without a wake-up signal (e.g. by another process)
+const pollInterval = 5 * time.Second
+
+// TaskQueue manages transcription job processing. The queue itself lives in
+// the database: every pending job is a queue entry, so nothing is dropped when
+// many jobs arrive at once and the queue survives restarts.
 type TaskQueue struct {
 	minWorkers     int
 	maxWorkers     int
 	currentWorkers int64 // Use atomic for thread-safe access
-	jobChannel     chan string
+	wake           chan struct{}
 	ctx            context.Context
 	cancel         context.CancelFunc
 	wg             sync.WaitGroup
@@ -39,7 +49,17 @@ type TaskQueue struct {
 	lastScaleTime  time.Time
 	jobRepo        repository.JobRepository
 	onJobCompleted func(jobID string)
+	webhooks       *webhook.Dispatcher
+	broadcaster    *sse.Broadcaster
 	hookMutex      sync.RWMutex
+	retryPolicy    RetryPolicy
+	policyMutex    sync.RWMutex
+	paused         atomic.Bool // Workers start no new jobs while set
+	runningTasks   map[string]context.CancelFunc
+	activeTasks    int           // Tasks holding a worker slot, guarded by jobsMutex
+	busySlots      int64         // Jobs and tasks running, bounded by currentWorkers
+	slotFreed      chan struct{} // Closed and replaced when a slot is released
+	slotMutex      sync.Mutex
 }
 
 // JobProcessor defines the interface for processing jobs
@@ -101,14 +121,17 @@ func NewTaskQueue(legacyWorkers int, processor JobProcessor, jobRepo repository.
 		minWorkers:     min,
 		maxWorkers:     max,
 		currentWorkers: int64(min),
-		jobChannel:     make(chan string, 200), // Increased buffer for better throughput
+		wake:           make(chan struct{}, 1),
 		ctx:            ctx,
 		cancel:         cancel,
 		processor:      processor,
 		runningJobs:    make(map[string]*RunningJob),
+		runningTasks:   make(map[string]context.CancelFunc),
+		slotFreed:      make(chan struct{}),
 		autoScale:      autoScale,
 		lastScaleTime:  time.Now(),
 		jobRepo:        jobRepo,
+		retryPolicy:    DefaultRetryPolicy(),
 	}
 }
 
@@ -121,12 +144,17 @@ func (tq *TaskQueue) Start() {
 		"max_workers", tq.maxWorkers,
 		"auto_scale", tq.autoScale)
 
-	// Reset any zombie jobs from previous runs synchronously before starting workers
+	// Reset any zombie jobs from previous runs synchronously before starting workers.
+	// Pending jobs need no recovery: workers claim them straight from the database.
 	tq.ResetZombieJobs()
 
-	// One-time recovery: enqueue any pending jobs left from previous server run
-	// This is NOT a polling mechanism - it only runs once at startup
-	tq.recoverPendingJobs()
+	// A queue paused before the restart stays paused
+	if paused, err := tq.jobRepo.QueuePaused(tq.ctx); err != nil {
+		logger.Error("Failed to load queue state", "error", err)
+	} else if paused {
+		tq.paused.Store(true)
+		logger.Info("Task queue is paused; no jobs start until it is resumed")
+	}
 
 	// Start initial workers
 	for i := 0; i < workers; i++ {
@@ -146,7 +174,7 @@ func (tq *TaskQueue) Stop() {
 	logger.Debug("Stopping task queue")
 	logger.Debug("Stopping task queue")
 	tq.cancel()
-	// Do not close jobChannel here as it causes panics in EnqueueJob
+	// Do not close the wake channel here as it causes panics in EnqueueJob
 	// The channel will be garbage collected when the queue is no longer referenced
 	tq.wg.Wait()
 	logger.Debug("Task queue stopped")
@@ -159,7 +187,41 @@ func (tq *TaskQueue) SetOnJobCompleted(hook func(jobID string)) {
 	tq.onJobCompleted = hook
 }
 
-// EnqueueJob adds a job to the queue
+// SetWebhookDispatcher publishes job.started, job.completed and job.failed
+// events to the job owner's webhook subscriptions
+func (tq *TaskQueue) SetWebhookDispatcher(dispatcher *webhook.Dispatcher) {
+	tq.hookMutex.Lock()
+	defer tq.hookMutex.Unlock()
+	tq.webhooks = dispatcher
+}
+
+// SetBroadcaster reports job status changes and queue positions on the
+// owners' user-wide event streams
+func (tq *TaskQueue) SetBroadcaster(broadcaster *sse.Broadcaster) {
+	tq.hookMutex.Lock()
+	defer tq.hookMutex.Unlock()
+	tq.broadcaster = broadcaster
+}
+
+// SetRetryPolicy replaces the policy applied to failed jobs
+func (tq *TaskQueue) SetRetryPolicy(policy RetryPolicy) {
+	tq.policyMutex.Lock()
+	defer tq.policyMutex.Unlock()
+	tq.retryPolicy = policy
+}
+
+// RetryPolicy returns the policy applied to failed jobs
+func (tq *TaskQueue) RetryPolicy() RetryPolicy {
+	tq.policyMutex.RLock()
+	defer tq.policyMutex.RUnlock()
+	return tq.retryPolicy
+}
+
+// ErrJobNotQueueable is returned when queueing a job that is not found or not
+// in a status it can be queued from, e.g. because it is already being processed
+var ErrJobNotQueueable = errors.New("job cannot be queued")
+
+// EnqueueJob marks a job as pending and wakes a worker to pick it up
 func (tq *TaskQueue) EnqueueJob(jobID string) error {
 	// Check if queue is already shut down
 	select {
@@ -168,110 +230,192 @@ func (tq *TaskQueue) EnqueueJob(jobID string) error {
 	default:
 	}
 
+	queued, err := tq.jobRepo.MarkQueued(context.Background(), jobID)
+	if err != nil {
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+	if !queued {
+		return ErrJobNotQueueable
+	}
+	tq.jobQueued(jobID)
+	return nil
+}
+
+// RequeueJob saves changes made to a job, e.g. new parameters, and queues it
+// again in one step, provided its status is still one of from. Otherwise it
+// returns ErrJobNotQueueable and nothing is saved.
+func (tq *TaskQueue) RequeueJob(job *models.TranscriptionJob, from ...models.JobStatus) error {
 	select {
-	case tq.jobChannel <- jobID:
-		return nil
 	case <-tq.ctx.Done():
 		return fmt.Errorf("queue is shutting down")
 	default:
-		return fmt.Errorf("queue is full")
+	}
+
+	queued, err := tq.jobRepo.RequeueJob(context.Background(), job, from)
+	if err != nil {
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+	if !queued {
+		return ErrJobNotQueueable
+	}
+	tq.jobQueued(job.ID)
+	return nil
+}
+
+// jobQueued announces a newly queued job and wakes a worker for it
+func (tq *TaskQueue) jobQueued(jobID string) {
+	tq.announceJob(jobID, "")
+	tq.announceQueue()
+	go tq.recordAudioDuration(jobID)
+	tq.notifyWorkers()
+}
+
+// notifyWorkers wakes one idle worker without blocking
+func (tq *TaskQueue) notifyWorkers() {
+	select {
+	case tq.wake <- struct{}{}:
+	default:
 	}
 }
 
-// worker processes jobs from the channel
+// worker claims pending jobs from the database and processes them
 func (tq *TaskQueue) worker(id int) {
 	defer tq.wg.Done()
 
 	logger.Debug("Worker started", "worker_id", id)
 
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
 	for {
-		select {
-		case jobID, ok := <-tq.jobChannel:
-			if !ok {
-				logger.Debug("Worker stopped", "worker_id", id)
+		var job *models.TranscriptionJob
+		if !tq.IsPaused() && tq.tryAcquireSlot() {
+			var err error
+			job, err = tq.jobRepo.ClaimNextPending(tq.ctx)
+			if err != nil && tq.ctx.Err() == nil {
+				logger.Error("Failed to claim next job", "worker_id", id, "error", err)
+			}
+			if job == nil {
+				tq.releaseSlot()
+			}
+		}
+
+		if job == nil {
+			select {
+			case <-tq.wake:
+			case <-ticker.C:
+			case <-tq.ctx.Done():
+				logger.Debug("Worker stopped", "worker_id", id, "reason", "context_cancelled")
 				return
 			}
+			continue
+		}
 
-			logger.WorkerOperation(id, jobID, "start")
+		// There may be more work; let another idle worker look for it
+		tq.notifyWorkers()
+		tq.runJob(id, job)
+		tq.releaseSlot()
+	}
+}
 
-			// Update job status to processing
-			if err := tq.updateJobStatus(jobID, models.StatusProcessing); err != nil {
-				logger.Error("Failed to update job status", "worker_id", id, "job_id", jobID, "error", err)
-				continue
-			}
+// runJob processes a claimed job and records the outcome
+func (tq *TaskQueue) runJob(id int, job *models.TranscriptionJob) {
+	jobID := job.ID
+	logger.WorkerOperation(id, jobID, "start")
+	tq.announceJob(jobID, webhook.EventJobStarted)
+	tq.announceQueue()
+
+	// Create context for this job and track it
+	jobCtx, jobCancel := context.WithCancel(tq.ctx)
+	defer jobCancel()
+	runningJob := &RunningJob{
+		Cancel:    jobCancel,
+		Process:   nil, // Will be set by registerProcess callback
+		StartedAt: time.Now(),
+	}
 
-			// Create context for this job and track it
-			jobCtx, jobCancel := context.WithCancel(tq.ctx)
-			runningJob := &RunningJob{
-				Cancel:  jobCancel,
-				Process: nil, // Will be set by registerProcess callback
-			}
+	tq.jobsMutex.Lock()
+	tq.runningJobs[jobID] = runningJob
+	tq.jobsMutex.Unlock()
+
+	// Register process callback
+	registerProcess := func(cmd *exec.Cmd) {
+		tq.jobsMutex.Lock()
+		if running, exists := tq.runningJobs[jobID]; exists {
+			running.Process = cmd
+		}
+		tq.jobsMutex.Unlock()
+	}
 
-			tq.jobsMutex.Lock()
-			tq.runningJobs[jobID] = runningJob
-			tq.jobsMutex.Unlock()
+	// Process the job with process registration
+	err := tq.processor.ProcessJobWithProcess(jobCtx, jobID, registerProcess)
 
-			// Register process callback
-			registerProcess := func(cmd *exec.Cmd) {
-				tq.jobsMutex.Lock()
-				if job, exists := tq.runningJobs[jobID]; exists {
-					job.Process = cmd
-				}
-				tq.jobsMutex.Unlock()
-			}
+	// Remove job from running jobs
+	tq.jobsMutex.Lock()
+	delete(tq.runningJobs, jobID)
+	tq.jobsMutex.Unlock()
 
-			// Process the job with process registration
-			err := tq.processor.ProcessJobWithProcess(jobCtx, jobID, registerProcess)
-
-			// Remove job from running jobs
-			tq.jobsMutex.Lock()
-			delete(tq.runningJobs, jobID)
-			tq.jobsMutex.Unlock()
-
-			// Handle result
-			if err != nil {
-				if jobCtx.Err() == context.Canceled {
-					logger.Info("Job cancelled", "worker_id", id, "job_id", jobID)
-					if err := tq.updateJobStatus(jobID, models.StatusFailed); err != nil {
-						logger.Error("Failed to update job status", "job_id", jobID, "error", err)
-					}
-					if err := tq.updateJobError(jobID, "Job was cancelled by user"); err != nil {
-						logger.Error("Failed to update job error", "job_id", jobID, "error", err)
-					}
-				} else {
-					logger.Error("Job processing failed", "worker_id", id, "job_id", jobID, "error", err)
-					if err := tq.updateJobStatus(jobID, models.StatusFailed); err != nil {
-						logger.Error("Failed to update job status", "job_id", jobID, "error", err)
-					}
-					if err := tq.updateJobError(jobID, err.Error()); err != nil {
-						logger.Error("Failed to update job error", "job_id", jobID, "error", err)
-					}
-				}
-			} else {
-				logger.Debug("Job processed successfully", "worker_id", id, "job_id", jobID)
-				if err := tq.updateJobStatus(jobID, models.StatusCompleted); err != nil {
-					logger.Error("Failed to update job status", "job_id", jobID, "error", err)
-				} else {
-					tq.hookMutex.RLock()
-					onCompleted := tq.onJobCompleted
-					tq.hookMutex.RUnlock()
-					if onCompleted != nil {
-						go func(id string, fn func(string)) {
-							defer func() {
-								if recovered := recover(); recovered != nil {
-									logger.Error("onJobCompleted hook panic", "job_id", id, "error", recovered)
-								}
-							}()
-							fn(id)
-						}(jobID, onCompleted)
-					}
-				}
+	// Handle result
+	if err != nil {
+		if tq.ctx.Err() != nil {
+			// Shutting down rather than killed: the job stays processing so the
+			// next start resumes it from its checkpoints or fails it as interrupted
+			logger.Info("Job interrupted by shutdown", "worker_id", id, "job_id", jobID)
+		} else if jobCtx.Err() == context.Canceled {
+			logger.Info("Job cancelled", "worker_id", id, "job_id", jobID)
+			if err := tq.updateJobStatus(jobID, models.StatusFailed); err != nil {
+				logger.Error("Failed to update job status", "job_id", jobID, "error", err)
 			}
-
-		case <-tq.ctx.Done():
-			logger.Debug("Worker stopped", "worker_id", id, "reason", "context_cancelled")
-			return
+			if err := tq.updateJobError(jobID, "Job was cancelled by user"); err != nil {
+				logger.Error("Failed to update job error", "job_id", jobID, "error", err)
+			}
+			tq.announceJob(jobID, webhook.EventJobFailed)
+		} else if policy := tq.RetryPolicy(); policy.ShouldRetry(err, job.Attempts) {
+			delay := policy.Backoff(job.Attempts)
+			logger.Warn("Job attempt failed, retrying",
+				"worker_id", id,
+				"job_id", jobID,
+				"attempt", job.Attempts,
+				"max_attempts", policy.MaxAttempts,
+				"retry_in", delay.String(),
+				"error", err)
+			msg := fmt.Sprintf("Attempt %d of %d failed, retrying: %v", job.Attempts, policy.MaxAttempts, err)
+			if err := tq.jobRepo.ScheduleRetry(context.Background(), jobID, time.Now().Add(delay), msg); err != nil {
+				logger.Error("Failed to schedule job retry", "job_id", jobID, "error", err)
+			}
+			tq.announceJob(jobID, "")
+		} else {
+			logger.Error("Job processing failed", "worker_id", id, "job_id", jobID, "attempt", job.Attempts, "error", err)
+			if err := tq.updateJobStatus(jobID, models.StatusFailed); err != nil {
+				logger.Error("Failed to update job status", "job_id", jobID, "error", err)
+			}
+			if err := tq.updateJobError(jobID, err.Error()); err != nil {
+				logger.Error("Failed to update job error", "job_id", jobID, "error", err)
+			}
+			tq.announceJob(jobID, webhook.EventJobFailed)
 		}
+		return
+	}
+
+	logger.Debug("Job processed successfully", "worker_id", id, "job_id", jobID)
+	if err := tq.updateJobStatus(jobID, models.StatusCompleted); err != nil {
+		logger.Error("Failed to update job status", "job_id", jobID, "error", err)
+		return
+	}
+	tq.announceJob(jobID, webhook.EventJobCompleted)
+
+	tq.hookMutex.RLock()
+	onCompleted := tq.onJobCompleted
+	tq.hookMutex.RUnlock()
+	if onCompleted != nil {
+		go func(id string, fn func(string)) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					logger.Error("onJobCompleted hook panic", "job_id", id, "error", recovered)
+				}
+			}()
+			fn(id)
+		}(jobID, onCompleted)
 	}
 }
 
@@ -299,6 +443,7 @@ func (tq *TaskQueue) KillJob(jobID string) error {
 			if err := tq.updateJobError(jobID, "Job was forcefully terminated by user (zombie process)"); err != nil {
 				logger.Error("Failed to update zombie job error", "job_id", jobID, "error", err)
 			}
+			go tq.announceJob(jobID, webhook.EventJobFailed)
 			return nil
 		}
 
@@ -357,6 +502,77 @@ func (tq *TaskQueue) updateJobError(jobID string, errorMsg string) error {
 	return tq.jobRepo.UpdateError(context.Background(), jobID, errorMsg)
 }
 
+// announceJob tells a job's owner about the job's current state: a job_update
+// on their event stream and, when event is set, a webhook event
+func (tq *TaskQueue) announceJob(jobID string, event string) {
+	tq.hookMutex.RLock()
+	dispatcher, broadcaster := tq.webhooks, tq.broadcaster
+	tq.hookMutex.RUnlock()
+	if broadcaster == nil && (dispatcher == nil || event == "") {
+		return
+	}
+
+	ctx := context.Background()
+	job, err := tq.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		logger.Warn("Failed to load job for event", "job_id", jobID, "event", event, "error", err)
+		return
+	}
+	broadcaster.BroadcastToUser(job.UserID, "job_update", map[string]interface{}{
+		"job_id":   job.ID,
+		"title":    job.Title,
+		"status":   job.Status,
+		"paused":   job.Paused,
+		"attempts": job.Attempts,
+		"error":    job.ErrorMessage,
+	})
+	if event != "" {
+		dispatcher.Publish(ctx, job.UserID, event, webhook.NewJobEventData(job))
+	}
+}
+
+// announceQueue sends every user with queued jobs the jobs' places in line
+// and, when they can be estimated, when they are expected to start and finish
+func (tq *TaskQueue) announceQueue() {
+	tq.hookMutex.RLock()
+	broadcaster := tq.broadcaster
+	tq.hookMutex.RUnlock()
+	if broadcaster == nil {
+		return
+	}
+
+	snapshot, err := tq.Snapshot(context.Background())
+	if err != nil {
+		logger.Warn("Failed to load queue for event", "error", err)
+		return
+	}
+	positions := make(map[uint][]map[string]interface{})
+	var users []uint
+	for _, job := range snapshot.Pending {
+		if _, ok := positions[job.UserID]; !ok {
+			users = append(users, job.UserID)
+		}
+		position := map[string]interface{}{
+			"job_id":   job.JobID,
+			"position": job.Position,
+		}
+		if job.Paused {
+			position["paused"] = true
+		}
+		if job.EstimatedCompletionAt != nil {
+			position["estimated_start_at"] = job.EstimatedStartAt
+			position["estimated_completion_at"] = job.EstimatedCompletionAt
+		}
+		positions[job.UserID] = append(positions[job.UserID], position)
+	}
+	for _, userID := range users {
+		broadcaster.BroadcastToUser(userID, "queue_update", map[string]interface{}{
+			"jobs":         positions[userID],
+			"queue_length": len(snapshot.Pending),
+		})
+	}
+}
+
 // GetJobStatus gets the status of a job
 func (tq *TaskQueue) GetJobStatus(jobID string) (*models.TranscriptionJob, error) {
 	return tq.jobRepo.FindByID(context.Background(), jobID)
@@ -389,7 +605,11 @@ func (tq *TaskQueue) checkAndScale() {
 		return
 	}
 
-	queueSize := len(tq.jobChannel)
+	pending, err := tq.jobRepo.CountByStatus(context.Background(), models.StatusPending)
+	if err != nil {
+		return
+	}
+	queueSize := int(pending)
 	currentWorkers := int(atomic.LoadInt64(&tq.currentWorkers))
 
 	tq.jobsMutex.RLock()
@@ -433,8 +653,8 @@ func (tq *TaskQueue) GetQueueStats() map[string]interface{} {
 	tq.jobsMutex.RUnlock()
 
 	return map[string]interface{}{
-		"queue_size":      len(tq.jobChannel),
-		"queue_capacity":  cap(tq.jobChannel),
+		"paused":          tq.IsPaused(),
+		"queue_size":      int(pendingCount),
 		"current_workers": int(atomic.LoadInt64(&tq.currentWorkers)),
 		"min_workers":     tq.minWorkers,
 		"max_workers":     tq.maxWorkers,
@@ -447,7 +667,8 @@ func (tq *TaskQueue) GetQueueStats() map[string]interface{} {
 	}
 }
 
-// ResetZombieJobs finds jobs stuck in processing state from previous runs and marks them as failed
+// ResetZombieJobs finds jobs stuck in processing state from previous runs and marks them as failed.
+// Jobs transcribed in chunks that checkpointed finished chunks are queued again to resume instead.
 func (tq *TaskQueue) ResetZombieJobs() {
 	// Find all jobs with status "processing"
 	zombieJobs, err := tq.jobRepo.FindByStatus(context.Background(), models.StatusProcessing)
@@ -463,6 +684,16 @@ func (tq *TaskQueue) ResetZombieJobs() {
 	logger.Info("Found zombie jobs from previous run", "count", len(zombieJobs))
 
 	for _, job := range zombieJobs {
+		if completed, err := tq.jobRepo.CountCompletedChunks(context.Background(), job.ID); err == nil && completed > 0 {
+			logger.Info("Resuming chunked job", "job_id", job.ID, "completed_chunks", completed)
+			err := tq.jobRepo.ScheduleRetry(context.Background(), job.ID, time.Now(), "Job interrupted by server restart, resuming from finished chunks")
+			if err == nil {
+				tq.announceJob(job.ID, "")
+				continue
+			}
+			logger.Error("Failed to requeue chunked job", "job_id", job.ID, "error", err)
+		}
+
 		logger.Info("Resetting zombie job", "job_id", job.ID)
 
 		// Mark as failed
@@ -475,30 +706,6 @@ func (tq *TaskQueue) ResetZombieJobs() {
 		if err := tq.updateJobError(job.ID, "Job interrupted by server restart"); err != nil {
 			logger.Error("Failed to update zombie job error message", "job_id", job.ID, "error", err)
 		}
-	}
-}
-
-// recoverPendingJobs enqueues pending jobs from previous server runs
-// This runs ONCE at startup, not repeatedly like the old scanner
-func (tq *TaskQueue) recoverPendingJobs() {
-	pendingJobs, err := tq.jobRepo.FindByStatus(context.Background(), models.StatusPending)
-	if err != nil {
-		logger.Error("Failed to scan for pending jobs during startup recovery", "error", err)
-		return
-	}
-
-	if len(pendingJobs) == 0 {
-		return
-	}
-
-	logger.Info("Recovering pending jobs from previous server run", "count", len(pendingJobs))
-
-	for _, job := range pendingJobs {
-		select {
-		case tq.jobChannel <- job.ID:
-			logger.Debug("Recovered pending job", "job_id", job.ID)
-		default:
-			logger.Warn("Queue full during startup recovery, job will remain pending", "job_id", job.ID)
-		}
+		tq.announceJob(job.ID, webhook.EventJobFailed)
 	}
 }
@@ -0,0 +1,168 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RetryPolicy decides whether and when a failed job is attempted again
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts including the first; 1 disables retries
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for the delay between attempts
+	Multiplier     float64       // Growth factor applied to the delay after each attempt
+
+	// RetryableErrors lists case-insensitive phrases of error messages that
+	// indicate a transient failure. A phrase must start at the beginning of a
+	// word, so "rate limit" also matches "rate limited" while "timeout" does
+	// not match inside a longer word. Errors matching none of them, nor a
+	// retryable status code, fail the job.
+	RetryableErrors []string
+
+	// RetryableStatusCodes lists HTTP status codes that indicate a transient
+	// failure where an error message reports them, as in "status 503",
+	// "status code: 429" or "API error: 502". Other numbers in a message, such
+	// as sizes or IDs, never match.
+	RetryableStatusCodes []int
+}
+
+// defaultRetryableErrors covers failures that commonly succeed on a second try:
+// adapters killed for running out of memory, provider rate limits and network
+// hiccups while talking to APIs or downloading models.
+var defaultRetryableErrors = []string{
+	"out of memory",
+	"cuda error",
+	"signal: killed",
+	"rate limit",
+	"too many requests",
+	"bad gateway",
+	"service unavailable",
+	"timeout",
+	"timed out",
+	"connection reset",
+	"connection refused",
+	"temporarily unavailable",
+	"unexpected eof",
+	"failed to download",
+	"download failed",
+}
+
+// defaultRetryableStatusCodes are rate limits and overloaded or unreachable upstreams
+var defaultRetryableStatusCodes = []int{429, 502, 503, 504}
+
+// statusCodePattern finds the HTTP status codes reported in a lowercased error message
+var statusCodePattern = regexp.MustCompile(`\b(?:status(?: code)?|api error|http)\W{0,3}(\d{3})\b`)
+
+// DefaultRetryPolicy returns the retry policy, honouring QUEUE_MAX_ATTEMPTS,
+// QUEUE_RETRY_BACKOFF, QUEUE_RETRY_MAX_BACKOFF and QUEUE_RETRYABLE_ERRORS
+func DefaultRetryPolicy() RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       30 * time.Second,
+		MaxBackoff:           10 * time.Minute,
+		Multiplier:           2,
+		RetryableErrors:      defaultRetryableErrors,
+		RetryableStatusCodes: defaultRetryableStatusCodes,
+	}
+
+	if v := os.Getenv("QUEUE_MAX_ATTEMPTS"); v != "" {
+		if attempts, err := strconv.Atoi(v); err == nil && attempts > 0 {
+			policy.MaxAttempts = attempts
+		}
+	}
+	if v := os.Getenv("QUEUE_RETRY_BACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			policy.InitialBackoff = d
+		}
+	}
+	if v := os.Getenv("QUEUE_RETRY_MAX_BACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			policy.MaxBackoff = d
+		}
+	}
+	if v := os.Getenv("QUEUE_RETRYABLE_ERRORS"); v != "" {
+		var patterns []string
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				patterns = append(patterns, p)
+			}
+		}
+		policy.RetryableErrors = patterns
+	}
+
+	return policy
+}
+
+// IsRetryable reports whether err looks like a transient failure.
+// Cancellations are never retried.
+func (p RetryPolicy) IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range p.RetryableErrors {
+		if containsPhrase(msg, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	for _, match := range statusCodePattern.FindAllStringSubmatch(msg, -1) {
+		if code, err := strconv.Atoi(match[1]); err == nil && slices.Contains(p.RetryableStatusCodes, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPhrase reports whether phrase occurs in s starting at the beginning of a word
+func containsPhrase(s, phrase string) bool {
+	if phrase == "" {
+		return false
+	}
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], phrase)
+		if i < 0 {
+			return false
+		}
+		i += offset
+		previous, _ := utf8.DecodeLastRuneInString(s[:i])
+		if i == 0 || !isWordRune(previous) || !isWordRune(rune(phrase[0])) {
+			return true
+		}
+		offset = i + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// ShouldRetry reports whether a job that failed on the given attempt
+// (counting from 1) gets another one
+func (p RetryPolicy) ShouldRetry(err error, attempt int) bool {
+	return attempt < p.MaxAttempts && p.IsRetryable(err)
+}
+
+// Backoff returns the delay before the attempt that follows the given one
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(delay)
+}
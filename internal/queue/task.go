@@ -0,0 +1,111 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"scriberr/pkg/logger"
+)
+
+// ErrTaskNotFound is returned when cancelling a task that is neither waiting nor running
+var ErrTaskNotFound = errors.New("task not found")
+
+// RunTask runs work that is not a transcription job but competes with jobs
+// for the same GPU, such as a model comparison. It waits until the queue is
+// not paused and a worker slot is free, and holds the slot while run runs, so
+// jobs and tasks together never exceed the worker limit. CancelTask and
+// stopping the queue cancel the context passed to run; a task cancelled while
+// it waits returns the context's error without running.
+func (tq *TaskQueue) RunTask(ctx context.Context, taskID string, run func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(tq.ctx, cancel)
+	defer stop()
+
+	tq.jobsMutex.Lock()
+	tq.runningTasks[taskID] = cancel
+	tq.jobsMutex.Unlock()
+	defer func() {
+		tq.jobsMutex.Lock()
+		delete(tq.runningTasks, taskID)
+		tq.jobsMutex.Unlock()
+	}()
+
+	if err := tq.acquireSlot(ctx); err != nil {
+		return err
+	}
+	tq.jobsMutex.Lock()
+	tq.activeTasks++
+	tq.jobsMutex.Unlock()
+	defer func() {
+		tq.jobsMutex.Lock()
+		tq.activeTasks--
+		tq.jobsMutex.Unlock()
+		tq.releaseSlot()
+		tq.notifyWorkers()
+	}()
+
+	logger.Debug("Task started", "task_id", taskID)
+	return run(ctx)
+}
+
+// CancelTask cancels a task started with RunTask, whether it is still waiting
+// for a worker slot or already running
+func (tq *TaskQueue) CancelTask(taskID string) error {
+	tq.jobsMutex.RLock()
+	cancel, exists := tq.runningTasks[taskID]
+	tq.jobsMutex.RUnlock()
+	if !exists {
+		return ErrTaskNotFound
+	}
+
+	logger.Info("Cancelling task", "task_id", taskID)
+	cancel()
+	return nil
+}
+
+// acquireSlot waits until the queue is not paused and a worker slot is free
+func (tq *TaskQueue) acquireSlot(ctx context.Context) error {
+	for {
+		tq.slotMutex.Lock()
+		freed := tq.slotFreed
+		tq.slotMutex.Unlock()
+
+		if !tq.IsPaused() && tq.tryAcquireSlot() {
+			return nil
+		}
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tryAcquireSlot takes a worker slot if fewer jobs and tasks than the current
+// worker count are running
+func (tq *TaskQueue) tryAcquireSlot() bool {
+	tq.slotMutex.Lock()
+	defer tq.slotMutex.Unlock()
+	if tq.busySlots >= atomic.LoadInt64(&tq.currentWorkers) {
+		return false
+	}
+	tq.busySlots++
+	return true
+}
+
+// releaseSlot gives a worker slot back and wakes the tasks waiting for one
+func (tq *TaskQueue) releaseSlot() {
+	tq.slotMutex.Lock()
+	defer tq.slotMutex.Unlock()
+	tq.busySlots--
+	tq.signalSlots()
+}
+
+// signalSlots wakes the tasks waiting for a slot so they check again. The
+// caller must hold slotMutex.
+func (tq *TaskQueue) signalSlots() {
+	close(tq.slotFreed)
+	tq.slotFreed = make(chan struct{})
+}
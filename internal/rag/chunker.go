@@ -0,0 +1,113 @@
+// Package rag splits transcripts into passages and ranks them by embedding
+// similarity for retrieval-augmented chat and semantic search.
+package rag
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultChunkSize is the target length of a passage in characters
+// (roughly 250 tokens), small enough to retrieve precisely and large
+// enough to keep a few turns of conversation together
+const DefaultChunkSize = 1000
+
+// Chunk is a contiguous passage of a transcript
+type Chunk struct {
+	Index int
+	Text  string
+	Start float64 // Seconds; zero when the transcript has no timing
+	End   float64
+}
+
+type transcriptSegment struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	Speaker string  `json:"speaker"`
+}
+
+type transcriptJSON struct {
+	Text     string              `json:"text"`
+	Segments []transcriptSegment `json:"segments"`
+}
+
+// ChunkTranscript splits a stored transcript into passages of about size
+// characters. Segments are never split and each line is prefixed with the
+// speaker, using the display names in speakers where one is set. Transcripts
+// without segments are split on word boundaries.
+func ChunkTranscript(transcript string, speakers map[string]string, size int) ([]Chunk, error) {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+
+	var t transcriptJSON
+	if err := json.Unmarshal([]byte(transcript), &t); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+
+	if len(t.Segments) == 0 {
+		return ChunkText(t.Text, size), nil
+	}
+
+	var chunks []Chunk
+	var sb strings.Builder
+	var start, end float64
+	flush := func() {
+		if sb.Len() == 0 {
+			return
+		}
+		chunks = append(chunks, Chunk{Index: len(chunks), Text: strings.TrimSpace(sb.String()), Start: start, End: end})
+		sb.Reset()
+	}
+
+	for _, seg := range t.Segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		line := text
+		if seg.Speaker != "" {
+			name := seg.Speaker
+			if custom, ok := speakers[seg.Speaker]; ok && custom != "" {
+				name = custom
+			}
+			line = name + ": " + text
+		}
+
+		if sb.Len() > 0 && sb.Len()+len(line) > size {
+			flush()
+		}
+		if sb.Len() == 0 {
+			start = seg.Start
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+		end = seg.End
+	}
+	flush()
+
+	return chunks, nil
+}
+
+// ChunkText splits plain text into passages of about size characters on word
+// boundaries
+func ChunkText(text string, size int) []Chunk {
+	var chunks []Chunk
+	var sb strings.Builder
+	for _, word := range strings.Fields(text) {
+		if sb.Len() > 0 && sb.Len()+len(word)+1 > size {
+			chunks = append(chunks, Chunk{Index: len(chunks), Text: sb.String()})
+			sb.Reset()
+		}
+		if sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(word)
+	}
+	if sb.Len() > 0 {
+		chunks = append(chunks, Chunk{Index: len(chunks), Text: sb.String()})
+	}
+	return chunks
+}
@@ -0,0 +1,64 @@
+package rag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkTranscript(t *testing.T) {
+	t.Run("Segments", func(t *testing.T) {
+		transcript := `{"segments": [
+			{"start": 0, "end": 2, "text": "First line.", "speaker": "SPEAKER_00"},
+			{"start": 2, "end": 5, "text": "Second line.", "speaker": "SPEAKER_01"},
+			{"start": 5, "end": 6, "text": "   "},
+			{"start": 6, "end": 9, "text": "Third line.", "speaker": "SPEAKER_00"}
+		]}`
+		chunks, err := ChunkTranscript(transcript, map[string]string{"SPEAKER_00": "Alice"}, 50)
+		assert.NoError(t, err)
+		if assert.Len(t, chunks, 2) {
+			assert.Equal(t, "Alice: First line.\nSPEAKER_01: Second line.", chunks[0].Text)
+			assert.Equal(t, 0.0, chunks[0].Start)
+			assert.Equal(t, 5.0, chunks[0].End)
+			assert.Equal(t, 1, chunks[1].Index)
+			assert.Equal(t, "Alice: Third line.", chunks[1].Text)
+			assert.Equal(t, 6.0, chunks[1].Start)
+			assert.Equal(t, 9.0, chunks[1].End)
+		}
+	})
+
+	t.Run("PlainText", func(t *testing.T) {
+		chunks, err := ChunkTranscript(`{"text": "one two three four five"}`, nil, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"one two", "three four", "five"}, []string{chunks[0].Text, chunks[1].Text, chunks[2].Text})
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, err := ChunkTranscript("not json", nil, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("OversizedSegmentIsKeptWhole", func(t *testing.T) {
+		long := strings.Repeat("word ", 50)
+		chunks, err := ChunkTranscript(`{"segments": [{"start": 0, "end": 1, "text": "`+long+`"}]}`, nil, 20)
+		assert.NoError(t, err)
+		assert.Len(t, chunks, 1)
+	})
+}
+
+func TestVectors(t *testing.T) {
+	v := []float32{0.5, -1.25, 3}
+	assert.Equal(t, v, DecodeVector(EncodeVector(v)))
+
+	assert.InDelta(t, 1.0, Cosine([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0.0, Cosine([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.Equal(t, 0.0, Cosine([]float32{1, 0}, []float32{1, 0, 0}))
+	assert.Equal(t, 0.0, Cosine([]float32{0, 0}, []float32{1, 0}))
+
+	top := TopK([]float32{1, 0}, [][]float32{{0, 1}, {1, 0.1}, {1, 1}}, 2)
+	if assert.Len(t, top, 2) {
+		assert.Equal(t, 1, top[0].Index)
+		assert.Equal(t, 2, top[1].Index)
+	}
+}
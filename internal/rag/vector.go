@@ -0,0 +1,63 @@
+package rag
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// EncodeVector packs a vector as little-endian float32s for storage in a blob
+func EncodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+// DecodeVector is the inverse of EncodeVector
+func DecodeVector(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 when they differ in
+// length or either is all zeros
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// Scored is a candidate's position in the input slice and its similarity to
+// the query
+type Scored struct {
+	Index int
+	Score float64
+}
+
+// TopK returns up to k candidates most similar to query, best first
+func TopK(query []float32, candidates [][]float32, k int) []Scored {
+	scored := make([]Scored, 0, len(candidates))
+	for i, c := range candidates {
+		scored = append(scored, Scored{Index: i, Score: Cosine(query, c)})
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	if k > 0 && len(scored) > k {
+		scored = scored[:k]
+	}
+	return scored
+}
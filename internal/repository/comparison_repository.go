@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"scriberr/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ComparisonRepository stores model comparison runs
+type ComparisonRepository interface {
+	Create(ctx context.Context, comparison *models.ModelComparison) error
+	Update(ctx context.Context, comparison *models.ModelComparison) error
+	FindByID(ctx context.Context, id string) (*models.ModelComparison, error)
+	ListByJob(ctx context.Context, jobID string) ([]models.ModelComparison, error)
+	CountUnfinishedByUser(ctx context.Context, userID uint) (int64, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByJob(ctx context.Context, jobID string) error
+	FailInterrupted(ctx context.Context, message string) error
+}
+
+type comparisonRepository struct {
+	db *gorm.DB
+}
+
+func NewComparisonRepository(db *gorm.DB) ComparisonRepository {
+	return &comparisonRepository{db: db}
+}
+
+func (r *comparisonRepository) Create(ctx context.Context, comparison *models.ModelComparison) error {
+	return r.db.WithContext(ctx).Create(comparison).Error
+}
+
+// Update saves every field of an existing comparison. Unlike Save it never
+// inserts, so a run that ends after its comparison was deleted does not bring it back.
+func (r *comparisonRepository) Update(ctx context.Context, comparison *models.ModelComparison) error {
+	return r.db.WithContext(ctx).Model(comparison).Select("*").Updates(comparison).Error
+}
+
+func (r *comparisonRepository) FindByID(ctx context.Context, id string) (*models.ModelComparison, error) {
+	var comparison models.ModelComparison
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&comparison).Error; err != nil {
+		return nil, err
+	}
+	return &comparison, nil
+}
+
+// ListByJob returns the comparisons of a job without their results, newest first
+func (r *comparisonRepository) ListByJob(ctx context.Context, jobID string) ([]models.ModelComparison, error) {
+	var comparisons []models.ModelComparison
+	err := r.db.WithContext(ctx).
+		Omit("results").
+		Where("transcription_job_id = ?", jobID).
+		Order("created_at DESC").
+		Find(&comparisons).Error
+	return comparisons, err
+}
+
+// CountUnfinishedByUser counts a user's comparisons that are queued or running
+func (r *comparisonRepository) CountUnfinishedByUser(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ModelComparison{}).
+		Where("user_id = ? AND status IN ?", userID, []models.JobStatus{models.StatusPending, models.StatusProcessing}).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *comparisonRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.ModelComparison{}).Error
+}
+
+// DeleteByJob removes all comparisons of a job
+func (r *comparisonRepository) DeleteByJob(ctx context.Context, jobID string) error {
+	return r.db.WithContext(ctx).Where("transcription_job_id = ?", jobID).Delete(&models.ModelComparison{}).Error
+}
+
+// FailInterrupted marks comparisons that were still running as failed
+func (r *comparisonRepository) FailInterrupted(ctx context.Context, message string) error {
+	return r.db.WithContext(ctx).Model(&models.ModelComparison{}).
+		Where("status IN ?", []models.JobStatus{models.StatusPending, models.StatusProcessing}).
+		Updates(map[string]interface{}{"status": models.StatusFailed, "error_message": message}).Error
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"scriberr/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// EmbeddingRepository stores embedded transcript passages
+type EmbeddingRepository interface {
+	ReplaceChunks(ctx context.Context, jobID string, chunks []models.TranscriptChunk) error
+	DeleteByJob(ctx context.Context, jobID string) error
+	CountByJob(ctx context.Context, jobID, model string) (int64, error)
+	ListForUser(ctx context.Context, userID uint, jobIDs []string, model string) ([]models.TranscriptChunk, error)
+	JobsWithoutChunks(ctx context.Context, userID uint, model string, limit int) ([]string, error)
+}
+
+type embeddingRepository struct {
+	db *gorm.DB
+}
+
+func NewEmbeddingRepository(db *gorm.DB) EmbeddingRepository {
+	return &embeddingRepository{db: db}
+}
+
+// ReplaceChunks swaps all stored passages of a job for chunks
+func (r *embeddingRepository) ReplaceChunks(ctx context.Context, jobID string, chunks []models.TranscriptChunk) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.TranscriptChunk{}).Error; err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(chunks, 100).Error
+	})
+}
+
+// DeleteByJob removes the passages of a job
+func (r *embeddingRepository) DeleteByJob(ctx context.Context, jobID string) error {
+	return r.db.WithContext(ctx).Where("transcription_job_id = ?", jobID).Delete(&models.TranscriptChunk{}).Error
+}
+
+// CountByJob counts the passages of a job embedded with model
+func (r *embeddingRepository) CountByJob(ctx context.Context, jobID, model string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.TranscriptChunk{}).
+		Where("transcription_job_id = ? AND model = ?", jobID, model).
+		Count(&count).Error
+	return count, err
+}
+
+// ListForUser returns the passages embedded with model in the jobs owned by
+// userID, optionally restricted to jobIDs
+func (r *embeddingRepository) ListForUser(ctx context.Context, userID uint, jobIDs []string, model string) ([]models.TranscriptChunk, error) {
+	var chunks []models.TranscriptChunk
+	query := r.db.WithContext(ctx).
+		Joins("JOIN transcription_jobs ON transcription_jobs.id = transcript_chunks.transcription_job_id AND transcription_jobs.deleted_at IS NULL").
+		Where("transcription_jobs.user_id = ? AND transcript_chunks.model = ?", userID, model)
+	if len(jobIDs) > 0 {
+		query = query.Where("transcript_chunks.transcription_job_id IN ?", jobIDs)
+	}
+	err := query.Order("transcript_chunks.transcription_job_id, transcript_chunks.chunk_index").Find(&chunks).Error
+	return chunks, err
+}
+
+// JobsWithoutChunks lists completed jobs of userID that have a transcript but
+// no passages embedded with model, newest first
+func (r *embeddingRepository) JobsWithoutChunks(ctx context.Context, userID uint, model string, limit int) ([]string, error) {
+	var ids []string
+	query := r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).
+		Where("user_id = ? AND status = ? AND transcript IS NOT NULL", userID, models.StatusCompleted).
+		Where("NOT EXISTS (SELECT 1 FROM transcript_chunks tc WHERE tc.transcription_job_id = transcription_jobs.id AND tc.model = ?)", model).
+		Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Pluck("id", &ids).Error
+	return ids, err
+}
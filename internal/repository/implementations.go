@@ -2,20 +2,31 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"scriberr/internal/models"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository handles user-specific database operations
 type UserRepository interface {
 	Repository[models.User]
 	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	FindFirst(ctx context.Context) (*models.User, error)
 	Count(ctx context.Context) (int64, error)
 	CountWithAutoTranscription(ctx context.Context) (int64, error)
+	CountActiveAdmins(ctx context.Context) (int64, error)
+	CreateWithFirstAdmin(ctx context.Context, user *models.User) error
+	CreateInitialAdmin(ctx context.Context, user *models.User) error
+	DeleteAndReassign(ctx context.Context, userID, newOwnerID uint) error
 }
 
+// ErrAdminExists is returned when creating the initial administrator of an
+// instance that already has one
+var ErrAdminExists = errors.New("an administrator already exists")
+
 type userRepository struct {
 	*BaseRepository[models.User]
 }
@@ -35,6 +46,17 @@ func (r *userRepository) FindByUsername(ctx context.Context, username string) (*
 	return &user, nil
 }
 
+// FindFirst returns the earliest registered user, used as the owner of
+// jobs that are not tied to an authenticated request (e.g. the dropzone)
+func (r *userRepository) FindFirst(ctx context.Context) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Order("id ASC").First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
 	err := r.db.WithContext(ctx).Model(&models.User{}).Count(&count).Error
@@ -47,24 +69,135 @@ func (r *userRepository) CountWithAutoTranscription(ctx context.Context) (int64,
 	return count, err
 }
 
+// CountActiveAdmins counts admin accounts that have not been disabled
+func (r *userRepository) CountActiveAdmins(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("role = ? AND disabled = ?", models.RoleAdmin, false).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateWithFirstAdmin creates user as a member, or as the administrator when
+// the instance has none yet. The user is promoted by a conditional update in
+// the same transaction, so of several users registering at once only one
+// becomes admin. user.Role holds the role it was given.
+func (r *userRepository) CreateWithFirstAdmin(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createPromotingFirstAdmin(tx, user)
+	})
+}
+
+// CreateInitialAdmin creates user as the administrator of an instance without
+// one. When there already is an administrator nothing is created and
+// ErrAdminExists is returned.
+func (r *userRepository) CreateInitialAdmin(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createPromotingFirstAdmin(tx, user); err != nil {
+			return err
+		}
+		if user.Role != models.RoleAdmin {
+			return ErrAdminExists
+		}
+		return nil
+	})
+}
+
+func createPromotingFirstAdmin(tx *gorm.DB, user *models.User) error {
+	user.Role = models.RoleMember
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+	admins := tx.Model(&models.User{}).Select("1").Where("role = ?", models.RoleAdmin)
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND NOT EXISTS (?)", user.ID, admins).
+		Update("role", models.RoleAdmin)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		user.Role = models.RoleAdmin
+	}
+	return nil
+}
+
+// DeleteAndReassign removes a user together with their credentials and
+// webhook subscriptions, and hands their transcription jobs and the data built
+// around them (chats, comparisons, vocabularies, speaker library) over to
+// newOwnerID so no data is lost
+func (r *userRepository) DeleteAndReassign(ctx context.Context, userID, newOwnerID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{
+			&models.TranscriptionJob{},
+			&models.ChatSession{},
+			&models.ModelComparison{},
+			&models.Vocabulary{},
+			&models.Speaker{},
+		} {
+			if err := tx.Model(model).Where("user_id = ?", userID).Update("user_id", newOwnerID).Error; err != nil {
+				return err
+			}
+		}
+		subscriptions := tx.Model(&models.WebhookSubscription{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Where("subscription_id IN (?)", subscriptions).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.WebhookSubscription{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.APIKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.WatchedFolder{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, userID).Error
+	})
+}
+
 // JobRepository handles transcription job operations
 type JobRepository interface {
 	Repository[models.TranscriptionJob]
 	FindWithAssociations(ctx context.Context, id string) (*models.TranscriptionJob, error)
+	FindByIDForUser(ctx context.Context, id string, userID uint) (*models.TranscriptionJob, error)
 	FindActiveTrackJobs(ctx context.Context, parentJobID string) ([]models.TranscriptionJob, error)
 	FindLatestCompletedExecution(ctx context.Context, jobID string) (*models.TranscriptionJobExecution, error)
-	ListWithParams(ctx context.Context, offset, limit int, sortBy, sortOrder, searchQuery string, updatedAfter *time.Time) ([]models.TranscriptionJob, int64, error)
+	FindExecution(ctx context.Context, jobID, executionID string) (*models.TranscriptionJobExecution, error)
+	ListExecutions(ctx context.Context, jobID string) ([]models.TranscriptionJobExecution, error)
+	SetActiveExecution(ctx context.Context, jobID, executionID string) error
+	ListWithParams(ctx context.Context, userID uint, offset, limit int, sortBy, sortOrder, searchQuery string, updatedAfter *time.Time) ([]models.TranscriptionJob, int64, error)
 	ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.TranscriptionJob, int64, error)
 	UpdateTranscript(ctx context.Context, jobID string, transcript string) error
 	CreateExecution(ctx context.Context, execution *models.TranscriptionJobExecution) error
 	UpdateExecution(ctx context.Context, execution *models.TranscriptionJobExecution) error
 	DeleteExecutionsByJobID(ctx context.Context, jobID string) error
 	DeleteMultiTrackFilesByJobID(ctx context.Context, jobID string) error
+	ListChunks(ctx context.Context, jobID string) ([]models.AudioChunk, error)
+	ReplaceChunks(ctx context.Context, jobID string, chunks []models.AudioChunk) error
+	CompleteChunk(ctx context.Context, chunkID uint, result string) error
+	CountCompletedChunks(ctx context.Context, jobID string) (int64, error)
+	DeleteChunksByJobID(ctx context.Context, jobID string) error
 	UpdateStatus(ctx context.Context, jobID string, status models.JobStatus) error
 	UpdateError(ctx context.Context, jobID string, errorMsg string) error
 	FindByStatus(ctx context.Context, status models.JobStatus) ([]models.TranscriptionJob, error)
 	CountByStatus(ctx context.Context, status models.JobStatus) (int64, error)
 	UpdateSummary(ctx context.Context, jobID string, summary string) error
+	MarkQueued(ctx context.Context, jobID string) (bool, error)
+	RequeueJob(ctx context.Context, job *models.TranscriptionJob, from []models.JobStatus) (bool, error)
+	ClaimNextPending(ctx context.Context) (*models.TranscriptionJob, error)
+	ListQueued(ctx context.Context) ([]models.TranscriptionJob, error)
+	ScheduleRetry(ctx context.Context, jobID string, at time.Time, errorMsg string) error
+	SetAudioDuration(ctx context.Context, jobID string, seconds float64) error
+	ProcessingSpeed(ctx context.Context, modelFamily, model, device string, limit int) (float64, int, error)
+	SetPaused(ctx context.Context, jobID string, paused bool) (bool, error)
+	SetWebhookDeferred(ctx context.Context, jobID string, deferred bool) error
+	FindWebhookDeferred(ctx context.Context) ([]models.TranscriptionJob, error)
+	MoveToFront(ctx context.Context, jobID string) (bool, error)
+	QueuePaused(ctx context.Context) (bool, error)
+	SetQueuePaused(ctx context.Context, paused bool) error
 }
 
 type jobRepository struct {
@@ -89,12 +222,28 @@ func (r *jobRepository) FindWithAssociations(ctx context.Context, id string) (*m
 	return &job, nil
 }
 
-func (r *jobRepository) ListWithParams(ctx context.Context, offset, limit int, sortBy, sortOrder, searchQuery string, updatedAfter *time.Time) ([]models.TranscriptionJob, int64, error) {
+// FindByIDForUser returns the job only if it is owned by userID
+func (r *jobRepository) FindByIDForUser(ctx context.Context, id string, userID uint) (*models.TranscriptionJob, error) {
+	var job models.TranscriptionJob
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListWithParams lists the jobs owned by userID with search, sorting and delta sync.
+// A zero userID lists jobs across all users.
+func (r *jobRepository) ListWithParams(ctx context.Context, userID uint, offset, limit int, sortBy, sortOrder, searchQuery string, updatedAfter *time.Time) ([]models.TranscriptionJob, int64, error) {
 	var jobs []models.TranscriptionJob
 	var count int64
 
 	db := r.db.WithContext(ctx).Model(&models.TranscriptionJob{})
 
+	if userID != 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+
 	// Handle delta sync if updatedAfter provided
 	if updatedAfter != nil {
 		db = db.Unscoped().Where("updated_at > ?", *updatedAfter)
@@ -103,7 +252,7 @@ func (r *jobRepository) ListWithParams(ctx context.Context, offset, limit int, s
 	// Apply search filter
 	if searchQuery != "" {
 		search := "%" + searchQuery + "%"
-		db = db.Where("title LIKE ? OR audio_path LIKE ?", search, search)
+		db = db.Where("(title LIKE ? OR audio_path LIKE ?)", search, search)
 	}
 
 	// Count total matching records
@@ -132,12 +281,16 @@ func (r *jobRepository) ListWithParams(ctx context.Context, offset, limit int, s
 }
 
 func (r *jobRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.TranscriptionJob, int64, error) {
-	// Note: Currently TranscriptionJob doesn't have a UserID field in the provided model.
-	// Assuming we might need to add it or this is a placeholder for future multi-user support.
-	// For now, we'll just return all jobs as the current app seems single-user focused or
-	// missing the link.
-	// TODO: Add UserID to TranscriptionJob model if multi-user isolation is required.
-	return r.List(ctx, offset, limit)
+	var jobs []models.TranscriptionJob
+	var count int64
+
+	db := r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).Where("user_id = ?", userID)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Order("created_at desc").Offset(offset).Limit(limit).Find(&jobs).Error
+	return jobs, count, err
 }
 
 func (r *jobRepository) UpdateTranscript(ctx context.Context, jobID string, transcript string) error {
@@ -162,6 +315,52 @@ func (r *jobRepository) DeleteMultiTrackFilesByJobID(ctx context.Context, jobID
 	return r.db.WithContext(ctx).Where("transcription_job_id = ?", jobID).Delete(&models.MultiTrackFile{}).Error
 }
 
+// ListChunks returns the chunk checkpoints of a job in order
+func (r *jobRepository) ListChunks(ctx context.Context, jobID string) ([]models.AudioChunk, error) {
+	var chunks []models.AudioChunk
+	err := r.db.WithContext(ctx).
+		Where("transcription_job_id = ?", jobID).
+		Order("\"index\" ASC").
+		Find(&chunks).Error
+	return chunks, err
+}
+
+// ReplaceChunks discards a job's chunk checkpoints and stores a new plan
+func (r *jobRepository) ReplaceChunks(ctx context.Context, jobID string, chunks []models.AudioChunk) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.AudioChunk{}).Error; err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+		return tx.Omit("TranscriptionJob").Create(&chunks).Error
+	})
+}
+
+// CompleteChunk checkpoints the transcript of a finished chunk
+func (r *jobRepository) CompleteChunk(ctx context.Context, chunkID uint, result string) error {
+	return r.db.WithContext(ctx).Model(&models.AudioChunk{}).
+		Where("id = ?", chunkID).
+		Updates(map[string]interface{}{
+			"status":       models.StatusCompleted,
+			"result":       result,
+			"completed_at": time.Now(),
+		}).Error
+}
+
+func (r *jobRepository) CountCompletedChunks(ctx context.Context, jobID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.AudioChunk{}).
+		Where("transcription_job_id = ? AND status = ?", jobID, models.StatusCompleted).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *jobRepository) DeleteChunksByJobID(ctx context.Context, jobID string) error {
+	return r.db.WithContext(ctx).Where("transcription_job_id = ?", jobID).Delete(&models.AudioChunk{}).Error
+}
+
 func (r *jobRepository) FindActiveTrackJobs(ctx context.Context, parentJobID string) ([]models.TranscriptionJob, error) {
 	var jobs []models.TranscriptionJob
 	err := r.db.WithContext(ctx).
@@ -182,6 +381,35 @@ func (r *jobRepository) FindLatestCompletedExecution(ctx context.Context, jobID
 	return &execution, nil
 }
 
+// FindExecution returns an execution of a job
+func (r *jobRepository) FindExecution(ctx context.Context, jobID, executionID string) (*models.TranscriptionJobExecution, error) {
+	var execution models.TranscriptionJobExecution
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND transcription_job_id = ?", executionID, jobID).
+		First(&execution).Error
+	if err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// ListExecutions returns all executions of a job, oldest first
+func (r *jobRepository) ListExecutions(ctx context.Context, jobID string) ([]models.TranscriptionJobExecution, error) {
+	var executions []models.TranscriptionJobExecution
+	err := r.db.WithContext(ctx).
+		Where("transcription_job_id = ?", jobID).
+		Order("created_at ASC").
+		Find(&executions).Error
+	return executions, err
+}
+
+// SetActiveExecution marks the execution whose result is the job's current transcript
+func (r *jobRepository) SetActiveExecution(ctx context.Context, jobID, executionID string) error {
+	return r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).
+		Where("id = ?", jobID).
+		Update("active_execution_id", executionID).Error
+}
+
 func (r *jobRepository) UpdateStatus(ctx context.Context, jobID string, status models.JobStatus) error {
 	return r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).Where("id = ?", jobID).Update("status", status).Error
 }
@@ -209,11 +437,248 @@ func (r *jobRepository) UpdateSummary(ctx context.Context, jobID string, summary
 	return r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).Where("id = ?", jobID).Update("summary", summary).Error
 }
 
+// MarkQueued puts an uploaded or pending job into the pending state with a
+// fresh attempt budget, releasing any pause, and stamps the time it joined
+// the queue. Callers move finished jobs back to pending before queueing them
+// again, so running, completed and failed jobs are left alone. It reports
+// whether a job was updated.
+func (r *jobRepository) MarkQueued(ctx context.Context, jobID string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).
+		Where("id = ? AND status IN ?", jobID, []models.JobStatus{models.StatusUploaded, models.StatusPending}).
+		Updates(map[string]interface{}{
+			"status":          models.StatusPending,
+			"queued_at":       time.Now(),
+			"attempts":        0,
+			"next_attempt_at": nil,
+			"paused":          false,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RequeueJob saves job and puts it back in the queue in a single update, as
+// long as its status is still one of from. It reports whether the job was
+// queued; a job whose status changed meanwhile, e.g. because a worker claimed
+// it, is left alone.
+func (r *jobRepository) RequeueJob(ctx context.Context, job *models.TranscriptionJob, from []models.JobStatus) (bool, error) {
+	now := time.Now()
+	job.Status = models.StatusPending
+	job.QueuedAt = &now
+	job.Attempts = 0
+	job.NextAttemptAt = nil
+	job.Paused = false
+
+	result := r.db.WithContext(ctx).Model(job).
+		Where("status IN ?", from).
+		Select("*").
+		Omit(clause.Associations, "id", "user_id", "created_at", "deleted_at").
+		Updates(job)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ScheduleRetry returns a failed attempt to the queue. The job keeps its place
+// in line but is not claimed again before the given time.
+func (r *jobRepository) ScheduleRetry(ctx context.Context, jobID string, at time.Time, errorMsg string) error {
+	return r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).
+		Where("id = ?", jobID).
+		Updates(map[string]interface{}{
+			"status":          models.StatusPending,
+			"next_attempt_at": at,
+			"error_message":   errorMsg,
+		}).Error
+}
+
+// ClaimNextPending atomically moves the next pending job to processing and returns it.
+// Higher priority wins; within a priority the job that has waited longest goes first.
+// Paused jobs and jobs waiting out a retry backoff are skipped. Claiming counts as a new attempt.
+// It returns nil when there is nothing to do.
+func (r *jobRepository) ClaimNextPending(ctx context.Context) (*models.TranscriptionJob, error) {
+	for {
+		now := time.Now()
+		var candidates []models.TranscriptionJob
+		err := r.db.WithContext(ctx).
+			Where("status = ? AND paused = ?", models.StatusPending, false).
+			Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now).
+			Order("priority DESC").
+			Order("COALESCE(queued_at, created_at) ASC").
+			Limit(1).
+			Find(&candidates).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 {
+			return nil, nil
+		}
+
+		job := candidates[0]
+		// Re-check the pause: the job may have been paused since it was selected
+		result := r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).
+			Where("id = ? AND status = ? AND paused = ?", job.ID, models.StatusPending, false).
+			Updates(map[string]interface{}{
+				"status":          models.StatusProcessing,
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": nil,
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			job.Status = models.StatusProcessing
+			job.Attempts++
+			job.NextAttemptAt = nil
+			return &job, nil
+		}
+		// Another worker claimed it first, or it was paused; look for the next one
+	}
+}
+
+// ListQueued returns the pending jobs in the order workers claim them,
+// including paused jobs and jobs waiting to be retried
+func (r *jobRepository) ListQueued(ctx context.Context) ([]models.TranscriptionJob, error) {
+	var jobs []models.TranscriptionJob
+	err := r.db.WithContext(ctx).
+		Where("status = ?", models.StatusPending).
+		Order("priority DESC").
+		Order("COALESCE(queued_at, created_at) ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// SetPaused holds a pending job in the queue or releases it. It reports
+// whether a pending job was updated. Like the claim in ClaimNextPending it
+// only matches pending jobs, so exactly one of a claim and a pause racing for
+// the same job succeeds.
+func (r *jobRepository) SetPaused(ctx context.Context, jobID string, paused bool) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).
+		Where("id = ? AND status = ?", jobID, models.StatusPending).
+		Update("paused", paused)
+	return result.RowsAffected > 0, result.Error
+}
+
+// MoveToFront makes a pending job the next one claimed: it takes the highest
+// pending priority and a queue time just before the job currently first in
+// line, and skips the rest of any retry backoff. It reports whether a pending
+// job was moved.
+func (r *jobRepository) MoveToFront(ctx context.Context, jobID string) (bool, error) {
+	moved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var jobs []models.TranscriptionJob
+		if err := tx.Where("id = ? AND status = ?", jobID, models.StatusPending).Limit(1).Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		job := jobs[0]
+		moved = true
+
+		updates := map[string]interface{}{"next_attempt_at": nil}
+		var heads []models.TranscriptionJob
+		err := tx.Where("status = ? AND id <> ?", models.StatusPending, jobID).
+			Order("priority DESC").
+			Order("COALESCE(queued_at, created_at) ASC").
+			Limit(1).
+			Find(&heads).Error
+		if err != nil {
+			return err
+		}
+		if len(heads) > 0 && job.Priority <= heads[0].Priority {
+			head := heads[0]
+			headQueuedAt := head.CreatedAt
+			if head.QueuedAt != nil {
+				headQueuedAt = *head.QueuedAt
+			}
+			updates["priority"] = head.Priority
+			updates["queued_at"] = headQueuedAt.Add(-time.Millisecond)
+		}
+		return tx.Model(&models.TranscriptionJob{}).Where("id = ?", jobID).Updates(updates).Error
+	})
+	return moved, err
+}
+
+// QueuePaused reports whether the queue was paused
+func (r *jobRepository) QueuePaused(ctx context.Context) (bool, error) {
+	var settings []models.QueueSetting
+	if err := r.db.WithContext(ctx).Limit(1).Find(&settings).Error; err != nil {
+		return false, err
+	}
+	return len(settings) > 0 && settings[0].Paused, nil
+}
+
+// SetQueuePaused records whether the queue is paused
+func (r *jobRepository) SetQueuePaused(ctx context.Context, paused bool) error {
+	var settings models.QueueSetting
+	if err := r.db.WithContext(ctx).FirstOrCreate(&settings).Error; err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(&settings).Update("paused", paused).Error
+}
+
+// SetWebhookDeferred records whether a job's completion webhook was held back
+// to be sent once follow-up work is done
+func (r *jobRepository) SetWebhookDeferred(ctx context.Context, jobID string, deferred bool) error {
+	return r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).
+		Where("id = ?", jobID).
+		Update("webhook_deferred", deferred).Error
+}
+
+// FindWebhookDeferred returns the completed jobs whose completion webhook is
+// still held back
+func (r *jobRepository) FindWebhookDeferred(ctx context.Context) ([]models.TranscriptionJob, error) {
+	var jobs []models.TranscriptionJob
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND webhook_deferred = ?", models.StatusCompleted, true).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// SetAudioDuration records the length of a job's audio in seconds
+func (r *jobRepository) SetAudioDuration(ctx context.Context, jobID string, seconds float64) error {
+	return r.db.WithContext(ctx).Model(&models.TranscriptionJob{}).
+		Where("id = ?", jobID).
+		Update("audio_duration", seconds).Error
+}
+
+// ProcessingSpeed returns how many seconds the most recent completed
+// executions with a model and device took per second of audio, and how many
+// executions that is based on. An empty model matches any model of the family.
+func (r *jobRepository) ProcessingSpeed(ctx context.Context, modelFamily, model, device string, limit int) (float64, int, error) {
+	var samples []struct {
+		ProcessingDuration int64
+		AudioDuration      float64
+	}
+	query := r.db.WithContext(ctx).
+		Table("transcription_job_executions AS e").
+		Select("e.processing_duration, j.audio_duration").
+		Joins("JOIN transcription_jobs j ON j.id = e.transcription_job_id").
+		Where("e.status = ? AND e.processing_duration IS NOT NULL AND j.audio_duration > 0", models.StatusCompleted).
+		Where("e.actual_model_family = ? AND e.actual_device = ?", modelFamily, device)
+	if model != "" {
+		query = query.Where("e.actual_model = ?", model)
+	}
+	if err := query.Order("e.completed_at DESC").Limit(limit).Scan(&samples).Error; err != nil {
+		return 0, 0, err
+	}
+
+	var processing, audio float64
+	for _, sample := range samples {
+		processing += float64(sample.ProcessingDuration) / 1000
+		audio += sample.AudioDuration
+	}
+	if audio == 0 {
+		return 0, 0, nil
+	}
+	return processing / audio, len(samples), nil
+}
+
 // APIKeyRepository handles API key operations
 type APIKeyRepository interface {
 	Repository[models.APIKey]
 	FindByKey(ctx context.Context, key string) (*models.APIKey, error)
 	ListActive(ctx context.Context) ([]models.APIKey, error)
+	ListActiveByUser(ctx context.Context, userID uint) ([]models.APIKey, error)
 	Revoke(ctx context.Context, id uint) error
 }
 
@@ -227,9 +692,10 @@ func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
 	}
 }
 
+// FindByKey looks up an API key by its plaintext value
 func (r *apiKeyRepository) FindByKey(ctx context.Context, key string) (*models.APIKey, error) {
 	var apiKey models.APIKey
-	err := r.db.WithContext(ctx).Where("key = ?", key).First(&apiKey).Error
+	err := r.db.WithContext(ctx).Where("key_hash = ?", models.HashAPIKey(key)).First(&apiKey).Error
 	if err != nil {
 		return nil, err
 	}
@@ -245,6 +711,15 @@ func (r *apiKeyRepository) ListActive(ctx context.Context) ([]models.APIKey, err
 	return apiKeys, nil
 }
 
+func (r *apiKeyRepository) ListActiveByUser(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	err := r.db.WithContext(ctx).Where("is_active = ? AND user_id = ?", true, userID).Find(&apiKeys).Error
+	if err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
 func (r *apiKeyRepository) Revoke(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Update("is_active", false).Error
 }
@@ -365,9 +840,11 @@ func (r *summaryRepository) DeleteByTranscriptionID(ctx context.Context, transcr
 type ChatRepository interface {
 	Repository[models.ChatSession]
 	GetSessionWithMessages(ctx context.Context, id string) (*models.ChatSession, error)
+	FindSessionForUser(ctx context.Context, id string, userID uint) (*models.ChatSession, error)
 	GetSessionWithTranscription(ctx context.Context, id string) (*models.ChatSession, error)
 	AddMessage(ctx context.Context, message *models.ChatMessage) error
 	ListByJob(ctx context.Context, jobID string) ([]models.ChatSession, error)
+	ListLibraryForUser(ctx context.Context, userID uint) ([]models.ChatSession, error)
 	DeleteSession(ctx context.Context, id string) error
 	GetMessages(ctx context.Context, sessionID string, limit int) ([]models.ChatMessage, error)
 	DeleteByJobID(ctx context.Context, jobID string) error
@@ -394,6 +871,19 @@ func (r *chatRepository) GetSessionWithMessages(ctx context.Context, id string)
 	return &session, nil
 }
 
+// FindSessionForUser returns the session only if it, or its transcription, is owned by userID
+func (r *chatRepository) FindSessionForUser(ctx context.Context, id string, userID uint) (*models.ChatSession, error) {
+	var session models.ChatSession
+	err := r.db.WithContext(ctx).
+		Joins("LEFT JOIN transcription_jobs ON transcription_jobs.id = chat_sessions.transcription_id").
+		Where("chat_sessions.id = ? AND (chat_sessions.user_id = ? OR transcription_jobs.user_id = ?)", id, userID, userID).
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
 func (r *chatRepository) GetSessionWithTranscription(ctx context.Context, id string) (*models.ChatSession, error) {
 	var session models.ChatSession
 	err := r.db.WithContext(ctx).Preload("Transcription").Where("id = ?", id).First(&session).Error
@@ -416,6 +906,19 @@ func (r *chatRepository) ListByJob(ctx context.Context, jobID string) ([]models.
 	return sessions, nil
 }
 
+// ListLibraryForUser returns the user's library chat sessions, newest first
+func (r *chatRepository) ListLibraryForUser(ctx context.Context, userID uint) ([]models.ChatSession, error) {
+	var sessions []models.ChatSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND mode = ?", userID, models.ChatModeLibrary).
+		Order("created_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
 func (r *chatRepository) DeleteSession(ctx context.Context, id string) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		// Delete messages first
@@ -513,6 +1016,7 @@ func (r *chatRepository) GetLastMessagesBySessionIDs(ctx context.Context, sessio
 type NoteRepository interface {
 	Repository[models.Note]
 	ListByJob(ctx context.Context, jobID string) ([]models.Note, error)
+	FindByIDForUser(ctx context.Context, id string, userID uint) (*models.Note, error)
 	DeleteByTranscriptionID(ctx context.Context, transcriptionID string) error
 }
 
@@ -535,6 +1039,19 @@ func (r *noteRepository) ListByJob(ctx context.Context, jobID string) ([]models.
 	return notes, nil
 }
 
+// FindByIDForUser returns the note only if its transcription is owned by userID
+func (r *noteRepository) FindByIDForUser(ctx context.Context, id string, userID uint) (*models.Note, error) {
+	var note models.Note
+	err := r.db.WithContext(ctx).
+		Joins("JOIN transcription_jobs ON transcription_jobs.id = notes.transcription_id").
+		Where("notes.id = ? AND transcription_jobs.user_id = ?", id, userID).
+		First(&note).Error
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
 func (r *noteRepository) DeleteByTranscriptionID(ctx context.Context, transcriptionID string) error {
 	return r.db.WithContext(ctx).Where("transcription_id = ?", transcriptionID).Delete(&models.Note{}).Error
 }
@@ -544,6 +1061,8 @@ type SpeakerMappingRepository interface {
 	Repository[models.SpeakerMapping]
 	ListByJob(ctx context.Context, jobID string) ([]models.SpeakerMapping, error)
 	UpdateMappings(ctx context.Context, jobID string, mappings []models.SpeakerMapping) error
+	// SetNames names speaker labels of a job, keeping existing names unless overwrite is set
+	SetNames(ctx context.Context, jobID string, names map[string]string, overwrite bool) error
 	DeleteByJobID(ctx context.Context, jobID string) error
 }
 
@@ -587,12 +1106,38 @@ func (r *speakerMappingRepository) UpdateMappings(ctx context.Context, jobID str
 	})
 }
 
+func (r *speakerMappingRepository) SetNames(ctx context.Context, jobID string, names map[string]string, overwrite bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for label, name := range names {
+			var mapping models.SpeakerMapping
+			err := tx.Where("transcription_job_id = ? AND original_speaker = ?", jobID, label).First(&mapping).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				mapping = models.SpeakerMapping{TranscriptionJobID: jobID, OriginalSpeaker: label, CustomName: name}
+				if err := tx.Omit("TranscriptionJob").Create(&mapping).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if overwrite && mapping.CustomName != name {
+				if err := tx.Model(&mapping).Update("custom_name", name).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
 // RefreshTokenRepository handles refresh token operations
 type RefreshTokenRepository interface {
 	Create(ctx context.Context, token *models.RefreshToken) error
 	FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
 	Revoke(ctx context.Context, id uint) error
 	RevokeByHash(ctx context.Context, hash string) error
+	RevokeAllForUser(ctx context.Context, userID uint) error
 }
 
 type refreshTokenRepository struct {
@@ -623,3 +1168,7 @@ func (r *refreshTokenRepository) Revoke(ctx context.Context, id uint) error {
 func (r *refreshTokenRepository) RevokeByHash(ctx context.Context, hash string) error {
 	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).Where("hashed = ?", hash).Update("revoked", true).Error
 }
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).Where("user_id = ? AND revoked = ?", userID, false).Update("revoked", true).Error
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"scriberr/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrTranscriptChanged is returned when a transcript was modified while an
+// edit based on an older version of it was being applied
+var ErrTranscriptChanged = errors.New("transcript was changed by another edit")
+
+// RevisionRepository stores the edit history of transcripts
+type RevisionRepository interface {
+	Apply(ctx context.Context, revision *models.TranscriptRevision, previous, updated string) error
+	ListByJob(ctx context.Context, jobID string) ([]models.TranscriptRevision, error)
+	FindOriginal(ctx context.Context, jobID string) (*models.TranscriptRevision, error)
+	DeleteByJob(ctx context.Context, jobID string) error
+}
+
+type revisionRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) RevisionRepository {
+	return &revisionRepository{db: db}
+}
+
+// Apply stores updated as the transcript of the job and records revision with
+// the next revision number. previous must be the transcript the edit was made
+// against; the original model output is kept as revision 0 on the first edit.
+func (r *revisionRepository) Apply(ctx context.Context, revision *models.TranscriptRevision, previous, updated string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TranscriptionJob{}).
+			Where("id = ? AND transcript = ?", revision.TranscriptionJobID, previous).
+			Update("transcript", updated)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTranscriptChanged
+		}
+
+		var latest *int
+		if err := tx.Model(&models.TranscriptRevision{}).
+			Where("transcription_job_id = ?", revision.TranscriptionJobID).
+			Select("MAX(number)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		if latest == nil {
+			original := &models.TranscriptRevision{
+				TranscriptionJobID: revision.TranscriptionJobID,
+				Number:             0,
+				Action:             models.RevisionActionOriginal,
+				Transcript:         &previous,
+			}
+			if err := tx.Create(original).Error; err != nil {
+				return err
+			}
+			latest = &original.Number
+		}
+
+		revision.Number = *latest + 1
+		return tx.Create(revision).Error
+	})
+}
+
+// ListByJob returns the revisions of a job, oldest first
+func (r *revisionRepository) ListByJob(ctx context.Context, jobID string) ([]models.TranscriptRevision, error) {
+	var revisions []models.TranscriptRevision
+	err := r.db.WithContext(ctx).
+		Where("transcription_job_id = ?", jobID).
+		Order("number ASC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// FindOriginal returns revision 0 of a job, which holds the original model output
+func (r *revisionRepository) FindOriginal(ctx context.Context, jobID string) (*models.TranscriptRevision, error) {
+	var revision models.TranscriptRevision
+	err := r.db.WithContext(ctx).
+		Where("transcription_job_id = ? AND number = 0", jobID).
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// DeleteByJob removes the edit history of a job
+func (r *revisionRepository) DeleteByJob(ctx context.Context, jobID string) error {
+	return r.db.WithContext(ctx).Where("transcription_job_id = ?", jobID).Delete(&models.TranscriptRevision{}).Error
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"scriberr/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SearchRepository maintains the full-text index (the search_index FTS5 table)
+// over transcript segments, notes and the latest summary of each transcription.
+type SearchRepository interface {
+	IndexTranscript(ctx context.Context, jobID string) error
+	IndexNote(ctx context.Context, note *models.Note) error
+	RemoveNote(ctx context.Context, noteID string) error
+	IndexSummary(ctx context.Context, summary *models.Summary) error
+	DeleteByJob(ctx context.Context, jobID string) error
+	RebuildIfEmpty(ctx context.Context) error
+	Search(ctx context.Context, userID uint, query, kind string, offset, limit int) ([]models.SearchHit, int64, error)
+	SearchPassages(ctx context.Context, userID uint, jobIDs []string, query string, limit int) ([]models.SearchPassage, error)
+}
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+const searchIndexColumns = "content, job_id, kind, source_id, start_time, end_time, speaker"
+
+// Segments are read straight from the stored transcript JSON. Transcripts
+// without segments are indexed as a single block of text.
+const indexSegmentsSQL = `INSERT INTO search_index (` + searchIndexColumns + `)
+SELECT json_extract(s.value, '$.text'), j.id, 'segment', s.key,
+	json_extract(s.value, '$.start'), json_extract(s.value, '$.end'), json_extract(s.value, '$.speaker')
+FROM transcription_jobs j, json_each(j.transcript, '$.segments') s
+WHERE j.transcript IS NOT NULL AND json_valid(j.transcript) AND j.deleted_at IS NULL
+	AND TRIM(COALESCE(json_extract(s.value, '$.text'), '')) <> ''`
+
+const indexPlainTranscriptSQL = `INSERT INTO search_index (` + searchIndexColumns + `)
+SELECT json_extract(j.transcript, '$.text'), j.id, 'segment', '0', NULL, NULL, NULL
+FROM transcription_jobs j
+WHERE j.transcript IS NOT NULL AND json_valid(j.transcript) AND j.deleted_at IS NULL
+	AND COALESCE(json_array_length(j.transcript, '$.segments'), 0) = 0
+	AND TRIM(COALESCE(json_extract(j.transcript, '$.text'), '')) <> ''`
+
+const indexNotesSQL = `INSERT INTO search_index (` + searchIndexColumns + `)
+SELECT n.content, n.transcription_id, 'note', n.id, n.start_time, n.end_time, NULL
+FROM notes n
+WHERE TRIM(n.content) <> ''`
+
+const indexSummariesSQL = `INSERT INTO search_index (` + searchIndexColumns + `)
+SELECT s.content, s.transcription_id, 'summary', s.id, NULL, NULL, NULL
+FROM summaries s
+WHERE s.id = (SELECT latest.id FROM summaries latest WHERE latest.transcription_id = s.transcription_id ORDER BY latest.created_at DESC LIMIT 1)`
+
+// IndexTranscript replaces the indexed segments of a job with its stored transcript
+func (r *searchRepository) IndexTranscript(ctx context.Context, jobID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM search_index WHERE job_id = ? AND kind = ?", jobID, models.SearchKindSegment).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(indexSegmentsSQL+" AND j.id = ?", jobID).Error; err != nil {
+			return err
+		}
+		return tx.Exec(indexPlainTranscriptSQL+" AND j.id = ?", jobID).Error
+	})
+}
+
+// IndexNote adds or refreshes a note in the index
+func (r *searchRepository) IndexNote(ctx context.Context, note *models.Note) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM search_index WHERE kind = ? AND source_id = ?", models.SearchKindNote, note.ID).Error; err != nil {
+			return err
+		}
+		if strings.TrimSpace(note.Content) == "" {
+			return nil
+		}
+		return tx.Exec("INSERT INTO search_index ("+searchIndexColumns+") VALUES (?, ?, ?, ?, ?, ?, NULL)",
+			note.Content, note.TranscriptionID, models.SearchKindNote, note.ID, note.StartTime, note.EndTime).Error
+	})
+}
+
+// RemoveNote drops a note from the index
+func (r *searchRepository) RemoveNote(ctx context.Context, noteID string) error {
+	return r.db.WithContext(ctx).Exec("DELETE FROM search_index WHERE kind = ? AND source_id = ?", models.SearchKindNote, noteID).Error
+}
+
+// IndexSummary makes summary the indexed summary of its transcription
+func (r *searchRepository) IndexSummary(ctx context.Context, summary *models.Summary) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM search_index WHERE job_id = ? AND kind = ?", summary.TranscriptionID, models.SearchKindSummary).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO search_index ("+searchIndexColumns+") VALUES (?, ?, ?, ?, NULL, NULL, NULL)",
+			summary.Content, summary.TranscriptionID, models.SearchKindSummary, summary.ID).Error
+	})
+}
+
+// DeleteByJob removes everything indexed for a job
+func (r *searchRepository) DeleteByJob(ctx context.Context, jobID string) error {
+	return r.db.WithContext(ctx).Exec("DELETE FROM search_index WHERE job_id = ?", jobID).Error
+}
+
+// RebuildIfEmpty fills an empty index from existing data, e.g. after upgrading
+// from a version without search
+func (r *searchRepository) RebuildIfEmpty(ctx context.Context) error {
+	var indexed int64
+	if err := r.db.WithContext(ctx).Raw("SELECT COUNT(*) FROM search_index").Scan(&indexed).Error; err != nil {
+		return err
+	}
+	if indexed > 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{indexSegmentsSQL, indexPlainTranscriptSQL, indexNotesSQL, indexSummariesSQL} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Search finds indexed text in the jobs owned by userID, best matches first.
+// Snippets are HTML-escaped with the matches wrapped in <mark> tags.
+// kind optionally restricts results to segments, notes or summaries.
+func (r *searchRepository) Search(ctx context.Context, userID uint, query, kind string, offset, limit int) ([]models.SearchHit, int64, error) {
+	match := BuildMatchQuery(query)
+	if match == "" {
+		return []models.SearchHit{}, 0, nil
+	}
+
+	from := `FROM search_index
+JOIN transcription_jobs j ON j.id = search_index.job_id AND j.deleted_at IS NULL
+WHERE search_index MATCH ? AND j.user_id = ?`
+	args := []interface{}{match, userID}
+	if kind != "" {
+		from += " AND search_index.kind = ?"
+		args = append(args, kind)
+	}
+
+	var total int64
+	if err := r.db.WithContext(ctx).Raw("SELECT COUNT(*) "+from, args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	hits := []models.SearchHit{}
+	err := r.db.WithContext(ctx).Raw(`SELECT search_index.job_id, j.title AS job_title, search_index.kind, search_index.source_id,
+	search_index.start_time, search_index.end_time,
+	COALESCE((SELECT sm.custom_name FROM speaker_mappings sm
+		WHERE sm.transcription_job_id = search_index.job_id AND sm.original_speaker = search_index.speaker), search_index.speaker) AS speaker,
+	snippet(search_index, 0, char(2), char(3), '…', 16) AS snippet,
+	bm25(search_index) AS rank `+from+`
+ORDER BY rank
+LIMIT ? OFFSET ?`, append(args, limit, offset)...).Scan(&hits).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range hits {
+		hits[i].Snippet = highlightSnippet(hits[i].Snippet)
+	}
+	return hits, total, nil
+}
+
+// highlightSnippet escapes the indexed text of a snippet as HTML and only then
+// turns the match delimiters chosen in Search into <mark> tags, so the only
+// markup in a snippet is the highlighting
+func highlightSnippet(snippet string) string {
+	return strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>").Replace(html.EscapeString(snippet))
+}
+
+// SearchPassages finds the transcript segments in the jobs owned by userID that
+// best match query, limited to jobIDs when given. Unlike Search any word may
+// match, so a question finds the segments sharing the most words with it.
+func (r *searchRepository) SearchPassages(ctx context.Context, userID uint, jobIDs []string, query string, limit int) ([]models.SearchPassage, error) {
+	var terms []string
+	for _, word := range strings.FieldsFunc(query, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }) {
+		terms = append(terms, quoteMatchTerm(word))
+	}
+	if len(terms) == 0 {
+		return []models.SearchPassage{}, nil
+	}
+
+	where := "search_index MATCH ? AND j.user_id = ? AND search_index.kind = ?"
+	args := []interface{}{strings.Join(terms, " OR "), userID, models.SearchKindSegment}
+	if len(jobIDs) > 0 {
+		where += " AND search_index.job_id IN ?"
+		args = append(args, jobIDs)
+	}
+
+	passages := []models.SearchPassage{}
+	err := r.db.WithContext(ctx).Raw(`SELECT search_index.job_id, j.title AS job_title, search_index.content,
+	search_index.start_time, search_index.end_time,
+	COALESCE((SELECT sm.custom_name FROM speaker_mappings sm
+		WHERE sm.transcription_job_id = search_index.job_id AND sm.original_speaker = search_index.speaker), search_index.speaker) AS speaker,
+	bm25(search_index) AS rank
+FROM search_index
+JOIN transcription_jobs j ON j.id = search_index.job_id AND j.deleted_at IS NULL
+WHERE `+where+`
+ORDER BY rank
+LIMIT ?`, append(args, limit)...).Scan(&passages).Error
+	if err != nil {
+		return nil, err
+	}
+	return passages, nil
+}
+
+// BuildMatchQuery turns free text into an FTS5 query that matches documents
+// containing every word. Quoted phrases are kept together and FTS5 operators
+// in user input are treated as plain words.
+func BuildMatchQuery(input string) string {
+	var terms []string
+	for i, part := range strings.Split(input, `"`) {
+		if i%2 == 1 {
+			// Inside a quoted phrase
+			if phrase := strings.Join(strings.Fields(part), " "); phrase != "" {
+				terms = append(terms, quoteMatchTerm(phrase))
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			terms = append(terms, quoteMatchTerm(word))
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+func quoteMatchTerm(term string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(term, `"`, `""`))
+}
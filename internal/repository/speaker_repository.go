@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"scriberr/internal/models"
+	"scriberr/internal/rag"
+	"scriberr/internal/speakers"
+
+	"gorm.io/gorm"
+)
+
+// SpeakerRepository stores the speaker library and the voice embeddings of
+// the speakers in each job
+type SpeakerRepository interface {
+	Create(ctx context.Context, speaker *models.Speaker) error
+	Update(ctx context.Context, speaker *models.Speaker) error
+	FindByUserAndID(ctx context.Context, userID uint, id string) (*models.Speaker, error)
+	FindByIDs(ctx context.Context, ids []string) ([]models.Speaker, error)
+	ListByUser(ctx context.Context, userID uint) ([]models.Speaker, error)
+	Delete(ctx context.Context, id string) error
+
+	// ListVoicePrints returns the user's voice prints for one embedding model
+	ListVoicePrints(ctx context.Context, userID uint, model string) ([]models.SpeakerVoicePrint, error)
+	// AddSample averages a recording's embedding into the speaker's voice print for model
+	AddSample(ctx context.Context, speakerID, model string, embedding []float32) error
+	// Merge moves the voice prints and job matches of sources into target and deletes sources
+	Merge(ctx context.Context, targetID string, sourceIDs []string) error
+
+	ReplaceJobSpeakers(ctx context.Context, jobID string, jobSpeakers []models.JobSpeaker) error
+	ListJobSpeakers(ctx context.Context, jobID string) ([]models.JobSpeaker, error)
+	FindJobSpeaker(ctx context.Context, jobID, label string) (*models.JobSpeaker, error)
+	SetJobSpeakerMatch(ctx context.Context, id uint, speakerID *string, similarity *float64) error
+	DeleteJobSpeakers(ctx context.Context, jobID string) error
+}
+
+type speakerRepository struct {
+	db *gorm.DB
+}
+
+func NewSpeakerRepository(db *gorm.DB) SpeakerRepository {
+	return &speakerRepository{db: db}
+}
+
+func (r *speakerRepository) Create(ctx context.Context, speaker *models.Speaker) error {
+	return r.db.WithContext(ctx).Omit("VoicePrints").Create(speaker).Error
+}
+
+func (r *speakerRepository) Update(ctx context.Context, speaker *models.Speaker) error {
+	return r.db.WithContext(ctx).Omit("VoicePrints").Save(speaker).Error
+}
+
+// FindByUserAndID returns nil without an error when the user has no such speaker
+func (r *speakerRepository) FindByUserAndID(ctx context.Context, userID uint, id string) (*models.Speaker, error) {
+	var speaker models.Speaker
+	err := r.db.WithContext(ctx).Preload("VoicePrints").Where("id = ? AND user_id = ?", id, userID).First(&speaker).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &speaker, nil
+}
+
+// FindByIDs returns the speakers that exist among ids
+func (r *speakerRepository) FindByIDs(ctx context.Context, ids []string) ([]models.Speaker, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var found []models.Speaker
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&found).Error
+	return found, err
+}
+
+func (r *speakerRepository) ListByUser(ctx context.Context, userID uint) ([]models.Speaker, error) {
+	var list []models.Speaker
+	err := r.db.WithContext(ctx).
+		Preload("VoicePrints").
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// Delete removes a speaker and its voice prints. Jobs it was matched in keep
+// their speaker names but lose the match.
+func (r *speakerRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.JobSpeaker{}).Where("speaker_id = ?", id).
+			Updates(map[string]interface{}{"speaker_id": nil, "similarity": nil}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("speaker_id = ?", id).Delete(&models.SpeakerVoicePrint{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.Speaker{}).Error
+	})
+}
+
+func (r *speakerRepository) ListVoicePrints(ctx context.Context, userID uint, model string) ([]models.SpeakerVoicePrint, error) {
+	var voicePrints []models.SpeakerVoicePrint
+	err := r.db.WithContext(ctx).
+		Joins("JOIN speakers ON speakers.id = speaker_voice_prints.speaker_id").
+		Where("speakers.user_id = ? AND speaker_voice_prints.model = ?", userID, model).
+		Find(&voicePrints).Error
+	return voicePrints, err
+}
+
+func (r *speakerRepository) AddSample(ctx context.Context, speakerID, model string, embedding []float32) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return addVoicePrint(tx, models.SpeakerVoicePrint{
+			SpeakerID: speakerID,
+			Model:     model,
+			Embedding: rag.EncodeVector(speakers.Normalize(embedding)),
+			Samples:   1,
+		})
+	})
+}
+
+// addVoicePrint averages a voice print into the speaker's voice print for its model,
+// creating the voice print if the speaker has none yet
+func addVoicePrint(tx *gorm.DB, voicePrint models.SpeakerVoicePrint) error {
+	var existing models.SpeakerVoicePrint
+	err := tx.Where("speaker_id = ? AND model = ?", voicePrint.SpeakerID, voicePrint.Model).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		voicePrint.ID = 0
+		return tx.Create(&voicePrint).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	combined := speakers.Average(rag.DecodeVector(existing.Embedding), existing.Samples,
+		rag.DecodeVector(voicePrint.Embedding), voicePrint.Samples)
+	existing.Embedding = rag.EncodeVector(combined)
+	existing.Samples += voicePrint.Samples
+	return tx.Save(&existing).Error
+}
+
+func (r *speakerRepository) Merge(ctx context.Context, targetID string, sourceIDs []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var voicePrints []models.SpeakerVoicePrint
+		if err := tx.Where("speaker_id IN ?", sourceIDs).Find(&voicePrints).Error; err != nil {
+			return err
+		}
+		for _, voicePrint := range voicePrints {
+			voicePrint.SpeakerID = targetID
+			if err := addVoicePrint(tx, voicePrint); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("speaker_id IN ?", sourceIDs).Delete(&models.SpeakerVoicePrint{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.JobSpeaker{}).Where("speaker_id IN ?", sourceIDs).
+			Update("speaker_id", targetID).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", sourceIDs).Delete(&models.Speaker{}).Error
+	})
+}
+
+func (r *speakerRepository) ReplaceJobSpeakers(ctx context.Context, jobID string, jobSpeakers []models.JobSpeaker) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.JobSpeaker{}).Error; err != nil {
+			return err
+		}
+		if len(jobSpeakers) == 0 {
+			return nil
+		}
+		return tx.Omit("TranscriptionJob").Create(&jobSpeakers).Error
+	})
+}
+
+func (r *speakerRepository) ListJobSpeakers(ctx context.Context, jobID string) ([]models.JobSpeaker, error) {
+	var list []models.JobSpeaker
+	err := r.db.WithContext(ctx).
+		Where("transcription_job_id = ?", jobID).
+		Order("label ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// FindJobSpeaker returns nil without an error when the job has no embedding for label
+func (r *speakerRepository) FindJobSpeaker(ctx context.Context, jobID, label string) (*models.JobSpeaker, error) {
+	var jobSpeaker models.JobSpeaker
+	err := r.db.WithContext(ctx).Where("transcription_job_id = ? AND label = ?", jobID, label).First(&jobSpeaker).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &jobSpeaker, nil
+}
+
+func (r *speakerRepository) SetJobSpeakerMatch(ctx context.Context, id uint, speakerID *string, similarity *float64) error {
+	return r.db.WithContext(ctx).Model(&models.JobSpeaker{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"speaker_id": speakerID, "similarity": similarity}).Error
+}
+
+func (r *speakerRepository) DeleteJobSpeakers(ctx context.Context, jobID string) error {
+	return r.db.WithContext(ctx).Where("transcription_job_id = ?", jobID).Delete(&models.JobSpeaker{}).Error
+}
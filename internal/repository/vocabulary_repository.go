@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"scriberr/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// VocabularyRepository stores custom vocabularies
+type VocabularyRepository interface {
+	Create(ctx context.Context, vocabulary *models.Vocabulary) error
+	Update(ctx context.Context, vocabulary *models.Vocabulary) error
+	FindByID(ctx context.Context, id string) (*models.Vocabulary, error)
+	FindByUserAndID(ctx context.Context, userID uint, id string) (*models.Vocabulary, error)
+	FindUsableByIDs(ctx context.Context, userID uint, ids []string) ([]models.Vocabulary, error)
+	ListByUser(ctx context.Context, userID uint) ([]models.Vocabulary, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type vocabularyRepository struct {
+	db *gorm.DB
+}
+
+func NewVocabularyRepository(db *gorm.DB) VocabularyRepository {
+	return &vocabularyRepository{db: db}
+}
+
+func (r *vocabularyRepository) Create(ctx context.Context, vocabulary *models.Vocabulary) error {
+	return r.db.WithContext(ctx).Create(vocabulary).Error
+}
+
+func (r *vocabularyRepository) Update(ctx context.Context, vocabulary *models.Vocabulary) error {
+	return r.db.WithContext(ctx).Save(vocabulary).Error
+}
+
+func (r *vocabularyRepository) FindByID(ctx context.Context, id string) (*models.Vocabulary, error) {
+	var vocabulary models.Vocabulary
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&vocabulary).Error; err != nil {
+		return nil, err
+	}
+	return &vocabulary, nil
+}
+
+// FindByUserAndID returns nil without an error when the user has no such vocabulary
+func (r *vocabularyRepository) FindByUserAndID(ctx context.Context, userID uint, id string) (*models.Vocabulary, error) {
+	var vocabulary models.Vocabulary
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&vocabulary).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &vocabulary, nil
+}
+
+// FindUsableByIDs returns the vocabularies among ids that userID may apply,
+// in the order given: their own and those of admins, which reach members
+// through the transcription profiles admins manage
+func (r *vocabularyRepository) FindUsableByIDs(ctx context.Context, userID uint, ids []string) ([]models.Vocabulary, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var found []models.Vocabulary
+	err := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Where("user_id = ? OR user_id IN (?)", userID,
+			r.db.Model(&models.User{}).Select("id").Where("role = ?", models.RoleAdmin)).
+		Find(&found).Error
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Vocabulary, len(found))
+	for _, v := range found {
+		byID[v.ID] = v
+	}
+	vocabularies := make([]models.Vocabulary, 0, len(found))
+	for _, id := range ids {
+		if v, ok := byID[id]; ok {
+			vocabularies = append(vocabularies, v)
+			delete(byID, id)
+		}
+	}
+	return vocabularies, nil
+}
+
+func (r *vocabularyRepository) ListByUser(ctx context.Context, userID uint) ([]models.Vocabulary, error) {
+	var vocabularies []models.Vocabulary
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&vocabularies).Error
+	return vocabularies, err
+}
+
+func (r *vocabularyRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Vocabulary{}).Error
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"scriberr/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository stores webhook subscriptions and their delivery log
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	FindSubscription(ctx context.Context, userID uint, id string) (*models.WebhookSubscription, error)
+	FindSubscriptionByID(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, userID uint) ([]models.WebhookSubscription, error)
+	ListActiveSubscriptions(ctx context.Context, userID uint) ([]models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	FindDelivery(ctx context.Context, subscriptionID, id string) (*models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error)
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Save(subscription).Error
+}
+
+// FindSubscription returns nil without an error when the user has no such subscription
+func (r *webhookRepository) FindSubscription(ctx context.Context, userID uint, id string) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *webhookRepository) FindSubscriptionByID(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context, userID uint) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookRepository) ListActiveSubscriptions(ctx context.Context, userID uint) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND active = ?", userID, true).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// DeleteSubscription deletes a subscription together with its delivery log
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.WebhookSubscription{}).Error
+	})
+}
+
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Omit("Subscription").Save(delivery).Error
+}
+
+// FindDelivery returns nil without an error when the subscription has no such delivery
+func (r *webhookRepository) FindDelivery(ctx context.Context, subscriptionID, id string) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.WithContext(ctx).Where("id = ? AND subscription_id = ?", id, subscriptionID).First(&delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries returns a subscription's most recent deliveries first
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ListDueDeliveries returns pending deliveries whose next attempt is due,
+// oldest first. Deliveries queued behind an earlier one of the same
+// subscription that is waiting to be retried are held back until it is settled.
+func (r *webhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", models.WebhookDeliveryPending, now).
+		Where(`NOT EXISTS (SELECT 1 FROM webhook_deliveries earlier
+	WHERE earlier.subscription_id = webhook_deliveries.subscription_id AND earlier.status = ?
+		AND earlier.created_at < webhook_deliveries.created_at AND earlier.next_attempt_at > ?)`, models.WebhookDeliveryPending, now).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
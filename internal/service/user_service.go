@@ -46,12 +46,13 @@ func (s *userService) Register(ctx context.Context, username, password string) (
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	// The first account on an instance becomes its administrator
 	user := &models.User{
 		Username: username,
 		Password: hashedPassword,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
+	if err := s.userRepo.CreateWithFirstAdmin(ctx, user); err != nil {
 		return nil, err
 	}
 
@@ -68,6 +69,10 @@ func (s *userService) Login(ctx context.Context, username, password string) (str
 		return "", nil, errors.New("invalid credentials")
 	}
 
+	if user.Disabled {
+		return "", nil, errors.New("account is disabled")
+	}
+
 	token, err := s.authService.GenerateToken(user)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to generate token: %w", err)
@@ -0,0 +1,99 @@
+// Package speakers recognizes enrolled speakers in new recordings by the
+// cosine similarity of their voice embeddings.
+package speakers
+
+import (
+	"math"
+	"sort"
+
+	"scriberr/internal/rag"
+)
+
+// DefaultMatchThreshold is the similarity above which a voice is taken to be
+// an enrolled speaker when a job does not set its own threshold
+const DefaultMatchThreshold = 0.7
+
+// Normalize scales v to unit length, returning nil when v is all zeros
+func Normalize(v []float32) []float32 {
+	var norm float64
+	for _, f := range v {
+		norm += float64(f) * float64(f)
+	}
+	if norm == 0 || math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return nil
+	}
+	norm = math.Sqrt(norm)
+	out := make([]float32, len(v))
+	for i, f := range v {
+		out[i] = float32(float64(f) / norm)
+	}
+	return out
+}
+
+// Average combines two voice prints built from aSamples and bSamples
+// recordings into one, weighting each by its number of recordings
+func Average(a []float32, aSamples int, b []float32, bSamples int) []float32 {
+	if len(a) != len(b) || aSamples+bSamples <= 0 {
+		return Normalize(b)
+	}
+	a, b = Normalize(a), Normalize(b)
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	sum := make([]float32, len(a))
+	for i := range a {
+		sum[i] = (a[i]*float32(aSamples) + b[i]*float32(bSamples)) / float32(aSamples+bSamples)
+	}
+	return Normalize(sum)
+}
+
+// Candidate is an enrolled speaker's voice print
+type Candidate struct {
+	SpeakerID string
+	Embedding []float32
+}
+
+// Match is the enrolled speaker a label in a recording was recognized as
+type Match struct {
+	Label      string
+	SpeakerID  string
+	Similarity float64
+}
+
+// Assign matches the speaker labels of one recording to candidates whose
+// similarity reaches threshold. Two voices in the same recording are never the
+// same person, so the most similar pairs are matched first and each candidate
+// is used for at most one label.
+func Assign(labels map[string][]float32, candidates []Candidate, threshold float64) map[string]Match {
+	var pairs []Match
+	for label, embedding := range labels {
+		for _, c := range candidates {
+			if similarity := rag.Cosine(embedding, c.Embedding); similarity >= threshold {
+				pairs = append(pairs, Match{Label: label, SpeakerID: c.SpeakerID, Similarity: similarity})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Similarity != pairs[j].Similarity {
+			return pairs[i].Similarity > pairs[j].Similarity
+		}
+		if pairs[i].Label != pairs[j].Label {
+			return pairs[i].Label < pairs[j].Label
+		}
+		return pairs[i].SpeakerID < pairs[j].SpeakerID
+	})
+
+	matches := make(map[string]Match)
+	used := make(map[string]bool)
+	for _, p := range pairs {
+		if _, done := matches[p.Label]; done || used[p.SpeakerID] {
+			continue
+		}
+		matches[p.Label] = p
+		used[p.SpeakerID] = true
+	}
+	return matches
+}
@@ -0,0 +1,44 @@
+package speakers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.InDeltaSlice(t, []float32{0.6, 0.8}, Normalize([]float32{3, 4}), 1e-6)
+	assert.Nil(t, Normalize([]float32{0, 0}))
+}
+
+func TestAverage(t *testing.T) {
+	// Three recordings of one voice outweigh one of another
+	avg := Average([]float32{1, 0}, 3, []float32{0, 1}, 1)
+	assert.InDeltaSlice(t, Normalize([]float32{3, 1}), avg, 1e-6)
+
+	// A voice print from a different model is replaced
+	assert.InDeltaSlice(t, []float32{0, 1}, Average([]float32{1, 0, 0}, 2, []float32{0, 2}, 1), 1e-6)
+}
+
+func TestAssign(t *testing.T) {
+	candidates := []Candidate{
+		{SpeakerID: "alice", Embedding: []float32{1, 0, 0}},
+		{SpeakerID: "bob", Embedding: []float32{0, 1, 0}},
+	}
+	labels := map[string][]float32{
+		"SPEAKER_00": {0.9, 0.1, 0},
+		"SPEAKER_01": {0.8, 0.3, 0}, // Closer to Alice, but SPEAKER_00 is closer still
+		"SPEAKER_02": {0, 0, 1},
+	}
+
+	matches := Assign(labels, candidates, 0.2)
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "alice", matches["SPEAKER_00"].SpeakerID)
+	assert.Equal(t, "bob", matches["SPEAKER_01"].SpeakerID)
+	assert.InDelta(t, 0.3/0.8544, matches["SPEAKER_01"].Similarity, 1e-3)
+
+	// Nothing is matched below the threshold
+	matches = Assign(labels, candidates, 0.5)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "alice", matches["SPEAKER_00"].SpeakerID)
+}
@@ -36,6 +36,12 @@ type Broadcaster struct {
 	broadcast   chan Message
 	shutdown    chan struct{}
 	mutex       sync.RWMutex
+
+	// User-wide streams; see user_stream.go
+	users        map[uint]*userStream
+	usersMutex   sync.Mutex
+	lastEventID  uint64
+	startEventID uint64
 }
 
 // NewBroadcaster creates a new Broadcaster
@@ -46,7 +52,12 @@ func NewBroadcaster() *Broadcaster {
 		unregister:  make(chan Subscription),
 		broadcast:   make(chan Message),
 		shutdown:    make(chan struct{}),
+		users:       make(map[uint]*userStream),
 	}
+	// Event IDs keep growing across restarts, so IDs from an earlier run are
+	// recognized as older than anything still buffered
+	b.startEventID = uint64(time.Now().UnixMicro())
+	b.lastEventID = b.startEventID
 
 	go b.listen()
 	return b
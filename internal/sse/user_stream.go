@@ -0,0 +1,188 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"scriberr/pkg/logger"
+)
+
+const (
+	// UserEventBufferSize is how many recent events are kept per user so a
+	// reconnecting client can resume from its Last-Event-ID
+	UserEventBufferSize = 256
+	// userClientBuffer is how many events a slow client may fall behind before
+	// it is disconnected to resume from the buffer
+	userClientBuffer = 64
+)
+
+// bufferedEvent is an event of a user stream together with its ID
+type bufferedEvent struct {
+	id    uint64
+	event Event
+}
+
+// userStream is the event channel of one user: the connected clients and a
+// ring buffer of recent events
+type userStream struct {
+	clients map[chan bufferedEvent]bool
+	events  []bufferedEvent // Oldest first, at most UserEventBufferSize
+	// floor is the newest event ID no longer in events; a client that last
+	// saw an older event has missed some
+	floor uint64
+}
+
+// BroadcastToUser sends an event to every client of a user's event stream and
+// keeps it for clients that reconnect with Last-Event-ID. It is safe to call
+// on a nil broadcaster, which drops the event.
+func (b *Broadcaster) BroadcastToUser(userID uint, eventType string, payload interface{}) {
+	if b == nil {
+		return
+	}
+
+	b.usersMutex.Lock()
+	defer b.usersMutex.Unlock()
+
+	b.lastEventID++
+	buffered := bufferedEvent{id: b.lastEventID, event: Event{Type: eventType, Payload: payload}}
+	stream := b.userStream(userID)
+	if len(stream.events) == UserEventBufferSize {
+		stream.floor = stream.events[0].id
+		stream.events = append(stream.events[:0], stream.events[1:]...)
+	}
+	stream.events = append(stream.events, buffered)
+
+	for client := range stream.clients {
+		select {
+		case client <- buffered:
+		default:
+			// Disconnect rather than drop the event; the client resumes from the buffer
+			logger.Warn("Disconnecting slow SSE client", "user_id", userID)
+			delete(stream.clients, client)
+			close(client)
+		}
+	}
+}
+
+// userStream returns the stream of a user, creating it if needed. The caller holds usersMutex.
+func (b *Broadcaster) userStream(userID uint) *userStream {
+	stream, ok := b.users[userID]
+	if !ok {
+		// Events sent before the stream existed were lost with the last run
+		stream = &userStream{clients: make(map[chan bufferedEvent]bool), floor: b.startEventID}
+		b.users[userID] = stream
+	}
+	return stream
+}
+
+// subscribeUser registers a client of a user's stream and returns the
+// buffered events after lastEventID. missed reports that older events the
+// client has not seen are no longer buffered.
+func (b *Broadcaster) subscribeUser(userID uint, lastEventID uint64, resume bool) (client chan bufferedEvent, replay []bufferedEvent, missed bool, latest uint64) {
+	b.usersMutex.Lock()
+	defer b.usersMutex.Unlock()
+
+	stream := b.userStream(userID)
+	client = make(chan bufferedEvent, userClientBuffer)
+	stream.clients[client] = true
+
+	if resume {
+		if lastEventID < stream.floor || lastEventID > b.lastEventID {
+			return client, nil, true, b.lastEventID
+		}
+		for _, e := range stream.events {
+			if e.id > lastEventID {
+				replay = append(replay, e)
+			}
+		}
+	}
+	return client, replay, false, b.lastEventID
+}
+
+// unsubscribeUser removes a client unless a broadcast already disconnected it
+func (b *Broadcaster) unsubscribeUser(userID uint, client chan bufferedEvent) {
+	b.usersMutex.Lock()
+	defer b.usersMutex.Unlock()
+
+	if stream, ok := b.users[userID]; ok && stream.clients[client] {
+		delete(stream.clients, client)
+		close(client)
+	}
+}
+
+// ServeUser streams every event sent to a user with BroadcastToUser. Each
+// event carries an ID; a client that reconnects with a Last-Event-ID header
+// (or last_event_id query parameter) first receives the events it missed. If
+// they are no longer buffered it receives a resync event instead and should
+// reload its state.
+func (b *Broadcaster) ServeUser(w http.ResponseWriter, r *http.Request, userID uint) {
+	lastEventHeader := r.Header.Get("Last-Event-ID")
+	if lastEventHeader == "" {
+		lastEventHeader = r.URL.Query().Get("last_event_id")
+	}
+	var lastEventID uint64
+	resume := lastEventHeader != ""
+	if resume {
+		parsed, err := strconv.ParseUint(lastEventHeader, 10, 64)
+		if err != nil {
+			http.Error(w, "Last-Event-ID must be an event ID from this stream", http.StatusBadRequest)
+			return
+		}
+		lastEventID = parsed
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		return
+	}
+
+	client, replay, missed, latest := b.subscribeUser(userID, lastEventID, resume)
+	defer b.unsubscribeUser(userID, client)
+
+	fmt.Fprintf(w, "data: {\"type\":\"connected\", \"user_id\":%d}\n\n", userID)
+	if missed {
+		writeUserEvent(w, bufferedEvent{id: latest, event: Event{Type: "resync", Payload: map[string]interface{}{
+			"reason": "Events since the last event ID are no longer available",
+		}}})
+	}
+	for _, e := range replay {
+		writeUserEvent(w, e)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-b.shutdown:
+			return
+		case e, ok := <-client:
+			if !ok {
+				return // Disconnected for falling behind
+			}
+			writeUserEvent(w, e)
+			flusher.Flush()
+		case <-time.After(30 * time.Second):
+			// Keep-alive heartbeat
+			fmt.Fprintf(w, ": keepalive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeUserEvent(w http.ResponseWriter, e bufferedEvent) {
+	data, err := json.Marshal(e.event)
+	if err != nil {
+		logger.Error("Failed to marshal SSE message", "error", err)
+		return
+	}
+	fmt.Fprintf(w, "id: %d\ndata: %s\n\n", e.id, data)
+}
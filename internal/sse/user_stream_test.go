@@ -0,0 +1,120 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readUserEvents reads the first n events with an ID from a user stream
+func readUserEvents(t *testing.T, b *Broadcaster, userID uint, lastEventID string, n int, during func()) []string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.ServeUser(w, r, userID)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var events []string
+	var id string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: ") && id != "":
+			events = append(events, id+" "+strings.TrimPrefix(line, "data: "))
+			id = ""
+		case strings.Contains(line, `"type":"connected"`) && during != nil:
+			during()
+		}
+	}
+	return events
+}
+
+func TestUserStreamResumesFromLastEventID(t *testing.T) {
+	b := NewBroadcaster()
+	defer b.Shutdown()
+	start := b.startEventID
+
+	b.BroadcastToUser(1, "job_created", map[string]string{"job_id": "a"})
+	b.BroadcastToUser(2, "job_created", map[string]string{"job_id": "other user"})
+	b.BroadcastToUser(1, "job_update", map[string]string{"job_id": "a", "status": "pending"})
+	b.BroadcastToUser(1, "title_updated", map[string]string{"job_id": "a", "title": "Standup"})
+
+	events := readUserEvents(t, b, 1, strconv.FormatUint(start+1, 10), 3, func() {
+		b.BroadcastToUser(1, "job_update", map[string]string{"job_id": "a", "status": "processing"})
+	})
+	assert.Equal(t, []string{
+		strconv.FormatUint(start+3, 10) + ` {"type":"job_update","payload":{"job_id":"a","status":"pending"}}`,
+		strconv.FormatUint(start+4, 10) + ` {"type":"title_updated","payload":{"job_id":"a","title":"Standup"}}`,
+		strconv.FormatUint(start+5, 10) + ` {"type":"job_update","payload":{"job_id":"a","status":"processing"}}`,
+	}, events)
+}
+
+func TestUserStreamAsksForResyncAfterGap(t *testing.T) {
+	b := NewBroadcaster()
+	defer b.Shutdown()
+
+	// An ID from before this broadcaster started
+	events := readUserEvents(t, b, 1, "42", 1, nil)
+	if assert.Len(t, events, 1) {
+		assert.Contains(t, events[0], `"type":"resync"`)
+	}
+
+	for i := 0; i < UserEventBufferSize+5; i++ {
+		b.BroadcastToUser(1, "job_update", i)
+	}
+	start := b.startEventID
+
+	_, replay, missed, latest := b.subscribeUser(1, start+3, true)
+	assert.True(t, missed, "events 4 and 5 were evicted")
+	assert.Empty(t, replay)
+	assert.Equal(t, start+UserEventBufferSize+5, latest)
+
+	_, replay, missed, _ = b.subscribeUser(1, start+5, true)
+	assert.False(t, missed)
+	assert.Len(t, replay, UserEventBufferSize)
+
+	_, replay, missed, _ = b.subscribeUser(1, 0, false)
+	assert.False(t, missed)
+	assert.Empty(t, replay, "a fresh connection starts with live events")
+}
+
+func TestUserStreamDisconnectsSlowClients(t *testing.T) {
+	b := NewBroadcaster()
+	defer b.Shutdown()
+
+	client, _, _, _ := b.subscribeUser(1, 0, false)
+	for i := 0; i <= userClientBuffer; i++ {
+		b.BroadcastToUser(1, "job_update", i)
+	}
+
+	received := 0
+	for range client {
+		received++
+	}
+	assert.Equal(t, userClientBuffer, received, "the channel is closed once the client falls behind")
+
+	var none *Broadcaster
+	none.BroadcastToUser(1, "job_update", nil)
+}
@@ -0,0 +1,208 @@
+// Package transcriptedit applies manual corrections to stored transcripts,
+// keeping segment and word timings consistent with each other.
+package transcriptedit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+var (
+	// ErrSegmentNotFound is returned when an edit targets a segment index that does not exist
+	ErrSegmentNotFound = errors.New("segment not found")
+	// ErrInvalidEdit is returned when an edit cannot be applied as requested
+	ErrInvalidEdit = errors.New("invalid edit")
+)
+
+// Change is the diff of one edit: the segments in Before, starting at Index,
+// were replaced by the segments in After
+type Change struct {
+	Index  int                            `json:"index"`
+	Before []interfaces.TranscriptSegment `json:"before"`
+	After  []interfaces.TranscriptSegment `json:"after"`
+}
+
+// EditText replaces the text of a segment. Word timings are kept when the
+// number of words is unchanged and estimated across the segment otherwise.
+func EditText(t *interfaces.TranscriptResult, index int, text string) (*Change, error) {
+	seg, err := segmentAt(t, index)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: text cannot be empty", ErrInvalidEdit)
+	}
+
+	updated := seg
+	updated.Text = text
+
+	if len(t.WordSegments) > 0 {
+		lo, hi := wordRange(t.WordSegments, seg)
+		tokens := strings.Fields(text)
+		if hi-lo == len(tokens) {
+			for i, token := range tokens {
+				t.WordSegments[lo+i].Word = token
+			}
+		} else {
+			t.WordSegments = splice(t.WordSegments, lo, hi, estimateWords(tokens, updated))
+		}
+	}
+
+	return replaceSegments(t, index, 1, updated), nil
+}
+
+// SetSpeaker assigns a segment and its words to speaker
+func SetSpeaker(t *interfaces.TranscriptResult, index int, speaker string) (*Change, error) {
+	seg, err := segmentAt(t, index)
+	if err != nil {
+		return nil, err
+	}
+	speaker = strings.TrimSpace(speaker)
+	if speaker == "" {
+		return nil, fmt.Errorf("%w: speaker cannot be empty", ErrInvalidEdit)
+	}
+
+	updated := seg
+	updated.Speaker = &speaker
+	setWordSpeaker(t, seg, &speaker)
+
+	return replaceSegments(t, index, 1, updated), nil
+}
+
+// Split divides a segment in two before the word at wordIndex. The split
+// point is taken from the word timings when they match the text and
+// interpolated from the text length otherwise.
+func Split(t *interfaces.TranscriptResult, index, wordIndex int) (*Change, error) {
+	seg, err := segmentAt(t, index)
+	if err != nil {
+		return nil, err
+	}
+	tokens := strings.Fields(seg.Text)
+	if wordIndex < 1 || wordIndex >= len(tokens) {
+		return nil, fmt.Errorf("%w: word index must be between 1 and %d", ErrInvalidEdit, len(tokens)-1)
+	}
+
+	firstText := strings.Join(tokens[:wordIndex], " ")
+	secondText := strings.Join(tokens[wordIndex:], " ")
+
+	at := seg.Start + (seg.End-seg.Start)*float64(len(firstText)+1)/float64(len(firstText)+len(secondText)+1)
+	if len(t.WordSegments) > 0 {
+		if lo, hi := wordRange(t.WordSegments, seg); hi-lo == len(tokens) {
+			at = t.WordSegments[lo+wordIndex].Start
+		}
+	}
+
+	first, second := seg, seg
+	first.Text, first.End = firstText, at
+	second.Text, second.Start = secondText, at
+
+	return replaceSegments(t, index, 1, first, second), nil
+}
+
+// Merge joins a segment with the one after it. The merged segment keeps the
+// speaker of the first one.
+func Merge(t *interfaces.TranscriptResult, index int) (*Change, error) {
+	first, err := segmentAt(t, index)
+	if err != nil {
+		return nil, err
+	}
+	if index+1 >= len(t.Segments) {
+		return nil, fmt.Errorf("%w: the last segment has nothing to merge with", ErrInvalidEdit)
+	}
+	second := t.Segments[index+1]
+
+	setWordSpeaker(t, second, first.Speaker)
+
+	merged := first
+	merged.Text = strings.TrimSpace(strings.TrimSpace(first.Text) + " " + strings.TrimSpace(second.Text))
+	merged.End = max(first.End, second.End)
+
+	return replaceSegments(t, index, 2, merged), nil
+}
+
+// Replace swaps the whole transcript for other and records it as one change
+func Replace(t *interfaces.TranscriptResult, other interfaces.TranscriptResult) *Change {
+	change := &Change{
+		Index:  0,
+		Before: append([]interfaces.TranscriptSegment{}, t.Segments...),
+		After:  append([]interfaces.TranscriptSegment{}, other.Segments...),
+	}
+	*t = other
+	return change
+}
+
+func segmentAt(t *interfaces.TranscriptResult, index int) (interfaces.TranscriptSegment, error) {
+	if index < 0 || index >= len(t.Segments) {
+		return interfaces.TranscriptSegment{}, ErrSegmentNotFound
+	}
+	return t.Segments[index], nil
+}
+
+// replaceSegments swaps count segments at index for after and refreshes the
+// full text of the transcript
+func replaceSegments(t *interfaces.TranscriptResult, index, count int, after ...interfaces.TranscriptSegment) *Change {
+	change := &Change{
+		Index:  index,
+		Before: append([]interfaces.TranscriptSegment{}, t.Segments[index:index+count]...),
+		After:  after,
+	}
+	t.Segments = splice(t.Segments, index, index+count, after)
+
+	texts := make([]string, 0, len(t.Segments))
+	for _, seg := range t.Segments {
+		if text := strings.TrimSpace(seg.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	t.Text = strings.Join(texts, " ")
+	return change
+}
+
+// wordRange returns the bounds of the words whose midpoint falls inside seg.
+// Words are expected in time order.
+func wordRange(words []interfaces.TranscriptWord, seg interfaces.TranscriptSegment) (int, int) {
+	lo := sort.Search(len(words), func(i int) bool {
+		return (words[i].Start+words[i].End)/2 >= seg.Start
+	})
+	hi := lo
+	for hi < len(words) && (words[hi].Start+words[hi].End)/2 <= seg.End {
+		hi++
+	}
+	return lo, hi
+}
+
+func setWordSpeaker(t *interfaces.TranscriptResult, seg interfaces.TranscriptSegment, speaker *string) {
+	lo, hi := wordRange(t.WordSegments, seg)
+	for i := lo; i < hi; i++ {
+		t.WordSegments[i].Speaker = speaker
+	}
+}
+
+// estimateWords spreads tokens over the duration of seg in proportion to
+// their length. Estimated words have a score of zero.
+func estimateWords(tokens []string, seg interfaces.TranscriptSegment) []interfaces.TranscriptWord {
+	total := 0
+	for _, token := range tokens {
+		total += len(token) + 1
+	}
+	words := make([]interfaces.TranscriptWord, 0, len(tokens))
+	at := seg.Start
+	for _, token := range tokens {
+		end := at + (seg.End-seg.Start)*float64(len(token)+1)/float64(total)
+		words = append(words, interfaces.TranscriptWord{Start: at, End: end, Word: token, Speaker: seg.Speaker})
+		at = end
+	}
+	return words
+}
+
+func splice[T any](items []T, lo, hi int, with []T) []T {
+	out := make([]T, 0, len(items)-(hi-lo)+len(with))
+	out = append(out, items[:lo]...)
+	out = append(out, with...)
+	return append(out, items[hi:]...)
+}
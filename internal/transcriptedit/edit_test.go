@@ -0,0 +1,141 @@
+package transcriptedit
+
+import (
+	"testing"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string { return &s }
+
+func sampleTranscript() *interfaces.TranscriptResult {
+	a, b := strPtr("SPEAKER_00"), strPtr("SPEAKER_01")
+	return &interfaces.TranscriptResult{
+		Text: "Hello there world. How are you?",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 3, Text: "Hello there world.", Speaker: a},
+			{Start: 3, End: 5, Text: "How are you?", Speaker: b},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0, End: 1, Word: "Hello", Score: 0.9, Speaker: a},
+			{Start: 1, End: 2, Word: "there", Score: 0.9, Speaker: a},
+			{Start: 2, End: 3, Word: "world.", Score: 0.9, Speaker: a},
+			{Start: 3, End: 3.5, Word: "How", Score: 0.9, Speaker: b},
+			{Start: 3.5, End: 4, Word: "are", Score: 0.9, Speaker: b},
+			{Start: 4, End: 5, Word: "you?", Score: 0.9, Speaker: b},
+		},
+	}
+}
+
+func TestEditText(t *testing.T) {
+	t.Run("SameWordCountKeepsTimings", func(t *testing.T) {
+		tr := sampleTranscript()
+		change, err := EditText(tr, 0, "Hello their world.")
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello there world.", change.Before[0].Text)
+		assert.Equal(t, "Hello their world.", change.After[0].Text)
+		assert.Equal(t, "Hello their world. How are you?", tr.Text)
+		assert.Equal(t, "their", tr.WordSegments[1].Word)
+		assert.Equal(t, 1.0, tr.WordSegments[1].Start)
+		assert.Equal(t, 0.9, tr.WordSegments[1].Score)
+	})
+
+	t.Run("DifferentWordCountEstimatesTimings", func(t *testing.T) {
+		tr := sampleTranscript()
+		_, err := EditText(tr, 1, "How are you doing?")
+		assert.NoError(t, err)
+		assert.Len(t, tr.WordSegments, 7)
+		words := tr.WordSegments[3:]
+		assert.Equal(t, "doing?", words[3].Word)
+		assert.Equal(t, 3.0, words[0].Start)
+		assert.InDelta(t, 5.0, words[3].End, 1e-9)
+		assert.Equal(t, "SPEAKER_01", *words[3].Speaker)
+		for i := 1; i < len(words); i++ {
+			assert.Equal(t, words[i-1].End, words[i].Start)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tr := sampleTranscript()
+		_, err := EditText(tr, 2, "x")
+		assert.ErrorIs(t, err, ErrSegmentNotFound)
+		_, err = EditText(tr, 0, "  ")
+		assert.ErrorIs(t, err, ErrInvalidEdit)
+	})
+}
+
+func TestSetSpeaker(t *testing.T) {
+	tr := sampleTranscript()
+	change, err := SetSpeaker(tr, 1, "SPEAKER_00")
+	assert.NoError(t, err)
+	assert.Equal(t, "SPEAKER_01", *change.Before[0].Speaker)
+	assert.Equal(t, "SPEAKER_00", *tr.Segments[1].Speaker)
+	for _, w := range tr.WordSegments {
+		assert.Equal(t, "SPEAKER_00", *w.Speaker)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	t.Run("UsesWordTimings", func(t *testing.T) {
+		tr := sampleTranscript()
+		change, err := Split(tr, 0, 1)
+		assert.NoError(t, err)
+		assert.Len(t, change.After, 2)
+		if assert.Len(t, tr.Segments, 3) {
+			assert.Equal(t, "Hello", tr.Segments[0].Text)
+			assert.Equal(t, 1.0, tr.Segments[0].End)
+			assert.Equal(t, "there world.", tr.Segments[1].Text)
+			assert.Equal(t, 1.0, tr.Segments[1].Start)
+			assert.Equal(t, 3.0, tr.Segments[1].End)
+		}
+		assert.Equal(t, "Hello there world. How are you?", tr.Text)
+	})
+
+	t.Run("InterpolatesWithoutWords", func(t *testing.T) {
+		tr := sampleTranscript()
+		tr.WordSegments = nil
+		_, err := Split(tr, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "How are", tr.Segments[1].Text)
+		assert.Greater(t, tr.Segments[1].End, 3.0)
+		assert.Less(t, tr.Segments[1].End, 5.0)
+		assert.Equal(t, tr.Segments[1].End, tr.Segments[2].Start)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tr := sampleTranscript()
+		_, err := Split(tr, 0, 0)
+		assert.ErrorIs(t, err, ErrInvalidEdit)
+		_, err = Split(tr, 0, 3)
+		assert.ErrorIs(t, err, ErrInvalidEdit)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	tr := sampleTranscript()
+	change, err := Merge(tr, 0)
+	assert.NoError(t, err)
+	assert.Len(t, change.Before, 2)
+	if assert.Len(t, tr.Segments, 1) {
+		assert.Equal(t, "Hello there world. How are you?", tr.Segments[0].Text)
+		assert.Equal(t, 0.0, tr.Segments[0].Start)
+		assert.Equal(t, 5.0, tr.Segments[0].End)
+		assert.Equal(t, "SPEAKER_00", *tr.Segments[0].Speaker)
+	}
+	assert.Equal(t, "SPEAKER_00", *tr.WordSegments[5].Speaker)
+
+	_, err = Merge(tr, 0)
+	assert.ErrorIs(t, err, ErrInvalidEdit)
+}
+
+func TestReplace(t *testing.T) {
+	tr := sampleTranscript()
+	original := *sampleTranscript()
+	_, _ = Merge(tr, 0)
+	change := Replace(tr, original)
+	assert.Len(t, change.Before, 1)
+	assert.Len(t, change.After, 2)
+	assert.Equal(t, original, *tr)
+}
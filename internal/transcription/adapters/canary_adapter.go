@@ -296,7 +296,7 @@ func (c *CanaryAdapter) copyTranscriptionScript() error {
 		return fmt.Errorf("failed to write transcription script: %w", err)
 	}
 
-	return nil
+	return writeProgressModule(c.envPath)
 }
 
 // Transcribe processes audio using Canary
@@ -355,7 +355,7 @@ func (c *CanaryAdapter) Transcribe(ctx context.Context, input interfaces.AudioIn
 	} else {
 		defer logFile.Close()
 		cmd.Stdout = logFile
-		cmd.Stderr = logFile
+		cmd.Stderr = c.NewProgressWriter(procCtx, logFile)
 	}
 
 	logger.Info("Executing Canary command", "args", strings.Join(args, " "))
@@ -264,7 +264,7 @@ func (p *ParakeetAdapter) copyTranscriptionScript() error {
 		return fmt.Errorf("failed to write transcription script: %w", err)
 	}
 
-	return nil
+	return writeProgressModule(p.envPath)
 }
 
 // Transcribe processes audio using Parakeet
@@ -331,12 +331,12 @@ func (p *ParakeetAdapter) Transcribe(ctx context.Context, input interfaces.Audio
 		logger.Info("Using buffered inference for long audio",
 			"duration_secs", audioDuration.Seconds(),
 			"threshold_secs", chunkThreshold)
-		result, err = p.transcribeBuffered(ctx, audioInput, params, tempDir, procCtx.OutputDirectory)
+		result, err = p.transcribeBuffered(ctx, audioInput, params, tempDir, procCtx)
 	} else {
 		logger.Info("Using standard transcription for short audio",
 			"duration_secs", audioDuration.Seconds(),
 			"threshold_secs", chunkThreshold)
-		result, err = p.transcribeStandard(ctx, audioInput, params, tempDir, procCtx.OutputDirectory)
+		result, err = p.transcribeStandard(ctx, audioInput, params, tempDir, procCtx)
 	}
 
 	if err != nil {
@@ -378,7 +378,9 @@ func (p *ParakeetAdapter) detectAudioDuration(audioPath string) (float64, error)
 }
 
 // transcribeStandard uses the standard Parakeet transcription (original method)
-func (p *ParakeetAdapter) transcribeStandard(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, tempDir, outputDir string) (*interfaces.TranscriptResult, error) {
+func (p *ParakeetAdapter) transcribeStandard(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, tempDir string, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, error) {
+	outputDir := procCtx.OutputDirectory
+
 	// Build command arguments
 	args, err := p.buildParakeetArgs(input, params, tempDir)
 	if err != nil {
@@ -399,7 +401,7 @@ func (p *ParakeetAdapter) transcribeStandard(ctx context.Context, input interfac
 	} else {
 		defer logFile.Close()
 		cmd.Stdout = logFile
-		cmd.Stderr = logFile
+		cmd.Stderr = p.NewProgressWriter(procCtx, logFile)
 	}
 
 	logger.Info("Executing Parakeet command", "args", strings.Join(args, " "))
@@ -430,7 +432,9 @@ func (p *ParakeetAdapter) transcribeStandard(ctx context.Context, input interfac
 }
 
 // transcribeBuffered uses NeMo's buffered inference for long audio
-func (p *ParakeetAdapter) transcribeBuffered(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, tempDir, outputDir string) (*interfaces.TranscriptResult, error) {
+func (p *ParakeetAdapter) transcribeBuffered(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, tempDir string, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, error) {
+	outputDir := procCtx.OutputDirectory
+
 	// Build command arguments for buffered inference
 	args, err := p.buildBufferedArgs(input, params, tempDir)
 	if err != nil {
@@ -451,7 +455,7 @@ func (p *ParakeetAdapter) transcribeBuffered(ctx context.Context, input interfac
 	} else {
 		defer logFile.Close()
 		cmd.Stdout = logFile
-		cmd.Stderr = logFile
+		cmd.Stderr = p.NewProgressWriter(procCtx, logFile)
 	}
 
 	logger.Info("Executing Parakeet buffered inference", "args", strings.Join(args, " "))
@@ -588,7 +592,7 @@ func (p *ParakeetAdapter) copyBufferedScript() error {
 	}
 
 	logger.Info("Created buffered transcription script", "path", scriptPath)
-	return nil
+	return writeProgressModule(p.envPath)
 }
 
 // buildBufferedArgs builds the command arguments for buffered inference
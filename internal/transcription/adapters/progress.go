@@ -0,0 +1,150 @@
+package adapters
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// ProgressPrefix starts a progress line on the stderr of the Python scripts.
+// The rest of the line is a JSON object such as
+//
+//	{"stage": "transcribing", "percent": 42.5, "eta_seconds": 31, "message": "Chunk 3 of 7"}
+//
+// where stage is one of the interfaces.Stage values and everything else is
+// optional. When a script reports a percentage without an ETA, the ETA is
+// estimated from how long the stage has been running.
+const ProgressPrefix = "SCRIBERR_PROGRESS "
+
+// progressModule is the Python helper the scripts report progress with. It is
+// written next to each script as scriberr_progress.py and imported from there.
+//
+//go:embed py/scriberr_progress.py
+var progressModule []byte
+
+// writeProgressModule writes the progress helper into a script directory
+func writeProgressModule(dir string) error {
+	if err := os.WriteFile(filepath.Join(dir, "scriberr_progress.py"), progressModule, 0644); err != nil {
+		return fmt.Errorf("failed to write progress module: %w", err)
+	}
+	return nil
+}
+
+// maxProgressLine bounds the partial line kept between writes, so output that
+// never ends a line (e.g. a progress bar redrawn with \r) cannot grow it unbounded
+const maxProgressLine = 4096
+
+// progressLine is the JSON body of a progress line
+type progressLine struct {
+	Stage      string   `json:"stage"`
+	Percent    *float64 `json:"percent"`
+	ETASeconds *float64 `json:"eta_seconds"`
+	Message    string   `json:"message"`
+}
+
+// ParseProgressLine parses a line of the progress protocol. It reports false
+// for any other output.
+func ParseProgressLine(line string) (interfaces.Progress, bool) {
+	body, ok := strings.CutPrefix(strings.TrimSpace(line), ProgressPrefix)
+	if !ok {
+		return interfaces.Progress{}, false
+	}
+
+	var parsed progressLine
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil || parsed.Stage == "" {
+		return interfaces.Progress{}, false
+	}
+	progress := interfaces.Progress{
+		Stage:      interfaces.ProgressStage(parsed.Stage),
+		ETASeconds: parsed.ETASeconds,
+		Message:    parsed.Message,
+	}
+	if parsed.Percent != nil {
+		percent := min(max(*parsed.Percent, 0), 100)
+		progress.Percent = &percent
+	}
+	if progress.ETASeconds != nil && *progress.ETASeconds < 0 {
+		progress.ETASeconds = nil
+	}
+	return progress, true
+}
+
+// ProgressWriter passes a script's output through to its log and reports the
+// progress lines in it to the processing context
+type ProgressWriter struct {
+	out    io.Writer
+	report func(interfaces.Progress)
+	// parse recognises progress lines; adapters of tools with their own
+	// progress output replace it
+	parse func(line string) (interfaces.Progress, bool)
+	now   func() time.Time
+
+	mu         sync.Mutex
+	partial    []byte
+	stage      interfaces.ProgressStage
+	stageStart time.Time
+}
+
+// NewProgressWriter creates a writer for a script's stderr that copies
+// everything to log, which may be nil, and reports progress to procCtx
+func (b *BaseAdapter) NewProgressWriter(procCtx interfaces.ProcessingContext, log io.Writer) *ProgressWriter {
+	if log == nil {
+		log = io.Discard
+	}
+	return &ProgressWriter{
+		out:    log,
+		report: procCtx.ReportProgress,
+		parse:  ParseProgressLine,
+		now:    time.Now,
+	}
+}
+
+// Write copies p to the log and reports any complete progress lines in it
+func (w *ProgressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n, err := w.out.Write(p)
+	for _, c := range p {
+		if c != '\n' && c != '\r' {
+			if len(w.partial) < maxProgressLine {
+				w.partial = append(w.partial, c)
+			}
+			continue
+		}
+		if len(w.partial) > 0 && len(w.partial) < maxProgressLine {
+			w.handleLine(string(w.partial))
+		}
+		w.partial = w.partial[:0]
+	}
+	return n, err
+}
+
+// handleLine reports a progress line, estimating the ETA if it has none
+func (w *ProgressWriter) handleLine(line string) {
+	progress, ok := w.parse(line)
+	if !ok {
+		return
+	}
+
+	now := w.now()
+	if progress.Stage != w.stage {
+		w.stage = progress.Stage
+		w.stageStart = now
+	}
+	elapsed := now.Sub(w.stageStart).Seconds()
+	if progress.ETASeconds == nil && progress.Percent != nil && *progress.Percent > 0 && elapsed > 0 {
+		eta := elapsed * (100 - *progress.Percent) / *progress.Percent
+		progress.ETASeconds = &eta
+	}
+	progress.UpdatedAt = now
+	w.report(progress)
+}
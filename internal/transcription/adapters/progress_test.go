@@ -0,0 +1,119 @@
+package adapters
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	progress, ok := ParseProgressLine(`SCRIBERR_PROGRESS {"stage": "transcribing", "percent": 42.5, "eta_seconds": 31, "message": "Chunk 3 of 7"}` + "\n")
+	if assert.True(t, ok) {
+		assert.Equal(t, interfaces.StageTranscribing, progress.Stage)
+		assert.Equal(t, 42.5, *progress.Percent)
+		assert.Equal(t, 31.0, *progress.ETASeconds)
+		assert.Equal(t, "Chunk 3 of 7", progress.Message)
+	}
+
+	progress, ok = ParseProgressLine(`SCRIBERR_PROGRESS {"stage": "diarizing", "percent": 140, "eta_seconds": -1}`)
+	if assert.True(t, ok) {
+		assert.Equal(t, 100.0, *progress.Percent)
+		assert.Nil(t, progress.ETASeconds)
+	}
+
+	for _, line := range []string{
+		"Loading NVIDIA Parakeet model",
+		`{"stage": "transcribing"}`,
+		`SCRIBERR_PROGRESS {"percent": 10}`,
+		`SCRIBERR_PROGRESS not json`,
+	} {
+		_, ok := ParseProgressLine(line)
+		assert.False(t, ok, line)
+	}
+}
+
+func TestProgressWriterReportsProgressLines(t *testing.T) {
+	var reports []interfaces.Progress
+	var log bytes.Buffer
+	procCtx := interfaces.ProcessingContext{Progress: func(p interfaces.Progress) {
+		reports = append(reports, p)
+	}}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	w := NewBaseAdapter("test", "", interfaces.ModelCapabilities{}, nil).NewProgressWriter(procCtx, &log)
+	w.now = func() time.Time { return now }
+
+	output := "Loading model\nSCRIBERR_PROGRESS {\"stage\": \"transcribing\", \"percent\": 0}\n"
+	// Lines may be split across writes
+	w.Write([]byte(output[:30]))
+	w.Write([]byte(output[30:]))
+	now = now.Add(30 * time.Second)
+	w.Write([]byte("SCRIBERR_PROGRESS {\"stage\": \"transcribing\", \"percent\": 25}\r"))
+	w.Write([]byte("SCRIBERR_PROGRESS {\"stage\": \"transcribing\", \"percent\": 50, \"eta_seconds\": 5}\n"))
+	w.Write([]byte("SCRIBERR_PROGRESS {\"stage\": \"diarizing\"}"))
+
+	assert.Equal(t, output+
+		"SCRIBERR_PROGRESS {\"stage\": \"transcribing\", \"percent\": 25}\r"+
+		"SCRIBERR_PROGRESS {\"stage\": \"transcribing\", \"percent\": 50, \"eta_seconds\": 5}\n"+
+		"SCRIBERR_PROGRESS {\"stage\": \"diarizing\"}", log.String(), "all output reaches the log")
+	if !assert.Len(t, reports, 3, "the unterminated line is not reported yet") {
+		return
+	}
+	assert.Nil(t, reports[0].ETASeconds)
+	assert.Equal(t, now.Add(-30*time.Second), reports[0].UpdatedAt)
+	assert.Equal(t, 90.0, *reports[1].ETASeconds, "estimated from 30s for the first quarter")
+	assert.Equal(t, 5.0, *reports[2].ETASeconds, "reported ETAs are kept")
+
+	// A context without a callback drops progress
+	w = NewBaseAdapter("test", "", interfaces.ModelCapabilities{}, nil).NewProgressWriter(interfaces.ProcessingContext{}, nil)
+	_, err := w.Write([]byte("SCRIBERR_PROGRESS {\"stage\": \"vad\"}\n"))
+	assert.NoError(t, err)
+}
+
+func TestWriteProgressModule(t *testing.T) {
+	dir := t.TempDir()
+	if !assert.NoError(t, writeProgressModule(dir)) {
+		return
+	}
+	module, err := os.ReadFile(filepath.Join(dir, "scriberr_progress.py"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(module), `PROGRESS_PREFIX = "`+ProgressPrefix+`"`)
+	assert.Contains(t, string(module), "def emit_progress(")
+}
+
+func TestWhisperXProgressParser(t *testing.T) {
+	parse := newWhisperXProgressParser()
+	var reports []interfaces.Progress
+	for _, line := range []string{
+		"Performing voice activity detection using Pyannote...",
+		">>Performing transcription...",
+		"Progress: 50.00%...",
+		"Transcript: [0.031 --> 4.112] Hello",
+		">>Performing alignment...",
+		"Progress: 12.50%...",
+		`SCRIBERR_PROGRESS {"stage": "diarizing", "percent": 5}`,
+	} {
+		if progress, ok := parse(line); ok {
+			reports = append(reports, progress)
+		}
+	}
+
+	if !assert.Len(t, reports, 6) {
+		return
+	}
+	assert.Equal(t, interfaces.StageVAD, reports[0].Stage)
+	assert.Equal(t, interfaces.StageTranscribing, reports[1].Stage)
+	assert.Nil(t, reports[1].Percent)
+	assert.Equal(t, interfaces.StageTranscribing, reports[2].Stage)
+	assert.Equal(t, 50.0, *reports[2].Percent)
+	assert.Equal(t, interfaces.StageAligning, reports[3].Stage)
+	assert.Equal(t, interfaces.StageAligning, reports[4].Stage)
+	assert.Equal(t, 12.5, *reports[4].Percent)
+	assert.Equal(t, interfaces.StageDiarizing, reports[5].Stage)
+}
@@ -0,0 +1,10 @@
+"""Shared pytest setup for the adapter script tests."""
+
+import os
+from pathlib import Path
+
+# The scripts import scriberr_progress, which Scriberr writes next to them in
+# the model environment. Here they run from the source tree, so point the
+# subprocesses at this directory instead.
+_PY_DIR = str(Path(__file__).parent)
+os.environ["PYTHONPATH"] = os.pathsep.join(filter(None, [_PY_DIR, os.environ.get("PYTHONPATH")]))
@@ -9,6 +9,7 @@ import sys
 import os
 from pathlib import Path
 import nemo.collections.asr as nemo_asr
+from scriberr_progress import emit_progress
 
 
 def transcribe_audio(
@@ -42,12 +43,14 @@ def transcribe_audio(
         sys.exit(1)
 
     print(f"Loading NVIDIA Canary model from: {model_path}")
+    emit_progress("loading_model")
     asr_model = nemo_asr.models.ASRModel.restore_from(model_path)
 
     print(f"Processing: {audio_path}")
     print(f"Task: {task}")
     print(f"Source language: {source_lang}")
     print(f"Target language: {target_lang}")
+    emit_progress("transcribing")
 
     if timestamps:
         if task == "translate" and source_lang != target_lang:
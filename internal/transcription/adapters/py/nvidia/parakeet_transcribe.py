@@ -9,6 +9,7 @@ import sys
 import os
 from pathlib import Path
 import nemo.collections.asr as nemo_asr
+from scriberr_progress import emit_progress
 
 
 def transcribe_audio(
@@ -40,6 +41,7 @@ def transcribe_audio(
         sys.exit(1)
 
     print(f"Loading NVIDIA Parakeet model from: {model_path}")
+    emit_progress("loading_model")
     asr_model = nemo_asr.models.ASRModel.restore_from(model_path)
 
     # Disable CUDA graphs to fix Error 35 on RTX 2000e Ada GPU
@@ -71,6 +73,7 @@ def transcribe_audio(
             print("Continuing with default attention settings")
 
     print(f"Transcribing: {audio_path}")
+    emit_progress("transcribing")
 
     if timestamps:
         output = asr_model.transcribe([audio_path], timestamps=True)
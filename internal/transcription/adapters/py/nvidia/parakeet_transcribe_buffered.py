@@ -13,6 +13,7 @@ import soundfile as sf
 import numpy as np
 from pathlib import Path
 import nemo.collections.asr as nemo_asr
+from scriberr_progress import emit_progress
 
 
 def split_audio_file(audio_path, chunk_duration_secs=300):
@@ -61,6 +62,7 @@ def transcribe_buffered(
         sys.exit(1)
 
     print(f"Loading NVIDIA Parakeet model from: {model_path}")
+    emit_progress("loading_model")
 
     asr_model = nemo_asr.models.ASRModel.restore_from(model_path)
 
@@ -89,6 +91,7 @@ def transcribe_buffered(
 
     for i, chunk_info in enumerate(chunks):
         print(f"Transcribing chunk {i+1}/{len(chunks)} (duration: {chunk_info['duration']:.1f}s)...")
+        emit_progress("transcribing", 100 * i / len(chunks), message=f"Chunk {i+1} of {len(chunks)}")
 
         # Save chunk to temporary file
         chunk_path = f"/tmp/chunk_{i}.wav"
@@ -131,6 +134,7 @@ def transcribe_buffered(
             if os.path.exists(chunk_path):
                 os.remove(chunk_path)
 
+    emit_progress("transcribing", 100)
     final_text = " ".join(full_text)
     print(f"Transcription complete: {len(final_text)} characters total")
 
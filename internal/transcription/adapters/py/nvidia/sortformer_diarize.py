@@ -10,6 +10,8 @@ import sys
 import os
 from pathlib import Path
 import torch
+from scriberr_progress import emit_progress
+
 
 try:
     from nemo.collections.asr.models import SortformerEncLabelModel
@@ -27,6 +29,7 @@ def diarize_audio(
     output_format: str = "rttm",
     streaming_mode: bool = False,
     chunk_length_s: float = 30.0,
+    embeddings: bool = False,
 ):
     """
     Perform speaker diarization using NVIDIA's Sortformer model.
@@ -40,6 +43,7 @@ def diarize_audio(
 
     print(f"Using device: {device}")
     print(f"Loading NVIDIA Sortformer diarization model...")
+    emit_progress("loading_model")
 
     # Determine model path
     model_filename = "diar_streaming_sortformer_4spk-v2.nemo"
@@ -85,6 +89,7 @@ def diarize_audio(
     try:
         # Run diarization
         print(f"Running diarization with batch_size={batch_size}, max_speakers={max_speakers}")
+        emit_progress("diarizing")
 
         if streaming_mode:
             print(f"Using streaming mode with chunk_length_s={chunk_length_s}")
@@ -97,27 +102,89 @@ def diarize_audio(
         print(f"Diarization completed. Found segments: {len(predicted_segments)}")
 
         # Process and save results
-        save_results(predicted_segments, output_file, audio_path, output_format)
+        save_results(predicted_segments, output_file, audio_path, output_format,
+                     device if embeddings else None)
 
     except Exception as e:
         print(f"Error during diarization: {e}")
         sys.exit(1)
 
 
-def save_results(segments, output_file: str, audio_path: str, output_format: str):
+def save_results(segments, output_file: str, audio_path: str, output_format: str,
+                 embedding_device: str = None):
     """
     Save diarization results to output file.
     Supports both JSON and RTTM formats based on output_format parameter.
+    Speaker embeddings are included in JSON output when an embedding device is given.
     """
     output_path = Path(output_file)
 
     if output_format == "rttm":
         save_rttm_format(segments, output_file, audio_path)
     else:
-        save_json_format(segments, output_file, audio_path)
+        save_json_format(segments, output_file, audio_path, embedding_device)
+
+
+EMBEDDING_MODEL = "nvidia/speakerverification_en_titanet_large"
+MAX_EMBEDDING_AUDIO_S = 60.0
+
+
+def speaker_embeddings(segments, audio_path: str, device: str) -> dict:
+    """
+    Return an L2-normalized TitaNet voice embedding per speaker, computed from
+    up to a minute of each speaker's longest segments.
+    """
+    import tempfile
+    import numpy as np
+    import soundfile as sf
 
+    try:
+        from nemo.collections.asr.models import EncDecSpeakerLabelModel
+        model = EncDecSpeakerLabelModel.from_pretrained(EMBEDDING_MODEL, map_location=device)
+        model.eval()
+    except Exception as e:
+        print(f"Warning: Could not load speaker embedding model: {e}")
+        return {}
+
+    audio, sample_rate = sf.read(audio_path, dtype="float32")
+    if audio.ndim > 1:
+        audio = audio.mean(axis=1)
+
+    by_speaker = {}
+    for seg in segments:
+        by_speaker.setdefault(seg["speaker"], []).append(seg)
+
+    result = {}
+    for speaker, speaker_segments in by_speaker.items():
+        speaker_segments.sort(key=lambda x: x["duration"], reverse=True)
+        chunks, total = [], 0.0
+        for seg in speaker_segments:
+            if total >= MAX_EMBEDDING_AUDIO_S:
+                break
+            start = int(seg["start"] * sample_rate)
+            end = int(min(seg["end"], seg["start"] + MAX_EMBEDDING_AUDIO_S - total) * sample_rate)
+            if end > start:
+                chunks.append(audio[start:end])
+                total += (end - start) / sample_rate
+        if not chunks:
+            continue
+
+        with tempfile.NamedTemporaryFile(suffix=".wav") as tmp:
+            sf.write(tmp.name, np.concatenate(chunks), sample_rate)
+            try:
+                embedding = model.get_embedding(tmp.name)
+            except Exception as e:
+                print(f"Warning: Could not embed speaker {speaker}: {e}")
+                continue
 
-def save_json_format(segments, output_file: str, audio_path: str):
+        vector = embedding.detach().cpu().numpy().astype(np.float64).reshape(-1)
+        norm = np.linalg.norm(vector)
+        if np.isfinite(norm) and norm > 0:
+            result[speaker] = (vector / norm).tolist()
+    return result
+
+
+def save_json_format(segments, output_file: str, audio_path: str, embedding_device: str = None):
     """Save results in JSON format."""
     results = {
         "audio_file": audio_path,
@@ -202,6 +269,10 @@ def save_json_format(segments, output_file: str, audio_path: str):
     results["total_segments"] = len(results["segments"])
     results["total_duration"] = max(seg["end"] for seg in results["segments"]) if results["segments"] else 0
 
+    if embedding_device is not None:
+        results["speaker_embeddings"] = speaker_embeddings(results["segments"], audio_path, embedding_device)
+        results["embedding_model"] = EMBEDDING_MODEL
+
     with open(output_file, "w") as f:
         json.dump(results, f, indent=2)
 
@@ -295,6 +366,7 @@ Note: This script requires diar_streaming_sortformer_4spk-v2.nemo to be in the s
     parser.add_argument("--output-format", choices=["json", "rttm"], help="Output format (auto-detected from file extension if not specified)")
     parser.add_argument("--streaming", action="store_true", help="Enable streaming mode")
     parser.add_argument("--chunk-length-s", type=float, default=30.0, help="Chunk length in seconds for streaming mode (default: 30.0)")
+    parser.add_argument("--embeddings", action="store_true", help="Include a voice embedding per speaker in JSON output")
 
     args = parser.parse_args()
 
@@ -328,6 +400,7 @@ Note: This script requires diar_streaming_sortformer_4spk-v2.nemo to be in the s
         output_format=output_format,
         streaming_mode=args.streaming,
         chunk_length_s=args.chunk_length_s,
+        embeddings=args.embeddings,
     )
 
 
@@ -11,6 +11,8 @@ import os
 from pathlib import Path
 from pyannote.audio import Pipeline
 import torch
+from scriberr_progress import emit_progress
+
 
 # Fix for PyTorch 2.6+ which defaults weights_only=True
 # We need to allowlist PyAnnote's custom classes
@@ -24,6 +26,14 @@ except Exception as e:
     print(f"Warning: Could not add safe globals: {e}")
 
 
+def progress_hook(step_name, step_artifact, file=None, total=None, completed=None):
+    """Report the steps of the pyannote pipeline as diarization progress."""
+    if total:
+        emit_progress("diarizing", 100 * (completed or 0) / total, message=step_name)
+    elif completed is None:
+        emit_progress("diarizing", message=step_name)
+
+
 def diarize_audio(
     audio_path: str,
     output_file: str,
@@ -35,11 +45,13 @@ def diarize_audio(
     device: str = "auto",
     segmentation_onset: float = None,
     segmentation_offset: float = None,
+    embeddings: bool = False,
 ):
     """
     Perform speaker diarization on audio file using PyAnnote.
     """
     print(f"Loading PyAnnote speaker diarization pipeline: {model}")
+    emit_progress("loading_model")
 
     try:
         # Initialize the diarization pipeline
@@ -105,10 +117,10 @@ def diarize_audio(
 
         if diarization_params:
             print(f"Using speaker constraints: {diarization_params}")
-            diarization = pipeline(audio_path, **diarization_params)
+            diarization = pipeline(audio_path, hook=progress_hook, **diarization_params)
         else:
             print("Using automatic speaker detection")
-            diarization = pipeline(audio_path)
+            diarization = pipeline(audio_path, hook=progress_hook)
 
         print(f"Diarization completed. Saving results to: {output_file}")
 
@@ -118,7 +130,7 @@ def diarize_audio(
                 diarization.write_rttm(rttm)
         else:
             # Save as JSON format
-            save_json_format(diarization, output_file, audio_path)
+            save_json_format(diarization, output_file, audio_path, model, embeddings)
 
         # Print summary
         speakers = set()
@@ -152,7 +164,29 @@ def diarize_audio(
         sys.exit(1)
 
 
-def save_json_format(diarization, output_file: str, audio_path: str):
+def speaker_embeddings(diarization) -> dict:
+    """Return an L2-normalized voice embedding per speaker label."""
+    # Only PyAnnote 4.x pipelines return embeddings alongside the diarization
+    embeddings = getattr(diarization, "speaker_embeddings", None)
+    if embeddings is None or not hasattr(diarization, "speaker_diarization"):
+        print("Warning: This pipeline does not provide speaker embeddings")
+        return {}
+
+    import numpy as np
+
+    result = {}
+    for label, embedding in zip(diarization.speaker_diarization.labels(), embeddings):
+        vector = np.asarray(embedding, dtype=np.float64)
+        norm = np.linalg.norm(vector)
+        if not np.isfinite(norm) or norm == 0:
+            continue
+        result[label] = (vector / norm).tolist()
+    return result
+
+
+def save_json_format(diarization, output_file: str, audio_path: str,
+                     model: str = "pyannote/speaker-diarization-community-1",
+                     embeddings: bool = False):
     """Save diarization results in JSON format."""
     segments = []
     speakers = set()
@@ -185,7 +219,7 @@ def save_json_format(diarization, output_file: str, audio_path: str):
 
     results = {
         "audio_file": audio_path,
-        "model": "pyannote/speaker-diarization-community-1",
+        "model": model,
         "segments": segments,
         "speakers": sorted(speakers),
         "speaker_count": len(speakers),
@@ -196,6 +230,10 @@ def save_json_format(diarization, output_file: str, audio_path: str):
         }
     }
 
+    if embeddings:
+        results["speaker_embeddings"] = speaker_embeddings(diarization)
+        results["embedding_model"] = model
+
     with open(output_file, "w") as f:
         json.dump(results, f, indent=2)
 
@@ -255,6 +293,11 @@ def main():
         type=float,
         help="Voice activity detection offset/min_duration_off (0.0-1.0). Lower values are more sensitive to speech endings."
     )
+    parser.add_argument(
+        "--embeddings",
+        action="store_true",
+        help="Include a voice embedding per speaker in JSON output"
+    )
 
     args = parser.parse_args()
 
@@ -293,6 +336,7 @@ def main():
             device=args.device,
             segmentation_onset=args.segmentation_onset,
             segmentation_offset=args.segmentation_offset,
+            embeddings=args.embeddings,
         )
     except Exception as e:
         print(f"Error during diarization: {e}")
@@ -0,0 +1,23 @@
+"""
+Progress reporting shared by the Scriberr adapter scripts.
+
+Scriberr writes this module next to each script in the model environment, so
+the scripts import it as a top-level module.
+"""
+
+import json
+import sys
+
+PROGRESS_PREFIX = "SCRIBERR_PROGRESS "
+
+
+def emit_progress(stage, percent=None, eta_seconds=None, message=None):
+    """Report progress to Scriberr as a JSON line on stderr."""
+    report = {"stage": stage}
+    if percent is not None:
+        report["percent"] = round(percent, 1)
+    if eta_seconds is not None:
+        report["eta_seconds"] = round(eta_seconds, 1)
+    if message:
+        report["message"] = message
+    print(PROGRESS_PREFIX + json.dumps(report), file=sys.stderr, flush=True)
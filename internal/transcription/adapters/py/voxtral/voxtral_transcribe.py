@@ -10,6 +10,7 @@ import sys
 import torch
 from pathlib import Path
 from transformers import VoxtralForConditionalGeneration, AutoProcessor
+from scriberr_progress import emit_progress
 
 
 def transcribe_audio(
@@ -40,6 +41,7 @@ def transcribe_audio(
     device = "cuda" if torch.cuda.is_available() else "cpu"
 
     print(f"Loading Voxtral model on {device}...", file=sys.stderr)
+    emit_progress("loading_model")
 
     # Load processor and model
     processor = AutoProcessor.from_pretrained(model_id)
@@ -65,6 +67,7 @@ def transcribe_audio(
     inputs = inputs.to(device, dtype=dtype)
 
     print(f"Generating transcription...", file=sys.stderr)
+    emit_progress("transcribing")
 
     # Generate transcription
     with torch.no_grad():
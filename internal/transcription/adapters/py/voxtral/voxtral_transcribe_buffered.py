@@ -13,6 +13,7 @@ import soundfile as sf
 import torch
 from pathlib import Path
 from transformers import VoxtralForConditionalGeneration, AutoProcessor
+from scriberr_progress import emit_progress
 
 
 def split_audio_file(audio_path, chunk_duration_secs=1500):
@@ -58,6 +59,7 @@ def transcribe_buffered(
     device = "cuda" if torch.cuda.is_available() else "cpu"
 
     print(f"Loading Voxtral model on {device}...", file=sys.stderr)
+    emit_progress("loading_model")
 
     # Load processor and model
     processor = AutoProcessor.from_pretrained(model_id)
@@ -84,6 +86,9 @@ def transcribe_buffered(
             f"Transcribing chunk {i + 1}/{len(chunks)} (duration: {chunk_info['duration']:.1f}s)...",
             file=sys.stderr,
         )
+        emit_progress(
+            "transcribing", 100 * i / len(chunks), message=f"Chunk {i + 1} of {len(chunks)}"
+        )
 
         # Save chunk to temporary file
         chunk_path = f"/tmp/voxtral_chunk_{i}.wav"
@@ -122,6 +127,8 @@ def transcribe_buffered(
             if os.path.exists(chunk_path):
                 os.remove(chunk_path)
 
+    emit_progress("transcribing", 100)
+
     # Concatenate all chunks
     final_text = " ".join(full_text)
     print(
@@ -108,6 +108,14 @@ func NewPyAnnoteAdapter(envPath string) *PyAnnoteAdapter {
 			Description: "Output format for diarization results",
 			Group:       "advanced",
 		},
+		{
+			Name:        "speaker_embeddings",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Include a voice embedding per speaker in JSON output, used by the speaker library",
+			Group:       "advanced",
+		},
 
 		// Performance settings
 		{
@@ -270,7 +278,7 @@ func (p *PyAnnoteAdapter) copyDiarizationScript() error {
 		return fmt.Errorf("failed to write diarization script: %w", err)
 	}
 
-	return nil
+	return writeProgressModule(p.envPath)
 }
 
 // Diarize processes audio using PyAnnote
@@ -326,7 +334,7 @@ func (p *PyAnnoteAdapter) Diarize(ctx context.Context, input interfaces.AudioInp
 	} else {
 		defer logFile.Close()
 		cmd.Stdout = logFile
-		cmd.Stderr = logFile
+		cmd.Stderr = p.NewProgressWriter(procCtx, logFile)
 	}
 
 	logger.Info("Executing PyAnnote command", "args", strings.Join(args, " "))
@@ -407,6 +415,11 @@ func (p *PyAnnoteAdapter) buildPyAnnoteArgs(input interfaces.AudioInput, params
 		args = append(args, "--segmentation-offset", fmt.Sprintf("%.3f", offset))
 	}
 
+	// Speaker embeddings for the speaker library
+	if p.GetBoolParameter(params, "speaker_embeddings") {
+		args = append(args, "--embeddings")
+	}
+
 	// Device is handled automatically by the script
 
 	return args, nil
@@ -441,9 +454,11 @@ func (p *PyAnnoteAdapter) parseJSONResult(tempDir string) (*interfaces.Diarizati
 			Confidence float64 `json:"confidence"`
 			Duration   float64 `json:"duration"`
 		} `json:"segments"`
-		Speakers      []string `json:"speakers"`
-		SpeakerCount  int      `json:"speaker_count"`
-		TotalDuration float64  `json:"total_duration"`
+		Speakers          []string             `json:"speakers"`
+		SpeakerCount      int                  `json:"speaker_count"`
+		TotalDuration     float64              `json:"total_duration"`
+		SpeakerEmbeddings map[string][]float32 `json:"speaker_embeddings,omitempty"`
+		EmbeddingModel    string               `json:"embedding_model,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &pyannoteResult); err != nil {
@@ -452,9 +467,11 @@ func (p *PyAnnoteAdapter) parseJSONResult(tempDir string) (*interfaces.Diarizati
 
 	// Convert to standard format
 	result := &interfaces.DiarizationResult{
-		Segments:     make([]interfaces.DiarizationSegment, len(pyannoteResult.Segments)),
-		SpeakerCount: pyannoteResult.SpeakerCount,
-		Speakers:     pyannoteResult.Speakers,
+		Segments:          make([]interfaces.DiarizationSegment, len(pyannoteResult.Segments)),
+		SpeakerCount:      pyannoteResult.SpeakerCount,
+		Speakers:          pyannoteResult.Speakers,
+		SpeakerEmbeddings: pyannoteResult.SpeakerEmbeddings,
+		EmbeddingModel:    pyannoteResult.EmbeddingModel,
 	}
 
 	for i, seg := range pyannoteResult.Segments {
@@ -86,6 +86,14 @@ func NewSortformerAdapter(envPath string) *SortformerAdapter {
 			Description: "Output format for diarization results",
 			Group:       "advanced",
 		},
+		{
+			Name:        "speaker_embeddings",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Include a voice embedding per speaker in JSON output, used by the speaker library",
+			Group:       "advanced",
+		},
 
 		// Performance settings
 		{
@@ -280,7 +288,7 @@ func (s *SortformerAdapter) copyDiarizationScript() error {
 		return fmt.Errorf("failed to write diarization script: %w", err)
 	}
 
-	return nil
+	return writeProgressModule(s.envPath)
 }
 
 // Diarize processes audio using Sortformer
@@ -339,7 +347,7 @@ func (s *SortformerAdapter) Diarize(ctx context.Context, input interfaces.AudioI
 	} else {
 		defer logFile.Close()
 		cmd.Stdout = logFile
-		cmd.Stderr = logFile
+		cmd.Stderr = s.NewProgressWriter(procCtx, logFile)
 	}
 
 	logger.Info("Executing Sortformer command", "args", strings.Join(args, " "))
@@ -421,6 +429,11 @@ func (s *SortformerAdapter) buildSortformerArgs(input interfaces.AudioInput, par
 		}
 	}
 
+	// Speaker embeddings for the speaker library
+	if s.GetBoolParameter(params, "speaker_embeddings") {
+		args = append(args, "--embeddings")
+	}
+
 	return args, nil
 }
 
@@ -453,10 +466,12 @@ func (s *SortformerAdapter) parseJSONResult(tempDir string) (*interfaces.Diariza
 			Confidence float64 `json:"confidence"`
 			Duration   float64 `json:"duration"`
 		} `json:"segments"`
-		Speakers      []string `json:"speakers"`
-		SpeakerCount  int      `json:"speaker_count"`
-		TotalSegments int      `json:"total_segments"`
-		TotalDuration float64  `json:"total_duration"`
+		Speakers          []string             `json:"speakers"`
+		SpeakerCount      int                  `json:"speaker_count"`
+		TotalSegments     int                  `json:"total_segments"`
+		TotalDuration     float64              `json:"total_duration"`
+		SpeakerEmbeddings map[string][]float32 `json:"speaker_embeddings,omitempty"`
+		EmbeddingModel    string               `json:"embedding_model,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &sortformerResult); err != nil {
@@ -465,9 +480,11 @@ func (s *SortformerAdapter) parseJSONResult(tempDir string) (*interfaces.Diariza
 
 	// Convert to standard format
 	result := &interfaces.DiarizationResult{
-		Segments:     make([]interfaces.DiarizationSegment, len(sortformerResult.Segments)),
-		SpeakerCount: sortformerResult.SpeakerCount,
-		Speakers:     sortformerResult.Speakers,
+		Segments:          make([]interfaces.DiarizationSegment, len(sortformerResult.Segments)),
+		SpeakerCount:      sortformerResult.SpeakerCount,
+		Speakers:          sortformerResult.Speakers,
+		SpeakerEmbeddings: sortformerResult.SpeakerEmbeddings,
+		EmbeddingModel:    sortformerResult.EmbeddingModel,
 	}
 
 	for i, seg := range sortformerResult.Segments {
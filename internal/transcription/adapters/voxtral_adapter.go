@@ -200,7 +200,7 @@ func (v *VoxtralAdapter) copyTranscriptionScript() error {
 		return fmt.Errorf("failed to write buffered transcription script: %w", err)
 	}
 
-	return nil
+	return writeProgressModule(v.envPath)
 }
 
 // Transcribe processes audio using Voxtral
@@ -245,7 +245,7 @@ func (v *VoxtralAdapter) Transcribe(ctx context.Context, input interfaces.AudioI
 	} else {
 		defer logFile.Close()
 		cmd.Stdout = logFile
-		cmd.Stderr = logFile
+		cmd.Stderr = v.NewProgressWriter(procCtx, logFile)
 	}
 
 	logger.Info("Executing Voxtral command", "args", strings.Join(args, " "))
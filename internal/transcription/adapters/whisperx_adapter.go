@@ -13,6 +13,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -166,6 +167,14 @@ func NewWhisperXAdapter(envPath string) *WhisperXAdapter {
 			Description: "Diarization model to use",
 			Group:       "advanced",
 		},
+		{
+			Name:        "speaker_embeddings",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Include a voice embedding per speaker, used by the speaker library",
+			Group:       "advanced",
+		},
 		{
 			Name:        "min_speakers",
 			Type:        "int",
@@ -277,6 +286,24 @@ func NewWhisperXAdapter(envPath string) *WhisperXAdapter {
 			Description: "Custom alignment model (e.g. KBLab/wav2vec2-large-voxrex-swedish)",
 			Group:       "advanced",
 		},
+
+		// Vocabulary hints
+		{
+			Name:        "initial_prompt",
+			Type:        "string",
+			Required:    false,
+			Default:     nil,
+			Description: "Text to condition the first window on, e.g. names and terms that appear in the audio",
+			Group:       "advanced",
+		},
+		{
+			Name:        "hotwords",
+			Type:        "string",
+			Required:    false,
+			Default:     nil,
+			Description: "Comma-separated words and phrases the model should favor",
+			Group:       "advanced",
+		},
 	}
 
 	baseAdapter := NewBaseAdapter("whisperx", filepath.Join(envPath, "WhisperX"), capabilities, schema)
@@ -640,11 +667,19 @@ func (w *WhisperXAdapter) Transcribe(ctx context.Context, input interfaces.Audio
 		logger.Warn("Failed to create log file", "error", err)
 	} else {
 		defer logFile.Close()
-		cmd.Stdout = logFile
-		cmd.Stderr = logFile
 	}
+	var log io.Writer
+	if logFile != nil {
+		log = logFile
+	}
+	// WhisperX prints its progress on stdout
+	progress := w.NewProgressWriter(procCtx, log)
+	progress.parse = newWhisperXProgressParser()
+	cmd.Stdout = progress
+	cmd.Stderr = progress
 
 	logger.Info("Executing WhisperX command", "args", strings.Join(args, " "))
+	procCtx.ReportProgress(interfaces.Progress{Stage: interfaces.StageLoadingModel, UpdatedAt: time.Now()})
 
 	if err := cmd.Run(); err != nil {
 		if ctx.Err() == context.Canceled {
@@ -680,6 +715,62 @@ func (w *WhisperXAdapter) Transcribe(ctx context.Context, input interfaces.Audio
 	return result, nil
 }
 
+// whisperxProgressPattern matches the progress lines of WhisperX's --print_progress
+var whisperxProgressPattern = regexp.MustCompile(`Progress: ([0-9.]+)%`)
+
+// newWhisperXProgressParser parses WhisperX's own output, which announces each
+// step (">>Performing alignment...") and then prints its percentage. Lines of
+// the Scriberr progress protocol are understood too.
+func newWhisperXProgressParser() func(string) (interfaces.Progress, bool) {
+	stage := interfaces.StageLoadingModel
+	return func(line string) (interfaces.Progress, bool) {
+		if progress, ok := ParseProgressLine(line); ok {
+			stage = progress.Stage
+			return progress, true
+		}
+
+		lower := strings.ToLower(line)
+		next := stage
+		switch {
+		case strings.Contains(lower, "voice activity detection"):
+			next = interfaces.StageVAD
+		case strings.Contains(lower, "performing transcription"):
+			next = interfaces.StageTranscribing
+		case strings.Contains(lower, "performing alignment"):
+			next = interfaces.StageAligning
+		case strings.Contains(lower, "performing diarization"):
+			next = interfaces.StageDiarizing
+		}
+		if next != stage {
+			stage = next
+			return interfaces.Progress{Stage: stage}, true
+		}
+
+		match := whisperxProgressPattern.FindStringSubmatch(line)
+		if match == nil {
+			return interfaces.Progress{}, false
+		}
+		percent, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return interfaces.Progress{}, false
+		}
+		if stage == interfaces.StageLoadingModel || stage == interfaces.StageVAD {
+			// Percentages before any other step are transcription progress
+			stage = interfaces.StageTranscribing
+		}
+		percent = min(max(percent, 0), 100)
+		return interfaces.Progress{Stage: stage, Percent: &percent}, true
+	}
+}
+
+// whisperxDiarizeModel resolves the diarization model aliases WhisperX accepts
+func whisperxDiarizeModel(model string) string {
+	if model == "pyannote" || model == "pyannote/speaker-diarization-3.1" {
+		return "pyannote/speaker-diarization-community-1"
+	}
+	return model
+}
+
 // buildWhisperXArgs builds the command arguments for WhisperX
 func (w *WhisperXAdapter) buildWhisperXArgs(input interfaces.AudioInput, params map[string]interface{}, outputDir string) ([]string, error) {
 	whisperxPath := filepath.Join(w.envPath, "WhisperX")
@@ -721,16 +812,25 @@ func (w *WhisperXAdapter) buildWhisperXArgs(input interfaces.AudioInput, params
 		args = append(args, "--align_model", alignModel)
 	}
 
+	// Vocabulary hints
+	if initialPrompt := w.GetStringParameter(params, "initial_prompt"); initialPrompt != "" {
+		args = append(args, "--initial_prompt", initialPrompt)
+	}
+	if hotwords := w.GetStringParameter(params, "hotwords"); hotwords != "" {
+		args = append(args, "--hotwords", hotwords)
+	}
+
 	// Diarization
 	if w.GetBoolParameter(params, "diarize") {
 		args = append(args, "--diarize")
 
-		diarizeModel := w.GetStringParameter(params, "diarize_model")
-		if diarizeModel == "pyannote" || diarizeModel == "pyannote/speaker-diarization-3.1" {
-			diarizeModel = "pyannote/speaker-diarization-community-1"
-		}
+		diarizeModel := whisperxDiarizeModel(w.GetStringParameter(params, "diarize_model"))
 		args = append(args, "--diarize_model", diarizeModel)
 
+		if w.GetBoolParameter(params, "speaker_embeddings") {
+			args = append(args, "--speaker_embeddings")
+		}
+
 		if minSpeakers := w.GetIntParameter(params, "min_speakers"); minSpeakers > 0 {
 			args = append(args, "--min_speakers", strconv.Itoa(minSpeakers))
 		}
@@ -754,8 +854,9 @@ func (w *WhisperXAdapter) buildWhisperXArgs(input interfaces.AudioInput, params
 		args = append(args, "--hf_token", hfToken)
 	}
 
-	// Disable print progress for cleaner output
-	args = append(args, "--print_progress", "False")
+	// WhisperX progress lines are parsed into progress reports rather than
+	// the Scriberr progress protocol
+	args = append(args, "--print_progress", "True")
 
 	return args, nil
 }
@@ -795,8 +896,9 @@ func (w *WhisperXAdapter) parseResult(outputDir string, input interfaces.AudioIn
 			Score   float64 `json:"score"`
 			Speaker *string `json:"speaker,omitempty"`
 		} `json:"word_segments,omitempty"`
-		Language string `json:"language"`
-		Text     string `json:"text,omitempty"`
+		Language          string               `json:"language"`
+		Text              string               `json:"text,omitempty"`
+		SpeakerEmbeddings map[string][]float32 `json:"speaker_embeddings,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &whisperxResult); err != nil {
@@ -841,6 +943,12 @@ func (w *WhisperXAdapter) parseResult(outputDir string, input interfaces.AudioIn
 		result.Text = strings.Join(textParts, " ")
 	}
 
+	// Speaker embeddings come from the pyannote pipeline used for diarization
+	if len(whisperxResult.SpeakerEmbeddings) > 0 {
+		result.SpeakerEmbeddings = whisperxResult.SpeakerEmbeddings
+		result.EmbeddingModel = whisperxDiarizeModel(w.GetStringParameter(params, "diarize_model"))
+	}
+
 	return result, nil
 }
 
@@ -54,6 +54,14 @@ func (m *MockJobRepository) FindWithAssociations(ctx context.Context, id string)
 	return args.Get(0).(*models.TranscriptionJob), args.Error(1)
 }
 
+func (m *MockJobRepository) FindByIDForUser(ctx context.Context, id string, userID uint) (*models.TranscriptionJob, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TranscriptionJob), args.Error(1)
+}
+
 func (m *MockJobRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.TranscriptionJob, int64, error) {
 	args := m.Called(ctx, userID, offset, limit)
 	return args.Get(0).([]models.TranscriptionJob), args.Get(1).(int64), args.Error(2)
@@ -84,8 +92,36 @@ func (m *MockJobRepository) DeleteMultiTrackFilesByJobID(ctx context.Context, jo
 	return args.Error(0)
 }
 
-func (m *MockJobRepository) ListWithParams(ctx context.Context, offset, limit int, sortBy, sortOrder, searchQuery string, updatedAfter *time.Time) ([]models.TranscriptionJob, int64, error) {
-	args := m.Called(ctx, offset, limit, sortBy, sortOrder, searchQuery, updatedAfter)
+func (m *MockJobRepository) ListChunks(ctx context.Context, jobID string) ([]models.AudioChunk, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AudioChunk), args.Error(1)
+}
+
+func (m *MockJobRepository) ReplaceChunks(ctx context.Context, jobID string, chunks []models.AudioChunk) error {
+	args := m.Called(ctx, jobID, chunks)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) CompleteChunk(ctx context.Context, chunkID uint, result string) error {
+	args := m.Called(ctx, chunkID, result)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) CountCompletedChunks(ctx context.Context, jobID string) (int64, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockJobRepository) DeleteChunksByJobID(ctx context.Context, jobID string) error {
+	args := m.Called(ctx, jobID)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) ListWithParams(ctx context.Context, userID uint, offset, limit int, sortBy, sortOrder, searchQuery string, updatedAfter *time.Time) ([]models.TranscriptionJob, int64, error) {
+	args := m.Called(ctx, userID, offset, limit, sortBy, sortOrder, searchQuery, updatedAfter)
 	return args.Get(0).([]models.TranscriptionJob), args.Get(1).(int64), args.Error(2)
 }
 
@@ -105,6 +141,27 @@ func (m *MockJobRepository) FindLatestCompletedExecution(ctx context.Context, jo
 	return args.Get(0).(*models.TranscriptionJobExecution), args.Error(1)
 }
 
+func (m *MockJobRepository) FindExecution(ctx context.Context, jobID, executionID string) (*models.TranscriptionJobExecution, error) {
+	args := m.Called(ctx, jobID, executionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TranscriptionJobExecution), args.Error(1)
+}
+
+func (m *MockJobRepository) ListExecutions(ctx context.Context, jobID string) ([]models.TranscriptionJobExecution, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TranscriptionJobExecution), args.Error(1)
+}
+
+func (m *MockJobRepository) SetActiveExecution(ctx context.Context, jobID, executionID string) error {
+	args := m.Called(ctx, jobID, executionID)
+	return args.Error(0)
+}
+
 func (m *MockJobRepository) UpdateStatus(ctx context.Context, jobID string, status models.JobStatus) error {
 	args := m.Called(ctx, jobID, status)
 	return args.Error(0)
@@ -133,6 +190,80 @@ func (m *MockJobRepository) UpdateSummary(ctx context.Context, jobID string, sum
 	return args.Error(0)
 }
 
+func (m *MockJobRepository) MarkQueued(ctx context.Context, jobID string) (bool, error) {
+	args := m.Called(ctx, jobID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) RequeueJob(ctx context.Context, job *models.TranscriptionJob, from []models.JobStatus) (bool, error) {
+	args := m.Called(ctx, job, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) ClaimNextPending(ctx context.Context) (*models.TranscriptionJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TranscriptionJob), args.Error(1)
+}
+
+func (m *MockJobRepository) ListQueued(ctx context.Context) ([]models.TranscriptionJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TranscriptionJob), args.Error(1)
+}
+
+func (m *MockJobRepository) ScheduleRetry(ctx context.Context, jobID string, at time.Time, errorMsg string) error {
+	args := m.Called(ctx, jobID, at, errorMsg)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) SetWebhookDeferred(ctx context.Context, jobID string, deferred bool) error {
+	args := m.Called(ctx, jobID, deferred)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) FindWebhookDeferred(ctx context.Context) ([]models.TranscriptionJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TranscriptionJob), args.Error(1)
+}
+
+func (m *MockJobRepository) SetAudioDuration(ctx context.Context, jobID string, seconds float64) error {
+	args := m.Called(ctx, jobID, seconds)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) ProcessingSpeed(ctx context.Context, modelFamily, model, device string, limit int) (float64, int, error) {
+	args := m.Called(ctx, modelFamily, model, device, limit)
+	return args.Get(0).(float64), args.Int(1), args.Error(2)
+}
+
+func (m *MockJobRepository) SetPaused(ctx context.Context, jobID string, paused bool) (bool, error) {
+	args := m.Called(ctx, jobID, paused)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) MoveToFront(ctx context.Context, jobID string) (bool, error) {
+	args := m.Called(ctx, jobID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) QueuePaused(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) SetQueuePaused(ctx context.Context, paused bool) error {
+	args := m.Called(ctx, paused)
+	return args.Error(0)
+}
+
 // MockTranscriptionAdapter is a mock implementation of TranscriptionAdapter
 type MockTranscriptionAdapter struct {
 	mock.Mock
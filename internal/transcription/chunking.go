@@ -0,0 +1,373 @@
+package transcription
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/internal/transcription/pipeline"
+	"scriberr/pkg/binaries"
+	"scriberr/pkg/logger"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// chunkBoundaryWindow is how far from its target length, as a fraction
+	// of it, a chunk may end so that it ends in a pause
+	chunkBoundaryWindow = 0.2
+	// chunkTailFactor keeps the last chunk from being a short leftover: audio
+	// up to this fraction of the target length beyond it joins the last chunk
+	chunkTailFactor = 0.25
+	// chunkPauseDuration is the shortest pause considered as a cut point
+	chunkPauseDuration = 0.5
+)
+
+// chunkSpan is a piece of audio in seconds
+type chunkSpan struct {
+	start, end float64
+}
+
+// shouldChunk reports whether the job asked for chunking and the audio is
+// long enough to need more than one chunk
+func shouldChunk(params models.WhisperXParams, input interfaces.AudioInput) bool {
+	target := float64(params.ChunkMinutes) * 60
+	return target > 0 && input.Duration.Seconds() > target*(1+chunkTailFactor)
+}
+
+// planChunks splits duration seconds of audio into pieces of about target
+// seconds. Each piece ends in the middle of the pause closest to its target
+// length, or at the target length when there is no pause near it.
+func planChunks(duration, target float64, silences []pipeline.Silence) []chunkSpan {
+	var spans []chunkSpan
+	start := 0.0
+	for duration-start > target*(1+chunkTailFactor) {
+		goal := start + target
+		cut, best := goal, target*chunkBoundaryWindow
+		for _, s := range silences {
+			if s.End < 0 {
+				continue
+			}
+			middle := (s.Start + s.End) / 2
+			if d := math.Abs(middle - goal); d <= best {
+				cut, best = middle, d
+			}
+		}
+		spans = append(spans, chunkSpan{start: start, end: cut})
+		start = cut
+	}
+	return append(spans, chunkSpan{start: start, end: duration})
+}
+
+// chunkPlanDigest identifies the audio and parameters a chunk plan was made
+// for, so checkpoints are only reused for the same work
+func chunkPlanDigest(job *models.TranscriptionJob, input interfaces.AudioInput) string {
+	params := job.Parameters
+	params.ChunkWorkers = 0 // Parallelism does not change the result
+	encoded, _ := json.Marshal(params)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s", job.AudioPath, input.Duration, encoded)))
+	return hex.EncodeToString(sum[:])
+}
+
+// chunkPlan returns the job's chunk checkpoints, planning new chunks unless
+// checkpoints from an interrupted run of the same work exist
+func (u *UnifiedTranscriptionService) chunkPlan(ctx context.Context, job *models.TranscriptionJob, input interfaces.AudioInput) ([]models.AudioChunk, error) {
+	digest := chunkPlanDigest(job, input)
+	existing, err := u.jobRepo.ListChunks(ctx, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chunk checkpoints: %w", err)
+	}
+	if len(existing) > 0 && existing[0].PlanDigest == digest {
+		return existing, nil
+	}
+
+	threshold := job.Parameters.SilenceThreshold
+	if threshold == 0 {
+		threshold = pipeline.DefaultSilenceThreshold
+	}
+	silences, err := pipeline.DetectSilences(ctx, input.FilePath, threshold, chunkPauseDuration)
+	if err != nil {
+		logger.Warn("Silence detection failed, splitting at fixed lengths", "job_id", job.ID, "error", err)
+	}
+
+	spans := planChunks(input.Duration.Seconds(), float64(job.Parameters.ChunkMinutes)*60, silences)
+	chunks := make([]models.AudioChunk, len(spans))
+	for i, span := range spans {
+		chunks[i] = models.AudioChunk{
+			TranscriptionJobID: job.ID,
+			Index:              i,
+			Start:              span.start,
+			End:                span.end,
+			PlanDigest:         digest,
+			Status:             models.StatusPending,
+		}
+	}
+	if err := u.jobRepo.ReplaceChunks(ctx, job.ID, chunks); err != nil {
+		return nil, fmt.Errorf("failed to save chunk plan: %w", err)
+	}
+	return u.jobRepo.ListChunks(ctx, job.ID)
+}
+
+// transcribeInChunks transcribes long audio in pieces split at pauses, up to
+// the job's ChunkWorkers at a time. Every finished piece is checkpointed, so
+// a retried or resumed job only transcribes the pieces it has not finished.
+func (u *UnifiedTranscriptionService) transcribeInChunks(ctx context.Context, job *models.TranscriptionJob, adapter interfaces.TranscriptionAdapter, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, error) {
+	chunks, err := u.chunkPlan(ctx, job, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*interfaces.TranscriptResult, len(chunks))
+	var completed int64
+	for i, chunk := range chunks {
+		if chunk.Status != models.StatusCompleted || chunk.Result == nil {
+			continue
+		}
+		var result interfaces.TranscriptResult
+		if err := json.Unmarshal([]byte(*chunk.Result), &result); err != nil {
+			logger.Warn("Discarding unreadable chunk checkpoint", "job_id", job.ID, "chunk", chunk.Index, "error", err)
+			continue
+		}
+		results[i] = &result
+		completed++
+	}
+	logger.Info("Transcribing in chunks", "job_id", job.ID, "chunks", len(chunks), "already_completed", completed)
+	u.broadcastChunkProgress(job.ID, completed, len(chunks))
+
+	// Chunks report their own progress; the job's covers all of them
+	progress := newChunkProgress(procCtx.Progress, len(chunks), int(completed))
+	procCtx.Progress = nil
+
+	workers := job.Parameters.ChunkWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(workers)
+	for i := range chunks {
+		if results[i] != nil {
+			continue
+		}
+		group.Go(func() error {
+			chunkCtx := procCtx
+			chunkCtx.Progress = progress.chunk(i)
+			result, err := u.transcribeChunk(groupCtx, adapter, input, chunks[i], i == len(chunks)-1, maps.Clone(params), chunkCtx)
+			if err != nil {
+				return fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+			}
+			if encoded, err := json.Marshal(result); err == nil {
+				if err := u.jobRepo.CompleteChunk(ctx, chunks[i].ID, string(encoded)); err != nil {
+					logger.Warn("Failed to checkpoint chunk", "job_id", job.ID, "chunk", i, "error", err)
+				}
+			}
+			results[i] = result
+			progress.finish(i)
+			u.broadcastChunkProgress(job.ID, atomic.AddInt64(&completed, 1), len(chunks))
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return stitchChunks(chunks, results), nil
+}
+
+// chunkProgress merges the progress of chunks transcribed side by side into
+// one report for the whole job. The percent counts finished chunks in full
+// and running ones by their own percent; the ETA extrapolates how fast that
+// percent has grown since transcribing started.
+type chunkProgress struct {
+	mu        sync.Mutex
+	report    func(interfaces.Progress)
+	total     int
+	finished  int
+	running   map[int]float64 // Percent of each chunk being transcribed
+	stage     interfaces.ProgressStage
+	startedAt time.Time
+	startedAs float64 // Percent when transcribing started, from checkpointed chunks
+}
+
+func newChunkProgress(report func(interfaces.Progress), total, finished int) *chunkProgress {
+	if total < 1 {
+		total = 1
+	}
+	p := &chunkProgress{
+		report:    report,
+		total:     total,
+		finished:  finished,
+		running:   make(map[int]float64),
+		stage:     interfaces.StageTranscribing,
+		startedAt: time.Now(),
+	}
+	p.startedAs = p.percent()
+	return p
+}
+
+// chunk returns the progress callback for the adapter transcribing a chunk,
+// or nil when nobody listens
+func (p *chunkProgress) chunk(index int) func(interfaces.Progress) {
+	if p.report == nil {
+		return nil
+	}
+	return func(progress interfaces.Progress) {
+		p.mu.Lock()
+		if progress.Percent != nil {
+			p.running[index] = *progress.Percent
+		}
+		p.stage = progress.Stage
+		p.mu.Unlock()
+		p.emit(progress.Message)
+	}
+}
+
+// finish counts a chunk as done
+func (p *chunkProgress) finish(index int) {
+	if p.report == nil {
+		return
+	}
+	p.mu.Lock()
+	delete(p.running, index)
+	p.finished++
+	p.mu.Unlock()
+	p.emit("")
+}
+
+// percent of the whole job; the caller must hold mu unless p is not shared yet
+func (p *chunkProgress) percent() float64 {
+	sum := float64(p.finished) * 100
+	for _, percent := range p.running {
+		sum += percent
+	}
+	return math.Min(sum/float64(p.total), 100)
+}
+
+func (p *chunkProgress) emit(message string) {
+	p.mu.Lock()
+	percent := p.percent()
+	progress := interfaces.Progress{
+		Stage:     p.stage,
+		Percent:   &percent,
+		Message:   message,
+		UpdatedAt: time.Now(),
+	}
+	if gained, elapsed := percent-p.startedAs, time.Since(p.startedAt).Seconds(); gained > 0 {
+		eta := elapsed * (100 - percent) / gained
+		progress.ETASeconds = &eta
+	}
+	p.mu.Unlock()
+	p.report(progress)
+}
+
+// transcribeChunk cuts one chunk out of the audio and transcribes it. The
+// adapter gets its own job ID and output directory so chunks can run side by side.
+func (u *UnifiedTranscriptionService) transcribeChunk(ctx context.Context, adapter interfaces.TranscriptionAdapter, input interfaces.AudioInput, chunk models.AudioChunk, last bool, params map[string]interface{}, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, error) {
+	chunkCtx := procCtx
+	chunkCtx.JobID = fmt.Sprintf("%s_chunk%03d", procCtx.JobID, chunk.Index)
+	chunkCtx.OutputDirectory = filepath.Join(procCtx.OutputDirectory, "chunks", fmt.Sprintf("%03d", chunk.Index))
+	if err := os.MkdirAll(chunkCtx.OutputDirectory, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create chunk output directory: %w", err)
+	}
+
+	chunkPath := filepath.Join(chunkCtx.OutputDirectory, "audio.wav")
+	args := []string{"-ss", formatSeconds(chunk.Start), "-i", input.FilePath}
+	if !last {
+		// The last chunk runs to the end, whatever the probed duration said
+		args = append(args, "-t", formatSeconds(chunk.End-chunk.Start))
+	}
+	args = append(args, "-c:a", "pcm_s16le", "-y", chunkPath)
+	cmd := exec.CommandContext(ctx, binaries.FFmpeg(), args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.Error("FFmpeg chunk extraction failed", "output", string(output), "error", err)
+		return nil, fmt.Errorf("failed to extract chunk audio: %w", err)
+	}
+	defer os.Remove(chunkPath)
+
+	chunkInput := input
+	chunkInput.FilePath = chunkPath
+	chunkInput.Format = "wav"
+	chunkInput.Duration = time.Duration((chunk.End - chunk.Start) * float64(time.Second))
+	chunkInput.TempFilePath = chunkPath
+	chunkInput.Timeline = nil
+	if stat, err := os.Stat(chunkPath); err == nil {
+		chunkInput.Size = stat.Size()
+	}
+
+	logger.Info("Transcribing chunk", "job_id", procCtx.JobID, "chunk", chunk.Index, "start", chunk.Start, "end", chunk.End)
+	return adapter.Transcribe(ctx, chunkInput, params, chunkCtx)
+}
+
+// stitchChunks joins the transcripts of consecutive chunks, moving their
+// segments and words from each chunk's start onto the whole recording
+func stitchChunks(chunks []models.AudioChunk, results []*interfaces.TranscriptResult) *interfaces.TranscriptResult {
+	stitched := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{},
+		Metadata: map[string]string{},
+	}
+	var texts []string
+	var confidence float64
+	for i, result := range results {
+		offset := chunks[i].Start
+		for _, segment := range result.Segments {
+			segment.Start += offset
+			segment.End += offset
+			stitched.Segments = append(stitched.Segments, segment)
+		}
+		for _, word := range result.WordSegments {
+			word.Start += offset
+			word.End += offset
+			stitched.WordSegments = append(stitched.WordSegments, word)
+		}
+		if text := strings.TrimSpace(result.Text); text != "" {
+			texts = append(texts, text)
+		}
+		if stitched.Language == "" {
+			stitched.Language = result.Language
+		}
+		if stitched.ModelUsed == "" {
+			stitched.ModelUsed = result.ModelUsed
+		}
+		for key, value := range result.Metadata {
+			if _, ok := stitched.Metadata[key]; !ok {
+				stitched.Metadata[key] = value
+			}
+		}
+		stitched.ProcessingTime += result.ProcessingTime
+		confidence += result.Confidence
+	}
+	stitched.Text = strings.Join(texts, " ")
+	if len(results) > 0 {
+		stitched.Confidence = confidence / float64(len(results))
+	}
+	stitched.Metadata["chunks"] = strconv.Itoa(len(results))
+	return stitched
+}
+
+// broadcastChunkProgress reports how many chunks of a job are transcribed
+func (u *UnifiedTranscriptionService) broadcastChunkProgress(jobID string, completed int64, total int) {
+	if u.broadcaster == nil {
+		return
+	}
+	u.broadcaster.Broadcast(jobID, "chunk_progress", map[string]interface{}{
+		"job_id":    jobID,
+		"completed": completed,
+		"total":     total,
+	})
+}
+
+func formatSeconds(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
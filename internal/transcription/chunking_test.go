@@ -0,0 +1,147 @@
+package transcription
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"scriberr/internal/models"
+	"scriberr/internal/repository"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/internal/transcription/pipeline"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestPlanChunks(t *testing.T) {
+	silences := []pipeline.Silence{
+		{Start: 100, End: 101},
+		{Start: 590, End: 592}, // Closest to the first target of 600
+		{Start: 640, End: 641},
+		{Start: 1400, End: 1402}, // Too far from the second target of 1191
+		{Start: 2300, End: -1},
+	}
+	spans := planChunks(2400, 600, silences)
+	assert.Equal(t, []chunkSpan{
+		{start: 0, end: 591},
+		{start: 591, end: 1191},
+		{start: 1191, end: 1791},
+		{start: 1791, end: 2400},
+	}, spans)
+
+	// A short remainder joins the last chunk
+	assert.Equal(t, []chunkSpan{{start: 0, end: 700}}, planChunks(700, 600, nil))
+}
+
+func TestShouldChunk(t *testing.T) {
+	params := models.WhisperXParams{ChunkMinutes: 10}
+	assert.True(t, shouldChunk(params, interfaces.AudioInput{Duration: 2 * time.Hour}))
+	assert.False(t, shouldChunk(params, interfaces.AudioInput{Duration: 12 * time.Minute}))
+	assert.False(t, shouldChunk(models.WhisperXParams{}, interfaces.AudioInput{Duration: 2 * time.Hour}))
+}
+
+func TestStitchChunks(t *testing.T) {
+	speaker := "SPEAKER_00"
+	chunks := []models.AudioChunk{{Index: 0, Start: 0, End: 591}, {Index: 1, Start: 591, End: 1200}}
+	results := []*interfaces.TranscriptResult{
+		{
+			Text:         "Hello there. ",
+			Language:     "en",
+			Segments:     []interfaces.TranscriptSegment{{Start: 1, End: 3, Text: "Hello there.", Speaker: &speaker}},
+			WordSegments: []interfaces.TranscriptWord{{Start: 1, End: 1.5, Word: "Hello"}},
+			Confidence:   0.8,
+			ModelUsed:    "small",
+		},
+		{
+			Text:         "Welcome back.",
+			Language:     "en",
+			Segments:     []interfaces.TranscriptSegment{{Start: 2, End: 4.5, Text: "Welcome back."}},
+			WordSegments: []interfaces.TranscriptWord{{Start: 2, End: 2.6, Word: "Welcome"}},
+			Confidence:   0.6,
+		},
+	}
+
+	stitched := stitchChunks(chunks, results)
+	assert.Equal(t, "Hello there. Welcome back.", stitched.Text)
+	assert.Equal(t, "en", stitched.Language)
+	assert.Equal(t, "small", stitched.ModelUsed)
+	assert.InDelta(t, 0.7, stitched.Confidence, 1e-9)
+	assert.Equal(t, "2", stitched.Metadata["chunks"])
+	if assert.Len(t, stitched.Segments, 2) {
+		assert.Equal(t, 1.0, stitched.Segments[0].Start)
+		assert.Equal(t, &speaker, stitched.Segments[0].Speaker)
+		assert.Equal(t, 593.0, stitched.Segments[1].Start)
+		assert.Equal(t, 595.5, stitched.Segments[1].End)
+	}
+	if assert.Len(t, stitched.WordSegments, 2) {
+		assert.Equal(t, 593.0, stitched.WordSegments[1].Start)
+	}
+}
+
+func TestChunkProgressCoversWholeJob(t *testing.T) {
+	var reported []float64
+	progress := newChunkProgress(func(p interfaces.Progress) {
+		reported = append(reported, *p.Percent)
+	}, 4, 1)
+	percent := func(v float64) *float64 { return &v }
+
+	// One chunk was checkpointed; two more run side by side
+	progress.chunk(1)(interfaces.Progress{Stage: interfaces.StageTranscribing, Percent: percent(50)})
+	progress.chunk(2)(interfaces.Progress{Stage: interfaces.StageTranscribing, Percent: percent(20)})
+	progress.finish(1)
+	progress.chunk(2)(interfaces.Progress{Stage: interfaces.StageTranscribing, Percent: percent(60)})
+
+	assert.Equal(t, []float64{37.5, 42.5, 55, 65}, reported)
+
+	// Without a listener chunks get no callback
+	assert.Nil(t, newChunkProgress(nil, 4, 0).chunk(0))
+}
+
+func TestTranscribeInChunksResumesFromCheckpoints(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&models.TranscriptionJob{}, &models.AudioChunk{}))
+
+	ctx := context.Background()
+	jobRepo := repository.NewJobRepository(db)
+	job := &models.TranscriptionJob{ID: "job-1", UserID: 1, AudioPath: "lecture.wav"}
+	job.Parameters.ChunkMinutes = 10
+	assert.NoError(t, db.Create(job).Error)
+
+	input := interfaces.AudioInput{FilePath: "lecture_converted.wav", Duration: 20 * time.Minute}
+	digest := chunkPlanDigest(job, input)
+	first, second := `{"text":"Part one","segments":[{"start":1,"end":2,"text":"Part one"}]}`,
+		`{"text":"Part two","segments":[{"start":3,"end":4,"text":"Part two"}]}`
+	assert.NoError(t, jobRepo.ReplaceChunks(ctx, job.ID, []models.AudioChunk{
+		{TranscriptionJobID: job.ID, Index: 0, Start: 0, End: 600, PlanDigest: digest, Status: models.StatusCompleted, Result: &first},
+		{TranscriptionJobID: job.ID, Index: 1, Start: 600, End: 1200, PlanDigest: digest, Status: models.StatusCompleted, Result: &second},
+	}))
+
+	// Every chunk is checkpointed, so the adapter is not needed again
+	adapter := new(MockTranscriptionAdapter)
+	service := NewUnifiedTranscriptionService(jobRepo, t.TempDir(), t.TempDir())
+	result, err := service.transcribeInChunks(ctx, job, adapter, input, map[string]interface{}{}, interfaces.ProcessingContext{JobID: job.ID})
+	assert.NoError(t, err)
+	adapter.AssertNotCalled(t, "Transcribe", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	if assert.NotNil(t, result) {
+		assert.Equal(t, "Part one Part two", result.Text)
+		if assert.Len(t, result.Segments, 2) {
+			assert.Equal(t, 603.0, result.Segments[1].Start)
+		}
+	}
+
+	// Other parameters make the checkpoints stale
+	model := job.Parameters.Model
+	job.Parameters.Model = "large-v3"
+	assert.NotEqual(t, digest, chunkPlanDigest(job, input))
+	job.Parameters.Model = model
+	job.Parameters.ChunkWorkers = 4
+	assert.Equal(t, digest, chunkPlanDigest(job, input))
+}
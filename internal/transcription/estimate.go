@@ -0,0 +1,110 @@
+package transcription
+
+import (
+	"context"
+	"time"
+
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+)
+
+const (
+	// processingSpeedSamples is how many recent executions a model's processing speed is averaged over
+	processingSpeedSamples = 20
+	// processingSpeedTTL is how long a looked up processing speed is reused
+	processingSpeedTTL = time.Minute
+)
+
+// speedKey identifies the executions a processing speed is averaged over
+type speedKey struct {
+	modelFamily, model, device string
+}
+
+type cachedSpeed struct {
+	speed     float64
+	samples   int
+	fetchedAt time.Time
+}
+
+// EstimateProcessingTime estimates how long processing a job takes. The
+// job's audio length is scaled by how fast recent jobs with the same model
+// and device were processed; without such history the model adapters' own
+// estimates are used. It reports false when the audio length is not recorded
+// yet: estimating never probes the audio, as it runs for every queued job
+// whenever the queue is listed.
+func (u *UnifiedTranscriptionService) EstimateProcessingTime(ctx context.Context, job *models.TranscriptionJob) (time.Duration, bool) {
+	if job.AudioDuration == nil || *job.AudioDuration <= 0 {
+		return 0, false
+	}
+	audio := *job.AudioDuration
+
+	// Prefer the exact model, then any model of the family on the same device
+	params := job.Parameters
+	for _, model := range []string{params.Model, ""} {
+		speed, samples, err := u.processingSpeed(ctx, speedKey{params.ModelFamily, model, params.Device})
+		if err != nil {
+			logger.Warn("Failed to load processing history", "job_id", job.ID, "error", err)
+			break
+		}
+		if samples > 0 {
+			return time.Duration(audio * speed * float64(time.Second)), true
+		}
+	}
+
+	input := interfaces.AudioInput{Duration: time.Duration(audio * float64(time.Second))}
+	transcriptionModelID, diarizationModelID := modelsFor(params)
+	estimate, err := u.registry.GetEstimatedProcessingTime(transcriptionModelID, input)
+	if err != nil {
+		return 0, false
+	}
+	if diarizationModelID != "" && !u.transcriptionIncludesDiarization(transcriptionModelID, params) {
+		if diarization, err := u.registry.GetEstimatedProcessingTime(diarizationModelID, input); err == nil {
+			estimate += diarization
+		}
+	}
+	return estimate, true
+}
+
+// processingSpeed returns the processing speed of a model and device from
+// the execution history, looking it up at most once per processingSpeedTTL
+func (u *UnifiedTranscriptionService) processingSpeed(ctx context.Context, key speedKey) (float64, int, error) {
+	u.speedMutex.Lock()
+	cached, ok := u.speeds[key]
+	u.speedMutex.Unlock()
+	if ok && time.Since(cached.fetchedAt) < processingSpeedTTL {
+		return cached.speed, cached.samples, nil
+	}
+
+	speed, samples, err := u.jobRepo.ProcessingSpeed(ctx, key.modelFamily, key.model, key.device, processingSpeedSamples)
+	if err != nil {
+		return 0, 0, err
+	}
+	u.speedMutex.Lock()
+	u.speeds[key] = cachedSpeed{speed: speed, samples: samples, fetchedAt: time.Now()}
+	u.speedMutex.Unlock()
+	return speed, samples, nil
+}
+
+// RecordAudioDuration probes the length of a job's audio and records it for
+// queue estimates. It reports whether a length was recorded. A failed probe
+// is recorded as zero so the audio is not probed again; processing the job
+// records the real length.
+func (u *UnifiedTranscriptionService) RecordAudioDuration(ctx context.Context, jobID string) (bool, error) {
+	job, err := u.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		return false, err
+	}
+	if job.AudioDuration != nil || job.AudioPath == "" {
+		return false, nil
+	}
+
+	var seconds float64
+	if input, err := u.createAudioInput(job.AudioPath); err == nil && input.Duration > 0 {
+		seconds = input.Duration.Seconds()
+	}
+	if err := u.jobRepo.SetAudioDuration(ctx, job.ID, seconds); err != nil {
+		return false, err
+	}
+	return seconds > 0, nil
+}
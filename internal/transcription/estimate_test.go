@@ -0,0 +1,58 @@
+package transcription
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"scriberr/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEstimateProcessingTimeUsesRecordedDuration(t *testing.T) {
+	mockRepo := new(MockJobRepository)
+	service := NewUnifiedTranscriptionService(mockRepo, t.TempDir(), t.TempDir())
+	params := models.WhisperXParams{ModelFamily: "whisper", Model: "small", Device: "cpu"}
+
+	// Without a recorded length nothing is probed or looked up
+	unknown := &models.TranscriptionJob{ID: "unknown", AudioPath: filepath.Join(t.TempDir(), "audio.wav"), Parameters: params}
+	_, ok := service.EstimateProcessingTime(context.Background(), unknown)
+	assert.False(t, ok)
+	mockRepo.AssertNotCalled(t, "SetAudioDuration", mock.Anything, mock.Anything, mock.Anything)
+
+	// The speed of a model is looked up once for all jobs using it
+	mockRepo.On("ProcessingSpeed", mock.Anything, "whisper", "small", "cpu", processingSpeedSamples).Return(0.5, 3, nil).Once()
+	seconds := 120.0
+	for _, id := range []string{"first", "second"} {
+		job := &models.TranscriptionJob{ID: id, AudioDuration: &seconds, Parameters: params}
+		estimate, ok := service.EstimateProcessingTime(context.Background(), job)
+		assert.True(t, ok)
+		assert.Equal(t, 60*time.Second, estimate)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRecordAudioDurationProbesOnce(t *testing.T) {
+	mockRepo := new(MockJobRepository)
+	service := NewUnifiedTranscriptionService(mockRepo, t.TempDir(), t.TempDir())
+
+	// A probe that fails is recorded as zero
+	job := &models.TranscriptionJob{ID: "job-1", AudioPath: filepath.Join(t.TempDir(), "missing.wav")}
+	mockRepo.On("FindByID", mock.Anything, job.ID).Return(job, nil).Once()
+	mockRepo.On("SetAudioDuration", mock.Anything, job.ID, 0.0).Return(nil).Once()
+	recorded, err := service.RecordAudioDuration(context.Background(), job.ID)
+	assert.NoError(t, err)
+	assert.False(t, recorded)
+
+	// and never repeated
+	zero := 0.0
+	probed := &models.TranscriptionJob{ID: job.ID, AudioPath: job.AudioPath, AudioDuration: &zero}
+	mockRepo.On("FindByID", mock.Anything, job.ID).Return(probed, nil).Once()
+	recorded, err = service.RecordAudioDuration(context.Background(), job.ID)
+	assert.NoError(t, err)
+	assert.False(t, recorded)
+	mockRepo.AssertExpectations(t)
+}
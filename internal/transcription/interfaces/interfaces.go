@@ -53,6 +53,17 @@ type AudioInput struct {
 	Size         int64             `json:"size"`
 	Metadata     map[string]string `json:"metadata"`
 	TempFilePath string            `json:"temp_file_path,omitempty"` // For converted files
+
+	// Stretches of the original recording kept when a preprocessor cut parts
+	// of it, in order; empty when the audio follows the original timeline
+	Timeline []TimelineRange `json:"timeline,omitempty"`
+}
+
+// TimelineRange places a stretch of processed audio on the original timeline
+type TimelineRange struct {
+	Start         float64 `json:"start"`          // Seconds into the processed audio
+	OriginalStart float64 `json:"original_start"` // Seconds into the original recording
+	Duration      float64 `json:"duration"`       // Zero when the stretch runs to the end
 }
 
 // TranscriptSegment represents a segment of transcribed audio
@@ -83,6 +94,11 @@ type TranscriptResult struct {
 	ProcessingTime time.Duration    `json:"processing_time"`
 	ModelUsed    string             `json:"model_used"`
 	Metadata     map[string]string  `json:"metadata"`
+
+	// Voice embedding of each speaker label, when requested. Embeddings from
+	// different models are not comparable, so they carry the model's name.
+	SpeakerEmbeddings map[string][]float32 `json:"-"`
+	EmbeddingModel    string               `json:"-"`
 }
 
 // DiarizationSegment represents speaker diarization information
@@ -101,6 +117,10 @@ type DiarizationResult struct {
 	ProcessingTime time.Duration        `json:"processing_time"`
 	ModelUsed      string               `json:"model_used"`
 	Metadata       map[string]string    `json:"metadata"`
+
+	// Voice embedding of each speaker label, when requested
+	SpeakerEmbeddings map[string][]float32 `json:"-"`
+	EmbeddingModel    string               `json:"-"`
 }
 
 // ProcessingContext contains context information for processing
@@ -110,6 +130,37 @@ type ProcessingContext struct {
 	OutputDirectory string            `json:"output_directory"`
 	TempDirectory   string            `json:"temp_directory"`
 	Metadata        map[string]string `json:"metadata"`
+
+	// Progress receives the progress an adapter reports while it runs, if set
+	Progress func(Progress) `json:"-"`
+}
+
+// ReportProgress passes a progress report to the context's Progress callback, if any
+func (p ProcessingContext) ReportProgress(progress Progress) {
+	if p.Progress != nil {
+		p.Progress(progress)
+	}
+}
+
+// ProgressStage is a step of a model run
+type ProgressStage string
+
+// Stages reported by the model adapters
+const (
+	StageLoadingModel ProgressStage = "loading_model"
+	StageVAD          ProgressStage = "vad"
+	StageTranscribing ProgressStage = "transcribing"
+	StageAligning     ProgressStage = "aligning"
+	StageDiarizing    ProgressStage = "diarizing"
+)
+
+// Progress is a fine-grained progress report of a running model
+type Progress struct {
+	Stage      ProgressStage `json:"stage"`
+	Percent    *float64      `json:"percent,omitempty"`     // Of the current stage, 0-100
+	ETASeconds *float64      `json:"eta_seconds,omitempty"` // Remaining time of the current stage
+	Message    string        `json:"message,omitempty"`
+	UpdatedAt  time.Time     `json:"updated_at"`
 }
 
 // ModelAdapter is the base interface that all model adapters must implement
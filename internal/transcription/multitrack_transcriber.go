@@ -15,6 +15,7 @@ import (
 	"scriberr/internal/database"
 	"scriberr/internal/models"
 	"scriberr/internal/transcription/interfaces"
+	"scriberr/internal/transcription/pipeline"
 	"scriberr/pkg/logger"
 
 	"golang.org/x/text/cases"
@@ -204,6 +205,7 @@ func (mt *MultiTrackTranscriber) ProcessMultiTrackTranscription(ctx context.Cont
 	if err := mt.db.Model(&models.TranscriptionJob{}).Where("id = ?", jobID).Updates(updates).Error; err != nil {
 		return fmt.Errorf("failed to save transcription results: %w", err)
 	}
+	mt.unifiedProcessor.GetUnifiedService().indexTranscript(context.Background(), jobID)
 
 	// Create execution record with timing data for multi-track job
 	overallEndTime := time.Now()
@@ -225,7 +227,9 @@ func (mt *MultiTrackTranscriber) ProcessMultiTrackTranscription(ctx context.Cont
 
 // transcribeIndividualTrack transcribes a single track file using the direct transcription method
 func (mt *MultiTrackTranscriber) transcribeIndividualTrack(ctx context.Context, job *models.TranscriptionJob, trackFile *models.MultiTrackFile) (*interfaces.TranscriptResult, error) {
-	// Create a proper copy of parameters for this track (disable diarization, enable word timestamps)
+	// Create a proper copy of parameters for this track (disable diarization, enable word timestamps).
+	// The track is processed as a single-track job, so the audio filters and
+	// silence trimming the job asked for run on each track before transcription.
 	trackParams := job.Parameters
 
 	// Ensure essential fields are properly set for individual track processing
@@ -282,6 +286,7 @@ func (mt *MultiTrackTranscriber) transcribeIndividualTrack(ctx context.Context,
 	// Use StatusProcessing to prevent the main queue scanner from picking it up
 	tempJob := models.TranscriptionJob{
 		ID:         trackJobID,
+		UserID:     job.UserID,
 		AudioPath:  trackFile.FilePath,
 		Parameters: trackParams,
 		Status:     models.StatusProcessing, // Prevent queue scanner from picking this up
@@ -510,6 +515,16 @@ func (mt *MultiTrackTranscriber) mergeTrackTranscripts(trackTranscripts []TrackT
 		Text:         mergedText.String(),
 	}
 
+	// Every track ran through the same audio filters; keep the record of them
+	for _, trackTranscript := range trackTranscripts {
+		if trackTranscript.Result != nil && trackTranscript.Result.Metadata[pipeline.MetadataAudioFilters] != "" {
+			mergedResult.Metadata = map[string]string{
+				pipeline.MetadataAudioFilters: trackTranscript.Result.Metadata[pipeline.MetadataAudioFilters],
+			}
+			break
+		}
+	}
+
 	logger.Info("Sort-and-group merging completed successfully",
 		"input_words", len(allWords),
 		"output_turns", len(speakerTurns),
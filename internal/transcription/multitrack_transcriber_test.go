@@ -0,0 +1,30 @@
+package transcription
+
+import (
+	"testing"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/internal/transcription/pipeline"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeTrackTranscriptsKeepsAudioFilters(t *testing.T) {
+	filtered := map[string]string{pipeline.MetadataAudioFilters: "afftdn=nr=12"}
+	tracks := []TrackTranscript{
+		{FileName: "alice.wav", Speaker: "alice", Result: &interfaces.TranscriptResult{
+			WordSegments: []interfaces.TranscriptWord{{Start: 0, End: 0.5, Word: "hello"}},
+			Metadata:     filtered,
+		}},
+		{FileName: "bob.wav", Speaker: "bob", Offset: 1, Result: &interfaces.TranscriptResult{
+			WordSegments: []interfaces.TranscriptWord{{Start: 0, End: 0.5, Word: "hi"}},
+			Metadata:     filtered,
+		}},
+	}
+
+	merged, err := (&MultiTrackTranscriber{}).mergeTrackTranscripts(tracks)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello hi", merged.Text)
+	assert.Equal(t, 1.0, merged.WordSegments[1].Start)
+	assert.Equal(t, "afftdn=nr=12", merged.Metadata[pipeline.MetadataAudioFilters])
+}
@@ -0,0 +1,161 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/binaries"
+	"scriberr/pkg/logger"
+)
+
+// Audio filter options, passed in the audio input's metadata
+const (
+	MetadataHighpassFrequency = "highpass_frequency" // Hz; unset disables the filter
+	MetadataLowpassFrequency  = "lowpass_frequency"  // Hz; unset disables the filter
+	MetadataDenoise           = "denoise"            // "true" enables noise reduction
+	MetadataDenoiseStrength   = "denoise_strength"   // Noise reduction in dB
+	MetadataNormalizeLoudness = "normalize_loudness" // "true" enables loudness normalization
+	MetadataLoudnessTarget    = "loudness_target"    // Integrated loudness in LUFS
+
+	// MetadataAudioFilters lists the ffmpeg filters that ran, comma-separated.
+	// RecordAudioFilters copies it into the transcript's metadata.
+	MetadataAudioFilters = "audio_filters"
+)
+
+const (
+	DefaultDenoiseStrength = 12.0  // dB, afftdn's own default
+	DefaultLoudnessTarget  = -16.0 // LUFS, EBU R128 for speech on small speakers
+)
+
+// jobOptionPreprocessor is embedded by the preprocessors that each job turns
+// on in the input's metadata. They apply to every model and do nothing when
+// the job leaves them off.
+type jobOptionPreprocessor struct{}
+
+// AppliesTo reports true for every model
+func (jobOptionPreprocessor) AppliesTo(capabilities interfaces.ModelCapabilities) bool {
+	return true
+}
+
+// GetRequiredFormats returns the output formats this preprocessor can produce
+func (jobOptionPreprocessor) GetRequiredFormats() []string {
+	return []string{"wav"}
+}
+
+// FrequencyFilterPreprocessor cuts rumble below a high-pass frequency and
+// hiss above a low-pass frequency
+type FrequencyFilterPreprocessor struct{ jobOptionPreprocessor }
+
+// Process applies the highpass and lowpass filters the job enabled
+func (f *FrequencyFilterPreprocessor) Process(ctx context.Context, input interfaces.AudioInput) (interfaces.AudioInput, error) {
+	var filters []string
+	if hz := floatMetadata(input.Metadata, MetadataHighpassFrequency, 0); hz > 0 {
+		filters = append(filters, "highpass=f="+formatFloat(hz))
+	}
+	if hz := floatMetadata(input.Metadata, MetadataLowpassFrequency, 0); hz > 0 {
+		filters = append(filters, "lowpass=f="+formatFloat(hz))
+	}
+	if len(filters) == 0 {
+		return input, nil
+	}
+	return applyAudioFilter(ctx, input, strings.Join(filters, ","), "bandpass")
+}
+
+// NoiseReductionPreprocessor removes stationary background noise with
+// ffmpeg's FFT denoiser
+type NoiseReductionPreprocessor struct{ jobOptionPreprocessor }
+
+// Process applies afftdn when the job enabled denoising
+func (n *NoiseReductionPreprocessor) Process(ctx context.Context, input interfaces.AudioInput) (interfaces.AudioInput, error) {
+	if input.Metadata[MetadataDenoise] != "true" {
+		return input, nil
+	}
+	strength := floatMetadata(input.Metadata, MetadataDenoiseStrength, DefaultDenoiseStrength)
+	return applyAudioFilter(ctx, input, "afftdn=nr="+formatFloat(strength), "denoised")
+}
+
+// LoudnessNormalizationPreprocessor brings quiet recordings to a standard
+// loudness with ffmpeg's EBU R128 loudnorm filter
+type LoudnessNormalizationPreprocessor struct{ jobOptionPreprocessor }
+
+// Process applies loudnorm when the job enabled loudness normalization
+func (l *LoudnessNormalizationPreprocessor) Process(ctx context.Context, input interfaces.AudioInput) (interfaces.AudioInput, error) {
+	if input.Metadata[MetadataNormalizeLoudness] != "true" {
+		return input, nil
+	}
+	target := floatMetadata(input.Metadata, MetadataLoudnessTarget, DefaultLoudnessTarget)
+	return applyAudioFilter(ctx, input, fmt.Sprintf("loudnorm=I=%s:TP=-1.5:LRA=11", formatFloat(target)), "normalized")
+}
+
+// applyAudioFilter writes the input through an ffmpeg filter chain to a new
+// temporary wav and records the chain in the output's metadata
+func applyAudioFilter(ctx context.Context, input interfaces.AudioInput, filter, suffix string) (interfaces.AudioInput, error) {
+	outputPath := strings.TrimSuffix(input.FilePath, filepath.Ext(input.FilePath)) + "_" + suffix + ".wav"
+	args := []string{"-i", input.FilePath, "-af", filter}
+	// loudnorm resamples to 192 kHz; keep the rate the models expect
+	if input.SampleRate > 0 {
+		args = append(args, "-ar", strconv.Itoa(input.SampleRate))
+	}
+	args = append(args, "-c:a", "pcm_s16le", "-y", outputPath)
+
+	cmd := exec.CommandContext(ctx, binaries.FFmpeg(), args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.Error("FFmpeg audio filter failed", "filter", filter, "output", string(output), "error", err)
+		os.Remove(outputPath)
+		return input, fmt.Errorf("audio filter %s failed: %w", filter, err)
+	}
+
+	// The input is no longer needed when an earlier preprocessor created it
+	if input.TempFilePath != "" {
+		if err := os.Remove(input.TempFilePath); err != nil {
+			logger.Warn("Failed to clean up temporary file", "file", input.TempFilePath, "error", err)
+		}
+	}
+
+	filtered := input
+	filtered.FilePath = outputPath
+	filtered.Format = "wav"
+	filtered.TempFilePath = outputPath
+	filtered.Size = 0
+	if stat, err := os.Stat(outputPath); err == nil {
+		filtered.Size = stat.Size()
+	}
+	filtered.Metadata = withAudioFilter(input.Metadata, filter)
+
+	logger.Info("Applied audio filter", "filter", filter, "output_path", outputPath)
+	return filtered, nil
+}
+
+// withAudioFilter returns a copy of metadata with filter added to the list of
+// filters that ran, leaving the caller's map untouched
+func withAudioFilter(metadata map[string]string, filter string) map[string]string {
+	updated := maps.Clone(metadata)
+	if updated == nil {
+		updated = map[string]string{}
+	}
+	if previous := updated[MetadataAudioFilters]; previous != "" {
+		filter = previous + "," + filter
+	}
+	updated[MetadataAudioFilters] = filter
+	return updated
+}
+
+// RecordAudioFilters notes in the transcript's metadata which audio filters
+// ran on the audio it was transcribed from
+func RecordAudioFilters(result *interfaces.TranscriptResult, input interfaces.AudioInput) {
+	filters := input.Metadata[MetadataAudioFilters]
+	if result == nil || filters == "" {
+		return
+	}
+	if result.Metadata == nil {
+		result.Metadata = map[string]string{}
+	}
+	result.Metadata[MetadataAudioFilters] = filters
+}
@@ -0,0 +1,50 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudioFiltersOffByDefault(t *testing.T) {
+	input := interfaces.AudioInput{FilePath: "/does/not/exist.wav", Metadata: map[string]string{}}
+	preprocessors := []interfaces.Preprocessor{
+		&FrequencyFilterPreprocessor{},
+		&NoiseReductionPreprocessor{},
+		&LoudnessNormalizationPreprocessor{},
+		&SilenceTrimPreprocessor{},
+	}
+	for _, preprocessor := range preprocessors {
+		assert.True(t, preprocessor.AppliesTo(interfaces.ModelCapabilities{}))
+		output, err := preprocessor.Process(context.Background(), input)
+		assert.NoError(t, err)
+		assert.Equal(t, input, output)
+	}
+}
+
+func TestWithAudioFilter(t *testing.T) {
+	original := map[string]string{"source": "upload"}
+
+	first := withAudioFilter(original, "highpass=f=80,lowpass=f=8000")
+	second := withAudioFilter(first, "afftdn=nr=12")
+
+	assert.Equal(t, "highpass=f=80,lowpass=f=8000,afftdn=nr=12", second[MetadataAudioFilters])
+	assert.Equal(t, "upload", second["source"])
+	assert.Equal(t, map[string]string{"source": "upload"}, original, "the caller's metadata is not modified")
+	assert.Equal(t, "afftdn=nr=12", withAudioFilter(nil, "afftdn=nr=12")[MetadataAudioFilters])
+}
+
+func TestRecordAudioFilters(t *testing.T) {
+	result := &interfaces.TranscriptResult{}
+	input := interfaces.AudioInput{Metadata: map[string]string{MetadataAudioFilters: "loudnorm=I=-16:TP=-1.5:LRA=11"}}
+
+	RecordAudioFilters(result, input)
+	assert.Equal(t, "loudnorm=I=-16:TP=-1.5:LRA=11", result.Metadata[MetadataAudioFilters])
+
+	unfiltered := &interfaces.TranscriptResult{}
+	RecordAudioFilters(unfiltered, interfaces.AudioInput{})
+	assert.Nil(t, unfiltered.Metadata)
+}
@@ -0,0 +1,421 @@
+package pipeline
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Number word classes, used to decide which words may follow each other
+// within one number
+type numberClass int
+
+const (
+	classNone numberClass = iota
+	classUnit             // one to nine
+	classTeen             // ten to nineteen
+	classTens             // twenty, thirty, ...
+	classHundred
+	classScale // thousand, million, billion
+	classAnd
+)
+
+var unitWords = map[string]int64{
+	"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4,
+	"five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+}
+
+var teenWords = map[string]int64{
+	"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14,
+	"fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+}
+
+var tensWords = map[string]int64{
+	"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+	"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+}
+
+var scaleWords = map[string]int64{
+	"thousand": 1_000, "million": 1_000_000, "billion": 1_000_000_000,
+}
+
+// irregularOrdinals maps ordinals that are not formed by adding "th"
+var irregularOrdinals = map[string]string{
+	"first": "one", "second": "two", "third": "three", "fifth": "five",
+	"eighth": "eight", "ninth": "nine", "twelfth": "twelve",
+}
+
+var monthNames = setOf("january", "february", "march", "april", "may", "june",
+	"july", "august", "september", "october", "november", "december")
+
+// numberWord classifies a lower-case number word, which may be an ordinal
+func numberWord(word string) (value int64, class numberClass, ordinal bool, ok bool) {
+	if cardinal, found := irregularOrdinals[word]; found {
+		word, ordinal = cardinal, true
+	} else if strings.HasSuffix(word, "ieth") {
+		word, ordinal = strings.TrimSuffix(word, "ieth")+"y", true
+	} else if strings.HasSuffix(word, "th") {
+		word, ordinal = strings.TrimSuffix(word, "th"), true
+	}
+
+	if v, found := unitWords[word]; found {
+		return v, classUnit, ordinal, true
+	}
+	if v, found := teenWords[word]; found {
+		return v, classTeen, ordinal, true
+	}
+	if v, found := tensWords[word]; found {
+		return v, classTens, ordinal, true
+	}
+	if word == "hundred" {
+		return 100, classHundred, ordinal, true
+	}
+	if v, found := scaleWords[word]; found {
+		return v, classScale, ordinal, true
+	}
+	return 0, classNone, false, false
+}
+
+// numberFollows reports whether a number word of class next can continue a
+// number whose last word was of class last
+func numberFollows(last, next numberClass) bool {
+	switch last {
+	case classNone:
+		return next == classUnit || next == classTeen || next == classTens
+	case classUnit, classTeen:
+		return next == classHundred || next == classScale
+	case classTens:
+		return next == classUnit || next == classScale
+	case classHundred:
+		return next != classHundred && next != classNone
+	case classScale, classAnd:
+		return next == classUnit || next == classTeen || next == classTens
+	}
+	return false
+}
+
+// spokenNumber is a number read from consecutive words
+type spokenNumber struct {
+	value    int64
+	ordinal  bool
+	decimals string // Digits after "point"
+	percent  bool
+	numbers  int // Number words read
+	words    int // Input words consumed
+	lead     string
+	trail    string
+}
+
+// text formats the number as digits, with thousands separators from five digits
+func (n spokenNumber) text() string {
+	digits := strconv.FormatInt(n.value, 10)
+	if n.value >= 10_000 {
+		var b strings.Builder
+		for i, r := range digits {
+			if i > 0 && (len(digits)-i)%3 == 0 {
+				b.WriteByte(',')
+			}
+			b.WriteRune(r)
+		}
+		digits = b.String()
+	}
+	if n.decimals != "" {
+		digits += "." + n.decimals
+	}
+	if n.ordinal {
+		digits += ordinalSuffix(n.value)
+	}
+	if n.percent {
+		digits += "%"
+	}
+	return n.lead + digits + n.trail
+}
+
+func ordinalSuffix(n int64) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// parseNumber reads a spoken number starting at words[start]. Hyphenated
+// words such as "twenty-five" are read as several number words.
+func parseNumber(words []string, start int) (spokenNumber, bool) {
+	var n spokenNumber
+	var total, current, lastScale int64
+	last := classNone
+
+	for i := start; i < len(words) && !n.ordinal; i++ {
+		lead, core, trail := splitPunctuation(words[i])
+		if core == "" || (lead != "" && i > start) {
+			break
+		}
+		lower := strings.ToLower(core)
+
+		// "and" only joins the parts of a number, as in "one hundred and five"
+		if lower == "and" {
+			if (last != classHundred && last != classScale) || trail != "" || i+1 >= len(words) {
+				break
+			}
+			_, nextCore, _ := splitPunctuation(words[i+1])
+			if _, class, _, ok := numberWord(strings.ToLower(strings.Split(nextCore, "-")[0])); !ok || !numberFollows(classAnd, class) {
+				break
+			}
+			last = classAnd
+			n.words++
+			continue
+		}
+
+		// Read all parts of the word, or none of it
+		t, c, s, l := total, current, lastScale, last
+		ordinal, parts := false, 0
+		for _, part := range strings.Split(lower, "-") {
+			value, class, isOrdinal, ok := numberWord(part)
+			if !ok || ordinal || !numberFollows(l, class) || (class == classScale && s != 0 && value >= s) {
+				parts = 0
+				break
+			}
+			switch class {
+			case classHundred:
+				c *= 100
+			case classScale:
+				t += c * value
+				c, s = 0, value
+			default:
+				c += value
+			}
+			l, ordinal = class, isOrdinal
+			parts++
+		}
+		if parts == 0 {
+			break
+		}
+
+		total, current, lastScale, last = t, c, s, l
+		n.ordinal = ordinal
+		n.numbers += parts
+		n.words = i - start + 1
+		if i == start {
+			n.lead = lead
+		}
+		n.trail = trail
+		if trail != "" {
+			break
+		}
+	}
+	if n.numbers == 0 {
+		return n, false
+	}
+	// A trailing "and" is not part of the number
+	if last == classAnd {
+		return n, false
+	}
+	n.value = total + current
+
+	// Decimals and percentages
+	next := start + n.words
+	if !n.ordinal && n.trail == "" && next+1 < len(words) && strings.EqualFold(words[next], "point") {
+		var digits strings.Builder
+		var trail string
+		read := 0
+		for i := next + 1; i < len(words); i++ {
+			lead, core, t := splitPunctuation(words[i])
+			lower := strings.ToLower(core)
+			digit, found := unitWords[lower]
+			if lower == "oh" {
+				digit, found = 0, true
+			}
+			if !found || lead != "" {
+				break
+			}
+			digits.WriteString(strconv.FormatInt(digit, 10))
+			read++
+			trail = t
+			if t != "" {
+				break
+			}
+		}
+		if read > 0 {
+			n.decimals = digits.String()
+			n.words += 1 + read
+			n.trail = trail
+			next = start + n.words
+		}
+	}
+	if !n.ordinal && n.trail == "" && next < len(words) {
+		if lead, core, trail := splitPunctuation(words[next]); lead == "" && strings.EqualFold(core, "percent") {
+			n.percent = true
+			n.words++
+			n.trail = trail
+		}
+	}
+	return n, true
+}
+
+// parseYear reads a year spoken in two parts, such as "nineteen ninety nine",
+// "twenty twenty four" or "twenty oh five", or as a number of 1000 to 2999
+func parseYear(words []string, start int) (spokenNumber, bool) {
+	if n, ok := parseNumber(words, start); ok && !n.ordinal && n.decimals == "" && !n.percent &&
+		n.numbers > 1 && n.value >= 1000 && n.value < 3000 {
+		return n, true
+	}
+
+	century, ok := parseNumber(words, start)
+	if !ok || century.words != 1 || century.numbers != 1 || century.ordinal || century.trail != "" ||
+		century.value < 11 || century.value > 20 {
+		return spokenNumber{}, false
+	}
+	next := start + 1
+	if next >= len(words) {
+		return spokenNumber{}, false
+	}
+
+	year := century
+	_, core, trail := splitPunctuation(words[next])
+	switch lower := strings.ToLower(core); {
+	case lower == "hundred":
+		year.value = century.value * 100
+		year.words, year.trail = 2, trail
+		return year, true
+	case lower == "oh" && trail == "":
+		unit, ok := parseNumber(words, next+1)
+		if !ok || unit.numbers != 1 || unit.value < 1 || unit.value > 9 || unit.ordinal || unit.decimals != "" || unit.percent {
+			return spokenNumber{}, false
+		}
+		year.value = century.value*100 + unit.value
+		year.words, year.trail = 2+unit.words, unit.trail
+		return year, true
+	}
+
+	rest, ok := parseNumber(words, next)
+	if !ok || rest.ordinal || rest.decimals != "" || rest.percent || rest.value < 10 || rest.value > 99 || rest.lead != "" {
+		return spokenNumber{}, false
+	}
+	year.value = century.value*100 + rest.value
+	year.words, year.trail = 1+rest.words, rest.trail
+	return year, true
+}
+
+// isMonth reports whether word is a capitalized month name. Requiring the
+// capital keeps words like "may" and "march" used as verbs apart.
+func isMonth(word string) bool {
+	_, core, _ := splitPunctuation(word)
+	return core != "" && core[0] >= 'A' && core[0] <= 'Z' && monthNames[strings.ToLower(core)]
+}
+
+// parseDay reads the day of a date, which must be spoken as an ordinal
+func parseDay(words []string, start int) (spokenNumber, bool) {
+	day, ok := parseNumber(words, start)
+	if !ok || !day.ordinal || day.value < 1 || day.value > 31 {
+		return spokenNumber{}, false
+	}
+	day.ordinal = false
+	return day, true
+}
+
+// normalizeNumbers writes spoken English numbers and dates with digits:
+// "twenty five percent" becomes "25%", "March fifth twenty twenty four"
+// becomes "March 5, 2024" and "the first of May" becomes "May 1". Single
+// number words below ten are kept, as in "one of them". Other languages
+// are left unchanged.
+func normalizeNumbers(words []string, sc segmentContext) []textToken {
+	if sc.Language != "" && sc.Language != "en" {
+		tokens := make([]textToken, len(words))
+		for i := range words {
+			tokens[i] = unchanged(words, i)
+		}
+		return tokens
+	}
+
+	tokens := make([]textToken, 0, len(words))
+	for i := 0; i < len(words); {
+		if consumed, dateTokens := parseDate(words, i); consumed > 0 {
+			tokens = append(tokens, dateTokens...)
+			i += consumed
+			continue
+		}
+
+		n, ok := parseNumber(words, i)
+
+		// Years of this and the last century, as in "in nineteen ninety nine"
+		if year, isYear := parseYear(words, i); isYear && year.words > n.words && year.value >= 1900 && year.value < 2100 {
+			tokens = append(tokens, textToken{Text: year.text(), From: i, To: i + year.words})
+			i += year.words
+			continue
+		}
+
+		if ok && (n.numbers > 1 || n.value >= 10 || n.decimals != "" || n.percent) {
+			tokens = append(tokens, textToken{Text: n.text(), From: i, To: i + n.words})
+			i += n.words
+			continue
+		}
+
+		tokens = append(tokens, unchanged(words, i))
+		i++
+	}
+	return tokens
+}
+
+// parseDate reads a date starting at words[start] and returns how many words
+// it consumed and the tokens replacing them
+func parseDate(words []string, start int) (int, []textToken) {
+	// "March fifth", "March fifth, twenty twenty four" or "March twenty twenty four"
+	if isMonth(words[start]) {
+		_, _, monthTrail := splitPunctuation(words[start])
+		if monthTrail != "" || start+1 >= len(words) {
+			return 0, nil
+		}
+		month := unchanged(words, start)
+		if day, ok := parseDay(words, start+1); ok {
+			dayEnd := start + 1 + day.words
+			tokens := []textToken{month, {Text: day.text(), From: start + 1, To: dayEnd}}
+			if day.trail == "" || day.trail == "," {
+				if year, ok := parseYear(words, dayEnd); ok && year.lead == "" {
+					tokens[1].Text = day.lead + strconv.FormatInt(day.value, 10) + ","
+					tokens = append(tokens, textToken{Text: year.text(), From: dayEnd, To: dayEnd + year.words})
+					return dayEnd + year.words - start, tokens
+				}
+			}
+			return dayEnd - start, tokens
+		}
+		if year, ok := parseYear(words, start+1); ok && year.lead == "" {
+			return 1 + year.words, []textToken{month, {Text: year.text(), From: start + 1, To: start + 1 + year.words}}
+		}
+		return 0, nil
+	}
+
+	// "the fifth of March" becomes "March 5"
+	lead, core, trail := splitPunctuation(words[start])
+	if !strings.EqualFold(core, "the") || trail != "" {
+		return 0, nil
+	}
+	day, ok := parseDay(words, start+1)
+	if !ok || day.trail != "" || day.lead != "" {
+		return 0, nil
+	}
+	of := start + 1 + day.words
+	if of+1 >= len(words) || !strings.EqualFold(words[of], "of") || !isMonth(words[of+1]) {
+		return 0, nil
+	}
+	_, monthCore, monthTrail := splitPunctuation(words[of+1])
+	tokens := []textToken{
+		{Text: lead + monthCore, From: start, To: of},
+		{Text: strconv.FormatInt(day.value, 10) + monthTrail, From: of, To: of + 2},
+	}
+	end := of + 2
+	if monthTrail == "" || monthTrail == "," {
+		if year, ok := parseYear(words, end); ok && year.lead == "" {
+			tokens[1].Text = strconv.FormatInt(day.value, 10) + ","
+			tokens = append(tokens, textToken{Text: year.text(), From: end, To: end + year.words})
+			end += year.words
+		}
+	}
+	return end - start, tokens
+}
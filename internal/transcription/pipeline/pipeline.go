@@ -27,8 +27,23 @@ func NewProcessingPipeline() *ProcessingPipeline {
 		postprocessors: make([]interfaces.Postprocessor, 0),
 	}
 
-	// Register default preprocessors
+	// Register default preprocessors. The filters and silence trimming only
+	// run when the job enables them: band filters first so denoising does
+	// not model rumble or hiss, loudness last among the filters so it
+	// measures cleaned audio, and silence is found in the filtered result.
 	pipeline.RegisterPreprocessor(&AudioFormatPreprocessor{})
+	pipeline.RegisterPreprocessor(&FrequencyFilterPreprocessor{})
+	pipeline.RegisterPreprocessor(&NoiseReductionPreprocessor{})
+	pipeline.RegisterPreprocessor(&LoudnessNormalizationPreprocessor{})
+	pipeline.RegisterPreprocessor(&SilenceTrimPreprocessor{})
+
+	// Register default postprocessors; each is enabled by its own job parameter.
+	// Fillers are removed first so they cannot split spoken numbers, and the
+	// user's replacement rules run last so their spelling wins.
+	pipeline.RegisterPostprocessor(&FillerWordPostprocessor{})
+	pipeline.RegisterPostprocessor(&NumberNormalizationPostprocessor{})
+	pipeline.RegisterPostprocessor(&CapitalizationPostprocessor{})
+	pipeline.RegisterPostprocessor(&ReplacementPostprocessor{})
 
 	return pipeline
 }
@@ -62,6 +77,25 @@ func (p *ProcessingPipeline) ProcessAudio(ctx context.Context, input interfaces.
 	return currentInput, nil
 }
 
+// ProcessTranscript applies all applicable postprocessors to the transcript
+func (p *ProcessingPipeline) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, capabilities interfaces.ModelCapabilities, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	currentResult := result
+
+	for _, postprocessor := range p.postprocessors {
+		if postprocessor.AppliesTo(capabilities, params) {
+			logger.Info("Applying postprocessor", "type", fmt.Sprintf("%T", postprocessor))
+			processedResult, err := postprocessor.ProcessTranscript(ctx, currentResult, params)
+			if err != nil {
+				logger.Warn("Postprocessor failed, continuing with previous result", "error", err)
+				continue
+			}
+			currentResult = processedResult
+		}
+	}
+
+	return currentResult, nil
+}
+
 // AudioFormatPreprocessor converts audio to required formats
 type AudioFormatPreprocessor struct{}
 
@@ -129,6 +163,7 @@ func (a *AudioFormatPreprocessor) Process(ctx context.Context, input interfaces.
 		Size:         0,              // Will be set when file is read
 		Metadata:     input.Metadata,
 		TempFilePath: outputPath, // Mark as temporary
+		Timeline:     input.Timeline,
 	}
 
 	// Get file size
@@ -165,28 +200,6 @@ func (v *VoiceActivityDetectionPreprocessor) Process(ctx context.Context, input
 	return input, nil
 }
 
-// NoiseReductionPreprocessor applies noise reduction
-type NoiseReductionPreprocessor struct{}
-
-// AppliesTo checks if this preprocessor should be used
-func (n *NoiseReductionPreprocessor) AppliesTo(capabilities interfaces.ModelCapabilities) bool {
-	// Apply to models that would benefit from noise reduction
-	return capabilities.Features["high_quality"]
-}
-
-// GetRequiredFormats returns the output formats this preprocessor can produce
-func (n *NoiseReductionPreprocessor) GetRequiredFormats() []string {
-	return []string{"wav"}
-}
-
-// Process applies noise reduction preprocessing
-func (n *NoiseReductionPreprocessor) Process(ctx context.Context, input interfaces.AudioInput) (interfaces.AudioInput, error) {
-	// For now, this is a placeholder
-	// In a real implementation, this would apply noise reduction using FFmpeg or other tools
-	logger.Info("Noise reduction preprocessing (placeholder)", "file", input.FilePath)
-	return input, nil
-}
-
 // TextPostprocessor handles transcription result post-processing
 type TextPostprocessor struct{}
 
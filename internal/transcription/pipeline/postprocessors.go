@@ -0,0 +1,324 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// Postprocessing parameters, set from the job's transcription parameters
+const (
+	ParamRemoveFillerWords    = "remove_filler_words"
+	ParamNormalizeNumbers     = "normalize_numbers"
+	ParamRepairCapitalization = "repair_capitalization"
+	ParamLanguage             = "language"
+	ParamReplacementRules     = "replacement_rules" // []models.ReplacementRule
+)
+
+// FillerWordPostprocessor removes hesitation sounds such as "um" and "uh"
+type FillerWordPostprocessor struct{}
+
+// ProcessTranscript removes filler words from segments and timed words
+func (f *FillerWordPostprocessor) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	transformTranscript(result, transcriptLanguage(result, params), removeFillers)
+	return result, nil
+}
+
+// ProcessDiarization leaves diarization results unchanged
+func (f *FillerWordPostprocessor) ProcessDiarization(ctx context.Context, result *interfaces.DiarizationResult, params map[string]interface{}) (*interfaces.DiarizationResult, error) {
+	return result, nil
+}
+
+// AppliesTo reports whether filler word removal is enabled
+func (f *FillerWordPostprocessor) AppliesTo(capabilities interfaces.ModelCapabilities, params map[string]interface{}) bool {
+	return boolParam(params, ParamRemoveFillerWords)
+}
+
+// NumberNormalizationPostprocessor writes spoken numbers and dates as digits
+type NumberNormalizationPostprocessor struct{}
+
+// ProcessTranscript normalizes numbers and dates in segments and timed words
+func (n *NumberNormalizationPostprocessor) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	transformTranscript(result, transcriptLanguage(result, params), normalizeNumbers)
+	return result, nil
+}
+
+// ProcessDiarization leaves diarization results unchanged
+func (n *NumberNormalizationPostprocessor) ProcessDiarization(ctx context.Context, result *interfaces.DiarizationResult, params map[string]interface{}) (*interfaces.DiarizationResult, error) {
+	return result, nil
+}
+
+// AppliesTo reports whether number normalization is enabled
+func (n *NumberNormalizationPostprocessor) AppliesTo(capabilities interfaces.ModelCapabilities, params map[string]interface{}) bool {
+	return boolParam(params, ParamNormalizeNumbers)
+}
+
+// CapitalizationPostprocessor capitalizes sentence starts, and "I" in English
+type CapitalizationPostprocessor struct{}
+
+// ProcessTranscript repairs capitalization in segments and timed words
+func (c *CapitalizationPostprocessor) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	transformTranscript(result, transcriptLanguage(result, params), repairCapitalization)
+	return result, nil
+}
+
+// ProcessDiarization leaves diarization results unchanged
+func (c *CapitalizationPostprocessor) ProcessDiarization(ctx context.Context, result *interfaces.DiarizationResult, params map[string]interface{}) (*interfaces.DiarizationResult, error) {
+	return result, nil
+}
+
+// AppliesTo reports whether capitalization repair is enabled
+func (c *CapitalizationPostprocessor) AppliesTo(capabilities interfaces.ModelCapabilities, params map[string]interface{}) bool {
+	return boolParam(params, ParamRepairCapitalization)
+}
+
+func boolParam(params map[string]interface{}, name string) bool {
+	enabled, _ := params[name].(bool)
+	return enabled
+}
+
+// transcriptLanguage returns the base language code of a transcript, preferring
+// the language the job asked for over the one the model detected
+func transcriptLanguage(result *interfaces.TranscriptResult, params map[string]interface{}) string {
+	language, _ := params[ParamLanguage].(string)
+	if language == "" {
+		language = result.Language
+	}
+	language = strings.ToLower(language)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	return language
+}
+
+// textToken is a word produced by a text transform. It replaces the input
+// words From up to To; input words not covered by any token are dropped.
+type textToken struct {
+	Text     string
+	From, To int
+}
+
+// segmentContext tells a text transform where the words it rewrites belong
+type segmentContext struct {
+	Language string
+	Previous string // Text of the previous segment after the transform, empty for the first
+}
+
+// textTransform rewrites the whitespace-separated words of one segment
+type textTransform func(words []string, sc segmentContext) []textToken
+
+// transformTranscript applies transform to the text of each segment and to
+// the timed words within it, so both stay consistent. A token replacing
+// several words spans their timings, and segments left without text are
+// dropped.
+func transformTranscript(result *interfaces.TranscriptResult, language string, transform textTransform) {
+	if len(result.Segments) == 0 {
+		sc := segmentContext{Language: language}
+		result.Text = joinTokens(transform(strings.Fields(result.Text), sc))
+		result.WordSegments = transformWords(result.WordSegments, sc, transform)
+		return
+	}
+
+	words := result.WordSegments
+	updatedWords := make([]interfaces.TranscriptWord, 0, len(words))
+	segments := make([]interfaces.TranscriptSegment, 0, len(result.Segments))
+	texts := make([]string, 0, len(result.Segments))
+	next := 0
+	for _, segment := range result.Segments {
+		sc := segmentContext{Language: language}
+		if len(texts) > 0 {
+			sc.Previous = texts[len(texts)-1]
+		}
+
+		// Words are matched to segments by their midpoint; words outside
+		// every segment are kept as they are
+		for next < len(words) && (words[next].Start+words[next].End)/2 < segment.Start {
+			updatedWords = append(updatedWords, words[next])
+			next++
+		}
+		first := next
+		for next < len(words) && (words[next].Start+words[next].End)/2 <= segment.End {
+			next++
+		}
+		updatedWords = append(updatedWords, transformWords(words[first:next], sc, transform)...)
+
+		segment.Text = joinTokens(transform(strings.Fields(segment.Text), sc))
+		if segment.Text == "" {
+			continue
+		}
+		segments = append(segments, segment)
+		texts = append(texts, segment.Text)
+	}
+	updatedWords = append(updatedWords, words[next:]...)
+
+	result.Segments = segments
+	result.WordSegments = updatedWords
+	result.Text = strings.Join(texts, " ")
+}
+
+func transformWords(words []interfaces.TranscriptWord, sc segmentContext, transform textTransform) []interfaces.TranscriptWord {
+	if len(words) == 0 {
+		return words
+	}
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = strings.TrimSpace(w.Word)
+	}
+
+	tokens := transform(texts, sc)
+	result := make([]interfaces.TranscriptWord, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Text == "" {
+			continue
+		}
+		word := words[token.From]
+		word.Word = token.Text
+		word.End = words[token.To-1].End
+		for _, w := range words[token.From+1 : token.To] {
+			word.Score = min(word.Score, w.Score)
+		}
+		result = append(result, word)
+	}
+	return result
+}
+
+func joinTokens(tokens []textToken) string {
+	texts := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Text != "" {
+			texts = append(texts, token.Text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// unchanged passes a word through a transform as it is
+func unchanged(words []string, i int) textToken {
+	return textToken{Text: words[i], From: i, To: i + 1}
+}
+
+// splitPunctuation splits a word into its leading punctuation, the word
+// itself and its trailing punctuation
+func splitPunctuation(word string) (lead, core, trail string) {
+	isPunct := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+	start := strings.IndexFunc(word, func(r rune) bool { return !isPunct(r) })
+	if start < 0 {
+		return word, "", ""
+	}
+	end := strings.LastIndexFunc(word, func(r rune) bool { return !isPunct(r) })
+	_, size := utf8.DecodeRuneInString(word[end:])
+	return word[:start], word[start : end+size], word[end+size:]
+}
+
+// endsSentence reports whether trailing punctuation closes a sentence. An
+// ellipsis usually marks a pause rather than the end of a sentence.
+func endsSentence(trail string) bool {
+	if strings.Contains(trail, "...") || strings.Contains(trail, "…") {
+		return false
+	}
+	return strings.ContainsAny(trail, ".?!")
+}
+
+// fillerWords are hesitation sounds per language. Elongated forms such as
+// "ummm" are matched by collapsing repeated letters first.
+var fillerWords = map[string]map[string]bool{
+	"en": setOf("uh", "um", "uhm", "umm", "er", "erm", "hm", "hmm", "mm"),
+	"de": setOf("äh", "ähm", "öh", "öhm", "hm", "hmm", "mh"),
+	"fr": setOf("euh", "heu", "hum", "hm"),
+	"es": setOf("eh", "em", "ehm", "hm", "mm"),
+	"it": setOf("eh", "ehm", "uhm", "hm", "mm"),
+	"nl": setOf("uh", "uhm", "eh", "ehm", "hm"),
+	"pt": setOf("ahn", "hum", "eh", "hm"),
+}
+
+func setOf(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// isFiller reports whether word is one of the filler words
+func isFiller(fillers map[string]bool, word string) bool {
+	word = strings.ToLower(word)
+	if fillers[word] {
+		return true
+	}
+	// Collapse runs of three or more equal letters, so "ummm" matches "um"
+	// while real words with double letters are left alone
+	var b strings.Builder
+	runes := []rune(word)
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		if j-i >= 3 {
+			b.WriteRune(runes[i])
+		} else {
+			b.WriteString(string(runes[i:j]))
+		}
+		i = j
+	}
+	return fillers[b.String()]
+}
+
+func removeFillers(words []string, sc segmentContext) []textToken {
+	language := sc.Language
+	if language == "" {
+		language = "en"
+	}
+	fillers := fillerWords[language]
+
+	tokens := make([]textToken, 0, len(words))
+	for i, word := range words {
+		_, core, trail := splitPunctuation(word)
+		if core == "" || !isFiller(fillers, core) {
+			tokens = append(tokens, unchanged(words, i))
+			continue
+		}
+		// Keep the end of the sentence when the filler closed it
+		if n := len(tokens); n > 0 && endsSentence(trail) {
+			tokens[n-1].Text = strings.TrimRight(tokens[n-1].Text, ",;:") + strings.TrimLeft(trail, ",;: ")
+		}
+	}
+	return tokens
+}
+
+// englishAbbreviations end with a period that does not close the sentence
+var englishAbbreviations = setOf("mr", "mrs", "ms", "dr", "prof", "st", "vs", "jr", "sr")
+
+func repairCapitalization(words []string, sc segmentContext) []textToken {
+	english := sc.Language == "" || sc.Language == "en"
+	sentenceStart := sc.Previous == ""
+	if !sentenceStart {
+		_, _, trail := splitPunctuation(sc.Previous[strings.LastIndex(sc.Previous, " ")+1:])
+		sentenceStart = endsSentence(trail)
+	}
+
+	tokens := make([]textToken, 0, len(words))
+	for i, word := range words {
+		lead, core, trail := splitPunctuation(word)
+		token := unchanged(words, i)
+		if core != "" {
+			lower := strings.ToLower(core)
+			if english && (lower == "i" || strings.HasPrefix(lower, "i'")) {
+				core = "I" + core[1:]
+			}
+			if sentenceStart {
+				r, size := utf8.DecodeRuneInString(core)
+				core = string(unicode.ToUpper(r)) + core[size:]
+			}
+			token.Text = lead + core + trail
+
+			abbreviation := strings.Contains(core, ".") || (english && englishAbbreviations[lower])
+			sentenceStart = endsSentence(trail) && !abbreviation
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// applyText runs transform over a single segment of text
+func applyText(transform textTransform, language, text string) string {
+	return joinTokens(transform(strings.Fields(text), segmentContext{Language: language}))
+}
+
+func TestRemoveFillers(t *testing.T) {
+	cases := []struct{ language, in, want string }{
+		{"en", "So um I think, uh, we should go", "So I think, we should go"},
+		{"en", "Ummm, that works", "that works"},
+		{"en", "It was summer and the hammer fell", "It was summer and the hammer fell"},
+		{"en", "We are done, uh.", "We are done."},
+		{"de", "Ich äh weiß nicht", "Ich weiß nicht"},
+		{"fr", "Alors euh on y va", "Alors on y va"},
+		{"", "Well, um, yes", "Well, yes"},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, applyText(removeFillers, tc.language, tc.in), tc.in)
+	}
+}
+
+func TestNormalizeNumbers(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"one of them came", "one of them came"},
+		{"we sold twenty five units", "we sold 25 units"},
+		{"about twenty-five people", "about 25 people"},
+		{"two hundred and fifty thousand dollars", "250,000 dollars"},
+		{"one thousand two hundred", "1200"},
+		{"three point one four is close", "3.14 is close"},
+		{"a rise of fifty percent.", "a rise of 50%."},
+		{"the twenty first century", "the 21st century"},
+		{"the first time", "the first time"},
+		{"ten and twenty", "10 and 20"},
+		{"Born in nineteen ninety nine", "Born in 1999"},
+		{"due March fifth", "due March 5"},
+		{"on March third, twenty twenty four we met", "on March 3, 2024 we met"},
+		{"in May two thousand five", "in May 2005"},
+		{"in June twenty oh five", "in June 2005"},
+		{"on the first of May", "on May 1"},
+		{"you may fifth", "you may fifth"},
+		{"Seven, eight.", "Seven, eight."},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, applyText(normalizeNumbers, "en", tc.in), tc.in)
+	}
+	assert.Equal(t, "zwanzig Leute", applyText(normalizeNumbers, "de", "zwanzig Leute"))
+}
+
+func TestRepairCapitalization(t *testing.T) {
+	assert.Equal(t, "Hello there. I think I'm... sure. Dr. smith agrees! Yes",
+		applyText(repairCapitalization, "en", "hello there. i think i'm... sure. dr. smith agrees! yes"))
+	assert.Equal(t, "Il est là. Je viens", applyText(repairCapitalization, "fr", "il est là. je viens"))
+
+	// The sentence continues across segments
+	tokens := repairCapitalization([]string{"and", "then"}, segmentContext{Language: "en", Previous: "We left,"})
+	assert.Equal(t, "and then", joinTokens(tokens))
+	tokens = repairCapitalization([]string{"and", "then"}, segmentContext{Language: "en", Previous: "We left."})
+	assert.Equal(t, "And then", joinTokens(tokens))
+}
+
+func TestProcessTranscript(t *testing.T) {
+	result := &interfaces.TranscriptResult{
+		Language: "en",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 3, Text: "um we need twenty five chairs."},
+			{Start: 3, End: 4, Text: "uh"},
+			{Start: 4, End: 6, Text: "i agree."},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0, End: 0.5, Word: "um", Score: 0.5},
+			{Start: 0.5, End: 1, Word: "we", Score: 0.9},
+			{Start: 1, End: 1.5, Word: "need", Score: 0.9},
+			{Start: 1.5, End: 2, Word: "twenty", Score: 0.8},
+			{Start: 2, End: 2.5, Word: "five", Score: 0.6},
+			{Start: 2.5, End: 3, Word: "chairs.", Score: 0.9},
+			{Start: 3, End: 4, Word: "uh", Score: 0.4},
+			{Start: 4, End: 5, Word: "i", Score: 0.9},
+			{Start: 5, End: 6, Word: "agree.", Score: 0.9},
+		},
+	}
+	params := map[string]interface{}{
+		ParamRemoveFillerWords:    true,
+		ParamNormalizeNumbers:     true,
+		ParamRepairCapitalization: true,
+	}
+
+	processed, err := NewProcessingPipeline().ProcessTranscript(context.Background(), result, interfaces.ModelCapabilities{}, params)
+	assert.NoError(t, err)
+	assert.Equal(t, "We need 25 chairs. I agree.", processed.Text)
+	if assert.Len(t, processed.Segments, 2) {
+		assert.Equal(t, "We need 25 chairs.", processed.Segments[0].Text)
+		assert.Equal(t, 4.0, processed.Segments[1].Start)
+	}
+
+	words := make([]string, len(processed.WordSegments))
+	for i, w := range processed.WordSegments {
+		words[i] = w.Word
+	}
+	assert.Equal(t, []string{"We", "need", "25", "chairs.", "I", "agree."}, words)
+	number := processed.WordSegments[2]
+	assert.Equal(t, 1.5, number.Start)
+	assert.Equal(t, 2.5, number.End)
+	assert.Equal(t, 0.6, number.Score)
+}
+
+func TestPostprocessorsDisabled(t *testing.T) {
+	result := &interfaces.TranscriptResult{Text: "um twenty five"}
+	processed, err := NewProcessingPipeline().ProcessTranscript(context.Background(), result, interfaces.ModelCapabilities{}, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "um twenty five", processed.Text)
+}
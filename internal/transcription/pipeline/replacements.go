@@ -0,0 +1,201 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+)
+
+// ReplacementPostprocessor applies the find-and-replace rules of the
+// vocabularies a job uses. It runs whenever rules are given.
+type ReplacementPostprocessor struct{}
+
+// ProcessTranscript applies the replacement rules in order to segments and timed words
+func (r *ReplacementPostprocessor) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	rules, _ := params[ParamReplacementRules].([]models.ReplacementRule)
+	replacers := make([]replacer, 0, len(rules))
+	for _, rule := range rules {
+		compiled, err := compileReplacement(rule)
+		if err != nil {
+			return nil, err
+		}
+		replacers = append(replacers, compiled)
+	}
+
+	transformTranscript(result, transcriptLanguage(result, params), func(words []string, sc segmentContext) []textToken {
+		return replaceWords(words, replacers)
+	})
+	return result, nil
+}
+
+// ProcessDiarization leaves diarization results unchanged
+func (r *ReplacementPostprocessor) ProcessDiarization(ctx context.Context, result *interfaces.DiarizationResult, params map[string]interface{}) (*interfaces.DiarizationResult, error) {
+	return result, nil
+}
+
+// AppliesTo reports whether any replacement rules were given
+func (r *ReplacementPostprocessor) AppliesTo(capabilities interfaces.ModelCapabilities, params map[string]interface{}) bool {
+	rules, _ := params[ParamReplacementRules].([]models.ReplacementRule)
+	return len(rules) > 0
+}
+
+// ValidateReplacementRule reports why a rule cannot be applied, if it cannot
+func ValidateReplacementRule(rule models.ReplacementRule) error {
+	_, err := compileReplacement(rule)
+	return err
+}
+
+// replacer is a compiled replacement rule
+type replacer struct {
+	re      *regexp.Regexp
+	replace string
+	literal bool
+}
+
+func compileReplacement(rule models.ReplacementRule) (replacer, error) {
+	if strings.TrimSpace(rule.Find) == "" {
+		return replacer{}, fmt.Errorf("replacement rule has nothing to find")
+	}
+
+	pattern := rule.Find
+	if !rule.Regex {
+		// Words of a literal may be separated by any whitespace
+		fields := strings.Fields(rule.Find)
+		for i, field := range fields {
+			fields[i] = regexp.QuoteMeta(field)
+		}
+		pattern = strings.Join(fields, `\s+`)
+	}
+	if !rule.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return replacer{}, fmt.Errorf("invalid pattern %q: %w", rule.Find, err)
+	}
+	return replacer{re: re, replace: rule.Replace, literal: !rule.Regex}, nil
+}
+
+// matches returns the non-empty matches of the rule in text. Literal rules
+// only match whole words, so "art" does not match inside "start".
+func (r replacer) matches(text string) [][]int {
+	var matches [][]int
+	for _, m := range r.re.FindAllStringSubmatchIndex(text, -1) {
+		if m[1] == m[0] {
+			continue
+		}
+		if r.literal && !wholeWord(text, m[0], m[1]) {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+func (r replacer) expand(text string, match []int) string {
+	if r.literal {
+		return r.replace
+	}
+	return string(r.re.ExpandString(nil, r.replace, text, match))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wholeWord reports whether text[start:end] neither starts nor ends inside a word
+func wholeWord(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(text[start:])
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) && isWordRune(first) {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(text[:end])
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) && isWordRune(last) {
+		return false
+	}
+	return true
+}
+
+// replaceWords applies each replacer in turn; the tokens of every pass are
+// mapped back to the input words so timings follow the replaced text
+func replaceWords(words []string, replacers []replacer) []textToken {
+	tokens := make([]textToken, len(words))
+	for i := range words {
+		tokens[i] = unchanged(words, i)
+	}
+	for _, r := range replacers {
+		texts := make([]string, len(tokens))
+		for i, token := range tokens {
+			texts[i] = token.Text
+		}
+		replaced := replaceOnce(texts, r)
+		next := make([]textToken, 0, len(replaced))
+		for _, token := range replaced {
+			next = append(next, textToken{Text: token.Text, From: tokens[token.From].From, To: tokens[token.To-1].To})
+		}
+		tokens = next
+	}
+	return tokens
+}
+
+// replaceOnce applies one replacer to the words joined by spaces. The words a
+// match touches are replaced together by the words of the rewritten text.
+func replaceOnce(words []string, r replacer) []textToken {
+	text := strings.Join(words, " ")
+	matches := r.matches(text)
+
+	starts := make([]int, len(words))
+	pos := 0
+	for i, word := range words {
+		starts[i] = pos
+		pos += len(word) + 1
+	}
+	// wordAt returns the word containing offset, or the one after it when
+	// offset falls on a separating space
+	wordAt := func(offset int) int {
+		return sort.Search(len(words), func(i int) bool { return starts[i]+len(words[i]) > offset })
+	}
+
+	tokens := make([]textToken, 0, len(words))
+	next := 0
+	for k := 0; k < len(matches); {
+		first, last := wordAt(matches[k][0]), wordAt(matches[k][1]-1)
+		end := k + 1
+		for end < len(matches) && wordAt(matches[end][0]) <= last {
+			last = max(last, wordAt(matches[end][1]-1))
+			end++
+		}
+		for ; next < first; next++ {
+			tokens = append(tokens, unchanged(words, next))
+		}
+
+		spanStart := min(starts[first], matches[k][0])
+		spanEnd := max(starts[last]+len(words[last]), matches[end-1][1])
+		var b strings.Builder
+		pos := spanStart
+		for _, m := range matches[k:end] {
+			b.WriteString(text[pos:m[0]])
+			b.WriteString(r.expand(text, m))
+			pos = m[1]
+		}
+		b.WriteString(text[pos:spanEnd])
+		for _, field := range strings.Fields(b.String()) {
+			tokens = append(tokens, textToken{Text: field, From: first, To: last + 1})
+		}
+
+		next = last + 1
+		k = end
+	}
+	for ; next < len(words); next++ {
+		tokens = append(tokens, unchanged(words, next))
+	}
+	return tokens
+}
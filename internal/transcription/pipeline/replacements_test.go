@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func replaceText(t *testing.T, text string, rules ...models.ReplacementRule) string {
+	replacers := make([]replacer, len(rules))
+	for i, rule := range rules {
+		var err error
+		replacers[i], err = compileReplacement(rule)
+		assert.NoError(t, err)
+	}
+	return joinTokens(replaceWords(strings.Fields(text), replacers))
+}
+
+func TestReplaceWords(t *testing.T) {
+	scriber := models.ReplacementRule{Find: "scribe er", Replace: "Scriberr"}
+	assert.Equal(t, "Try Scriberr, it works", replaceText(t, "Try Scribe  er, it works", scriber))
+	assert.Equal(t, "describe era", replaceText(t, "describe era", scriber))
+
+	// Literal rules match whole words only, and case when asked to
+	art := models.ReplacementRule{Find: "art", Replace: "ART", CaseSensitive: true}
+	assert.Equal(t, "start ART Art", replaceText(t, "start art Art", art))
+	assert.Equal(t, "Müller and Muller", replaceText(t, "Müller and Muller",
+		models.ReplacementRule{Find: "ller", Replace: "x"}))
+
+	// Regex rules can use capture groups and touch parts of words
+	version := models.ReplacementRule{Find: `v(\d+) point (\d+)`, Replace: "v$1.$2", Regex: true}
+	assert.Equal(t, "Upgrade to v2.5 now", replaceText(t, "Upgrade to v2 point 5 now", version))
+	assert.Equal(t, "colour flavour", replaceText(t, "color flavor",
+		models.ReplacementRule{Find: `or\b`, Replace: "our", Regex: true}))
+
+	// A replacement may remove words or be rewritten by a later rule
+	assert.Equal(t, "so we go", replaceText(t, "so you know we go",
+		models.ReplacementRule{Find: "you know", Replace: ""}))
+	assert.Equal(t, "Kubernetes cluster", replaceText(t, "cooper netties cluster",
+		models.ReplacementRule{Find: "cooper netties", Replace: "kubernetes"},
+		models.ReplacementRule{Find: "kubernetes", Replace: "Kubernetes", CaseSensitive: true}))
+}
+
+func TestValidateReplacementRule(t *testing.T) {
+	assert.NoError(t, ValidateReplacementRule(models.ReplacementRule{Find: "a(b", Replace: "x"}))
+	assert.Error(t, ValidateReplacementRule(models.ReplacementRule{Find: "a(b", Replace: "x", Regex: true}))
+	assert.Error(t, ValidateReplacementRule(models.ReplacementRule{Find: "  ", Replace: "x"}))
+}
+
+func TestReplacementTimings(t *testing.T) {
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{{Start: 0, End: 3, Text: "ask jon doe now"}},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0, End: 1, Word: "ask", Score: 0.9},
+			{Start: 1, End: 1.5, Word: "jon", Score: 0.5},
+			{Start: 1.5, End: 2, Word: "doe", Score: 0.7},
+			{Start: 2, End: 3, Word: "now", Score: 0.9},
+		},
+	}
+	params := map[string]interface{}{
+		ParamReplacementRules: []models.ReplacementRule{{Find: "jon doe", Replace: "John Doe-Smith"}},
+	}
+
+	processed, err := NewProcessingPipeline().ProcessTranscript(context.Background(), result, interfaces.ModelCapabilities{}, params)
+	assert.NoError(t, err)
+	assert.Equal(t, "ask John Doe-Smith now", processed.Text)
+	if assert.Len(t, processed.WordSegments, 4) {
+		for _, w := range processed.WordSegments[1:3] {
+			assert.Equal(t, 1.0, w.Start)
+			assert.Equal(t, 2.0, w.End)
+			assert.Equal(t, 0.5, w.Score)
+		}
+		assert.Equal(t, "Doe-Smith", processed.WordSegments[2].Word)
+	}
+}
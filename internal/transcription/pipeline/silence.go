@@ -0,0 +1,255 @@
+package pipeline
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/binaries"
+	"scriberr/pkg/logger"
+)
+
+// Silence trimming options, passed in the audio input's metadata
+const (
+	MetadataTrimSilence        = "trim_silence"         // "true" enables trimming
+	MetadataSilenceThreshold   = "silence_threshold"    // dBFS below which audio counts as silence
+	MetadataMinSilenceDuration = "min_silence_duration" // Shortest gap in seconds that is cut
+)
+
+const (
+	DefaultSilenceThreshold   = -40.0
+	DefaultMinSilenceDuration = 2.0
+
+	// silencePadding is kept on each side of a cut so word onsets and
+	// trailing syllables are not clipped
+	silencePadding = 0.25
+)
+
+// Silence is a gap reported by ffmpeg's silencedetect, in seconds. An End of
+// -1 means the silence runs to the end of the recording.
+type Silence struct {
+	Start, End float64
+}
+
+// SilenceTrimPreprocessor cuts long silences out of the audio before any model
+// runs. The stretches kept are recorded in the output's Timeline so the
+// transcript's timestamps can be mapped back with RemapTranscript.
+type SilenceTrimPreprocessor struct{ jobOptionPreprocessor }
+
+// Process detects silences with ffmpeg's silencedetect and writes the audio
+// without them
+func (s *SilenceTrimPreprocessor) Process(ctx context.Context, input interfaces.AudioInput) (interfaces.AudioInput, error) {
+	if input.Metadata[MetadataTrimSilence] != "true" {
+		return input, nil
+	}
+	threshold := floatMetadata(input.Metadata, MetadataSilenceThreshold, DefaultSilenceThreshold)
+	minDuration := floatMetadata(input.Metadata, MetadataMinSilenceDuration, DefaultMinSilenceDuration)
+
+	silences, err := DetectSilences(ctx, input.FilePath, threshold, minDuration)
+	if err != nil {
+		return input, err
+	}
+	timeline := keptRanges(silences, silencePadding)
+	if timeline == nil {
+		logger.Info("No silence to trim", "file", input.FilePath, "silences", len(silences))
+		return input, nil
+	}
+
+	outputPath := strings.TrimSuffix(input.FilePath, filepath.Ext(input.FilePath)) + "_trimmed.wav"
+	args := []string{
+		"-i", input.FilePath,
+		"-af", fmt.Sprintf("aselect='%s',asetpts=N/SR/TB", selectExpression(timeline)),
+		"-c:a", "pcm_s16le",
+		"-y",
+		outputPath,
+	}
+	cmd := exec.CommandContext(ctx, binaries.FFmpeg(), args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.Error("FFmpeg silence trimming failed", "output", string(output), "error", err)
+		os.Remove(outputPath)
+		return input, fmt.Errorf("silence trimming failed: %w", err)
+	}
+
+	// The input is no longer needed when an earlier preprocessor created it
+	if input.TempFilePath != "" {
+		if err := os.Remove(input.TempFilePath); err != nil {
+			logger.Warn("Failed to clean up temporary file", "file", input.TempFilePath, "error", err)
+		}
+	}
+
+	trimmed := input
+	trimmed.FilePath = outputPath
+	trimmed.Format = "wav"
+	trimmed.TempFilePath = outputPath
+	trimmed.Timeline = timeline
+	trimmed.Size = 0
+	if stat, err := os.Stat(outputPath); err == nil {
+		trimmed.Size = stat.Size()
+	}
+	if last := timeline[len(timeline)-1]; last.Duration > 0 {
+		trimmed.Duration = time.Duration((last.Start + last.Duration) * float64(time.Second))
+	} else if input.Duration > 0 {
+		trimmed.Duration = input.Duration - time.Duration((last.OriginalStart-last.Start)*float64(time.Second))
+	}
+
+	logger.Info("Trimmed silence",
+		"output_path", outputPath,
+		"silences", len(silences),
+		"kept_ranges", len(timeline),
+		"original_duration", input.Duration,
+		"trimmed_duration", trimmed.Duration)
+
+	return trimmed, nil
+}
+
+// DetectSilences runs ffmpeg's silencedetect filter over the file, reporting
+// gaps of at least minDuration seconds quieter than threshold dBFS
+func DetectSilences(ctx context.Context, path string, threshold, minDuration float64) ([]Silence, error) {
+	args := []string{
+		"-hide_banner", "-nostats",
+		"-i", path,
+		"-af", fmt.Sprintf("silencedetect=noise=%sdB:d=%s", formatFloat(threshold), formatFloat(minDuration)),
+		"-f", "null", "-",
+	}
+	cmd := exec.CommandContext(ctx, binaries.FFmpeg(), args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		logger.Error("FFmpeg silence detection failed", "output", string(output), "error", err)
+		return nil, fmt.Errorf("silence detection failed: %w", err)
+	}
+	return parseSilences(output), nil
+}
+
+var (
+	silenceStartPattern = regexp.MustCompile(`silence_start:\s*(-?[0-9.]+)`)
+	silenceEndPattern   = regexp.MustCompile(`silence_end:\s*(-?[0-9.]+)`)
+)
+
+// parseSilences reads the silence_start and silence_end lines that
+// silencedetect logs. A start without an end is silence up to the end.
+func parseSilences(output []byte) []Silence {
+	var silences []Silence
+	open := false
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := silenceStartPattern.FindStringSubmatch(line); m != nil {
+			start, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				continue
+			}
+			silences = append(silences, Silence{Start: math.Max(start, 0), End: -1})
+			open = true
+		} else if m := silenceEndPattern.FindStringSubmatch(line); m != nil && open {
+			end, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				continue
+			}
+			silences[len(silences)-1].End = end
+			open = false
+		}
+	}
+	return silences
+}
+
+// keptRanges returns the stretches of audio between the silences, keeping
+// padding seconds of each silence next to speech. It returns nil when
+// nothing would be cut or nothing would be left.
+func keptRanges(silences []Silence, padding float64) []interfaces.TimelineRange {
+	var timeline []interfaces.TimelineRange
+	position, kept := 0.0, 0.0
+	for _, s := range silences {
+		cutStart := s.Start + padding
+		if s.Start <= 0 {
+			cutStart = 0
+		}
+		if s.End < 0 {
+			if cutStart > position {
+				timeline = append(timeline, interfaces.TimelineRange{Start: kept, OriginalStart: position, Duration: cutStart - position})
+			}
+			return timeline
+		}
+		cutEnd := s.End - padding
+		if cutEnd <= cutStart || cutEnd <= position {
+			continue
+		}
+		if cutStart > position {
+			timeline = append(timeline, interfaces.TimelineRange{Start: kept, OriginalStart: position, Duration: cutStart - position})
+			kept += cutStart - position
+		}
+		position = cutEnd
+	}
+	if position == 0 {
+		return nil
+	}
+	timeline = append(timeline, interfaces.TimelineRange{Start: kept, OriginalStart: position})
+	return timeline
+}
+
+// selectExpression is an aselect expression that keeps the ranges of timeline
+func selectExpression(timeline []interfaces.TimelineRange) string {
+	parts := make([]string, len(timeline))
+	for i, r := range timeline {
+		if r.Duration == 0 {
+			parts[i] = fmt.Sprintf("gte(t,%s)", formatFloat(r.OriginalStart))
+		} else {
+			parts[i] = fmt.Sprintf("between(t,%s,%s)", formatFloat(r.OriginalStart), formatFloat(r.OriginalStart+r.Duration))
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// RemapTranscript moves the timestamps of a transcript of processed audio
+// back onto the original recording's timeline
+func RemapTranscript(result *interfaces.TranscriptResult, timeline []interfaces.TimelineRange) {
+	if result == nil || len(timeline) == 0 {
+		return
+	}
+	for i := range result.Segments {
+		result.Segments[i].Start = originalTime(timeline, result.Segments[i].Start, false)
+		result.Segments[i].End = originalTime(timeline, result.Segments[i].End, true)
+	}
+	for i := range result.WordSegments {
+		result.WordSegments[i].Start = originalTime(timeline, result.WordSegments[i].Start, false)
+		result.WordSegments[i].End = originalTime(timeline, result.WordSegments[i].End, true)
+	}
+}
+
+// originalTime maps a time in the processed audio to the original recording.
+// An end time on the boundary between two ranges belongs to the earlier one,
+// so a segment ending at a cut does not stretch over the removed silence.
+func originalTime(timeline []interfaces.TimelineRange, t float64, end bool) float64 {
+	index := 0
+	for i, r := range timeline {
+		if r.Start < t || (!end && r.Start == t) {
+			index = i
+		}
+	}
+	r := timeline[index]
+	offset := math.Max(t-r.Start, 0)
+	if r.Duration > 0 {
+		offset = math.Min(offset, r.Duration)
+	}
+	return math.Round((r.OriginalStart+offset)*1000) / 1000
+}
+
+func floatMetadata(metadata map[string]string, key string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(metadata[key], 64); err == nil && v != 0 {
+		return v
+	}
+	return fallback
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"testing"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSilences(t *testing.T) {
+	output := []byte(`Input #0, wav, from 'memo.wav':
+  Duration: 00:02:00.00, bitrate: 256 kb/s
+[silencedetect @ 0x5581] silence_start: -0.0125
+[silencedetect @ 0x5581] silence_end: 3.2 | silence_duration: 3.2125
+[silencedetect @ 0x5581] silence_start: 10.5
+[silencedetect @ 0x5581] silence_end: 70.25 | silence_duration: 59.75
+[silencedetect @ 0x5581] silence_start: 110
+size=N/A time=00:02:00.00 bitrate=N/A speed= 900x`)
+
+	assert.Equal(t, []Silence{{0, 3.2}, {10.5, 70.25}, {110, -1}}, parseSilences(output))
+	assert.Empty(t, parseSilences([]byte("size=N/A time=00:00:10.00")))
+}
+
+func TestKeptRanges(t *testing.T) {
+	timeline := keptRanges([]Silence{{0, 3.2}, {10.5, 70.25}, {110, -1}}, 0.25)
+	assert.Equal(t, []interfaces.TimelineRange{
+		{Start: 0, OriginalStart: 2.95, Duration: 7.8},
+		{Start: 7.8, OriginalStart: 70, Duration: 40.25},
+	}, roundTimeline(timeline))
+
+	// Speech to the end of the recording
+	timeline = keptRanges([]Silence{{5, 9}}, 0.25)
+	assert.Equal(t, []interfaces.TimelineRange{
+		{Start: 0, OriginalStart: 0, Duration: 5.25},
+		{Start: 5.25, OriginalStart: 8.75},
+	}, roundTimeline(timeline))
+
+	// Gaps shorter than the padding on both sides are kept
+	assert.Nil(t, keptRanges([]Silence{{5, 5.4}}, 0.25))
+	assert.Nil(t, keptRanges(nil, 0.25))
+	// All silence
+	assert.Nil(t, keptRanges([]Silence{{0, -1}}, 0.25))
+}
+
+func TestSelectExpression(t *testing.T) {
+	timeline := []interfaces.TimelineRange{
+		{Start: 0, OriginalStart: 0, Duration: 5.25},
+		{Start: 5.25, OriginalStart: 8.75},
+	}
+	assert.Equal(t, "between(t,0,5.25)+gte(t,8.75)", selectExpression(timeline))
+}
+
+func TestRemapTranscript(t *testing.T) {
+	timeline := []interfaces.TimelineRange{
+		{Start: 0, OriginalStart: 2.95, Duration: 7.8},
+		{Start: 7.8, OriginalStart: 70, Duration: 40.25},
+	}
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0.5, End: 7.8, Text: "Before the break"},
+			{Start: 7.8, End: 12, Text: "After the break"},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 7, End: 7.8, Word: "break"},
+			{Start: 8, End: 8.5, Word: "After"},
+		},
+	}
+
+	RemapTranscript(result, timeline)
+
+	assert.Equal(t, 3.45, result.Segments[0].Start)
+	assert.Equal(t, 10.75, result.Segments[0].End, "an end on a cut stays before the removed silence")
+	assert.Equal(t, 70.0, result.Segments[1].Start)
+	assert.Equal(t, 74.2, result.Segments[1].End)
+	assert.Equal(t, 9.95, result.WordSegments[0].Start)
+	assert.Equal(t, 10.75, result.WordSegments[0].End)
+	assert.Equal(t, 70.2, result.WordSegments[1].Start)
+	assert.Equal(t, 70.7, result.WordSegments[1].End)
+
+	// Untrimmed audio keeps its timestamps
+	untouched := &interfaces.TranscriptResult{Segments: []interfaces.TranscriptSegment{{Start: 1.234, End: 2}}}
+	RemapTranscript(untouched, nil)
+	assert.Equal(t, 1.234, untouched.Segments[0].Start)
+}
+
+// roundTimeline rounds away float noise from adding and subtracting padding
+func roundTimeline(timeline []interfaces.TimelineRange) []interfaces.TimelineRange {
+	for i := range timeline {
+		timeline[i].Start = roundMillis(timeline[i].Start)
+		timeline[i].OriginalStart = roundMillis(timeline[i].OriginalStart)
+		timeline[i].Duration = roundMillis(timeline[i].Duration)
+	}
+	return timeline
+}
+
+func roundMillis(v float64) float64 {
+	return float64(int64(v*1000+0.5)) / 1000
+}
@@ -0,0 +1,40 @@
+package transcription
+
+import (
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+)
+
+// reportProgress keeps the latest adapter progress of a job and sends it to
+// the job's event stream and its owner's
+func (u *UnifiedTranscriptionService) reportProgress(job *models.TranscriptionJob, progress interfaces.Progress) {
+	u.progressMutex.Lock()
+	u.progress[job.ID] = progress
+	u.progressMutex.Unlock()
+
+	if u.broadcaster == nil {
+		return
+	}
+	u.broadcaster.Broadcast(job.ID, "progress", progress)
+	u.broadcaster.BroadcastToUser(job.UserID, "job_progress", map[string]interface{}{
+		"job_id":   job.ID,
+		"progress": progress,
+	})
+}
+
+// JobProgress returns the latest progress reported for a job while it is processed
+func (u *UnifiedTranscriptionService) JobProgress(jobID string) (interfaces.Progress, bool) {
+	u.progressMutex.RLock()
+	defer u.progressMutex.RUnlock()
+
+	progress, ok := u.progress[jobID]
+	return progress, ok
+}
+
+// clearProgress forgets a job's progress once it is no longer processed
+func (u *UnifiedTranscriptionService) clearProgress(jobID string) {
+	u.progressMutex.Lock()
+	defer u.progressMutex.Unlock()
+
+	delete(u.progress, jobID)
+}
@@ -0,0 +1,32 @@
+package transcription
+
+import (
+	"testing"
+	"time"
+
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportProgress(t *testing.T) {
+	service := NewUnifiedTranscriptionService(nil, t.TempDir(), t.TempDir())
+	job := &models.TranscriptionJob{ID: "job-1", UserID: 1}
+
+	_, ok := service.JobProgress(job.ID)
+	assert.False(t, ok)
+
+	// Without a broadcaster progress is only kept
+	percent := 40.0
+	service.reportProgress(job, interfaces.Progress{Stage: interfaces.StageAligning, Percent: &percent, UpdatedAt: time.Now()})
+	progress, ok := service.JobProgress(job.ID)
+	if assert.True(t, ok) {
+		assert.Equal(t, interfaces.StageAligning, progress.Stage)
+		assert.Equal(t, 40.0, *progress.Percent)
+	}
+
+	service.clearProgress(job.ID)
+	_, ok = service.JobProgress(job.ID)
+	assert.False(t, ok)
+}
@@ -3,7 +3,9 @@ package transcription
 import (
 	"context"
 	"os/exec"
+	"time"
 
+	"scriberr/internal/models"
 	"scriberr/internal/repository"
 	"scriberr/pkg/logger"
 )
@@ -45,6 +47,16 @@ func (u *UnifiedJobProcessor) ProcessJobWithProcess(ctx context.Context, jobID s
 	return u.unifiedService.ProcessJob(ctx, jobID)
 }
 
+// EstimateProcessingTime estimates how long processing a job takes
+func (u *UnifiedJobProcessor) EstimateProcessingTime(ctx context.Context, job *models.TranscriptionJob) (time.Duration, bool) {
+	return u.unifiedService.EstimateProcessingTime(ctx, job)
+}
+
+// RecordAudioDuration probes and records the length of a job's audio
+func (u *UnifiedJobProcessor) RecordAudioDuration(ctx context.Context, jobID string) (bool, error) {
+	return u.unifiedService.RecordAudioDuration(ctx, jobID)
+}
+
 // GetUnifiedService returns the underlying unified service for direct access to new features
 func (u *UnifiedJobProcessor) GetUnifiedService() *UnifiedTranscriptionService {
 	return u.unifiedService
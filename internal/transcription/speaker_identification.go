@@ -0,0 +1,102 @@
+package transcription
+
+import (
+	"context"
+	"sort"
+
+	"scriberr/internal/models"
+	"scriberr/internal/rag"
+	"scriberr/internal/repository"
+	"scriberr/internal/speakers"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+)
+
+// SetSpeakerLibrary enables matching the speakers of new jobs against the
+// speakers their owner enrolled
+func (u *UnifiedTranscriptionService) SetSpeakerLibrary(speakerRepo repository.SpeakerRepository, mappingRepo repository.SpeakerMappingRepository) {
+	u.speakerRepo = speakerRepo
+	u.speakerMappingRepo = mappingRepo
+}
+
+// identifySpeakers stores the voice embedding of each speaker in a job along
+// with the enrolled speaker it matches, naming the labels when the job asks
+// for it. Like search indexing this is secondary to transcription, so
+// failures are only logged.
+func (u *UnifiedTranscriptionService) identifySpeakers(ctx context.Context, job *models.TranscriptionJob, result *interfaces.TranscriptResult) {
+	if u.speakerRepo == nil || len(result.SpeakerEmbeddings) == 0 || result.EmbeddingModel == "" {
+		return
+	}
+
+	labels := make(map[string][]float32, len(result.SpeakerEmbeddings))
+	for label, embedding := range result.SpeakerEmbeddings {
+		if normalized := speakers.Normalize(embedding); normalized != nil {
+			labels[label] = normalized
+		}
+	}
+
+	voicePrints, err := u.speakerRepo.ListVoicePrints(ctx, job.UserID, result.EmbeddingModel)
+	if err != nil {
+		logger.Warn("Failed to load speaker library", "job_id", job.ID, "error", err)
+		return
+	}
+	candidates := make([]speakers.Candidate, len(voicePrints))
+	for i, vp := range voicePrints {
+		candidates[i] = speakers.Candidate{SpeakerID: vp.SpeakerID, Embedding: rag.DecodeVector(vp.Embedding)}
+	}
+
+	threshold := job.Parameters.SpeakerMatchThreshold
+	if threshold <= 0 {
+		threshold = speakers.DefaultMatchThreshold
+	}
+	matches := speakers.Assign(labels, candidates, threshold)
+
+	jobSpeakers := make([]models.JobSpeaker, 0, len(labels))
+	for label, embedding := range labels {
+		jobSpeaker := models.JobSpeaker{
+			TranscriptionJobID: job.ID,
+			Label:              label,
+			Model:              result.EmbeddingModel,
+			Embedding:          rag.EncodeVector(embedding),
+		}
+		if match, ok := matches[label]; ok {
+			jobSpeaker.SpeakerID = &match.SpeakerID
+			jobSpeaker.Similarity = &match.Similarity
+		}
+		jobSpeakers = append(jobSpeakers, jobSpeaker)
+	}
+	sort.Slice(jobSpeakers, func(i, j int) bool { return jobSpeakers[i].Label < jobSpeakers[j].Label })
+
+	if err := u.speakerRepo.ReplaceJobSpeakers(ctx, job.ID, jobSpeakers); err != nil {
+		logger.Warn("Failed to save speaker embeddings", "job_id", job.ID, "error", err)
+		return
+	}
+	logger.Info("Matched speakers against library", "job_id", job.ID, "speakers", len(jobSpeakers), "matched", len(matches))
+
+	if !job.Parameters.AutoApplySpeakerNames || len(matches) == 0 || u.speakerMappingRepo == nil {
+		return
+	}
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.SpeakerID)
+	}
+	found, err := u.speakerRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		logger.Warn("Failed to load matched speakers", "job_id", job.ID, "error", err)
+		return
+	}
+	nameByID := make(map[string]string, len(found))
+	for _, s := range found {
+		nameByID[s.ID] = s.Name
+	}
+	names := make(map[string]string, len(matches))
+	for label, match := range matches {
+		if name, ok := nameByID[match.SpeakerID]; ok {
+			names[label] = name
+		}
+	}
+	// Names the user already gave, such as on a rerun, are kept
+	if err := u.speakerMappingRepo.SetNames(ctx, job.ID, names, false); err != nil {
+		logger.Warn("Failed to apply speaker names", "job_id", job.ID, "error", err)
+	}
+}
@@ -0,0 +1,88 @@
+package transcription
+
+import (
+	"context"
+	"testing"
+
+	"scriberr/internal/models"
+	"scriberr/internal/rag"
+	"scriberr/internal/repository"
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestIdentifySpeakers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&models.TranscriptionJob{}, &models.SpeakerMapping{},
+		&models.Speaker{}, &models.SpeakerVoicePrint{}, &models.JobSpeaker{}))
+
+	ctx := context.Background()
+	speakerRepo := repository.NewSpeakerRepository(db)
+	mappingRepo := repository.NewSpeakerMappingRepository(db)
+	alice := models.Speaker{UserID: 1, Name: "Alice"}
+	bob := models.Speaker{UserID: 1, Name: "Bob"}
+	assert.NoError(t, speakerRepo.Create(ctx, &alice))
+	assert.NoError(t, speakerRepo.Create(ctx, &bob))
+	assert.NoError(t, speakerRepo.AddSample(ctx, alice.ID, "pyannote", []float32{1, 0, 0}))
+	assert.NoError(t, speakerRepo.AddSample(ctx, bob.ID, "pyannote", []float32{0, 1, 0}))
+	// Another user's speaker with the same voice is never matched
+	mallory := models.Speaker{UserID: 2, Name: "Mallory"}
+	assert.NoError(t, speakerRepo.Create(ctx, &mallory))
+	assert.NoError(t, speakerRepo.AddSample(ctx, mallory.ID, "pyannote", []float32{0, 0, 1}))
+
+	job := &models.TranscriptionJob{ID: "job-1", UserID: 1, AudioPath: "meeting.wav"}
+	job.Parameters.AutoApplySpeakerNames = true
+	assert.NoError(t, db.Create(job).Error)
+	// Names the user gave already are kept
+	assert.NoError(t, mappingRepo.SetNames(ctx, job.ID, map[string]string{"SPEAKER_01": "Robert"}, false))
+
+	service := NewUnifiedTranscriptionService(new(MockJobRepository), t.TempDir(), t.TempDir())
+	service.SetSpeakerLibrary(speakerRepo, mappingRepo)
+	service.identifySpeakers(ctx, job, &interfaces.TranscriptResult{
+		EmbeddingModel: "pyannote",
+		SpeakerEmbeddings: map[string][]float32{
+			"SPEAKER_00": {2, 0.2, 0},
+			"SPEAKER_01": {0.1, 1, 0},
+			"SPEAKER_02": {0, 0, 1},
+		},
+	})
+
+	jobSpeakers, err := speakerRepo.ListJobSpeakers(ctx, job.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, jobSpeakers, 3) {
+		assert.Equal(t, &alice.ID, jobSpeakers[0].SpeakerID)
+		if assert.NotNil(t, jobSpeakers[0].Similarity) {
+			assert.InDelta(t, 0.995, *jobSpeakers[0].Similarity, 1e-3)
+		}
+		assert.InDeltaSlice(t, []float32{0.995, 0.0995, 0}, rag.DecodeVector(jobSpeakers[0].Embedding), 1e-3)
+		assert.Equal(t, &bob.ID, jobSpeakers[1].SpeakerID)
+		assert.Nil(t, jobSpeakers[2].SpeakerID)
+	}
+
+	mappings, err := mappingRepo.ListByJob(ctx, job.ID)
+	assert.NoError(t, err)
+	names := map[string]string{}
+	for _, m := range mappings {
+		names[m.OriginalSpeaker] = m.CustomName
+	}
+	assert.Equal(t, map[string]string{"SPEAKER_00": "Alice", "SPEAKER_01": "Robert"}, names)
+
+	// Embeddings of another model are not compared
+	service.identifySpeakers(ctx, job, &interfaces.TranscriptResult{
+		EmbeddingModel:    "titanet",
+		SpeakerEmbeddings: map[string][]float32{"speaker_0": {1, 0, 0}},
+	})
+	jobSpeakers, err = speakerRepo.ListJobSpeakers(ctx, job.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, jobSpeakers, 1) {
+		assert.Nil(t, jobSpeakers[0].SpeakerID)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"scriberr/internal/models"
@@ -54,6 +55,23 @@ type UnifiedTranscriptionService struct {
 	jobRepo               repository.JobRepository
 	webhookService        *webhook.Service
 	broadcaster           *sse.Broadcaster
+	searchRepo            repository.SearchRepository
+	vocabularyRepo        repository.VocabularyRepository
+	speakerRepo           repository.SpeakerRepository
+	speakerMappingRepo    repository.SpeakerMappingRepository
+
+	// progress holds the latest adapter progress of each running job
+	progressMutex sync.RWMutex
+	progress      map[string]interfaces.Progress
+
+	// speeds caches processing speeds so queue estimates do not query the
+	// execution history for every pending job
+	speedMutex sync.Mutex
+	speeds     map[speedKey]cachedSpeed
+
+	// deferCompletionWebhook reports jobs whose completion webhook is sent later
+	// through SendCompletionWebhook, once follow-up work such as summarizing is done
+	deferCompletionWebhook func(ctx context.Context, jobID string) bool
 }
 
 // NewUnifiedTranscriptionService creates a new unified transcription service
@@ -71,6 +89,8 @@ func NewUnifiedTranscriptionService(jobRepo repository.JobRepository, tempDir, o
 		},
 		jobRepo:        jobRepo,
 		webhookService: webhook.NewService(),
+		progress:       make(map[string]interfaces.Progress),
+		speeds:         make(map[speedKey]cachedSpeed),
 	}
 }
 
@@ -79,6 +99,119 @@ func (u *UnifiedTranscriptionService) SetBroadcaster(b *sse.Broadcaster) {
 	u.broadcaster = b
 }
 
+// SetSearchRepository enables full-text indexing of saved transcripts
+func (u *UnifiedTranscriptionService) SetSearchRepository(repo repository.SearchRepository) {
+	u.searchRepo = repo
+}
+
+// SetVocabularyRepository enables the custom vocabularies jobs refer to
+func (u *UnifiedTranscriptionService) SetVocabularyRepository(repo repository.VocabularyRepository) {
+	u.vocabularyRepo = repo
+}
+
+// indexTranscript refreshes the search index for a job's stored transcript.
+// Search is secondary to transcription, so failures are only logged.
+func (u *UnifiedTranscriptionService) indexTranscript(ctx context.Context, jobID string) {
+	if u.searchRepo == nil {
+		return
+	}
+	if err := u.searchRepo.IndexTranscript(ctx, jobID); err != nil {
+		logger.Warn("Failed to index transcript for search", "job_id", jobID, "error", err)
+	}
+}
+
+// SetCompletionWebhookDeferral registers a check that holds back the completion
+// webhook of matching jobs so the caller can send it later with SendDeferredCompletionWebhook
+func (u *UnifiedTranscriptionService) SetCompletionWebhookDeferral(fn func(ctx context.Context, jobID string) bool) {
+	u.deferCompletionWebhook = fn
+}
+
+// SendDeferredCompletionWebhook sends the completion webhook that was held
+// back when the job completed, if there is one
+func (u *UnifiedTranscriptionService) SendDeferredCompletionWebhook(ctx context.Context, jobID string) error {
+	job, err := u.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if !job.WebhookDeferred {
+		return nil
+	}
+
+	sendErr := u.SendCompletionWebhook(ctx, jobID)
+	if err := u.jobRepo.SetWebhookDeferred(ctx, jobID, false); err != nil {
+		logger.Warn("Failed to clear completion webhook deferral", "job_id", jobID, "error", err)
+	}
+	return sendErr
+}
+
+// ResumeDeferredCompletionWebhooks sends, in the background, the completion
+// webhooks a previous run held back and never sent, e.g. because it stopped
+// during the auto summary. Call it before the queue starts, so that no job
+// found here is still waiting for its summary.
+func (u *UnifiedTranscriptionService) ResumeDeferredCompletionWebhooks(ctx context.Context) error {
+	jobs, err := u.jobRepo.FindWebhookDeferred(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find deferred webhooks: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	logger.Info("Sending completion webhooks deferred by the previous run", "count", len(jobs))
+	go func() {
+		for _, job := range jobs {
+			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := u.SendDeferredCompletionWebhook(webhookCtx, job.ID); err != nil {
+				logger.Error("Failed to send webhook", "job_id", job.ID, "error", err)
+			}
+			cancel()
+		}
+	}()
+	return nil
+}
+
+// SendCompletionWebhook sends the completion webhook for a job using its current
+// state, so anything stored after transcription (e.g. a summary) is included
+func (u *UnifiedTranscriptionService) SendCompletionWebhook(ctx context.Context, jobID string) error {
+	job, err := u.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if job.Parameters.CallbackURL == nil || *job.Parameters.CallbackURL == "" {
+		return nil
+	}
+
+	completedAt := time.Now()
+	var duration *int64
+	if execution, err := u.jobRepo.FindLatestCompletedExecution(ctx, jobID); err == nil {
+		duration = execution.ProcessingDuration
+		if execution.CompletedAt != nil {
+			completedAt = *execution.CompletedAt
+		}
+	}
+
+	payload := newWebhookPayload(job, job.Status, job.ErrorMessage, completedAt, duration)
+	return u.webhookService.SendWebhook(ctx, *job.Parameters.CallbackURL, payload)
+}
+
+// newWebhookPayload builds the webhook body describing a job outcome
+func newWebhookPayload(job *models.TranscriptionJob, status models.JobStatus, errorMessage *string, completedAt time.Time, durationMs *int64) webhook.WebhookPayload {
+	return webhook.WebhookPayload{
+		JobID:        job.ID,
+		Status:       status,
+		AudioPath:    job.AudioPath,
+		Transcript:   job.Transcript,
+		Summary:      job.Summary,
+		ErrorMessage: errorMessage,
+		CompletedAt:  completedAt,
+		Metadata: map[string]interface{}{
+			"model":        job.Parameters.Model,
+			"model_family": job.Parameters.ModelFamily,
+			"duration_ms":  durationMs,
+		},
+	}
+}
+
 // Initialize prepares all registered models for use
 func (u *UnifiedTranscriptionService) Initialize(ctx context.Context) error {
 	logger.Info("Initializing unified transcription service")
@@ -113,10 +246,16 @@ func (u *UnifiedTranscriptionService) ProcessJob(ctx context.Context, jobID stri
 	if err != nil {
 		return fmt.Errorf("failed to get job: %w", err)
 	}
+	defer u.clearProgress(jobID)
 
-	// Create execution record
+	// Create execution record; every queue attempt gets its own
+	attempt := job.Attempts
+	if attempt < 1 {
+		attempt = 1
+	}
 	execution := &models.TranscriptionJobExecution{
 		TranscriptionJobID: jobID,
+		Attempt:            attempt,
 		StartedAt:          startTime,
 		ActualParameters:   job.Parameters,
 		Status:             models.StatusProcessing,
@@ -158,20 +297,18 @@ func (u *UnifiedTranscriptionService) ProcessJob(ctx context.Context, jobID stri
 
 		// Trigger webhook if callback URL is present
 		if job.Parameters.CallbackURL != nil && *job.Parameters.CallbackURL != "" {
-			payload := webhook.WebhookPayload{
-				JobID:        job.ID,
-				Status:       status,
-				AudioPath:    job.AudioPath,
-				Transcript:   job.Transcript,
-				Summary:      job.Summary,
-				ErrorMessage: execution.ErrorMessage,
-				CompletedAt:  completedAt,
-				Metadata: map[string]interface{}{
-					"model":        job.Parameters.Model,
-					"model_family": job.Parameters.ModelFamily,
-					"duration_ms":  execution.ProcessingDuration,
-				},
+			if status == models.StatusCompleted && u.deferCompletionWebhook != nil {
+				// The decision is kept on the job, so the webhook is sent later
+				// even if whatever it depended on has changed by then
+				deferred := u.deferCompletionWebhook(ctx, job.ID)
+				if err := u.jobRepo.SetWebhookDeferred(ctx, job.ID, deferred); err != nil {
+					logger.Warn("Failed to record completion webhook deferral, sending it now", "job_id", job.ID, "error", err)
+				} else if deferred {
+					logger.Debug("Deferring completion webhook", "job_id", job.ID)
+					return
+				}
 			}
+			payload := newWebhookPayload(job, status, execution.ErrorMessage, completedAt, execution.ProcessingDuration)
 
 			// Send webhook asynchronously to not block the main process
 			go func() {
@@ -203,6 +340,15 @@ func (u *UnifiedTranscriptionService) ProcessJob(ctx context.Context, jobID stri
 		}
 	}
 
+	// Keep the result on the execution so it stays available as an alternate
+	// after later runs, and make it the job's active transcript
+	if processed, err := u.jobRepo.FindByID(ctx, jobID); err == nil && processed.Transcript != nil {
+		execution.Transcript = processed.Transcript
+		if err := u.jobRepo.SetActiveExecution(ctx, jobID, execution.ID); err != nil {
+			logger.Warn("Failed to set active execution", "job_id", jobID, "execution_id", execution.ID, "error", err)
+		}
+	}
+
 	// Success
 	updateExecutionStatus(models.StatusCompleted, "")
 	logger.Info("Job processed successfully", "job_id", jobID, "duration", time.Since(startTime))
@@ -221,6 +367,9 @@ func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context,
 		OutputDirectory: filepath.Join(u.outputDirectory, job.ID),
 		TempDirectory:   u.tempDirectory,
 		Metadata:        map[string]string{},
+		Progress: func(progress interfaces.Progress) {
+			u.reportProgress(job, progress)
+		},
 	}
 
 	// Create output directory
@@ -233,6 +382,12 @@ func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context,
 	if err != nil {
 		return fmt.Errorf("failed to create audio input: %w", err)
 	}
+	if seconds := audioInput.Duration.Seconds(); seconds > 0 {
+		// Queue estimates scale with the audio length of finished jobs
+		if err := u.jobRepo.SetAudioDuration(ctx, job.ID, seconds); err != nil {
+			logger.Warn("Failed to record audio duration", "job_id", job.ID, "error", err)
+		}
+	}
 
 	// Determine models to use first
 	transcriptionModelID, diarizationModelID, err := u.selectModels(job.Parameters)
@@ -257,6 +412,9 @@ func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context,
 	}
 
 	// Apply preprocessing
+	for key, value := range preprocessingMetadata(job.Parameters) {
+		audioInput.Metadata[key] = value
+	}
 	preprocessedInput, err = u.pipeline.ProcessAudio(ctx, audioInput, capabilities)
 	if err != nil {
 		logger.Warn("Audio preprocessing failed, using original", "error", err)
@@ -288,6 +446,11 @@ func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context,
 
 	var transcriptResult *interfaces.TranscriptResult
 	var diarizationResult *interfaces.DiarizationResult
+	vocabulary := u.loadVocabulary(ctx, job.UserID, job.Parameters)
+
+	// Long recordings are transcribed in chunks, which cannot diarize
+	// consistently; speakers are then found over the whole recording at once
+	chunked := transcriptionModelID != "" && shouldChunk(job.Parameters, preprocessedInput)
 
 	// Perform transcription using the preprocessed audio
 	if transcriptionModelID != "" {
@@ -305,8 +468,14 @@ func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context,
 
 		// Convert parameters for this specific model
 		params := u.convertParametersForModel(job.Parameters, transcriptionModelID)
+		applyVocabularyTerms(params, transcriptionModelID, vocabulary.Terms)
 
-		transcriptResult, err = transcriptionAdapter.Transcribe(ctx, preprocessedInput, params, procCtx)
+		if chunked {
+			params["diarize"] = false
+			transcriptResult, err = u.transcribeInChunks(ctx, job, transcriptionAdapter, preprocessedInput, params, procCtx)
+		} else {
+			transcriptResult, err = transcriptionAdapter.Transcribe(ctx, preprocessedInput, params, procCtx)
+		}
 		if err != nil {
 			return fmt.Errorf("transcription failed: %w", err)
 		}
@@ -317,7 +486,7 @@ func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context,
 		// Convert parameters for diarization model
 		diarizationParams := u.convertParametersForModel(job.Parameters, diarizationModelID)
 
-		if !u.transcriptionIncludesDiarization(transcriptionModelID, job.Parameters) {
+		if chunked || !u.transcriptionIncludesDiarization(transcriptionModelID, job.Parameters) {
 			logger.Info("Running separate diarization", "model_id", diarizationModelID)
 			diarizationAdapter, err := u.registry.GetDiarizationAdapter(diarizationModelID)
 			if err != nil {
@@ -343,11 +512,27 @@ func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context,
 		}
 	}
 
+	// Move timestamps back onto the original recording if silence was cut,
+	// and note which audio filters ran
+	pipeline.RemapTranscript(transcriptResult, preprocessedInput.Timeline)
+	pipeline.RecordAudioFilters(transcriptResult, preprocessedInput)
+
+	// Apply the text postprocessors the job enabled
+	if transcriptResult != nil {
+		transcriptResult, _ = u.pipeline.ProcessTranscript(ctx, transcriptResult, capabilities, postprocessingParams(job.Parameters, vocabulary))
+	}
+
 	// Save results to database
 	if transcriptResult != nil {
 		if err := u.saveTranscriptionResults(job.ID, transcriptResult); err != nil {
 			return fmt.Errorf("failed to save transcription results: %w", err)
 		}
+		if chunked {
+			if err := u.jobRepo.DeleteChunksByJobID(ctx, job.ID); err != nil {
+				logger.Warn("Failed to clean up chunk checkpoints", "job_id", job.ID, "error", err)
+			}
+		}
+		u.identifySpeakers(ctx, job, transcriptResult)
 	}
 
 	return nil
@@ -389,6 +574,20 @@ func (u *UnifiedTranscriptionService) IsMultiTrackJob(jobID string) bool {
 
 // selectModels determines which models to use based on job parameters
 func (u *UnifiedTranscriptionService) selectModels(params models.WhisperXParams) (transcriptionModelID, diarizationModelID string, err error) {
+	transcriptionModelID, diarizationModelID = modelsFor(params)
+
+	logger.Info("Selected models",
+		"transcription", transcriptionModelID,
+		"diarization", diarizationModelID,
+		"original_family", params.ModelFamily,
+		"original_diarize_model", params.DiarizeModel)
+
+	return transcriptionModelID, diarizationModelID, nil
+}
+
+// modelsFor maps job parameters to the transcription model and, if
+// diarization is requested, the diarization model that process them
+func modelsFor(params models.WhisperXParams) (transcriptionModelID, diarizationModelID string) {
 	// Determine transcription model
 	switch params.ModelFamily {
 	case FamilyNvidiaParakeet:
@@ -416,14 +615,7 @@ func (u *UnifiedTranscriptionService) selectModels(params models.WhisperXParams)
 			diarizationModelID = ModelPyannote // Default fallback
 		}
 	}
-
-	logger.Info("Selected models",
-		"transcription", transcriptionModelID,
-		"diarization", diarizationModelID,
-		"original_family", params.ModelFamily,
-		"original_diarize_model", params.DiarizeModel)
-
-	return transcriptionModelID, diarizationModelID, nil
+	return transcriptionModelID, diarizationModelID
 }
 
 // transcriptionIncludesDiarization checks if the transcription model already includes diarization
@@ -697,6 +889,7 @@ func (u *UnifiedTranscriptionService) convertToWhisperXParams(params models.Whis
 	// diarization is run as a separate adapter step, so we intentionally omit diarize_model.
 	if useWhisperXDiarization {
 		paramMap["diarize_model"] = params.DiarizeModel
+		paramMap["speaker_embeddings"] = params.SpeakerEmbeddings
 	}
 
 	// Handle pointer fields - only add if not nil
@@ -734,6 +927,7 @@ func (u *UnifiedTranscriptionService) convertToPyannoteParams(params models.Whis
 		"output_format":      OutputFormatJSON,
 		"auto_convert_audio": true,
 		"device":             "auto",
+		"speaker_embeddings": params.SpeakerEmbeddings,
 	}
 
 	if params.MinSpeakers != nil {
@@ -763,6 +957,7 @@ func (u *UnifiedTranscriptionService) convertToSortformerParams(params models.Wh
 	return map[string]interface{}{
 		"output_format":      OutputFormatJSON,
 		"auto_convert_audio": true,
+		"speaker_embeddings": params.SpeakerEmbeddings,
 		// Sortformer is optimized for 4 speakers, no additional config needed
 	}
 }
@@ -843,6 +1038,99 @@ func (u *UnifiedTranscriptionService) parametersToMap(params models.WhisperXPara
 	return paramMap
 }
 
+// jobVocabulary is the combined content of the vocabularies a job uses
+type jobVocabulary struct {
+	Terms []string
+	Rules []models.ReplacementRule
+}
+
+// loadVocabulary combines the vocabularies params refer to, in order.
+// Vocabularies deleted since the job was configured, and those userID may
+// not apply, are skipped.
+func (u *UnifiedTranscriptionService) loadVocabulary(ctx context.Context, userID uint, params models.WhisperXParams) jobVocabulary {
+	var vocabulary jobVocabulary
+	if u.vocabularyRepo == nil || len(params.VocabularyIDs) == 0 {
+		return vocabulary
+	}
+	found, err := u.vocabularyRepo.FindUsableByIDs(ctx, userID, params.VocabularyIDs)
+	if err != nil {
+		logger.Warn("Failed to load vocabularies, transcribing without them", "error", err)
+		return vocabulary
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range found {
+		for _, term := range v.Terms {
+			term = strings.TrimSpace(term)
+			key := strings.ToLower(term)
+			if term == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			vocabulary.Terms = append(vocabulary.Terms, term)
+		}
+		vocabulary.Rules = append(vocabulary.Rules, v.Rules...)
+	}
+	return vocabulary
+}
+
+// applyVocabularyTerms passes vocabulary terms to the models that take hints:
+// WhisperX as hotwords and the OpenAI API as a glossary after the prompt,
+// where it survives the API keeping only the end of long prompts. Other
+// models rely on the replacement rules alone.
+func applyVocabularyTerms(paramMap map[string]interface{}, modelID string, terms []string) {
+	if len(terms) == 0 {
+		return
+	}
+	switch modelID {
+	case ModelWhisperX:
+		paramMap["hotwords"] = strings.Join(terms, ", ")
+	case ModelOpenAI:
+		prompt, _ := paramMap["prompt"].(string)
+		paramMap["prompt"] = strings.TrimSpace(prompt + " Glossary: " + strings.Join(terms, ", ") + ".")
+	}
+}
+
+// postprocessingParams returns the parameters the transcript postprocessors read
+func postprocessingParams(params models.WhisperXParams, vocabulary jobVocabulary) map[string]interface{} {
+	paramMap := map[string]interface{}{
+		pipeline.ParamRemoveFillerWords:    params.RemoveFillerWords,
+		pipeline.ParamNormalizeNumbers:     params.NormalizeNumbers,
+		pipeline.ParamRepairCapitalization: params.RepairCapitalization,
+		pipeline.ParamReplacementRules:     vocabulary.Rules,
+	}
+	if params.Language != nil {
+		paramMap[pipeline.ParamLanguage] = *params.Language
+	}
+	return paramMap
+}
+
+// preprocessingMetadata passes the job's preprocessing options to the
+// pipeline, which reads them from the audio input's metadata
+func preprocessingMetadata(params models.WhisperXParams) map[string]string {
+	metadata := map[string]string{}
+	if params.HighpassFrequency > 0 {
+		metadata[pipeline.MetadataHighpassFrequency] = strconv.Itoa(params.HighpassFrequency)
+	}
+	if params.LowpassFrequency > 0 {
+		metadata[pipeline.MetadataLowpassFrequency] = strconv.Itoa(params.LowpassFrequency)
+	}
+	if params.Denoise {
+		metadata[pipeline.MetadataDenoise] = "true"
+		metadata[pipeline.MetadataDenoiseStrength] = strconv.FormatFloat(params.DenoiseStrength, 'f', -1, 64)
+	}
+	if params.NormalizeLoudness {
+		metadata[pipeline.MetadataNormalizeLoudness] = "true"
+		metadata[pipeline.MetadataLoudnessTarget] = strconv.FormatFloat(params.LoudnessTarget, 'f', -1, 64)
+	}
+	if params.TrimSilence {
+		metadata[pipeline.MetadataTrimSilence] = "true"
+		metadata[pipeline.MetadataSilenceThreshold] = strconv.FormatFloat(params.SilenceThreshold, 'f', -1, 64)
+		metadata[pipeline.MetadataMinSilenceDuration] = strconv.FormatFloat(params.MinSilenceDuration, 'f', -1, 64)
+	}
+	return metadata
+}
+
 // mergeDiarizationWithTranscription combines diarization results with transcription
 func (u *UnifiedTranscriptionService) mergeDiarizationWithTranscription(transcript *interfaces.TranscriptResult, diarization *interfaces.DiarizationResult) *interfaces.TranscriptResult {
 	logger.Info("Merging diarization with transcription",
@@ -877,6 +1165,10 @@ func (u *UnifiedTranscriptionService) mergeDiarizationWithTranscription(transcri
 		}
 	}
 
+	// Voice embeddings come with the diarization
+	mergedTranscript.SpeakerEmbeddings = diarization.SpeakerEmbeddings
+	mergedTranscript.EmbeddingModel = diarization.EmbeddingModel
+
 	return &mergedTranscript
 }
 
@@ -912,6 +1204,7 @@ func (u *UnifiedTranscriptionService) saveTranscriptionResults(jobID string, res
 	if err := u.jobRepo.UpdateTranscript(context.Background(), jobID, resultJSON); err != nil {
 		return fmt.Errorf("failed to update job transcript: %w", err)
 	}
+	u.indexTranscript(context.Background(), jobID)
 
 	logger.Info("Saved transcription results", "job_id", jobID, "text_length", len(result.Text))
 	return nil
@@ -933,6 +1226,72 @@ func (u *UnifiedTranscriptionService) GetSupportedModels() map[string]interfaces
 	return u.registry.GetAllCapabilities()
 }
 
+// GetTranscriptionModels returns the IDs of all registered transcription models
+func (u *UnifiedTranscriptionService) GetTranscriptionModels() []string {
+	return u.registry.GetTranscriptionModels()
+}
+
+// TranscribeWithModel transcribes an audio file with one specific model,
+// without creating or updating a job. Model outputs are written to a
+// directory named after runID and removed afterwards. Vocabularies are
+// applied as userID may. It is used to compare models on the same recording.
+func (u *UnifiedTranscriptionService) TranscribeWithModel(ctx context.Context, runID string, userID uint, audioPath, modelID string, params models.WhisperXParams) (*interfaces.TranscriptResult, error) {
+	adapter, err := u.registry.GetTranscriptionAdapter(modelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcription adapter: %w", err)
+	}
+
+	procCtx := interfaces.ProcessingContext{
+		JobID:           runID,
+		OutputDirectory: filepath.Join(u.outputDirectory, "comparisons", runID+"_"+modelID),
+		TempDirectory:   u.tempDirectory,
+		Metadata:        map[string]string{},
+	}
+	if err := os.MkdirAll(procCtx.OutputDirectory, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	defer os.RemoveAll(procCtx.OutputDirectory)
+
+	audioInput, err := u.createAudioInput(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audio input: %w", err)
+	}
+	for key, value := range preprocessingMetadata(params) {
+		audioInput.Metadata[key] = value
+	}
+	input, err := u.pipeline.ProcessAudio(ctx, audioInput, adapter.GetCapabilities())
+	if err != nil {
+		logger.Warn("Audio preprocessing failed, using original", "error", err)
+		input = audioInput
+	} else if input.TempFilePath != "" && input.TempFilePath != audioInput.FilePath {
+		defer os.Remove(input.TempFilePath)
+	}
+
+	if !adapter.IsReady(ctx) {
+		logger.Info("Preparing transcription model environment on demand", "model_id", modelID)
+		if err := adapter.PrepareEnvironment(ctx); err != nil {
+			return nil, fmt.Errorf("failed to prepare transcription model %s: %w", modelID, err)
+		}
+	}
+
+	vocabulary := u.loadVocabulary(ctx, userID, params)
+	modelParams := u.convertParametersForModel(params, modelID)
+	applyVocabularyTerms(modelParams, modelID, vocabulary.Terms)
+
+	startTime := time.Now()
+	result, err := adapter.Transcribe(ctx, input, modelParams, procCtx)
+	if err != nil {
+		return nil, fmt.Errorf("transcription failed: %w", err)
+	}
+	// Not every adapter measures itself
+	if result.ProcessingTime == 0 {
+		result.ProcessingTime = time.Since(startTime)
+	}
+	pipeline.RemapTranscript(result, input.Timeline)
+	pipeline.RecordAudioFilters(result, input)
+	return u.pipeline.ProcessTranscript(ctx, result, adapter.GetCapabilities(), postprocessingParams(params, vocabulary))
+}
+
 // GetModelStatus returns the status of all models
 func (u *UnifiedTranscriptionService) GetModelStatus(ctx context.Context) map[string]bool {
 	return u.registry.GetModelStatus(ctx)
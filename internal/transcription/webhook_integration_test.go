@@ -69,3 +69,91 @@ func TestWebhookIntegration_Failure(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestDeferredCompletionWebhookIsSentOnce(t *testing.T) {
+	mockRepo := new(MockJobRepository)
+
+	received := make(chan webhook.WebhookPayload, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhook.WebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewUnifiedTranscriptionService(mockRepo, t.TempDir(), t.TempDir())
+	callbackURL := server.URL
+	deferred := &models.TranscriptionJob{
+		ID:              "deferred-job",
+		Status:          models.StatusCompleted,
+		WebhookDeferred: true,
+		Parameters:      models.WhisperXParams{CallbackURL: &callbackURL},
+	}
+	sent := *deferred
+	sent.ID = "sent-job"
+	sent.WebhookDeferred = false
+
+	mockRepo.On("FindByID", mock.Anything, deferred.ID).Return(deferred, nil)
+	mockRepo.On("FindByID", mock.Anything, sent.ID).Return(&sent, nil)
+	mockRepo.On("FindLatestCompletedExecution", mock.Anything, deferred.ID).Return(nil, assert.AnError)
+	mockRepo.On("SetWebhookDeferred", mock.Anything, deferred.ID, false).Return(nil).Once()
+
+	// A held back webhook is sent and the deferral cleared
+	assert.NoError(t, service.SendDeferredCompletionWebhook(context.Background(), deferred.ID))
+	select {
+	case payload := <-received:
+		assert.Equal(t, deferred.ID, payload.JobID)
+		assert.Equal(t, models.StatusCompleted, payload.Status)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Deferred webhook was not sent")
+	}
+
+	// A webhook sent on completion is not sent again
+	assert.NoError(t, service.SendDeferredCompletionWebhook(context.Background(), sent.ID))
+	assert.Empty(t, received)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeferredCompletionWebhooksAreResumedOnStartup(t *testing.T) {
+	mockRepo := new(MockJobRepository)
+
+	received := make(chan webhook.WebhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhook.WebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewUnifiedTranscriptionService(mockRepo, t.TempDir(), t.TempDir())
+	callbackURL := server.URL
+	deferred := models.TranscriptionJob{
+		ID:              "left-deferred-job",
+		Status:          models.StatusCompleted,
+		WebhookDeferred: true,
+		Parameters:      models.WhisperXParams{CallbackURL: &callbackURL},
+	}
+
+	mockRepo.On("FindWebhookDeferred", mock.Anything).Return([]models.TranscriptionJob{deferred}, nil)
+	mockRepo.On("FindByID", mock.Anything, deferred.ID).Return(&deferred, nil)
+	mockRepo.On("FindLatestCompletedExecution", mock.Anything, deferred.ID).Return(nil, assert.AnError)
+	cleared := make(chan struct{})
+	mockRepo.On("SetWebhookDeferred", mock.Anything, deferred.ID, false).Return(nil).Once().Run(func(mock.Arguments) { close(cleared) })
+
+	// A webhook held back by the previous run is sent on startup
+	assert.NoError(t, service.ResumeDeferredCompletionWebhooks(context.Background()))
+	select {
+	case payload := <-received:
+		assert.Equal(t, deferred.ID, payload.JobID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Deferred webhook was not sent")
+	}
+	select {
+	case <-cleared:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Deferral was not cleared")
+	}
+	mockRepo.AssertExpectations(t)
+}
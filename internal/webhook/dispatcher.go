@@ -0,0 +1,515 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"scriberr/internal/models"
+	"scriberr/internal/repository"
+	"scriberr/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// Events a subscription can receive
+const (
+	EventJobCreated     = "job.created"
+	EventJobStarted     = "job.started"
+	EventJobCompleted   = "job.completed"
+	EventJobFailed      = "job.failed"
+	EventSummaryCreated = "summary.created"
+	EventNoteCreated    = "note.created"
+)
+
+// Events lists every event a subscription can receive
+var Events = []string{
+	EventJobCreated,
+	EventJobStarted,
+	EventJobCompleted,
+	EventJobFailed,
+	EventSummaryCreated,
+	EventNoteCreated,
+}
+
+// IsEvent reports whether name is an event subscriptions can receive
+func IsEvent(name string) bool {
+	for _, event := range Events {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Headers sent with every subscription delivery
+const (
+	HeaderEvent     = "X-Scriberr-Event"
+	HeaderDelivery  = "X-Scriberr-Delivery"
+	HeaderTimestamp = "X-Scriberr-Timestamp"
+	HeaderSignature = "X-Scriberr-Signature"
+)
+
+// maxResponseBody is how much of a receiver's response is kept in the delivery log
+const maxResponseBody = 2048
+
+// Event is the body of a subscription delivery
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// JobEventData describes the job a job.* event is about
+type JobEventData struct {
+	JobID        string           `json:"job_id"`
+	Title        *string          `json:"title,omitempty"`
+	Status       models.JobStatus `json:"status"`
+	Attempts     int              `json:"attempts"`
+	ErrorMessage *string          `json:"error_message,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// NewJobEventData describes job for a job.* event
+func NewJobEventData(job *models.TranscriptionJob) JobEventData {
+	return JobEventData{
+		JobID:        job.ID,
+		Title:        job.Title,
+		Status:       job.Status,
+		Attempts:     job.Attempts,
+		ErrorMessage: job.ErrorMessage,
+		CreatedAt:    job.CreatedAt,
+		UpdatedAt:    job.UpdatedAt,
+	}
+}
+
+// SummaryEventData describes the summary of a summary.created event
+type SummaryEventData struct {
+	SummaryID  string    `json:"summary_id"`
+	JobID      string    `json:"job_id"`
+	TemplateID *string   `json:"template_id,omitempty"`
+	Model      string    `json:"model"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewSummaryEventData describes summary for a summary.created event
+func NewSummaryEventData(summary *models.Summary) SummaryEventData {
+	return SummaryEventData{
+		SummaryID:  summary.ID,
+		JobID:      summary.TranscriptionID,
+		TemplateID: summary.TemplateID,
+		Model:      summary.Model,
+		Content:    summary.Content,
+		CreatedAt:  summary.CreatedAt,
+	}
+}
+
+// NoteEventData describes the note of a note.created event
+type NoteEventData struct {
+	NoteID    string    `json:"note_id"`
+	JobID     string    `json:"job_id"`
+	StartTime float64   `json:"start_time"`
+	EndTime   float64   `json:"end_time"`
+	Quote     string    `json:"quote"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewNoteEventData describes note for a note.created event
+func NewNoteEventData(note *models.Note) NoteEventData {
+	return NoteEventData{
+		NoteID:    note.ID,
+		JobID:     note.TranscriptionID,
+		StartTime: note.StartTime,
+		EndTime:   note.EndTime,
+		Quote:     note.Quote,
+		Content:   note.Content,
+		CreatedAt: note.CreatedAt,
+	}
+}
+
+// NewSecret generates a signing secret for a subscription
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value for a delivery body sent at
+// timestamp: the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the
+// subscription's secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature is the signature of body sent at timestamp
+func VerifySignature(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// ErrPrivateTarget is returned when a delivery would connect to a loopback,
+// private or link-local address
+var ErrPrivateTarget = errors.New("webhook target is a private or loopback address")
+
+// IsPrivateAddress reports whether ip is a loopback, private (RFC 1918, IPv6
+// unique local, carrier-grade NAT), link-local or unspecified address, none of
+// which a subscription may reach unless private targets are allowed
+func IsPrivateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Dispatcher delivers events to webhook subscriptions. Publishing records a
+// pending delivery per subscription; a background loop sends due deliveries
+// and reschedules failed ones with exponential backoff until they run out of
+// attempts. Each subscription's deliveries are sent in order: one waiting to
+// be retried holds back the later ones until it succeeds or fails for good.
+// Several subscriptions are delivered to at once, so a slow or failing
+// receiver only holds up its own events. Deliveries live in the database, so
+// retries survive a restart.
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+
+	MaxAttempts    int           // Attempts before a delivery is marked failed
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for the delay between attempts
+	PollInterval   time.Duration // How often due retries are looked for
+	Concurrency    int           // Subscriptions delivered to at once
+	// AllowPrivateTargets lets deliveries reach loopback and private network
+	// addresses, for receivers on the same host or LAN
+	AllowPrivateTargets bool
+
+	wake     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDispatcher creates a dispatcher for the subscriptions in repo
+func NewDispatcher(repo repository.WebhookRepository) *Dispatcher {
+	d := &Dispatcher{
+		repo:           repo,
+		MaxAttempts:    8,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     time.Hour,
+		PollInterval:   5 * time.Second,
+		Concurrency:    4,
+		wake:           make(chan struct{}, 1),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	// Targets are checked when connecting, after DNS resolution and on every
+	// redirect, so a hostname cannot be pointed at an internal address later.
+	// Deliveries never go through a proxy, which would hide the target.
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: d.checkTarget}
+	d.client = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+	return d
+}
+
+// checkTarget refuses connections to private addresses unless they are allowed
+func (d *Dispatcher) checkTarget(network, address string, _ syscall.RawConn) error {
+	if d.AllowPrivateTargets {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsPrivateAddress(ip) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
+
+// Start runs the delivery loop in the background
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+// Stop ends the delivery loop. Deliveries still pending are sent after the next start.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+		<-d.done
+	})
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.deliverDue(context.Background())
+		select {
+		case <-d.wake:
+		case <-ticker.C:
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// notify wakes the delivery loop without blocking
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Publish queues event for every active subscription of userID that wants
+// it. It is safe to call on a nil dispatcher, which drops the event.
+func (d *Dispatcher) Publish(ctx context.Context, userID uint, event string, data interface{}) {
+	if d == nil {
+		return
+	}
+
+	subscriptions, err := d.repo.ListActiveSubscriptions(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to load webhook subscriptions", "user_id", userID, "event", event, "error", err)
+		return
+	}
+
+	var payload []byte
+	var eventID string
+	queued := false
+	for i := range subscriptions {
+		if !subscriptions[i].Subscribes(event) {
+			continue
+		}
+		if payload == nil {
+			eventID = uuid.New().String()
+			payload, err = json.Marshal(Event{ID: eventID, Type: event, CreatedAt: time.Now().UTC(), Data: data})
+			if err != nil {
+				logger.Error("Failed to marshal webhook event", "event", event, "error", err)
+				return
+			}
+		}
+
+		now := time.Now()
+		delivery := &models.WebhookDelivery{
+			SubscriptionID: subscriptions[i].ID,
+			EventID:        eventID,
+			Event:          event,
+			Payload:        string(payload),
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  &now,
+		}
+		if err := d.repo.CreateDelivery(ctx, delivery); err != nil {
+			logger.Warn("Failed to queue webhook delivery", "subscription_id", subscriptions[i].ID, "event", event, "error", err)
+			continue
+		}
+		queued = true
+	}
+	if queued {
+		d.notify()
+	}
+}
+
+// Redeliver queues a new delivery of the same event as delivery
+func (d *Dispatcher) Redeliver(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	redelivery := &models.WebhookDelivery{
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Payload:        delivery.Payload,
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+		RedeliveryOf:   &delivery.ID,
+	}
+	if err := d.repo.CreateDelivery(ctx, redelivery); err != nil {
+		return nil, fmt.Errorf("failed to queue redelivery: %w", err)
+	}
+	d.notify()
+	return redelivery, nil
+}
+
+// deliverDue sends every delivery whose next attempt is due, one subscription
+// at a time per goroutine and at most Concurrency subscriptions at once
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for {
+		deliveries, err := d.repo.ListDueDeliveries(ctx, time.Now(), 50)
+		if err != nil {
+			logger.Error("Failed to load due webhook deliveries", "error", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+		d.deliverBySubscription(ctx, deliveries)
+		if len(deliveries) < 50 {
+			return
+		}
+	}
+}
+
+// deliverBySubscription sends deliveries concurrently across subscriptions,
+// keeping their order within each subscription, and waits until all are sent
+func (d *Dispatcher) deliverBySubscription(ctx context.Context, deliveries []models.WebhookDelivery) {
+	groups := make(map[string][]*models.WebhookDelivery)
+	var order []string
+	for i := range deliveries {
+		id := deliveries[i].SubscriptionID
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], &deliveries[i])
+	}
+
+	slots := make(chan struct{}, max(d.Concurrency, 1))
+	var wg sync.WaitGroup
+	for _, id := range order {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(group []*models.WebhookDelivery) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			for _, delivery := range group {
+				if !d.deliver(ctx, delivery) {
+					// The rest waits until this one is retried
+					return
+				}
+			}
+		}(groups[id])
+	}
+	wg.Wait()
+}
+
+// deliver makes one attempt at a delivery and records the outcome. It reports
+// false when the delivery is to be retried.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) bool {
+	subscription, err := d.repo.FindSubscriptionByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		d.finish(ctx, delivery, models.WebhookDeliveryFailed, "Subscription not found")
+		return true
+	}
+	if !subscription.Active {
+		d.finish(ctx, delivery, models.WebhookDeliveryFailed, "Subscription is inactive")
+		return true
+	}
+
+	delivery.Attempts++
+	status, body, err := d.send(ctx, subscription, delivery)
+	if status != 0 {
+		delivery.ResponseStatus = &status
+		delivery.ResponseBody = &body
+	}
+	if err == nil {
+		now := time.Now()
+		delivery.DeliveredAt = &now
+		d.finish(ctx, delivery, models.WebhookDeliverySucceeded, "")
+		return true
+	}
+
+	if delivery.Attempts >= d.MaxAttempts {
+		logger.Warn("Webhook delivery failed", "delivery_id", delivery.ID, "event", delivery.Event, "attempts", delivery.Attempts, "error", err)
+		d.finish(ctx, delivery, models.WebhookDeliveryFailed, err.Error())
+		return true
+	}
+
+	next := time.Now().Add(d.backoff(delivery.Attempts))
+	message := err.Error()
+	delivery.NextAttemptAt = &next
+	delivery.Error = &message
+	logger.Debug("Webhook delivery attempt failed, retrying", "delivery_id", delivery.ID, "attempt", delivery.Attempts, "retry_at", next, "error", err)
+	if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+		logger.Error("Failed to update webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+	return false
+}
+
+// finish records the final state of a delivery
+func (d *Dispatcher) finish(ctx context.Context, delivery *models.WebhookDelivery, status models.WebhookDeliveryStatus, message string) {
+	delivery.Status = status
+	delivery.NextAttemptAt = nil
+	delivery.Error = nil
+	if message != "" {
+		delivery.Error = &message
+	}
+	if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+		logger.Error("Failed to update webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// send posts a delivery's payload, signed with the subscription's secret, and
+// returns the response status and the start of its body
+func (d *Dispatcher) send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Scriberr-Webhook/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(response), fmt.Errorf("webhook returned non-success status: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(response), nil
+}
+
+// backoff returns the delay after the given number of failed attempts,
+// doubling from InitialBackoff up to MaxBackoff
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.InitialBackoff
+	for i := 1; i < attempts && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxBackoff {
+		delay = d.MaxBackoff
+	}
+	return delay
+}
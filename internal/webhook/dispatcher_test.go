@@ -0,0 +1,337 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"scriberr/internal/models"
+	"scriberr/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newTestRepository(t *testing.T) repository.WebhookRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}))
+	return repository.NewWebhookRepository(db)
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"type":"job.completed"}`)
+	signature := Sign("whsec_test", 1700000000, body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.True(t, VerifySignature("whsec_test", 1700000000, body, signature))
+	assert.False(t, VerifySignature("whsec_other", 1700000000, body, signature))
+	assert.False(t, VerifySignature("whsec_test", 1700000001, body, signature))
+	assert.False(t, VerifySignature("whsec_test", 1700000000, []byte(`{}`), signature))
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil)
+	d.InitialBackoff = 30 * time.Second
+	d.MaxBackoff = 5 * time.Minute
+
+	assert.Equal(t, 30*time.Second, d.backoff(1))
+	assert.Equal(t, time.Minute, d.backoff(2))
+	assert.Equal(t, 4*time.Minute, d.backoff(4))
+	assert.Equal(t, 5*time.Minute, d.backoff(5))
+	assert.Equal(t, 5*time.Minute, d.backoff(30))
+}
+
+func TestDispatcherDeliversSignedEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	subscribed := &models.WebhookSubscription{UserID: 1, URL: server.URL, Events: []string{EventJobCompleted}, Secret: "whsec_a", Active: true}
+	otherEvent := &models.WebhookSubscription{UserID: 1, URL: server.URL, Events: []string{EventNoteCreated}, Secret: "whsec_b", Active: true}
+	otherUser := &models.WebhookSubscription{UserID: 2, URL: server.URL, Events: []string{EventJobCompleted}, Secret: "whsec_c", Active: true}
+	for _, s := range []*models.WebhookSubscription{subscribed, otherEvent, otherUser} {
+		assert.NoError(t, repo.CreateSubscription(ctx, s))
+	}
+
+	d := NewDispatcher(repo)
+	d.AllowPrivateTargets = true // the test server listens on loopback
+	d.Publish(ctx, 1, EventJobCompleted, NewJobEventData(&models.TranscriptionJob{ID: "job-1", Status: models.StatusCompleted}))
+	d.deliverDue(ctx)
+
+	if !assert.Len(t, received, 1) {
+		return
+	}
+	r := received[0]
+	assert.Equal(t, EventJobCompleted, r.Header.Get(HeaderEvent))
+	timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	assert.NoError(t, err)
+	assert.True(t, VerifySignature("whsec_a", timestamp, bodies[0], r.Header.Get(HeaderSignature)))
+
+	var event struct {
+		ID   string       `json:"id"`
+		Type string       `json:"type"`
+		Data JobEventData `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(bodies[0], &event))
+	assert.Equal(t, EventJobCompleted, event.Type)
+	assert.Equal(t, "job-1", event.Data.JobID)
+
+	deliveries, err := repo.ListDeliveries(ctx, subscribed.ID, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, r.Header.Get(HeaderDelivery), deliveries[0].ID)
+		assert.Equal(t, event.ID, deliveries[0].EventID)
+		assert.Equal(t, models.WebhookDeliverySucceeded, deliveries[0].Status)
+		assert.Equal(t, 1, deliveries[0].Attempts)
+		assert.Equal(t, 200, *deliveries[0].ResponseStatus)
+		assert.Equal(t, "ok", *deliveries[0].ResponseBody)
+		assert.NotNil(t, deliveries[0].DeliveredAt)
+		assert.Nil(t, deliveries[0].NextAttemptAt)
+	}
+
+	// Nothing is left to send
+	d.deliverDue(ctx)
+	assert.Len(t, received, 1)
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	subscription := &models.WebhookSubscription{UserID: 1, URL: server.URL, Events: []string{EventJobFailed}, Secret: "whsec_a", Active: true}
+	assert.NoError(t, repo.CreateSubscription(ctx, subscription))
+
+	d := NewDispatcher(repo)
+	d.AllowPrivateTargets = true // the test server listens on loopback
+	d.MaxAttempts = 2
+	d.InitialBackoff = time.Hour
+	d.Publish(ctx, 1, EventJobFailed, map[string]string{"job_id": "job-1"})
+
+	before := time.Now()
+	d.deliverDue(ctx)
+	deliveries, _ := repo.ListDeliveries(ctx, subscription.ID, 10)
+	if !assert.Len(t, deliveries, 1) {
+		return
+	}
+	delivery := deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, 503, *delivery.ResponseStatus)
+	assert.Contains(t, *delivery.Error, "503")
+	if assert.NotNil(t, delivery.NextAttemptAt) {
+		assert.WithinDuration(t, before.Add(time.Hour), *delivery.NextAttemptAt, time.Minute)
+	}
+
+	// The retry is not due yet
+	d.deliverDue(ctx)
+	assert.Equal(t, 1, attempts)
+
+	// Once due, the last attempt fails the delivery
+	past := time.Now().Add(-time.Second)
+	delivery.NextAttemptAt = &past
+	assert.NoError(t, repo.UpdateDelivery(ctx, &delivery))
+	d.deliverDue(ctx)
+	assert.Equal(t, 2, attempts)
+
+	failed, _ := repo.FindDelivery(ctx, subscription.ID, delivery.ID)
+	assert.Equal(t, models.WebhookDeliveryFailed, failed.Status)
+	assert.Equal(t, 2, failed.Attempts)
+	assert.Nil(t, failed.NextAttemptAt)
+
+	// Redelivery queues a fresh copy of the event
+	redelivery, err := d.Redeliver(ctx, failed)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, redelivery.Status)
+	assert.Equal(t, failed.EventID, redelivery.EventID)
+	assert.Equal(t, failed.Payload, redelivery.Payload)
+	assert.Equal(t, failed.ID, *redelivery.RedeliveryOf)
+	d.deliverDue(ctx)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestDispatcherHoldsBackDeliveriesBehindARetry(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	// The receiver fails the first request and records the jobs it got
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			Data map[string]string `json:"data"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		received = append(received, event.Data["job_id"])
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	subscription := &models.WebhookSubscription{UserID: 1, URL: server.URL, Events: []string{EventJobCompleted}, Secret: "whsec_a", Active: true}
+	assert.NoError(t, repo.CreateSubscription(ctx, subscription))
+
+	d := NewDispatcher(repo)
+	d.AllowPrivateTargets = true // the test server listens on loopback
+	d.InitialBackoff = time.Hour
+	d.Publish(ctx, 1, EventJobCompleted, map[string]string{"job_id": "job-1"})
+	time.Sleep(time.Millisecond) // Deliveries are ordered by creation time
+	d.Publish(ctx, 1, EventJobCompleted, map[string]string{"job_id": "job-2"})
+
+	// The second event waits while the first is backing off
+	d.deliverDue(ctx)
+	d.deliverDue(ctx)
+	assert.Equal(t, []string{"job-1"}, received)
+
+	deliveries, _ := repo.ListDeliveries(ctx, subscription.ID, 10)
+	for i := range deliveries {
+		if deliveries[i].Attempts == 1 {
+			past := time.Now().Add(-time.Second)
+			deliveries[i].NextAttemptAt = &past
+			assert.NoError(t, repo.UpdateDelivery(ctx, &deliveries[i]))
+		}
+	}
+
+	// Once the retry succeeds the second event follows it
+	d.deliverDue(ctx)
+	assert.Equal(t, []string{"job-1", "job-1", "job-2"}, received)
+}
+
+func TestDispatcherSkipsInactiveSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	subscription := &models.WebhookSubscription{UserID: 1, URL: "http://127.0.0.1:1", Events: []string{EventJobCreated}, Secret: "whsec_a"}
+	assert.NoError(t, repo.CreateSubscription(ctx, subscription))
+
+	d := NewDispatcher(repo)
+	d.Publish(ctx, 1, EventJobCreated, nil)
+	deliveries, _ := repo.ListDeliveries(ctx, subscription.ID, 10)
+	assert.Empty(t, deliveries)
+
+	// A nil dispatcher drops events
+	var none *Dispatcher
+	none.Publish(ctx, 1, EventJobCreated, nil)
+}
+
+func TestDispatcherRefusesPrivateTargets(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	received := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+	}))
+	defer server.Close()
+
+	subscription := &models.WebhookSubscription{UserID: 1, URL: server.URL, Events: []string{EventJobCompleted}, Secret: "whsec_a", Active: true}
+	assert.NoError(t, repo.CreateSubscription(ctx, subscription))
+
+	d := NewDispatcher(repo)
+	d.Publish(ctx, 1, EventJobCompleted, NewJobEventData(&models.TranscriptionJob{ID: "job-1"}))
+	d.deliverDue(ctx)
+
+	assert.Equal(t, 0, received)
+	deliveries, err := repo.ListDeliveries(ctx, subscription.ID, 10)
+	if assert.NoError(t, err) && assert.Len(t, deliveries, 1) {
+		assert.Equal(t, models.WebhookDeliveryPending, deliveries[0].Status)
+		if assert.NotNil(t, deliveries[0].Error) {
+			assert.Contains(t, *deliveries[0].Error, ErrPrivateTarget.Error())
+		}
+	}
+
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "::1", "fd00::1", "fe80::1", "0.0.0.0"} {
+		assert.True(t, IsPrivateAddress(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"93.184.216.34", "2606:2800:220:1::1"} {
+		assert.False(t, IsPrivateAddress(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestDispatcherDeliversToSubscriptionsConcurrently(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	// The slow receiver holds its requests until released and records how
+	// many it was sent at once
+	release := make(chan struct{})
+	var mu sync.Mutex
+	inFlight, maxInFlight, slowReceived := 0, 0, 0
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		inFlight--
+		slowReceived++
+		mu.Unlock()
+	}))
+	defer slow.Close()
+	fastReceived := make(chan struct{}, 1)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fastReceived <- struct{}{}
+	}))
+	defer fast.Close()
+
+	for _, url := range []string{slow.URL, fast.URL} {
+		subscription := &models.WebhookSubscription{UserID: 1, URL: url, Events: []string{EventJobCompleted}, Secret: "whsec_a", Active: true}
+		assert.NoError(t, repo.CreateSubscription(ctx, subscription))
+	}
+
+	d := NewDispatcher(repo)
+	d.AllowPrivateTargets = true // the test servers listen on loopback
+	d.Publish(ctx, 1, EventJobCompleted, NewJobEventData(&models.TranscriptionJob{ID: "job-1"}))
+	d.Publish(ctx, 1, EventJobCompleted, NewJobEventData(&models.TranscriptionJob{ID: "job-2"}))
+
+	done := make(chan struct{})
+	go func() {
+		d.deliverDue(ctx)
+		close(done)
+	}()
+
+	// The fast receiver gets its events while the slow one is still busy
+	for i := 0; i < 2; i++ {
+		select {
+		case <-fastReceived:
+		case <-time.After(5 * time.Second):
+			t.Fatal("fast subscription waited for the slow one")
+		}
+	}
+	close(release)
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, slowReceived)
+	assert.Equal(t, 1, maxInFlight, "deliveries to one subscription are sent one at a time")
+}
@@ -52,15 +52,6 @@ func (s *Service) SendWebhook(ctx context.Context, url string, payload WebhookPa
 		return fmt.Errorf("failed to marshal webhook payload: %w", err)
 	}
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create webhook request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Scriberr-Webhook/1.0")
-
 	// Send request with retry logic
 	maxRetries := 3
 	var lastErr error
@@ -71,13 +62,21 @@ func (s *Service) SendWebhook(ctx context.Context, url string, payload WebhookPa
 			logger.Info("Retrying webhook", "job_id", payload.JobID, "attempt", i+1)
 		}
 
+		// A request body can only be read once, so every attempt gets its own request
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+		if err != nil {
+			return fmt.Errorf("failed to create webhook request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "Scriberr-Webhook/1.0")
+
 		resp, err := s.client.Do(req)
 		if err != nil {
 			lastErr = err
 			logger.Warn("Webhook request failed", "error", err, "attempt", i+1)
 			continue
 		}
-		defer resp.Body.Close()
+		resp.Body.Close()
 
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 			logger.Info("Webhook sent successfully", "job_id", payload.JobID, "status_code", resp.StatusCode)
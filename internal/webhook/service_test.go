@@ -55,6 +55,10 @@ func TestSendWebhook(t *testing.T) {
 		// Mock server that fails twice then succeeds
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			attempts++
+			// Every attempt carries the full payload
+			var payload WebhookPayload
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, "job-retry", payload.JobID)
 			if attempts < 3 {
 				w.WriteHeader(http.StatusInternalServerError)
 				return
@@ -18,9 +18,11 @@ func AuthMiddleware(authService *auth.AuthService) gin.HandlerFunc {
 		// Check for API key first
 		apiKey := c.GetHeader("X-API-Key")
 		if apiKey != "" {
-			if validateAPIKey(apiKey) {
-				c.Set("auth_type", "api_key")
-				c.Set("api_key", apiKey)
+			if key, ok := validateAPIKey(apiKey); ok {
+				if rejectInactiveUser(c, key.UserID) {
+					return
+				}
+				setAPIKeyContext(c, key)
 				c.Next()
 				return
 			}
@@ -57,6 +59,10 @@ func AuthMiddleware(authService *auth.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		if rejectInactiveUser(c, claims.UserID) {
+			return
+		}
+
 		c.Set("auth_type", "jwt")
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
@@ -65,19 +71,32 @@ func AuthMiddleware(authService *auth.AuthService) gin.HandlerFunc {
 }
 
 // validateAPIKey validates an API key against the database and updates last used timestamp
-func validateAPIKey(key string) bool {
+func validateAPIKey(key string) (*models.APIKey, bool) {
 	var apiKey models.APIKey
-	result := database.DB.Where("key = ? AND is_active = ?", key, true).First(&apiKey)
+	result := database.DB.Where("key_hash = ? AND is_active = ?", models.HashAPIKey(key), true).First(&apiKey)
 	if result.Error != nil {
-		return false
+		return nil, false
 	}
 
-	// Update last used timestamp
 	now := time.Now()
-	apiKey.LastUsed = &now
-	database.DB.Save(&apiKey)
+	if apiKey.IsExpired(now) {
+		return nil, false
+	}
+
+	// Update last used timestamp
+	database.DB.Model(&apiKey).UpdateColumn("last_used", now)
+
+	return &apiKey, true
+}
 
-	return true
+// setAPIKeyContext stores the API key and the user it belongs to on the request context
+func setAPIKeyContext(c *gin.Context, apiKey *models.APIKey) {
+	c.Set("auth_type", "api_key")
+	c.Set("api_key_id", apiKey.ID)
+	c.Set("api_key_scopes", apiKey.Scopes)
+	if apiKey.UserID != 0 {
+		c.Set("user_id", apiKey.UserID)
+	}
 }
 
 // APIKeyOnlyMiddleware only allows API key authentication
@@ -90,14 +109,18 @@ func APIKeyOnlyMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if !validateAPIKey(apiKey) {
+		key, ok := validateAPIKey(apiKey)
+		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
 			c.Abort()
 			return
 		}
 
-		c.Set("auth_type", "api_key")
-		c.Set("api_key", apiKey)
+		if rejectInactiveUser(c, key.UserID) {
+			return
+		}
+
+		setAPIKeyContext(c, key)
 		c.Next()
 	}
 }
@@ -127,6 +150,10 @@ func JWTOnlyMiddleware(authService *auth.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		if rejectInactiveUser(c, claims.UserID) {
+			return
+		}
+
 		c.Set("auth_type", "jwt")
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope rejects API key requests whose key was not granted scope.
+// JWT sessions are not restricted by scopes. It must be registered after
+// AuthMiddleware or APIKeyOnlyMiddleware.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasScope(c, scope) {
+			abortMissingScope(c, scope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireMethodScope requires readScope for safe methods (GET, HEAD) and
+// writeScope for everything else
+func RequireMethodScope(readScope, writeScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := writeScope
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = readScope
+		}
+		if !hasScope(c, scope) {
+			abortMissingScope(c, scope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// hasScope reports whether the request is allowed to use scope
+func hasScope(c *gin.Context, scope string) bool {
+	if c.GetString("auth_type") != "api_key" {
+		return true
+	}
+	for _, granted := range c.GetStringSlice("api_key_scopes") {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func abortMissingScope(c *gin.Context, scope string) {
+	c.JSON(http.StatusForbidden, gin.H{"error": "API key is missing required scope: " + scope})
+	c.Abort()
+}
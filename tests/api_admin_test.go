@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"scriberr/internal/api"
+	"scriberr/internal/auth"
+	"scriberr/internal/models"
+	"scriberr/internal/repository"
+	"scriberr/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// requestAs sends a JSON request authenticated with the given JWT, if any
+func (suite *APIHandlerTestSuite) requestAs(token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		assert.NoError(suite.T(), json.NewEncoder(&buf).Encode(body))
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *APIHandlerTestSuite) TestAdminOnlyRoutesRejectMembers() {
+	_, memberToken, _ := suite.createOtherUserJob()
+
+	for _, route := range []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"GET", "/api/v1/admin/queue", nil},
+		{"GET", "/api/v1/admin/queue/stats", nil},
+		{"POST", "/api/v1/admin/queue/pause", nil},
+		{"POST", "/api/v1/admin/queue/resume", nil},
+		{"POST", "/api/v1/admin/queue/jobs/123/pause", nil},
+		{"POST", "/api/v1/admin/queue/jobs/123/resume", nil},
+		{"POST", "/api/v1/admin/queue/jobs/123/move-to-front", nil},
+		{"GET", "/api/v1/admin/users", nil},
+		{"POST", "/api/v1/llm/config", map[string]string{"provider": "ollama", "base_url": "http://localhost:11434"}},
+		{"POST", "/api/v1/summaries/", map[string]string{"name": "T", "prompt": "P"}},
+		{"DELETE", "/api/v1/summaries/123", nil},
+		{"POST", "/api/v1/summaries/settings", map[string]string{"default_model": "gpt-4"}},
+		{"POST", "/api/v1/profiles/", map[string]string{"name": "P"}},
+		{"DELETE", "/api/v1/profiles/123", nil},
+	} {
+		resp := suite.requestAs(memberToken, route.method, route.path, route.body)
+		assert.Equal(suite.T(), http.StatusForbidden, resp.Code, route.path)
+	}
+
+	// Shared configuration stays readable for members
+	resp := suite.requestAs(memberToken, "GET", "/api/v1/summaries/", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	resp = suite.requestAs(memberToken, "GET", "/api/v1/profiles/", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	// Admins pass through
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/admin/queue/stats", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestAdminInviteAndListUsers() {
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/admin/users", api.InviteUserRequest{Username: "invited"}, true)
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+
+	var invite api.InviteUserResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &invite))
+	assert.Equal(suite.T(), models.RoleMember, invite.User.Role)
+	assert.NotEmpty(suite.T(), invite.TemporaryPassword)
+
+	// The invited user can log in with the temporary password
+	loginResp := suite.requestAs("", "POST", "/api/v1/auth/login", api.LoginRequest{
+		Username: "invited",
+		Password: invite.TemporaryPassword,
+	})
+	assert.Equal(suite.T(), http.StatusOK, loginResp.Code)
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/admin/users", api.InviteUserRequest{Username: "invited"}, true)
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/admin/users", api.InviteUserRequest{Username: "owner", Role: "owner"}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/admin/users", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var list struct {
+		Users []models.User `json:"users"`
+	}
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &list))
+	assert.Len(suite.T(), list.Users, 2)
+}
+
+func (suite *APIHandlerTestSuite) TestAdminDisableAndDeleteUser() {
+	member, memberToken, memberJob := suite.createOtherUserJob()
+	memberPath := fmt.Sprintf("/api/v1/admin/users/%d", member.ID)
+
+	resp := suite.makeAuthenticatedRequest("POST", memberPath+"/disable", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	// A disabled account can no longer use its credentials
+	resp = suite.requestAs(memberToken, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, resp.Code)
+	loginResp := suite.requestAs("", "POST", "/api/v1/auth/login", api.LoginRequest{
+		Username: member.Username,
+		Password: "otherpassword123",
+	})
+	assert.Equal(suite.T(), http.StatusForbidden, loginResp.Code)
+
+	resp = suite.makeAuthenticatedRequest("POST", memberPath+"/enable", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	resp = suite.requestAs(memberToken, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	// The last admin cannot remove themselves
+	selfPath := fmt.Sprintf("/api/v1/admin/users/%d", suite.helper.TestUser.ID)
+	resp = suite.makeAuthenticatedRequest("DELETE", selfPath, nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", selfPath+"/disable", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	// Deleting a user hands their transcriptions to the acting admin
+	resp = suite.makeAuthenticatedRequest("DELETE", memberPath, nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	var count int64
+	suite.helper.DB.Model(&models.User{}).Where("id = ?", member.ID).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+
+	var job models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.First(&job, "id = ?", memberJob.ID).Error)
+	assert.Equal(suite.T(), suite.helper.TestUser.ID, job.UserID)
+
+	resp = suite.makeAuthenticatedRequest("DELETE", memberPath, nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestConcurrentFirstRegistrationsMakeOneAdmin() {
+	// An instance without an administrator yet
+	suite.helper.DB.Model(&models.User{}).Where("id = ?", suite.helper.TestUser.ID).Update("role", models.RoleMember)
+	userRepo := repository.NewUserRepository(suite.helper.DB)
+	users := service.NewUserService(userRepo, suite.helper.AuthService)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := users.Register(context.Background(), fmt.Sprintf("racer%d", i), "racerpassword123")
+			assert.NoError(suite.T(), err)
+		}(i)
+	}
+	wg.Wait()
+
+	var admins int64
+	suite.helper.DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins)
+	assert.Equal(suite.T(), int64(1), admins)
+
+	// The initial admin endpoint creates nothing once there is an admin
+	hashed, err := auth.HashPassword("latepassword123")
+	assert.NoError(suite.T(), err)
+	late := &models.User{Username: "late", Password: hashed}
+	assert.ErrorIs(suite.T(), userRepo.CreateInitialAdmin(context.Background(), late), repository.ErrAdminExists)
+	var count int64
+	suite.helper.DB.Model(&models.User{}).Where("username = ?", "late").Count(&count)
+	assert.Zero(suite.T(), count)
+}
+
+func (suite *APIHandlerTestSuite) TestDeletedUserTokenIsRejected() {
+	member, memberToken, _ := suite.createOtherUserJob()
+
+	resp := suite.requestAs(memberToken, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/admin/users/%d", member.ID), nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	// The member's unexpired JWT no longer grants access to member routes
+	resp = suite.requestAs(memberToken, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.Code)
+	resp = suite.requestAs(memberToken, "GET", "/api/v1/vocabularies", nil)
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestAdminDeleteUserHandsOverOwnedData() {
+	member, _, memberJob := suite.createOtherUserJob()
+	adminID := suite.helper.TestUser.ID
+
+	vocabulary := &models.Vocabulary{UserID: member.ID, Name: "Member terms"}
+	speaker := &models.Speaker{UserID: member.ID, Name: "Colleague"}
+	comparison := &models.ModelComparison{UserID: member.ID, TranscriptionJobID: memberJob.ID, ModelIDs: `["whisper"]`}
+	chat := &models.ChatSession{UserID: &member.ID, JobID: &memberJob.ID, TranscriptionID: &memberJob.ID, Title: "Chat", Model: "gpt-4"}
+	subscription := &models.WebhookSubscription{UserID: member.ID, URL: "https://example.com/hook", Events: []string{"job.completed"}, Secret: "whsec_member", Active: true}
+	for _, record := range []interface{}{vocabulary, speaker, comparison, chat, subscription} {
+		assert.NoError(suite.T(), suite.helper.DB.Create(record).Error)
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		EventID:        "event-1",
+		Event:          "job.completed",
+		Payload:        "{}",
+		Status:         models.WebhookDeliveryPending,
+	}).Error)
+
+	resp := suite.makeAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/admin/users/%d", member.ID), nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	// What was built around the transcriptions moves with them
+	for _, model := range []interface{}{&models.Vocabulary{}, &models.Speaker{}, &models.ModelComparison{}, &models.ChatSession{}} {
+		var left, handedOver int64
+		suite.helper.DB.Model(model).Where("user_id = ?", member.ID).Count(&left)
+		suite.helper.DB.Model(model).Where("user_id = ?", adminID).Count(&handedOver)
+		assert.Equal(suite.T(), int64(0), left, "%T", model)
+		assert.Equal(suite.T(), int64(1), handedOver, "%T", model)
+	}
+
+	// Webhooks and their secrets are removed
+	var subscriptions, deliveries int64
+	suite.helper.DB.Unscoped().Model(&models.WebhookSubscription{}).Where("user_id IN ?", []uint{member.ID, adminID}).Count(&subscriptions)
+	suite.helper.DB.Unscoped().Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscription.ID).Count(&deliveries)
+	assert.Equal(suite.T(), int64(0), subscriptions)
+	assert.Equal(suite.T(), int64(0), deliveries)
+
+	suite.helper.DB.Where("id = ?", vocabulary.ID).Delete(&models.Vocabulary{})
+	suite.helper.DB.Where("id = ?", speaker.ID).Delete(&models.Speaker{})
+}
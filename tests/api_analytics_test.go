@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"scriberr/internal/analytics"
+	"scriberr/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *APIHandlerTestSuite) getAnalytics(jobID string) analytics.Analytics {
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+jobID+"/analytics", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var result analytics.Analytics
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &result))
+	return result
+}
+
+func (suite *APIHandlerTestSuite) TestTranscriptAnalytics() {
+	pending := suite.helper.CreateTestTranscriptionJob(suite.T(), "Not yet")
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+pending.ID+"/analytics", nil, true)
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+
+	job := suite.createCompletedJobWithTranscript("Planning", `{"segments": [
+		{"start": 0, "end": 30, "text": "Let us go through the plan for the quarter", "speaker": "SPEAKER_00"},
+		{"start": 28, "end": 40, "text": "Can I add something here", "speaker": "SPEAKER_01"},
+		{"start": 41, "end": 50, "text": "Sure go ahead", "speaker": "SPEAKER_00"}
+	]}`)
+	completedAt := time.Now()
+	execution := &models.TranscriptionJobExecution{
+		TranscriptionJobID: job.ID,
+		StartedAt:          completedAt,
+		CompletedAt:        &completedAt,
+		Status:             models.StatusCompleted,
+		Transcript:         job.Transcript,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(execution).Error)
+	job.ActiveExecutionID = &execution.ID
+	suite.helper.DB.Save(job)
+	suite.helper.DB.Create(&models.SpeakerMapping{TranscriptionJobID: job.ID, OriginalSpeaker: "SPEAKER_01", CustomName: "Dana"})
+
+	result := suite.getAnalytics(job.ID)
+	assert.Equal(suite.T(), 2, result.SpeakerCount)
+	assert.Equal(suite.T(), 1, result.Interruptions)
+	if assert.Len(suite.T(), result.Speakers, 2) {
+		assert.Equal(suite.T(), "SPEAKER_00", result.Speakers[0].Speaker)
+		assert.Equal(suite.T(), 39.0, result.Speakers[0].TalkTime)
+		assert.Equal(suite.T(), "Dana", result.Speakers[1].Name)
+		assert.Equal(suite.T(), 25.0, result.Speakers[1].WordsPerMinute)
+	}
+
+	// Analytics are cached with the execution while the transcript is unchanged
+	var stored models.TranscriptionJobExecution
+	assert.NoError(suite.T(), suite.helper.DB.First(&stored, "id = ?", execution.ID).Error)
+	if assert.NotNil(suite.T(), stored.Analytics) {
+		assert.NoError(suite.T(), suite.helper.DB.Model(&stored).Update("analytics", `{"speaker_count": 7, "speakers": []}`).Error)
+	}
+	assert.Equal(suite.T(), 7, suite.getAnalytics(job.ID).SpeakerCount)
+
+	// and recomputed once it is edited
+	resp = suite.makeAuthenticatedRequest("PUT", "/api/v1/transcription/"+job.ID+"/transcript/segments/1/speaker",
+		map[string]string{"speaker": "SPEAKER_00"}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	result = suite.getAnalytics(job.ID)
+	assert.Equal(suite.T(), 1, result.SpeakerCount)
+	assert.Equal(suite.T(), 0, result.Interruptions)
+}
@@ -159,8 +159,8 @@ func (suite *APIHandlerTestSuite) TestSendChatMessageUsesSessionProvider() {
 
 	session := &models.ChatSession{
 		ID:              "test-chat-session-ollama-provider",
-		JobID:           job.ID,
-		TranscriptionID: job.ID,
+		JobID:           &job.ID,
+		TranscriptionID: &job.ID,
 		Title:           "Ollama Provider Session",
 		Model:           "llama3:latest",
 		Provider:        "ollama",
@@ -191,8 +191,8 @@ func (suite *APIHandlerTestSuite) TestGetChatSessions() {
 	// Create second session manually to avoid ID collision from helper
 	session2 := &models.ChatSession{
 		ID:              session1.ID + "-2",
-		JobID:           job.ID,
-		TranscriptionID: job.ID,
+		JobID:           &job.ID,
+		TranscriptionID: &job.ID,
 		Title:           "Session 2",
 		Model:           "gpt-4",
 		Provider:        "openai",
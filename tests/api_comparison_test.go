@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"scriberr/internal/api"
+	"scriberr/internal/evaluation"
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/adapters"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/internal/transcription/registry"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTranscriptionAdapter returns a fixed transcript without running a model
+type fakeTranscriptionAdapter struct {
+	*adapters.BaseAdapter
+	result *interfaces.TranscriptResult
+	err    error
+}
+
+func newFakeTranscriptionAdapter(modelID string, result *interfaces.TranscriptResult, err error) *fakeTranscriptionAdapter {
+	return &fakeTranscriptionAdapter{
+		BaseAdapter: adapters.NewBaseAdapter(modelID, "", interfaces.ModelCapabilities{ModelID: modelID}, nil),
+		result:      result,
+		err:         err,
+	}
+}
+
+func (a *fakeTranscriptionAdapter) Transcribe(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	result := *a.result
+	return &result, nil
+}
+
+func (a *fakeTranscriptionAdapter) GetSupportedModels() []string {
+	return nil
+}
+
+// blockingTranscriptionAdapter reports when it starts and runs until it is cancelled
+type blockingTranscriptionAdapter struct {
+	*adapters.BaseAdapter
+	started chan struct{}
+}
+
+func (a *blockingTranscriptionAdapter) Transcribe(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, error) {
+	a.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (a *blockingTranscriptionAdapter) GetSupportedModels() []string {
+	return nil
+}
+
+func (suite *APIHandlerTestSuite) waitForComparison(path string) api.ComparisonResponse {
+	var comparison api.ComparisonResponse
+	assert.Eventually(suite.T(), func() bool {
+		resp := suite.makeAuthenticatedRequest("GET", path, nil, true)
+		if resp.Code != http.StatusOK {
+			return false
+		}
+		comparison = api.ComparisonResponse{}
+		_ = json.Unmarshal(resp.Body.Bytes(), &comparison)
+		return comparison.Status == models.StatusCompleted || comparison.Status == models.StatusFailed
+	}, 10*time.Second, 20*time.Millisecond)
+	return comparison
+}
+
+func (suite *APIHandlerTestSuite) TestCompareModelsAgainstReference() {
+	registry.RegisterTranscriptionAdapter("compare-accurate", newFakeTranscriptionAdapter("compare-accurate", &interfaces.TranscriptResult{
+		Text: "Good morning, everyone. Let's begin.",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 2, Text: "Good morning, everyone."},
+			{Start: 2, End: 4, Text: "Let's begin."},
+		},
+		ProcessingTime: 2 * time.Second,
+	}, nil))
+	registry.RegisterTranscriptionAdapter("compare-sloppy", newFakeTranscriptionAdapter("compare-sloppy", &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 2, Text: "Good mourning every one."},
+			{Start: 2, End: 4, Text: "Let's"},
+		},
+		ProcessingTime: 500 * time.Millisecond,
+	}, nil))
+	registry.RegisterTranscriptionAdapter("compare-broken", newFakeTranscriptionAdapter("compare-broken", nil, errors.New("out of memory")))
+
+	audioPath := filepath.Join(suite.T().TempDir(), "meeting.wav")
+	assert.NoError(suite.T(), os.WriteFile(audioPath, []byte("not really audio"), 0644))
+	job := suite.createCompletedJobWithTranscript("Comparison Meeting", `{"text": "Good morning everyone."}`)
+	job.AudioPath = audioPath
+	suite.helper.DB.Save(job)
+	base := "/api/v1/transcription/" + job.ID + "/comparisons"
+
+	resp := suite.makeAuthenticatedRequest("POST", base, map[string]interface{}{"model_ids": []string{"compare-missing"}, "reference": "x"}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", base, map[string]interface{}{"model_ids": []string{"compare-accurate"}}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("POST", base, map[string]interface{}{
+		"model_ids": []string{"compare-accurate", "compare-sloppy", "compare-broken"},
+		"reference": "Good morning everyone. Let's begin.",
+	}, true)
+	assert.Equal(suite.T(), http.StatusAccepted, resp.Code)
+	var created api.ComparisonResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &created))
+	assert.True(suite.T(), created.HasReference)
+
+	comparison := suite.waitForComparison(base + "/" + created.ID)
+	assert.Equal(suite.T(), models.StatusCompleted, comparison.Status)
+	if assert.Len(suite.T(), comparison.Results, 3) {
+		accurate, sloppy, broken := comparison.Results[0], comparison.Results[1], comparison.Results[2]
+		assert.Equal(suite.T(), 0.0, accurate.WER.Rate)
+		assert.Equal(suite.T(), 2.0, accurate.ProcessingTime)
+
+		assert.Equal(suite.T(), 0.5, sloppy.ProcessingTime)
+		assert.Equal(suite.T(), 2, sloppy.WER.Substitutions)
+		assert.Equal(suite.T(), 1, sloppy.WER.Insertions)
+		assert.Equal(suite.T(), 1, sloppy.WER.Deletions)
+		assert.InDelta(suite.T(), 0.8, sloppy.WER.Rate, 1e-9)
+		// "mourning" costs one character, "every one" none and "begin" five
+		assert.InDelta(suite.T(), 6.0/29, sloppy.CER.Rate, 1e-9)
+		if assert.Len(suite.T(), sloppy.Segments, 2) {
+			assert.Equal(suite.T(), 3, sloppy.Segments[0].Errors)
+			assert.Equal(suite.T(), "Let's", sloppy.Segments[1].Text)
+			assert.Equal(suite.T(), []evaluation.Op{
+				{Type: evaluation.OpEqual, Ref: "let's", Hyp: "let's"},
+				{Type: evaluation.OpDelete, Ref: "begin"},
+			}, sloppy.Segments[1].Ops)
+		}
+
+		assert.Equal(suite.T(), models.StatusFailed, broken.Status)
+		assert.Contains(suite.T(), *broken.ErrorMessage, "out of memory")
+	}
+	assert.Empty(suite.T(), comparison.Pairs)
+
+	// Without a reference the outputs are scored against each other; the
+	// models are sent as a form this time
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("model_ids", "compare-accurate")
+	writer.WriteField("model_ids", "compare-sloppy")
+	writer.Close()
+	req, _ := http.NewRequest("POST", base, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusAccepted, w.Code)
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &created))
+
+	pairwise := suite.waitForComparison(base + "/" + created.ID)
+	assert.False(suite.T(), pairwise.HasReference)
+	assert.Nil(suite.T(), pairwise.Results[0].WER)
+	if assert.Len(suite.T(), pairwise.Pairs, 1) {
+		pair := pairwise.Pairs[0]
+		assert.Equal(suite.T(), "compare-accurate", pair.ReferenceModelID)
+		assert.Equal(suite.T(), "compare-sloppy", pair.HypothesisModelID)
+		assert.Greater(suite.T(), pair.WER.Rate, 0.0)
+	}
+
+	resp = suite.makeAuthenticatedRequest("GET", base, nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var list []api.ComparisonResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &list))
+	if assert.Len(suite.T(), list, 2) {
+		assert.Empty(suite.T(), list[0].Results)
+		assert.Equal(suite.T(), []string{"compare-accurate", "compare-sloppy"}, list[0].ModelIDs)
+	}
+
+	resp = suite.makeAuthenticatedRequest("DELETE", base+"/"+created.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusNoContent, resp.Code)
+	resp = suite.makeAuthenticatedRequest("GET", base+"/"+created.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestComparisonWaitsForQueueAndCanBeCancelled() {
+	adapter := &blockingTranscriptionAdapter{
+		BaseAdapter: adapters.NewBaseAdapter("compare-blocking", "", interfaces.ModelCapabilities{ModelID: "compare-blocking"}, nil),
+		started:     make(chan struct{}, 1),
+	}
+	registry.RegisterTranscriptionAdapter("compare-blocking", adapter)
+
+	audioPath := filepath.Join(suite.T().TempDir(), "meeting.wav")
+	assert.NoError(suite.T(), os.WriteFile(audioPath, []byte("not really audio"), 0644))
+	job := suite.createCompletedJobWithTranscript("Queued Comparison", `{"text": "Hello"}`)
+	job.AudioPath = audioPath
+	suite.helper.DB.Save(job)
+	base := "/api/v1/transcription/" + job.ID + "/comparisons"
+	request := map[string]interface{}{"model_ids": []string{"compare-blocking"}, "reference": "Hello"}
+
+	assert.NoError(suite.T(), suite.taskQueue.Pause())
+	defer suite.taskQueue.Resume()
+
+	resp := suite.makeAuthenticatedRequest("POST", base, request, true)
+	assert.Equal(suite.T(), http.StatusAccepted, resp.Code)
+	var created api.ComparisonResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &created))
+
+	// Nothing runs while the queue is paused, and one comparison at a time is queued
+	select {
+	case <-adapter.started:
+		suite.T().Fatal("comparison ran while the queue was paused")
+	case <-time.After(200 * time.Millisecond):
+	}
+	resp = suite.makeAuthenticatedRequest("POST", base, request, true)
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+
+	assert.NoError(suite.T(), suite.taskQueue.Resume())
+	select {
+	case <-adapter.started:
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("comparison did not start after the queue was resumed")
+	}
+
+	resp = suite.makeAuthenticatedRequest("POST", base+"/"+created.ID+"/cancel", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	comparison := suite.waitForComparison(base + "/" + created.ID)
+	assert.Equal(suite.T(), models.StatusFailed, comparison.Status)
+	if assert.NotNil(suite.T(), comparison.ErrorMessage) {
+		assert.Equal(suite.T(), "Comparison was cancelled", *comparison.ErrorMessage)
+	}
+
+	resp = suite.makeAuthenticatedRequest("POST", base+"/"+created.ID+"/cancel", nil, true)
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+}
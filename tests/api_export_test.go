@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *APIHandlerTestSuite) createExportJob(maxLineWidth, maxLineCount *int) *models.TranscriptionJob {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Export Test")
+
+	speaker0 := "SPEAKER_00"
+	speaker1 := "SPEAKER_01"
+	result := interfaces.TranscriptResult{
+		Text:     "Hello there and welcome to the show. Thanks for having me.",
+		Language: "en",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0.5, End: 3.0, Text: " Hello there and welcome to the show.", Speaker: &speaker0},
+			{Start: 3.2, End: 5.0, Text: " Thanks for having me.", Speaker: &speaker1},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0.5, End: 0.8, Word: "Hello", Speaker: &speaker0},
+			{Start: 0.8, End: 1.1, Word: "there", Speaker: &speaker0},
+			{Start: 1.1, End: 1.3, Word: "and", Speaker: &speaker0},
+			{Start: 1.3, End: 1.8, Word: "welcome", Speaker: &speaker0},
+			{Start: 1.8, End: 2.0, Word: "to", Speaker: &speaker0},
+			{Start: 2.0, End: 2.3, Word: "the", Speaker: &speaker0},
+			{Start: 2.3, End: 3.0, Word: "show.", Speaker: &speaker0},
+			{Start: 3.2, End: 3.8, Word: "Thanks", Speaker: &speaker1},
+			{Start: 3.8, End: 4.1, Word: "for", Speaker: &speaker1},
+			{Start: 4.1, End: 4.5, Word: "having", Speaker: &speaker1},
+			{Start: 4.5, End: 5.0, Word: "me.", Speaker: &speaker1},
+		},
+	}
+	data, err := json.Marshal(result)
+	assert.NoError(suite.T(), err)
+	transcript := string(data)
+
+	job.Status = models.StatusCompleted
+	job.Transcript = &transcript
+	job.Diarization = true
+	job.Parameters.Diarize = true
+	job.Parameters.MaxLineWidth = maxLineWidth
+	job.Parameters.MaxLineCount = maxLineCount
+	assert.NoError(suite.T(), suite.helper.DB.Save(job).Error)
+
+	mapping := models.SpeakerMapping{TranscriptionJobID: job.ID, OriginalSpeaker: "SPEAKER_00", CustomName: "Alice"}
+	assert.NoError(suite.T(), suite.helper.DB.Create(&mapping).Error)
+
+	return job
+}
+
+func (suite *APIHandlerTestSuite) TestExportTranscriptFormats() {
+	job := suite.createExportJob(nil, nil)
+
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+job.ID+"/export?format=srt", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), resp.Header().Get("Content-Type"), "application/x-subrip")
+	srt := resp.Body.String()
+	assert.True(suite.T(), strings.HasPrefix(srt, "1\n00:00:00,500 --> 00:00:03,000\n[Alice] Hello there and welcome to the show.\n"))
+	assert.Contains(suite.T(), srt, "2\n00:00:03,200 --> 00:00:05,000\n[SPEAKER_01] Thanks for having me.")
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+job.ID+"/export?format=vtt", nil, false)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	vtt := resp.Body.String()
+	assert.True(suite.T(), strings.HasPrefix(vtt, "WEBVTT\n\n"))
+	assert.Contains(suite.T(), vtt, "00:00:00.500 --> 00:00:03.000\n<v Alice>Hello there")
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+job.ID+"/export?format=txt", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "[00:00:00] Alice: Hello there and welcome to the show.")
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+job.ID+"/export?format=md", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "# Export Test")
+	assert.Contains(suite.T(), resp.Body.String(), "**Alice** _(00:00:00)_")
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+job.ID+"/export?format=tsv", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "500\t3000\tAlice\tHello there and welcome to the show.")
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+job.ID+"/export?format=json&download=true", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), resp.Header().Get("Content-Disposition"), "Export_Test.json")
+	var exported interfaces.TranscriptResult
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &exported))
+	assert.Equal(suite.T(), "Alice", *exported.Segments[0].Speaker)
+	assert.Equal(suite.T(), "Alice", *exported.WordSegments[0].Speaker)
+}
+
+func (suite *APIHandlerTestSuite) TestExportTranscriptLineWrapping() {
+	width, count := 12, 1
+	job := suite.createExportJob(&width, &count)
+
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+job.ID+"/export?format=srt", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	srt := resp.Body.String()
+
+	// "Hello there and welcome to the show." wraps to 12 chars with one line per cue
+	assert.Contains(suite.T(), srt, "1\n00:00:00,500 --> 00:00:01,100\n[Alice] Hello there\n")
+	assert.Contains(suite.T(), srt, "2\n00:00:01,100 --> 00:00:01,800\n[Alice] and welcome\n")
+	assert.Contains(suite.T(), srt, "3\n00:00:01,800 --> 00:00:03,000\n[Alice] to the show.\n")
+	assert.Contains(suite.T(), srt, "4\n00:00:03,200 --> 00:00:04,100\n[SPEAKER_01] Thanks for\n")
+}
+
+func (suite *APIHandlerTestSuite) TestExportTranscriptErrors() {
+	job := suite.createExportJob(nil, nil)
+
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+job.ID+"/export?format=docx", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/does-not-exist/export", nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	pending := suite.helper.CreateTestTranscriptionJob(suite.T(), "Pending Export")
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+pending.ID+"/export", nil, true)
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+}
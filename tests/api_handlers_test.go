@@ -21,6 +21,7 @@ import (
 	"scriberr/internal/service"
 	"scriberr/internal/sse"
 	"scriberr/internal/transcription"
+	"scriberr/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -53,6 +54,13 @@ func (suite *APIHandlerTestSuite) SetupSuite() {
 	noteRepo := repository.NewNoteRepository(suite.helper.DB)
 	speakerMappingRepo := repository.NewSpeakerMappingRepository(suite.helper.DB)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(suite.helper.DB)
+	searchRepo := repository.NewSearchRepository(suite.helper.DB)
+	embeddingRepo := repository.NewEmbeddingRepository(suite.helper.DB)
+	revisionRepo := repository.NewRevisionRepository(suite.helper.DB)
+	comparisonRepo := repository.NewComparisonRepository(suite.helper.DB)
+	vocabularyRepo := repository.NewVocabularyRepository(suite.helper.DB)
+	speakerRepo := repository.NewSpeakerRepository(suite.helper.DB)
+	webhookRepo := repository.NewWebhookRepository(suite.helper.DB)
 
 	// Initialize services
 	userService := service.NewUserService(userRepo, suite.helper.AuthService)
@@ -60,6 +68,8 @@ func (suite *APIHandlerTestSuite) SetupSuite() {
 
 	// Initialize services
 	suite.unifiedProcessor = transcription.NewUnifiedJobProcessor(jobRepo, suite.helper.Config.TempDir, suite.helper.Config.TranscriptsDir)
+	suite.unifiedProcessor.GetUnifiedService().SetVocabularyRepository(vocabularyRepo)
+	suite.unifiedProcessor.GetUnifiedService().SetSpeakerLibrary(speakerRepo, speakerMappingRepo)
 	var err error
 	suite.quickTranscription, err = transcription.NewQuickTranscriptionService(suite.helper.Config, suite.unifiedProcessor, jobRepo)
 	assert.NoError(suite.T(), err)
@@ -85,6 +95,14 @@ func (suite *APIHandlerTestSuite) SetupSuite() {
 		noteRepo,
 		speakerMappingRepo,
 		refreshTokenRepo,
+		searchRepo,
+		embeddingRepo,
+		revisionRepo,
+		comparisonRepo,
+		vocabularyRepo,
+		speakerRepo,
+		webhookRepo,
+		webhook.NewDispatcher(webhookRepo),
 		suite.taskQueue,
 		suite.unifiedProcessor,
 		suite.quickTranscription,
@@ -400,11 +418,13 @@ func (suite *APIHandlerTestSuite) TestGetJobStatus() {
 	w := suite.makeAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/transcription/%s/status", testJob.ID), nil, false)
 	assert.Equal(suite.T(), 200, w.Code)
 
-	var response models.TranscriptionJob
+	var response api.JobStatusResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), testJob.ID, response.ID)
 	assert.Equal(suite.T(), models.StatusPending, response.Status)
+	assert.Equal(suite.T(), 0, response.Attempts)
+	assert.Greater(suite.T(), response.MaxAttempts, 0)
 }
 
 // Test updating transcription title
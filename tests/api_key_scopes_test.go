@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"scriberr/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// requestWithAPIKey sends a request authenticated with the given API key
+func (suite *APIHandlerTestSuite) requestWithAPIKey(key, method, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *APIHandlerTestSuite) TestAPIKeysAreStoredHashed() {
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/api-keys/", map[string]interface{}{
+		"name": "n8n",
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	var created models.APIKey
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &created))
+	assert.NotEmpty(suite.T(), created.Key)
+	assert.ElementsMatch(suite.T(), models.DefaultAPIKeyScopes, created.Scopes)
+
+	var stored models.APIKey
+	assert.NoError(suite.T(), suite.helper.DB.First(&stored, created.ID).Error)
+	assert.Equal(suite.T(), models.HashAPIKey(created.Key), stored.KeyHash)
+	assert.Equal(suite.T(), created.Key[:8], stored.KeyPrefix)
+
+	resp = suite.requestWithAPIKey(created.Key, "GET", "/api/v1/transcription/list")
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	// Keys are not granted the admin scope unless asked for
+	resp = suite.requestWithAPIKey(created.Key, "GET", "/api/v1/admin/queue/stats")
+	assert.Equal(suite.T(), http.StatusForbidden, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestAPIKeyScopesAreEnforced() {
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/api-keys/", map[string]interface{}{
+		"name":   "read only",
+		"scopes": []string{models.ScopeTranscriptionRead},
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var readOnly models.APIKey
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &readOnly))
+
+	resp = suite.requestWithAPIKey(readOnly.Key, "GET", "/api/v1/transcription/list")
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	for _, route := range [][2]string{
+		{"POST", "/api/v1/transcription/submit"},
+		{"DELETE", "/api/v1/transcription/some-id"},
+		{"GET", "/api/v1/chat/models"},
+		{"GET", "/api/v1/admin/queue/stats"},
+	} {
+		resp = suite.requestWithAPIKey(readOnly.Key, route[0], route[1])
+		assert.Equal(suite.T(), http.StatusForbidden, resp.Code, route[1])
+	}
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/api-keys/", map[string]interface{}{
+		"name":   "bogus",
+		"scopes": []string{"everything"},
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	// Members cannot mint admin keys
+	_, memberToken, _ := suite.createOtherUserJob()
+	resp = suite.requestAs(memberToken, "POST", "/api/v1/api-keys/", map[string]interface{}{
+		"name":   "escalate",
+		"scopes": []string{models.ScopeAdmin},
+	})
+	assert.Equal(suite.T(), http.StatusForbidden, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestExpiredAPIKeyIsRejected() {
+	past := time.Now().Add(-time.Hour)
+	expired := models.APIKey{
+		UserID:    suite.helper.TestUser.ID,
+		Key:       "expired-test-api-key",
+		Name:      "Expired",
+		ExpiresAt: &past,
+		IsActive:  true,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(&expired).Error)
+
+	resp := suite.requestWithAPIKey("expired-test-api-key", "GET", "/api/v1/transcription/list")
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/api-keys/", map[string]interface{}{
+		"name":       "already expired",
+		"expires_at": past.Format(time.RFC3339),
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+}
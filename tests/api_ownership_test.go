@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"scriberr/internal/auth"
+	"scriberr/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createOtherUserJob creates a second account owning a completed job
+// that the default test user must not be able to see
+func (suite *APIHandlerTestSuite) createOtherUserJob() (*models.User, string, *models.TranscriptionJob) {
+	hashed, err := auth.HashPassword("otherpassword123")
+	assert.NoError(suite.T(), err)
+	other := &models.User{Username: "otheruser", Password: hashed}
+	assert.NoError(suite.T(), suite.helper.DB.Create(other).Error)
+
+	token, err := suite.helper.AuthService.GenerateToken(other)
+	assert.NoError(suite.T(), err)
+
+	title := "Other User Recording"
+	job := &models.TranscriptionJob{
+		UserID:    other.ID,
+		Title:     &title,
+		Status:    models.StatusCompleted,
+		AudioPath: "test/path/other.mp3",
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+
+	return other, token, job
+}
+
+func (suite *APIHandlerTestSuite) TestJobsAreScopedToOwner() {
+	own := suite.helper.CreateTestTranscriptionJob(suite.T(), "My Recording")
+	_, otherToken, otherJob := suite.createOtherUserJob()
+
+	// Listing only returns the caller's jobs, for both JWT and API key auth
+	for _, useJWT := range []bool{true, false} {
+		resp := suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/list", nil, useJWT)
+		assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+		var listResp struct {
+			Jobs []models.TranscriptionJob `json:"jobs"`
+		}
+		assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &listResp))
+		assert.Len(suite.T(), listResp.Jobs, 1)
+		assert.Equal(suite.T(), own.ID, listResp.Jobs[0].ID)
+	}
+
+	// Another user's job is reported as not found
+	for _, path := range []string{
+		"/api/v1/transcription/" + otherJob.ID,
+		"/api/v1/transcription/" + otherJob.ID + "/transcript",
+		"/api/v1/transcription/" + otherJob.ID + "/notes",
+		"/api/v1/transcription/" + otherJob.ID + "/speakers",
+		"/api/v1/transcription/" + otherJob.ID + "/summary",
+		"/api/v1/chat/transcriptions/" + otherJob.ID + "/sessions",
+	} {
+		resp := suite.makeAuthenticatedRequest("GET", path, nil, true)
+		assert.Equal(suite.T(), http.StatusNotFound, resp.Code, path)
+	}
+
+	resp := suite.makeAuthenticatedRequest("DELETE", "/api/v1/transcription/"+otherJob.ID, nil, false)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	var count int64
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", otherJob.ID).Count(&count)
+	assert.Equal(suite.T(), int64(1), count)
+
+	// The owner still has access
+	req, _ := http.NewRequest("GET", "/api/v1/transcription/"+otherJob.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+otherToken)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestNotesAndChatSessionsAreScopedToOwner() {
+	_, _, otherJob := suite.createOtherUserJob()
+	note := suite.helper.CreateTestNote(suite.T(), otherJob.ID)
+	session := suite.helper.CreateTestChatSession(suite.T(), otherJob.ID)
+
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/notes/"+note.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("DELETE", "/api/v1/notes/"+note.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/chat/sessions/"+session.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("DELETE", "/api/v1/chat/sessions/"+session.ID, nil, false)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/chat/sessions", map[string]string{
+		"transcription_id": otherJob.ID,
+		"model":            "gpt-4",
+	}, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestAPIKeysAreScopedToOwner() {
+	other, _, _ := suite.createOtherUserJob()
+	otherKey := models.APIKey{UserID: other.ID, Key: "other-user-api-key", Name: "Other", IsActive: true}
+	assert.NoError(suite.T(), suite.helper.DB.Create(&otherKey).Error)
+
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/api-keys/", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var keys struct {
+		APIKeys []struct {
+			ID uint `json:"id"`
+		} `json:"api_keys"`
+	}
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &keys))
+	for _, k := range keys.APIKeys {
+		assert.NotEqual(suite.T(), otherKey.ID, k.ID)
+	}
+
+	resp = suite.makeAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/api-keys/%d", otherKey.ID), nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestQuickTranscriptionsAreScopedToOwner() {
+	other, otherToken, _ := suite.createOtherUserJob()
+	job, err := suite.quickTranscription.SubmitQuickJob(other.ID, strings.NewReader("not really audio"), "other.mp3", models.WhisperXParams{})
+	assert.NoError(suite.T(), err)
+
+	resp := suite.requestAs(otherToken, "GET", "/api/v1/transcription/quick/"+job.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	for _, useJWT := range []bool{true, false} {
+		resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/quick/"+job.ID, nil, useJWT)
+		assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	}
+}
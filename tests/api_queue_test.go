@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"scriberr/internal/api"
+	"scriberr/internal/models"
+	"scriberr/internal/queue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *APIHandlerTestSuite) TestQueueEstimates() {
+	// A finished job processed at half real time
+	done := suite.helper.CreateTestTranscriptionJob(suite.T(), "Done")
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", done.ID).
+		Updates(map[string]interface{}{"status": models.StatusCompleted, "audio_duration": 600.0})
+	completedAt := time.Now()
+	processingMs := int64(300000)
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.TranscriptionJobExecution{
+		TranscriptionJobID: done.ID,
+		StartedAt:          completedAt.Add(-5 * time.Minute),
+		CompletedAt:        &completedAt,
+		ProcessingDuration: &processingMs,
+		ActualParameters:   done.Parameters,
+		Status:             models.StatusCompleted,
+	}).Error)
+
+	first := suite.helper.CreateTestTranscriptionJob(suite.T(), "First")
+	second := suite.helper.CreateTestTranscriptionJob(suite.T(), "Second")
+	for _, job := range []*models.TranscriptionJob{first, second} {
+		suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", job.ID).Update("audio_duration", 120.0)
+		assert.NoError(suite.T(), suite.taskQueue.EnqueueJob(job.ID))
+		time.Sleep(5 * time.Millisecond) // distinct queued_at timestamps
+	}
+
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/admin/queue", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var snapshot queue.QueueSnapshot
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &snapshot))
+	assert.Empty(suite.T(), snapshot.Running)
+	if assert.Len(suite.T(), snapshot.Pending, 2) {
+		assert.Equal(suite.T(), first.ID, snapshot.Pending[0].JobID)
+		assert.Equal(suite.T(), 60.0, *snapshot.Pending[0].EstimatedDurationSeconds)
+		assert.Equal(suite.T(), *snapshot.Pending[0].EstimatedCompletionAt, *snapshot.Pending[1].EstimatedStartAt)
+	}
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+second.ID+"/status", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var status api.JobStatusResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &status))
+	if assert.NotNil(suite.T(), status.Queue) {
+		assert.Equal(suite.T(), 2, status.Queue.Position)
+		assert.WithinDuration(suite.T(), time.Now().Add(2*time.Minute), *status.Queue.EstimatedCompletionAt, 10*time.Second)
+	}
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/list", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var list struct {
+		Jobs []api.JobListItem `json:"jobs"`
+	}
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &list))
+	queued := map[string]int{}
+	for _, job := range list.Jobs {
+		if job.Queue != nil {
+			queued[job.ID] = job.Queue.Position
+		}
+	}
+	assert.Equal(suite.T(), map[string]int{first.ID: 1, second.ID: 2}, queued)
+}
+
+func (suite *APIHandlerTestSuite) TestPauseAndReorderQueue() {
+	first := suite.helper.CreateTestTranscriptionJob(suite.T(), "First")
+	second := suite.helper.CreateTestTranscriptionJob(suite.T(), "Second")
+	urgent := suite.helper.CreateTestTranscriptionJob(suite.T(), "Urgent")
+	for _, job := range []*models.TranscriptionJob{first, second, urgent} {
+		assert.NoError(suite.T(), suite.taskQueue.EnqueueJob(job.ID))
+		time.Sleep(5 * time.Millisecond) // distinct queued_at timestamps
+	}
+	pending := func() []queue.QueuedJob {
+		resp := suite.makeAuthenticatedRequest("GET", "/api/v1/admin/queue", nil, true)
+		assert.Equal(suite.T(), http.StatusOK, resp.Code)
+		var snapshot queue.QueueSnapshot
+		assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &snapshot))
+		return snapshot.Pending
+	}
+
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/admin/queue/jobs/"+urgent.ID+"/move-to-front", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	jobs := pending()
+	if assert.Len(suite.T(), jobs, 3) {
+		assert.Equal(suite.T(), []string{urgent.ID, first.ID, second.ID}, []string{jobs[0].JobID, jobs[1].JobID, jobs[2].JobID})
+	}
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/admin/queue/jobs/"+first.ID+"/pause", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var paused models.TranscriptionJob
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &paused))
+	assert.True(suite.T(), paused.Paused)
+	jobs = pending()
+	if assert.Len(suite.T(), jobs, 3) {
+		assert.True(suite.T(), jobs[1].Paused)
+		assert.Equal(suite.T(), 0, jobs[1].Position)
+		assert.Equal(suite.T(), 2, jobs[2].Position, "jobs behind a paused job move up")
+	}
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/admin/queue/jobs/"+first.ID+"/resume", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), 2, pending()[1].Position)
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/admin/queue/pause", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	defer suite.taskQueue.Resume()
+	assert.True(suite.T(), suite.taskQueue.GetQueueStats()["paused"].(bool))
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/admin/queue/resume", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.False(suite.T(), suite.taskQueue.IsPaused())
+
+	// Only jobs waiting in the queue can be changed
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", second.ID).Update("status", models.StatusCompleted)
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/admin/queue/jobs/"+second.ID+"/pause", nil, true)
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/admin/queue/jobs/missing/move-to-front", nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestQueuePriorityIsAdminOnly() {
+	_, memberToken, _ := suite.createOtherUserJob()
+	submit := func(token, priority string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("audio", "priority.mp3")
+		assert.NoError(suite.T(), err)
+		part.Write([]byte("dummy audio data"))
+		writer.WriteField("priority", priority)
+		writer.Close()
+
+		req, _ := http.NewRequest("POST", "/api/v1/transcription/submit", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Members cannot jump the shared queue, but may let others go first
+	resp := submit(memberToken, "5")
+	assert.Equal(suite.T(), http.StatusForbidden, resp.Code)
+	resp = submit(memberToken, "-1")
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	resp = submit(suite.helper.TestToken, "5")
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var job models.TranscriptionJob
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &job))
+	assert.Equal(suite.T(), 5, job.Priority)
+}
@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"scriberr/internal/api"
+	"scriberr/internal/models"
+	"scriberr/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newRAGOllamaServer fakes an Ollama server whose embeddings count topic
+// keywords, so passages about the same topic end up close together. The
+// last chat prompt is stored in lastPrompt.
+func newRAGOllamaServer(lastPrompt *string) *httptest.Server {
+	topics := []string{"budget", "hiring", "weather"}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/show":
+			_, _ = w.Write([]byte(`{"details":{"context_length":8192}}`))
+		case "/api/embed":
+			var req struct {
+				Input []string `json:"input"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			embeddings := make([][]float32, len(req.Input))
+			for i, text := range req.Input {
+				vector := []float32{0.01, 0.01, 0.01}
+				for j, topic := range topics {
+					vector[j] += float32(strings.Count(strings.ToLower(text), topic))
+				}
+				embeddings[i] = vector
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": embeddings})
+		case "/api/chat":
+			var req struct {
+				Messages []struct {
+					Content string `json:"content"`
+				} `json:"messages"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			*lastPrompt = req.Messages[len(req.Messages)-1].Content
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = fmt.Fprintln(w, `{"model":"llama3","message":{"role":"assistant","content":"The budget was cut [1]."},"done":false}`)
+			_, _ = fmt.Fprintln(w, `{"model":"llama3","message":{"role":"assistant","content":""},"done":true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func (suite *APIHandlerTestSuite) createCompletedJobWithTranscript(title, transcript string) *models.TranscriptionJob {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), title)
+	job.Status = models.StatusCompleted
+	job.Transcript = &transcript
+	suite.helper.DB.Save(job)
+	return job
+}
+
+// indexTranscripts adds jobs to the full-text index, as saving a transcript does
+func (suite *APIHandlerTestSuite) indexTranscripts(jobs ...*models.TranscriptionJob) {
+	searchRepo := repository.NewSearchRepository(suite.helper.DB)
+	for _, job := range jobs {
+		assert.NoError(suite.T(), searchRepo.IndexTranscript(context.Background(), job.ID))
+	}
+}
+
+// waitForEmbeddedPassages waits until the background embedding has stored at least count passages
+func (suite *APIHandlerTestSuite) waitForEmbeddedPassages(model string, count int64) {
+	assert.Eventually(suite.T(), func() bool {
+		var embedded int64
+		suite.helper.DB.Model(&models.TranscriptChunk{}).Where("model = ?", model).Count(&embedded)
+		return embedded >= count
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (suite *APIHandlerTestSuite) TestLibraryChatWithCitations() {
+	var lastPrompt string
+	server := newRAGOllamaServer(&lastPrompt)
+	defer server.Close()
+
+	embeddingModel := "test-embed"
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.LLMConfig{
+		Provider:       "ollama",
+		BaseURL:        &server.URL,
+		EmbeddingModel: &embeddingModel,
+		IsActive:       true,
+	}).Error)
+
+	budgetJob := suite.createCompletedJobWithTranscript("Budget Review", `{"segments": [
+		{"start": 0, "end": 5, "text": "Good morning everyone.", "speaker": "SPEAKER_00"},
+		{"start": 65, "end": 80, "text": "We agreed to cut the marketing budget by ten percent.", "speaker": "SPEAKER_01"}
+	]}`)
+	hiringJob := suite.createCompletedJobWithTranscript("Hiring Sync", `{"segments": [
+		{"start": 3, "end": 9, "text": "Hiring is paused until the hiring plan is approved.", "speaker": "SPEAKER_00"}
+	]}`)
+	_, _, otherJob := suite.createOtherUserJob()
+	suite.helper.DB.Model(otherJob).Updates(map[string]interface{}{
+		"status":     models.StatusCompleted,
+		"transcript": `{"text": "Secret budget numbers from another team."}`,
+	})
+	suite.indexTranscripts(budgetJob, hiringJob, otherJob)
+
+	// Transcript sessions still need a transcription
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/chat/sessions", api.ChatCreateRequest{Model: "llama3"}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/chat/sessions", api.ChatCreateRequest{Mode: models.ChatModeLibrary, Model: "llama3"}, true)
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	var session api.ChatSessionResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &session))
+	assert.Equal(suite.T(), models.ChatModeLibrary, session.Mode)
+	assert.Empty(suite.T(), session.TranscriptionID)
+
+	// Nothing is embedded yet, so the first question is answered by keyword
+	// while the transcriptions are embedded in the background
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/chat/sessions/"+session.ID+"/messages",
+		api.ChatMessageRequest{Content: "What happened to the budget?"}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "The budget was cut [1].", resp.Body.String())
+	assert.Contains(suite.T(), lastPrompt, `[1] "Budget Review" (00:01:05 - 00:01:20)`+"\nSPEAKER_01: We agreed to cut the marketing budget by ten percent.")
+	assert.NotContains(suite.T(), lastPrompt, "Secret budget numbers")
+	suite.waitForEmbeddedPassages(embeddingModel, 2)
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/chat/sessions/"+session.ID+"/messages",
+		api.ChatMessageRequest{Content: "What happened to the budget?"}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	// The prompt carries the most relevant passage, numbered for citation,
+	// and nothing from other users
+	assert.Contains(suite.T(), lastPrompt, `[1] "Budget Review" (00:00:00 - 00:01:20)`)
+	assert.Contains(suite.T(), lastPrompt, "SPEAKER_01: We agreed to cut the marketing budget by ten percent.")
+	assert.Contains(suite.T(), lastPrompt, "User question: What happened to the budget?")
+	assert.NotContains(suite.T(), lastPrompt, "Secret budget numbers")
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/chat/sessions/"+session.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var withMessages api.ChatSessionWithMessages
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &withMessages))
+	if assert.Len(suite.T(), withMessages.Messages, 4) {
+		answer := withMessages.Messages[3]
+		assert.Equal(suite.T(), "assistant", answer.Role)
+		if assert.NotEmpty(suite.T(), answer.Citations) {
+			assert.Equal(suite.T(), 1, answer.Citations[0].Index)
+			assert.Equal(suite.T(), budgetJob.ID, answer.Citations[0].JobID)
+			assert.Equal(suite.T(), 0.0, answer.Citations[0].StartTime)
+			assert.Equal(suite.T(), 80.0, answer.Citations[0].EndTime)
+		}
+	}
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/chat/library/sessions", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var librarySessions []api.ChatSessionResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &librarySessions))
+	if assert.Len(suite.T(), librarySessions, 1) {
+		assert.Equal(suite.T(), session.ID, librarySessions[0].ID)
+		assert.Equal(suite.T(), 4, librarySessions[0].MessageCount)
+	}
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/search/semantic?q=hiring&limit=1", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var semantic api.SemanticSearchResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &semantic))
+	assert.Equal(suite.T(), embeddingModel, semantic.Model)
+	if assert.Len(suite.T(), semantic.Results, 1) {
+		assert.Equal(suite.T(), hiringJob.ID, semantic.Results[0].JobID)
+		assert.Equal(suite.T(), "Hiring Sync", *semantic.Results[0].JobTitle)
+		assert.Equal(suite.T(), 3.0, semantic.Results[0].StartTime)
+	}
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/search/semantic", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestChatFallsBackToRetrievalForLongTranscripts() {
+	var lastPrompt string
+	server := newRAGOllamaServer(&lastPrompt)
+	defer server.Close()
+
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.LLMConfig{
+		Provider: "ollama",
+		BaseURL:  &server.URL,
+		IsActive: true,
+	}).Error)
+
+	// Over 10k tokens of small talk, more than the 8k context window, around one relevant sentence
+	var segments []string
+	for i := 0; i < 400; i++ {
+		segments = append(segments, fmt.Sprintf(`{"start": %d, "end": %d, "text": "Some talk about the weather on day %d, it was sunny and warm outside today and nice.", "speaker": "SPEAKER_00"}`, i*10, i*10+10, i))
+	}
+	segments = append(segments, `{"start": 4000, "end": 4010, "text": "Finally, the budget is approved.", "speaker": "SPEAKER_01"}`)
+	job := suite.createCompletedJobWithTranscript("Long Meeting", `{"segments": [`+strings.Join(segments, ",")+`]}`)
+	suite.indexTranscripts(job)
+
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/chat/sessions", api.ChatCreateRequest{TranscriptionID: job.ID, Model: "llama3"}, true)
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	var session api.ChatSessionResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &session))
+	assert.Equal(suite.T(), models.ChatModeTranscript, session.Mode)
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/chat/sessions/"+session.ID+"/messages",
+		api.ChatMessageRequest{Content: "Was the budget approved?"}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), lastPrompt, "---EXCERPTS START---\n[1] \"Long Meeting\"")
+	assert.Contains(suite.T(), lastPrompt, "Finally, the budget is approved.")
+	assert.Less(suite.T(), len(lastPrompt), 8192*4)
+
+	// Meanwhile the transcript was embedded for the next question
+	suite.waitForEmbeddedPassages("nomic-embed-text", 1)
+}
@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"scriberr/internal/api"
+	"scriberr/internal/models"
+	"scriberr/internal/repository"
+	"scriberr/internal/transcription"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/internal/transcription/registry"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *APIHandlerTestSuite) listExecutions(jobID string) []api.ExecutionResultResponse {
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+jobID+"/executions", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var executions []api.ExecutionResultResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &executions))
+	return executions
+}
+
+func (suite *APIHandlerTestSuite) TestRerunKeepsAlternateResults() {
+	jobRepo := repository.NewJobRepository(suite.helper.DB)
+	job := suite.createCompletedJobWithTranscript("Rerun Meeting", `{"text": "Hello world.", "segments": [{"start": 0, "end": 1, "text": "Hello world."}]}`)
+	base := "/api/v1/transcription/" + job.ID
+
+	// A manual correction on the first result is kept with it
+	suite.editTranscript("PUT", base+"/transcript/segments/0/text", api.EditSegmentTextRequest{Text: "Hello, world."})
+
+	profile := suite.helper.CreateTestProfile(suite.T(), "Parakeet", false)
+	profile.Parameters.ModelFamily = "nvidia_parakeet"
+	profile.Parameters.Model = "parakeet-tdt-0.6b-v2"
+	suite.helper.DB.Save(profile)
+
+	resp := suite.makeAuthenticatedRequest("POST", base+"/rerun", map[string]interface{}{"profile_id": "missing"}, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", base+"/rerun", map[string]interface{}{"parameters": "large"}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("POST", base+"/rerun", map[string]interface{}{
+		"profile_id": profile.ID,
+		"parameters": map[string]interface{}{"language": "de"},
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var rerun models.TranscriptionJob
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &rerun))
+	assert.Equal(suite.T(), models.StatusPending, rerun.Status)
+	assert.Equal(suite.T(), "nvidia_parakeet", rerun.Parameters.ModelFamily)
+	assert.Equal(suite.T(), "de", *rerun.Parameters.Language)
+	assert.Equal(suite.T(), 8, rerun.Parameters.BatchSize)
+
+	// The first result was recorded as an execution, edits included
+	executions := suite.listExecutions(job.ID)
+	if !assert.Len(suite.T(), executions, 1) {
+		return
+	}
+	first := executions[0]
+	assert.True(suite.T(), first.Active)
+	assert.True(suite.T(), first.HasTranscript)
+	assert.Equal(suite.T(), "base", first.ActualParameters.Model)
+
+	var revisions int64
+	suite.helper.DB.Model(&models.TranscriptRevision{}).Where("transcription_job_id = ?", job.ID).Count(&revisions)
+	assert.Zero(suite.T(), revisions)
+
+	resp = suite.makeAuthenticatedRequest("POST", base+"/rerun", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	// The new run completes and becomes active
+	completedAt := time.Now()
+	second := &models.TranscriptionJobExecution{
+		TranscriptionJobID: job.ID,
+		StartedAt:          completedAt.Add(-time.Minute),
+		CompletedAt:        &completedAt,
+		ActualParameters:   rerun.Parameters,
+		Status:             models.StatusCompleted,
+		Transcript:         stringPtr(`{"text": "Hallo Welt.", "segments": [{"start": 0, "end": 1, "text": "Hallo Welt."}]}`),
+	}
+	assert.NoError(suite.T(), jobRepo.CreateExecution(context.Background(), second))
+	assert.NoError(suite.T(), jobRepo.UpdateTranscript(context.Background(), job.ID, *second.Transcript))
+	assert.NoError(suite.T(), jobRepo.SetActiveExecution(context.Background(), job.ID, second.ID))
+	assert.NoError(suite.T(), jobRepo.UpdateStatus(context.Background(), job.ID, models.StatusCompleted))
+
+	executions = suite.listExecutions(job.ID)
+	if assert.Len(suite.T(), executions, 2) {
+		assert.False(suite.T(), executions[0].Active)
+		assert.True(suite.T(), executions[1].Active)
+	}
+
+	resp = suite.makeAuthenticatedRequest("GET", base+"/executions/"+first.ID+"/transcript", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "Hello, world.")
+
+	// Switching back restores the first result and its parameters
+	resp = suite.makeAuthenticatedRequest("POST", base+"/executions/"+first.ID+"/activate", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var activated models.TranscriptionJob
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &activated))
+	assert.Equal(suite.T(), first.ID, *activated.ActiveExecutionID)
+	assert.Equal(suite.T(), "base", activated.Parameters.Model)
+
+	resp = suite.makeAuthenticatedRequest("GET", base+"/transcript", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "Hello, world.")
+	assert.Len(suite.T(), suite.searchFor("Hello").Results, 1)
+
+	resp = suite.makeAuthenticatedRequest("GET", base+"/executions/"+second.ID+"/transcript", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "Hallo Welt.")
+
+	// Failed runs have nothing to activate
+	failed := &models.TranscriptionJobExecution{TranscriptionJobID: job.ID, StartedAt: time.Now(), Status: models.StatusFailed}
+	assert.NoError(suite.T(), jobRepo.CreateExecution(context.Background(), failed))
+	resp = suite.makeAuthenticatedRequest("POST", base+"/executions/"+failed.ID+"/activate", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	resp = suite.makeAuthenticatedRequest("GET", base+"/executions/"+failed.ID+"/transcript", nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	// Executions are only reachable through their own job
+	_, _, otherJob := suite.createOtherUserJob()
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/transcription/"+otherJob.ID+"/rerun", nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	other := suite.createCompletedJobWithTranscript("Another Meeting", `{"text": "Other."}`)
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/transcription/"+other.ID+"/executions/"+second.ID+"/activate", nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestRerunProcessingActivatesNewResult() {
+	ctx := context.Background()
+	jobRepo := repository.NewJobRepository(suite.helper.DB)
+	job := suite.createCompletedJobWithTranscript("Rerun Processing", `{"text": "Hello world.", "segments": [{"start": 0, "end": 1, "text": "Hello world."}]}`)
+	job.AudioPath = filepath.Join(suite.T().TempDir(), "meeting.wav")
+	assert.NoError(suite.T(), os.WriteFile(job.AudioPath, []byte("RIFF"), 0644))
+	suite.helper.DB.Save(job)
+
+	// The rerun's model returns a fixed transcript
+	previous := registry.GetTranscriptionAdapters()[transcription.ModelParakeet]
+	registry.RegisterTranscriptionAdapter(transcription.ModelParakeet, newFakeTranscriptionAdapter(transcription.ModelParakeet, &interfaces.TranscriptResult{
+		Text:     "Hallo Welt.",
+		Language: "de",
+		Segments: []interfaces.TranscriptSegment{{Start: 0, End: 1, Text: "Hallo Welt."}},
+	}, nil))
+	defer func() {
+		if previous != nil {
+			registry.RegisterTranscriptionAdapter(transcription.ModelParakeet, previous)
+		}
+	}()
+
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/transcription/"+job.ID+"/rerun", map[string]interface{}{
+		"parameters": map[string]interface{}{"model_family": transcription.FamilyNvidiaParakeet},
+	}, true)
+	if !assert.Equal(suite.T(), http.StatusOK, resp.Code) {
+		return
+	}
+	executions := suite.listExecutions(job.ID)
+	if !assert.Len(suite.T(), executions, 1) {
+		return
+	}
+	first := executions[0]
+
+	// The queue runs the rerun through the unified service
+	assert.NoError(suite.T(), suite.unifiedProcessor.GetUnifiedService().ProcessJob(ctx, job.ID))
+
+	processed, err := jobRepo.FindByID(ctx, job.ID)
+	if !assert.NoError(suite.T(), err) || !assert.NotNil(suite.T(), processed.ActiveExecutionID) {
+		return
+	}
+	assert.NotEqual(suite.T(), first.ID, *processed.ActiveExecutionID)
+	assert.Contains(suite.T(), *processed.Transcript, "Hallo Welt.")
+
+	execution, err := jobRepo.FindExecution(ctx, job.ID, *processed.ActiveExecutionID)
+	if assert.NoError(suite.T(), err) && assert.NotNil(suite.T(), execution.Transcript) {
+		assert.Equal(suite.T(), models.StatusCompleted, execution.Status)
+		assert.Equal(suite.T(), *processed.Transcript, *execution.Transcript)
+	}
+
+	// The earlier result stays available as an alternate
+	executions = suite.listExecutions(job.ID)
+	if assert.Len(suite.T(), executions, 2) {
+		assert.False(suite.T(), executions[0].Active)
+		assert.True(suite.T(), executions[1].Active)
+	}
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+job.ID+"/executions/"+first.ID+"/transcript", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "Hello world.")
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"scriberr/internal/api"
+	"scriberr/internal/models"
+	"scriberr/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// searchFor runs a full-text search as the default test user
+func (suite *APIHandlerTestSuite) searchFor(query string) api.SearchResponse {
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/search?q="+url.QueryEscape(query), nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	var result api.SearchResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &result))
+	return result
+}
+
+func (suite *APIHandlerTestSuite) TestSearchTranscriptSegments() {
+	searchRepo := repository.NewSearchRepository(suite.helper.DB)
+
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Planning Meeting")
+	transcript := `{"text": "", "segments": [
+		{"start": 0.0, "end": 4.2, "text": "Welcome everyone to the planning call.", "speaker": "SPEAKER_00"},
+		{"start": 4.2, "end": 9.8, "text": "We need to cut the Q3 budget for marketing.", "speaker": "SPEAKER_01"}
+	]}`
+	job.Status = models.StatusCompleted
+	job.Transcript = &transcript
+	suite.helper.DB.Save(job)
+	suite.helper.DB.Create(&models.SpeakerMapping{TranscriptionJobID: job.ID, OriginalSpeaker: "SPEAKER_01", CustomName: "Dana"})
+	assert.NoError(suite.T(), searchRepo.IndexTranscript(context.Background(), job.ID))
+
+	// Another user's transcript with the same words must stay invisible
+	_, _, otherJob := suite.createOtherUserJob()
+	otherTranscript := `{"text": "The Q3 budget is final.", "segments": []}`
+	suite.helper.DB.Model(otherJob).Update("transcript", otherTranscript)
+	assert.NoError(suite.T(), searchRepo.IndexTranscript(context.Background(), otherJob.ID))
+
+	result := suite.searchFor("q3 budgets")
+	assert.Equal(suite.T(), int64(1), result.Total)
+	if assert.Len(suite.T(), result.Results, 1) {
+		hit := result.Results[0]
+		assert.Equal(suite.T(), job.ID, hit.JobID)
+		assert.Equal(suite.T(), "Planning Meeting", *hit.JobTitle)
+		assert.Equal(suite.T(), models.SearchKindSegment, hit.Kind)
+		assert.Equal(suite.T(), "1", hit.SourceID)
+		assert.InDelta(suite.T(), 4.2, *hit.StartTime, 0.001)
+		assert.InDelta(suite.T(), 9.8, *hit.EndTime, 0.001)
+		assert.Equal(suite.T(), "Dana", *hit.Speaker)
+		assert.Contains(suite.T(), hit.Snippet, "<mark>Q3</mark> <mark>budget</mark>")
+	}
+
+	// Markup in indexed text is escaped, only the highlighting is HTML
+	markup := `{"text": "", "segments": [{"start": 0.0, "end": 2.0, "text": "<img src=x onerror=alert(1)> injected & bold"}]}`
+	suite.helper.DB.Model(job).Update("transcript", markup)
+	assert.NoError(suite.T(), searchRepo.IndexTranscript(context.Background(), job.ID))
+	result = suite.searchFor("injected")
+	if assert.Len(suite.T(), result.Results, 1) {
+		assert.Equal(suite.T(), "&lt;img src=x onerror=alert(1)&gt; <mark>injected</mark> &amp; bold", result.Results[0].Snippet)
+	}
+
+	// Re-indexing replaces the old segments
+	updated := `{"text": "Nothing about money here.", "segments": []}`
+	suite.helper.DB.Model(job).Update("transcript", updated)
+	assert.NoError(suite.T(), searchRepo.IndexTranscript(context.Background(), job.ID))
+	assert.Equal(suite.T(), int64(0), suite.searchFor("budget").Total)
+	assert.Equal(suite.T(), int64(1), suite.searchFor("money").Total)
+
+	// FTS syntax in user input is treated as plain text
+	assert.Equal(suite.T(), int64(0), suite.searchFor(`budget" OR NEAR(`).Total)
+
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/search?q=", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/search?q=money&type=chat", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestSearchNotesAndSummaries() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Search Notes")
+
+	resp := suite.makeAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/transcription/%s/notes", job.ID), map[string]interface{}{
+		"start_word_index": 0,
+		"end_word_index":   3,
+		"start_time":       12.5,
+		"end_time":         15.0,
+		"quote":            "the vendor contract",
+		"content":          "Follow up with legal about the renewal",
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var note models.Note
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &note))
+
+	result := suite.searchFor("renewal")
+	if assert.Len(suite.T(), result.Results, 1) {
+		assert.Equal(suite.T(), models.SearchKindNote, result.Results[0].Kind)
+		assert.Equal(suite.T(), note.ID, result.Results[0].SourceID)
+		assert.InDelta(suite.T(), 12.5, *result.Results[0].StartTime, 0.001)
+	}
+
+	resp = suite.makeAuthenticatedRequest("DELETE", "/api/v1/notes/"+note.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), int64(0), suite.searchFor("renewal").Total)
+
+	// Only the latest summary of a transcription is searchable
+	searchRepo := repository.NewSearchRepository(suite.helper.DB)
+	for _, content := range []string{"Discussed the hiring freeze", "Agreed on the office move"} {
+		summary := &models.Summary{TranscriptionID: job.ID, Model: "gpt-4", Content: content}
+		assert.NoError(suite.T(), suite.helper.DB.Create(summary).Error)
+		assert.NoError(suite.T(), searchRepo.IndexSummary(context.Background(), summary))
+	}
+	assert.Equal(suite.T(), int64(0), suite.searchFor("hiring").Total)
+	result = suite.searchFor("office")
+	if assert.Len(suite.T(), result.Results, 1) {
+		assert.Equal(suite.T(), models.SearchKindSummary, result.Results[0].Kind)
+	}
+
+	// Deleting the transcription removes its entries
+	resp = suite.makeAuthenticatedRequest("DELETE", "/api/v1/transcription/"+job.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), int64(0), suite.searchFor("office").Total)
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"scriberr/internal/api"
+	"scriberr/internal/models"
+	"scriberr/internal/rag"
+	"scriberr/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createJobWithVoices creates a diarized job with a voice embedding per label,
+// as a job transcribed with speaker_embeddings leaves behind
+func (suite *APIHandlerTestSuite) createJobWithVoices(title string, voices map[string][]float32) *models.TranscriptionJob {
+	job := suite.createCompletedJobWithTranscript(title, `{"segments": []}`)
+	job.Parameters.Diarize = true
+	suite.helper.DB.Save(job)
+
+	var jobSpeakers []models.JobSpeaker
+	for label, embedding := range voices {
+		jobSpeakers = append(jobSpeakers, models.JobSpeaker{
+			TranscriptionJobID: job.ID,
+			Label:              label,
+			Model:              "pyannote/speaker-diarization-community-1",
+			Embedding:          rag.EncodeVector(embedding),
+		})
+	}
+	assert.NoError(suite.T(), repository.NewSpeakerRepository(suite.helper.DB).ReplaceJobSpeakers(suite.T().Context(), job.ID, jobSpeakers))
+	return job
+}
+
+func (suite *APIHandlerTestSuite) getSpeakerMatches(jobID string) []api.SpeakerMatchResponse {
+	resp := suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+jobID+"/speaker-matches", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var matches []api.SpeakerMatchResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &matches))
+	return matches
+}
+
+func (suite *APIHandlerTestSuite) TestSpeakerLibrary() {
+	standup := suite.createJobWithVoices("Standup", map[string][]float32{
+		"SPEAKER_00": {1, 0, 0},
+		"SPEAKER_01": {0, 1, 0},
+	})
+
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/speakers", api.EnrollSpeakerRequest{
+		Name: "Alice", JobID: standup.ID, SpeakerLabel: "SPEAKER_07",
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	// Enrolling from a job names the label in that job
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/speakers", api.EnrollSpeakerRequest{
+		Name: "Alice", JobID: standup.ID, SpeakerLabel: "SPEAKER_00",
+	}, true)
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	var alice models.Speaker
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &alice))
+	if assert.Len(suite.T(), alice.VoicePrints, 1) {
+		assert.Equal(suite.T(), 1, alice.VoicePrints[0].Samples)
+	}
+	matches := suite.getSpeakerMatches(standup.ID)
+	if assert.Len(suite.T(), matches, 2) {
+		assert.Equal(suite.T(), "Alice", *matches[0].CurrentName)
+		assert.Equal(suite.T(), "Alice", *matches[0].SpeakerName)
+		assert.Nil(suite.T(), matches[1].SpeakerID)
+	}
+
+	// A duplicate enrolled from a second recording is merged back into Alice
+	review := suite.createJobWithVoices("Review", map[string][]float32{"SPEAKER_00": {0.9, 0.1, 0}})
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/speakers", api.EnrollSpeakerRequest{
+		Name: "Alice B.", JobID: review.ID, SpeakerLabel: "SPEAKER_00",
+	}, true)
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	var duplicate models.Speaker
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &duplicate))
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/speakers/"+alice.ID+"/merge", api.MergeSpeakersRequest{
+		SpeakerIDs: []string{alice.ID},
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/speakers/"+alice.ID+"/merge", api.MergeSpeakersRequest{
+		SpeakerIDs: []string{duplicate.ID},
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &alice))
+	if assert.Len(suite.T(), alice.VoicePrints, 1) {
+		assert.Equal(suite.T(), 2, alice.VoicePrints[0].Samples)
+	}
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/speakers/"+duplicate.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	// Matches point at the merged speaker and can be applied to the job
+	resp = suite.makeAuthenticatedRequest("PUT", "/api/v1/speakers/"+alice.ID, api.UpdateSpeakerRequest{Name: "Alice Smith"}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/transcription/"+review.ID+"/speaker-matches/apply", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var applied []api.SpeakerMatchResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &applied))
+	if assert.Len(suite.T(), applied, 1) {
+		assert.Equal(suite.T(), alice.ID, *applied[0].SpeakerID)
+		assert.Equal(suite.T(), "Alice Smith", *applied[0].CurrentName)
+	}
+
+	// More samples can be added to an existing speaker
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/speakers", api.EnrollSpeakerRequest{Name: "Bob"}, true)
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	var bob models.Speaker
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &bob))
+	assert.Empty(suite.T(), bob.VoicePrints)
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/speakers/"+bob.ID+"/samples", api.SpeakerSampleRequest{
+		JobID: standup.ID, SpeakerLabel: "SPEAKER_01",
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/speakers", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var list []models.Speaker
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &list))
+	if assert.Len(suite.T(), list, 2) {
+		assert.Equal(suite.T(), "Alice Smith", list[0].Name)
+		assert.Equal(suite.T(), "Bob", list[1].Name)
+	}
+
+	// Deleting a speaker keeps the names it gave but drops the match
+	resp = suite.makeAuthenticatedRequest("DELETE", "/api/v1/speakers/"+bob.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusNoContent, resp.Code)
+	matches = suite.getSpeakerMatches(standup.ID)
+	if assert.Len(suite.T(), matches, 2) {
+		assert.Nil(suite.T(), matches[1].SpeakerID)
+		assert.Equal(suite.T(), "Bob", *matches[1].CurrentName)
+	}
+}
+
+func (suite *APIHandlerTestSuite) TestFailedSpeakerEnrollmentLeavesNoSpeaker() {
+	job := suite.createJobWithVoices("Retro", map[string][]float32{"SPEAKER_00": {0, 0, 1}})
+
+	// Naming the label in the job fails after the voice was enrolled
+	suite.helper.DB.Exec("CREATE TRIGGER fail_speaker_mapping BEFORE INSERT ON speaker_mappings BEGIN SELECT RAISE(ABORT, 'mapping failed'); END")
+	defer suite.helper.DB.Exec("DROP TRIGGER IF EXISTS fail_speaker_mapping")
+
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/speakers", api.EnrollSpeakerRequest{
+		Name: "Carol", JobID: job.ID, SpeakerLabel: "SPEAKER_00",
+	}, true)
+	assert.Equal(suite.T(), http.StatusInternalServerError, resp.Code)
+
+	var speakers, voicePrints int64
+	suite.helper.DB.Model(&models.Speaker{}).Where("name = ?", "Carol").Count(&speakers)
+	suite.helper.DB.Model(&models.SpeakerVoicePrint{}).
+		Joins("JOIN speakers ON speakers.id = speaker_voice_prints.speaker_id").
+		Where("speakers.name = ?", "Carol").Count(&voicePrints)
+	assert.Zero(suite.T(), speakers)
+	assert.Zero(suite.T(), voicePrints)
+	if matches := suite.getSpeakerMatches(job.ID); assert.Len(suite.T(), matches, 1) {
+		assert.Nil(suite.T(), matches[0].SpeakerID)
+	}
+}
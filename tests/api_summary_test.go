@@ -1,8 +1,16 @@
 package tests
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
 
 	"scriberr/internal/api"
 	"scriberr/internal/models"
@@ -69,3 +77,215 @@ func (suite *APIHandlerTestSuite) TestGetSummaryForTranscription() {
 	assert.Equal(suite.T(), "Stored summary content", summaryResp.Content)
 	assert.Equal(suite.T(), "gpt-4", summaryResp.Model)
 }
+
+func (suite *APIHandlerTestSuite) TestAutoSummarizeOnCompletion() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Auto Summary Test")
+	job.Status = models.StatusCompleted
+	transcript := `{"text": "We agreed to ship on Friday.", "segments": []}`
+	job.Transcript = &transcript
+	suite.helper.DB.Save(job)
+
+	// Nothing happens while the owner has auto-summary turned off
+	assert.False(suite.T(), suite.handler.WillAutoSummarize(context.Background(), job.ID))
+	summary, err := suite.handler.AutoSummarizeJob(context.Background(), job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), summary)
+
+	template := suite.helper.CreateTestSummaryTemplate(suite.T(), "Meeting Notes")
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.SummarySetting{DefaultModel: "gpt-3.5-turbo"}).Error)
+
+	enabled := true
+	resp := suite.makeAuthenticatedRequest("PUT", "/api/v1/user/settings", api.UpdateUserSettingsRequest{
+		AutoSummaryEnabled:       &enabled,
+		DefaultSummaryTemplateID: &template.ID,
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var settings api.UserSettingsResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &settings))
+	assert.Equal(suite.T(), template.ID, *settings.DefaultSummaryTemplateID)
+
+	assert.True(suite.T(), suite.handler.WillAutoSummarize(context.Background(), job.ID))
+	summary, err = suite.handler.AutoSummarizeJob(context.Background(), job.ID)
+	assert.NoError(suite.T(), err)
+	if assert.NotNil(suite.T(), summary) {
+		// The configured default model wins over the template's own model
+		assert.Equal(suite.T(), "gpt-3.5-turbo", summary.Model)
+		assert.Equal(suite.T(), template.ID, *summary.TemplateID)
+	}
+
+	var stored models.Summary
+	assert.NoError(suite.T(), suite.helper.DB.Where("transcription_id = ?", job.ID).First(&stored).Error)
+	assert.NotEmpty(suite.T(), stored.Content)
+
+	var updated models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.First(&updated, "id = ?", job.ID).Error)
+	assert.Equal(suite.T(), stored.Content, *updated.Summary)
+
+	// Unknown templates cannot be selected
+	missing := "missing-template"
+	resp = suite.makeAuthenticatedRequest("PUT", "/api/v1/user/settings", api.UpdateUserSettingsRequest{
+		DefaultSummaryTemplateID: &missing,
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestSummarizeLongTranscriptInParts() {
+	// A fake Ollama model with a 2k context; part notes come back from plain
+	// completions and the final summary is streamed
+	var mu sync.Mutex
+	var partPrompts []string
+	var finalPrompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/show":
+			_, _ = w.Write([]byte(`{"model_info":{"llama.context_length":2048}}`))
+		case "/api/chat":
+			var req struct {
+				Stream   bool `json:"stream"`
+				Messages []struct {
+					Content string `json:"content"`
+				} `json:"messages"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			prompt := req.Messages[len(req.Messages)-1].Content
+			mu.Lock()
+			defer mu.Unlock()
+			if !req.Stream {
+				partPrompts = append(partPrompts, prompt)
+				_, _ = fmt.Fprintf(w, `{"model":"llama3","message":{"role":"assistant","content":"Notes %d"},"done":true}`, len(partPrompts))
+				return
+			}
+			finalPrompt = prompt
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = fmt.Fprintln(w, `{"model":"llama3","message":{"role":"assistant","content":"The launch moved to May."},"done":false}`)
+			_, _ = fmt.Fprintln(w, `{"model":"llama3","message":{"role":"assistant","content":""},"done":true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.LLMConfig{
+		Provider: "ollama",
+		BaseURL:  &server.URL,
+		IsActive: true,
+	}).Error)
+
+	// Around 25k characters of conversation, several times the context window.
+	// The client sends an edited transcript with the speakers named.
+	var segments, lines []string
+	for i := 0; i < 300; i++ {
+		text := fmt.Sprintf("Update number %d on the launch plan, the team is still working through the checklist.", i)
+		segments = append(segments, fmt.Sprintf(`{"start": %d, "end": %d, "text": "%s", "speaker": "SPEAKER_0%d"}`, i*10, i*10+10, text, i%2))
+		lines = append(lines, fmt.Sprintf("Speaker %d: Edited update number %d on the launch plan, the team is still working through the checklist.", i%2, i))
+	}
+	job := suite.createCompletedJobWithTranscript("Launch Planning", `{"segments": [`+strings.Join(segments, ",")+`]}`)
+
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/summarize/", api.SummarizeRequest{
+		Model:           "llama3",
+		Content:         "Transcript:\n" + strings.Join(lines, "\n") + "\n\nInstructions:\nList the decisions.",
+		TranscriptionID: job.ID,
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "The launch moved to May.", resp.Body.String())
+	assert.Empty(suite.T(), resp.Result().Trailer.Get("X-Summary-Error"))
+
+	// Each part of the transcript the client sent is summarized separately
+	if assert.Greater(suite.T(), len(partPrompts), 1) {
+		assert.Contains(suite.T(), partPrompts[0], fmt.Sprintf("This is part 1 of %d.", len(partPrompts)))
+		assert.Contains(suite.T(), partPrompts[0], "Speaker 0: Edited update number 0 on the launch plan")
+		assert.Contains(suite.T(), partPrompts[0], "Speaker 1: Edited update number 1 on the launch plan")
+		assert.Contains(suite.T(), partPrompts[0], "List the decisions.")
+		assert.Contains(suite.T(), partPrompts[len(partPrompts)-1], "Edited update number 299")
+		for _, prompt := range partPrompts {
+			assert.Less(suite.T(), len(prompt)/4, 2048)
+			assert.NotContains(suite.T(), prompt, "SPEAKER_0")
+		}
+	}
+
+	// The final summary is written from the notes and keeps the instructions
+	assert.Contains(suite.T(), finalPrompt, "Notes on part 1 of")
+	assert.Contains(suite.T(), finalPrompt, "Notes 1")
+	assert.Contains(suite.T(), finalPrompt, fmt.Sprintf("Notes %d", len(partPrompts)))
+	assert.True(suite.T(), strings.HasSuffix(finalPrompt, "List the decisions."))
+	assert.NotContains(suite.T(), finalPrompt, "Update number 0")
+
+	var summary models.Summary
+	assert.NoError(suite.T(), suite.helper.DB.Where("transcription_id = ?", job.ID).First(&summary).Error)
+	assert.Equal(suite.T(), "The launch moved to May.", summary.Content)
+	assert.Equal(suite.T(), "llama3", summary.Model)
+}
+
+func (suite *APIHandlerTestSuite) TestSummarizeInPartsStartsStreamFirst() {
+	// Part notes are held back until the client has the response headers,
+	// and the last part fails
+	headers := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/show":
+			_, _ = w.Write([]byte(`{"model_info":{"llama.context_length":2048}}`))
+		case "/api/chat":
+			var req struct {
+				Messages []struct {
+					Content string `json:"content"`
+				} `json:"messages"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			select {
+			case <-headers:
+			case <-time.After(5 * time.Second):
+			}
+			if strings.Contains(req.Messages[0].Content, "This is part 1 of") {
+				_, _ = w.Write([]byte(`{"model":"llama3","message":{"role":"assistant","content":"Notes"},"done":true}`))
+				return
+			}
+			http.Error(w, "model crashed", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.LLMConfig{
+		Provider: "ollama",
+		BaseURL:  &server.URL,
+		IsActive: true,
+	}).Error)
+
+	var segments, lines []string
+	for i := 0; i < 300; i++ {
+		text := fmt.Sprintf("Update number %d on the launch plan, the team is still working through the checklist.", i)
+		segments = append(segments, fmt.Sprintf(`{"start": %d, "end": %d, "text": "%s"}`, i*10, i*10+10, text))
+		lines = append(lines, text)
+	}
+	job := suite.createCompletedJobWithTranscript("Launch Planning", `{"segments": [`+strings.Join(segments, ",")+`]}`)
+
+	app := httptest.NewServer(suite.router)
+	defer app.Close()
+	body, _ := json.Marshal(map[string]string{
+		"model":            "llama3",
+		"content":          "Transcript:\n" + strings.Join(lines, " "),
+		"transcription_id": job.ID,
+	})
+	req, _ := http.NewRequest("POST", app.URL+"/api/v1/summarize/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.helper.TestToken)
+
+	started := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	close(headers)
+	if !assert.NoError(suite.T(), err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Less(suite.T(), time.Since(started), 5*time.Second)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	// The failed part is reported in a trailer, not as summary text, and nothing is saved
+	text, _ := io.ReadAll(resp.Body)
+	assert.Empty(suite.T(), strings.TrimSpace(string(text)))
+	assert.Contains(suite.T(), resp.Trailer.Get("X-Summary-Error"), "Failed to summarize transcript: failed to summarize part 2 of")
+	var count int64
+	suite.helper.DB.Model(&models.Summary{}).Where("transcription_id = ?", job.ID).Count(&count)
+	assert.Zero(suite.T(), count)
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"scriberr/internal/api"
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const editableTranscript = `{"text": "We meet on Monday. The budget is final.", "language": "en", "segments": [
+	{"start": 0, "end": 2, "text": "We meet on Monday.", "speaker": "SPEAKER_00"},
+	{"start": 2, "end": 4, "text": "The budget is final.", "speaker": "SPEAKER_00"}
+], "word_segments": [
+	{"start": 0, "end": 0.5, "word": "We", "score": 0.9, "speaker": "SPEAKER_00"},
+	{"start": 0.5, "end": 1, "word": "meet", "score": 0.9, "speaker": "SPEAKER_00"},
+	{"start": 1, "end": 1.5, "word": "on", "score": 0.9, "speaker": "SPEAKER_00"},
+	{"start": 1.5, "end": 2, "word": "Monday.", "score": 0.9, "speaker": "SPEAKER_00"},
+	{"start": 2, "end": 2.5, "word": "The", "score": 0.9, "speaker": "SPEAKER_00"},
+	{"start": 2.5, "end": 3, "word": "budget", "score": 0.9, "speaker": "SPEAKER_00"},
+	{"start": 3, "end": 3.5, "word": "is", "score": 0.9, "speaker": "SPEAKER_00"},
+	{"start": 3.5, "end": 4, "word": "final.", "score": 0.9, "speaker": "SPEAKER_00"}
+]}`
+
+func (suite *APIHandlerTestSuite) editTranscript(method, path string, body interface{}) api.TranscriptEditResponse {
+	resp := suite.makeAuthenticatedRequest(method, path, body, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code, resp.Body.String())
+	var result api.TranscriptEditResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &result))
+	return result
+}
+
+func (suite *APIHandlerTestSuite) TestEditTranscriptWithRevisions() {
+	job := suite.createCompletedJobWithTranscript("Editable Meeting", editableTranscript)
+	base := "/api/v1/transcription/" + job.ID + "/transcript"
+
+	// Nothing to revert before the first edit
+	resp := suite.makeAuthenticatedRequest("POST", base+"/revert", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	edited := suite.editTranscript("PUT", base+"/segments/0/text", api.EditSegmentTextRequest{Text: "We meet on Tuesday."})
+	assert.Equal(suite.T(), 1, edited.Revision.Number)
+	assert.Equal(suite.T(), models.RevisionActionEditText, edited.Revision.Action)
+	assert.Equal(suite.T(), "testuser", edited.Revision.Author)
+	if assert.NotNil(suite.T(), edited.Revision.Diff) {
+		assert.Equal(suite.T(), "We meet on Monday.", edited.Revision.Diff.Before[0].Text)
+		assert.Equal(suite.T(), "We meet on Tuesday.", edited.Revision.Diff.After[0].Text)
+	}
+	assert.Equal(suite.T(), "We meet on Tuesday. The budget is final.", edited.Transcript.Text)
+	assert.Equal(suite.T(), "Tuesday.", edited.Transcript.WordSegments[3].Word)
+	assert.Equal(suite.T(), 1.5, edited.Transcript.WordSegments[3].Start)
+	assert.Equal(suite.T(), "en", edited.Transcript.Language)
+
+	// The search index follows the edit
+	assert.Len(suite.T(), suite.searchFor("Tuesday").Results, 1)
+	assert.Empty(suite.T(), suite.searchFor("Monday").Results)
+
+	reassigned := suite.editTranscript("PUT", base+"/segments/1/speaker", api.SetSegmentSpeakerRequest{Speaker: "SPEAKER_01"})
+	assert.Equal(suite.T(), "SPEAKER_01", *reassigned.Transcript.Segments[1].Speaker)
+	assert.Equal(suite.T(), "SPEAKER_01", *reassigned.Transcript.WordSegments[7].Speaker)
+
+	split := suite.editTranscript("POST", base+"/segments/1/split", api.SplitSegmentRequest{WordIndex: 2})
+	if assert.Len(suite.T(), split.Transcript.Segments, 3) {
+		assert.Equal(suite.T(), "The budget", split.Transcript.Segments[1].Text)
+		assert.Equal(suite.T(), 3.0, split.Transcript.Segments[1].End)
+		assert.Equal(suite.T(), "is final.", split.Transcript.Segments[2].Text)
+		assert.Equal(suite.T(), 3.0, split.Transcript.Segments[2].Start)
+	}
+
+	merged := suite.editTranscript("POST", base+"/segments/0/merge", nil)
+	if assert.Len(suite.T(), merged.Transcript.Segments, 2) {
+		assert.Equal(suite.T(), "We meet on Tuesday. The budget", merged.Transcript.Segments[0].Text)
+		assert.Equal(suite.T(), 3.0, merged.Transcript.Segments[0].End)
+		assert.Equal(suite.T(), "SPEAKER_00", *merged.Transcript.Segments[0].Speaker)
+	}
+	assert.Equal(suite.T(), "SPEAKER_00", *merged.Transcript.WordSegments[5].Speaker)
+
+	// Invalid edits are rejected without a revision
+	resp = suite.makeAuthenticatedRequest("PUT", base+"/segments/9/text", api.EditSegmentTextRequest{Text: "x"}, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	resp = suite.makeAuthenticatedRequest("PUT", base+"/segments/first/text", api.EditSegmentTextRequest{Text: "x"}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", base+"/segments/1/split", api.SplitSegmentRequest{WordIndex: 5}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", base+"/segments/1/merge", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	reverted := suite.editTranscript("POST", base+"/revert", nil)
+	assert.Equal(suite.T(), models.RevisionActionRevert, reverted.Revision.Action)
+	var original interfaces.TranscriptResult
+	assert.NoError(suite.T(), json.Unmarshal([]byte(editableTranscript), &original))
+	assert.Equal(suite.T(), original, reverted.Transcript)
+	assert.Len(suite.T(), suite.searchFor("Monday").Results, 1)
+
+	resp = suite.makeAuthenticatedRequest("GET", base+"/revisions", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var revisions []api.TranscriptRevisionResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &revisions))
+	if assert.Len(suite.T(), revisions, 6) {
+		assert.Equal(suite.T(), models.RevisionActionOriginal, revisions[0].Action)
+		assert.Nil(suite.T(), revisions[0].AuthorID)
+		assert.Nil(suite.T(), revisions[0].Diff)
+		for i, action := range []string{models.RevisionActionEditText, models.RevisionActionSpeaker, models.RevisionActionSplit, models.RevisionActionMerge, models.RevisionActionRevert} {
+			assert.Equal(suite.T(), i+1, revisions[i+1].Number)
+			assert.Equal(suite.T(), action, revisions[i+1].Action)
+			assert.Equal(suite.T(), suite.helper.TestUser.ID, *revisions[i+1].AuthorID)
+		}
+	}
+
+	// Transcripts of other users can neither be edited nor inspected
+	_, _, otherJob := suite.createOtherUserJob()
+	resp = suite.makeAuthenticatedRequest("PUT", "/api/v1/transcription/"+otherJob.ID+"/transcript/segments/0/text", api.EditSegmentTextRequest{Text: "Hijacked."}, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/transcription/"+otherJob.ID+"/transcript/revisions", nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	// Pending jobs have no transcript to edit
+	pending := suite.helper.CreateTestTranscriptionJob(suite.T(), "Pending")
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/transcription/"+pending.ID+"/transcript/segments/0/merge", nil, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	// Deleting the job removes its history
+	resp = suite.makeAuthenticatedRequest("DELETE", "/api/v1/transcription/"+job.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var count int64
+	suite.helper.DB.Model(&models.TranscriptRevision{}).Where("transcription_job_id = ?", job.ID).Count(&count)
+	assert.Zero(suite.T(), count)
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"scriberr/internal/api"
+	"scriberr/internal/models"
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/internal/transcription/registry"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *APIHandlerTestSuite) TestVocabularyManagement() {
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/vocabularies", map[string]interface{}{
+		"name":  "Broken",
+		"rules": []models.ReplacementRule{{Find: "v(\\d+", Replace: "x", Regex: true}},
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "Invalid rule 1")
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/vocabularies", map[string]interface{}{
+		"name":  "Product names",
+		"terms": []string{"Scriberr", " ", "WhisperX"},
+		"rules": []models.ReplacementRule{{Find: "scribe er", Replace: "Scriberr"}},
+	}, true)
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	var vocabulary models.Vocabulary
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &vocabulary))
+	assert.Equal(suite.T(), []string{"Scriberr", "WhisperX"}, vocabulary.Terms)
+
+	resp = suite.makeAuthenticatedRequest("PUT", "/api/v1/vocabularies/"+vocabulary.ID, api.VocabularyRequest{
+		Name:  "Product names",
+		Terms: []string{"Scriberr"},
+		Rules: []models.ReplacementRule{
+			{Find: "scribe er", Replace: "Scriberr"},
+			{Find: `v(\d+) point (\d+)`, Replace: "v$1.$2", Regex: true},
+		},
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/vocabularies", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var list []models.Vocabulary
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &list))
+	if assert.Len(suite.T(), list, 1) {
+		assert.Len(suite.T(), list[0].Rules, 2)
+	}
+
+	// Profiles may only refer to vocabularies that exist
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/profiles/", map[string]interface{}{
+		"name":       "Unknown vocabulary",
+		"parameters": map[string]interface{}{"vocabulary_ids": []string{"missing"}},
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/profiles/", map[string]interface{}{
+		"name":       "Product team",
+		"parameters": map[string]interface{}{"vocabulary_ids": []string{vocabulary.ID}},
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var profile models.TranscriptionProfile
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &profile))
+	assert.Equal(suite.T(), []string{vocabulary.ID}, profile.Parameters.VocabularyIDs)
+
+	// The rules are applied to what the model heard
+	registry.RegisterTranscriptionAdapter("vocabulary-model", newFakeTranscriptionAdapter("vocabulary-model", &interfaces.TranscriptResult{
+		Text:     "Scribe er v2 point 5 is out.",
+		Segments: []interfaces.TranscriptSegment{{Start: 0, End: 3, Text: "Scribe er v2 point 5 is out."}},
+	}, nil))
+	audioPath := filepath.Join(suite.T().TempDir(), "release.wav")
+	assert.NoError(suite.T(), os.WriteFile(audioPath, []byte("not really audio"), 0644))
+	job := suite.createCompletedJobWithTranscript("Release Notes", `{"text": "Scriberr v2.5 is out."}`)
+	job.AudioPath = audioPath
+	job.Parameters.VocabularyIDs = profile.Parameters.VocabularyIDs
+	suite.helper.DB.Save(job)
+
+	base := "/api/v1/transcription/" + job.ID + "/comparisons"
+	resp = suite.makeAuthenticatedRequest("POST", base, map[string]interface{}{
+		"model_ids": []string{"vocabulary-model"},
+		"reference": "Scriberr v2.5 is out.",
+	}, true)
+	assert.Equal(suite.T(), http.StatusAccepted, resp.Code)
+	var created api.ComparisonResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &created))
+	comparison := suite.waitForComparison(base + "/" + created.ID)
+	if assert.Len(suite.T(), comparison.Results, 1) {
+		assert.Equal(suite.T(), "Scriberr v2.5 is out.", comparison.Results[0].Text)
+		assert.Equal(suite.T(), 0.0, comparison.Results[0].WER.Rate)
+	}
+
+	resp = suite.makeAuthenticatedRequest("DELETE", "/api/v1/vocabularies/"+vocabulary.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusNoContent, resp.Code)
+	resp = suite.makeAuthenticatedRequest("GET", "/api/v1/vocabularies/"+vocabulary.ID, nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestVocabulariesAreScopedToOwner() {
+	other, _, _ := suite.createOtherUserJob()
+	private := &models.Vocabulary{
+		UserID: other.ID,
+		Name:   "Private",
+		Rules:  []models.ReplacementRule{{Find: "is out", Replace: "leaked"}},
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(private).Error)
+
+	// Another user's vocabulary cannot be referenced
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/profiles/", map[string]interface{}{
+		"name":       "Foreign vocabulary",
+		"parameters": map[string]interface{}{"vocabulary_ids": []string{private.ID}},
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "Unknown vocabulary")
+
+	// Nor is it applied to a job that refers to it anyway
+	registry.RegisterTranscriptionAdapter("scoped-vocabulary-model", newFakeTranscriptionAdapter("scoped-vocabulary-model", &interfaces.TranscriptResult{
+		Text:     "Scriberr is out.",
+		Segments: []interfaces.TranscriptSegment{{Start: 0, End: 3, Text: "Scriberr is out."}},
+	}, nil))
+	audioPath := filepath.Join(suite.T().TempDir(), "release.wav")
+	assert.NoError(suite.T(), os.WriteFile(audioPath, []byte("not really audio"), 0644))
+	job := suite.createCompletedJobWithTranscript("Release", `{"text": "Scriberr is out."}`)
+	job.AudioPath = audioPath
+	job.Parameters.VocabularyIDs = []string{private.ID}
+	suite.helper.DB.Save(job)
+
+	base := "/api/v1/transcription/" + job.ID + "/comparisons"
+	resp = suite.makeAuthenticatedRequest("POST", base, map[string]interface{}{
+		"model_ids": []string{"scoped-vocabulary-model"},
+		"reference": "Scriberr is out.",
+	}, true)
+	assert.Equal(suite.T(), http.StatusAccepted, resp.Code)
+	var created api.ComparisonResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &created))
+	comparison := suite.waitForComparison(base + "/" + created.ID)
+	if assert.Len(suite.T(), comparison.Results, 1) {
+		assert.Equal(suite.T(), "Scriberr is out.", comparison.Results[0].Text)
+	}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"scriberr/internal/api"
+	"scriberr/internal/models"
+	"scriberr/internal/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *APIHandlerTestSuite) TestWebhookSubscriptions() {
+	resp := suite.makeAuthenticatedRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":    "ftp://example.com/hook",
+		"events": []string{webhook.EventNoteCreated},
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":    "https://example.com/hook",
+		"events": []string{"job.deleted"},
+	}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), "Unknown event")
+
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":    "https://example.com/hook",
+		"events": []string{webhook.EventNoteCreated, webhook.EventNoteCreated, webhook.EventJobCompleted},
+	}, true)
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	var created api.WebhookSubscriptionCreatedResponse
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &created))
+	assert.True(suite.T(), strings.HasPrefix(created.Secret, "whsec_"))
+	assert.True(suite.T(), created.Active)
+	assert.Equal(suite.T(), []string{webhook.EventNoteCreated, webhook.EventJobCompleted}, created.Events)
+	base := "/api/v1/webhooks/" + created.ID
+
+	// The secret is only shown on creation
+	resp = suite.makeAuthenticatedRequest("GET", base, nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.NotContains(suite.T(), resp.Body.String(), created.Secret)
+
+	// Creating a note queues a delivery for the subscription
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Webhook job")
+	resp = suite.makeAuthenticatedRequest("POST", "/api/v1/transcription/"+job.ID+"/notes", map[string]interface{}{
+		"start_word_index": 0,
+		"end_word_index":   1,
+		"start_time":       0.0,
+		"end_time":         1.0,
+		"quote":            "Hello",
+		"content":          "Follow up",
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("GET", base+"/deliveries", nil, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var deliveries []models.WebhookDelivery
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &deliveries))
+	if !assert.Len(suite.T(), deliveries, 1) {
+		return
+	}
+	delivery := deliveries[0]
+	assert.Equal(suite.T(), webhook.EventNoteCreated, delivery.Event)
+	assert.Equal(suite.T(), models.WebhookDeliveryPending, delivery.Status)
+	var event struct {
+		Type string                `json:"type"`
+		Data webhook.NoteEventData `json:"data"`
+	}
+	assert.NoError(suite.T(), json.Unmarshal([]byte(delivery.Payload), &event))
+	assert.Equal(suite.T(), webhook.EventNoteCreated, event.Type)
+	assert.Equal(suite.T(), job.ID, event.Data.JobID)
+	assert.Equal(suite.T(), "Follow up", event.Data.Content)
+
+	// A failed delivery can be sent again
+	suite.helper.DB.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{"status": models.WebhookDeliveryFailed, "attempts": 8, "next_attempt_at": nil})
+	resp = suite.makeAuthenticatedRequest("POST", base+"/deliveries/"+delivery.ID+"/redeliver", nil, true)
+	assert.Equal(suite.T(), http.StatusAccepted, resp.Code)
+	var redelivery models.WebhookDelivery
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &redelivery))
+	assert.Equal(suite.T(), models.WebhookDeliveryPending, redelivery.Status)
+	assert.Equal(suite.T(), delivery.EventID, redelivery.EventID)
+	if assert.NotNil(suite.T(), redelivery.RedeliveryOf) {
+		assert.Equal(suite.T(), delivery.ID, *redelivery.RedeliveryOf)
+	}
+
+	resp = suite.makeAuthenticatedRequest("POST", base+"/deliveries/missing/redeliver", nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+
+	// Inactive subscriptions receive nothing
+	resp = suite.makeAuthenticatedRequest("PUT", base, map[string]interface{}{
+		"url":    "https://example.com/other-hook",
+		"events": []string{webhook.EventNoteCreated},
+		"active": false,
+	}, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	resp = suite.makeAuthenticatedRequest("POST", base+"/deliveries/"+delivery.ID+"/redeliver", nil, true)
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+
+	resp = suite.makeAuthenticatedRequest("DELETE", base, nil, true)
+	assert.Equal(suite.T(), http.StatusNoContent, resp.Code)
+	var remaining int64
+	suite.helper.DB.Model(&models.WebhookDelivery{}).Where("subscription_id = ?", created.ID).Count(&remaining)
+	assert.Equal(suite.T(), int64(0), remaining)
+	resp = suite.makeAuthenticatedRequest("GET", base, nil, true)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+}
+
+func (suite *APIHandlerTestSuite) TestWebhookTargetsAndResponsesAreGuarded() {
+	for _, target := range []string{
+		"http://localhost:8080/hook",
+		"http://127.0.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.5/hook",
+		"http://[::1]/hook",
+	} {
+		resp := suite.makeAuthenticatedRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+			"url":    target,
+			"events": []string{webhook.EventJobCompleted},
+		}, true)
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.Code, target)
+	}
+
+	// Members see their deliveries without the receiver's response body
+	other, otherToken, _ := suite.createOtherUserJob()
+	subscription := &models.WebhookSubscription{UserID: other.ID, URL: "https://example.com/hook", Events: []string{webhook.EventJobCompleted}, Secret: "whsec_test", Active: true}
+	assert.NoError(suite.T(), suite.helper.DB.Create(subscription).Error)
+	status, body := 200, "internal response"
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		EventID:        "event-1",
+		Event:          webhook.EventJobCompleted,
+		Payload:        "{}",
+		Status:         models.WebhookDeliverySucceeded,
+		ResponseStatus: &status,
+		ResponseBody:   &body,
+	}).Error)
+
+	resp := suite.requestAs(otherToken, "GET", "/api/v1/webhooks/"+subscription.ID+"/deliveries", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	var deliveries []models.WebhookDelivery
+	assert.NoError(suite.T(), json.Unmarshal(resp.Body.Bytes(), &deliveries))
+	if assert.Len(suite.T(), deliveries, 1) {
+		assert.Equal(suite.T(), 200, *deliveries[0].ResponseStatus)
+		assert.Nil(suite.T(), deliveries[0].ResponseBody)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"scriberr/internal/service"
 	"scriberr/internal/sse"
 	"scriberr/internal/transcription"
+	"scriberr/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -46,6 +47,13 @@ func (suite *CLIHandlerTestSuite) SetupSuite() {
 	noteRepo := repository.NewNoteRepository(suite.helper.DB)
 	speakerMappingRepo := repository.NewSpeakerMappingRepository(suite.helper.DB)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(suite.helper.DB)
+	searchRepo := repository.NewSearchRepository(suite.helper.DB)
+	embeddingRepo := repository.NewEmbeddingRepository(suite.helper.DB)
+	revisionRepo := repository.NewRevisionRepository(suite.helper.DB)
+	comparisonRepo := repository.NewComparisonRepository(suite.helper.DB)
+	vocabularyRepo := repository.NewVocabularyRepository(suite.helper.DB)
+	speakerRepo := repository.NewSpeakerRepository(suite.helper.DB)
+	webhookRepo := repository.NewWebhookRepository(suite.helper.DB)
 
 	// Initialize services
 	userService := service.NewUserService(userRepo, suite.helper.AuthService)
@@ -78,6 +86,14 @@ func (suite *CLIHandlerTestSuite) SetupSuite() {
 		noteRepo,
 		speakerMappingRepo,
 		refreshTokenRepo,
+		searchRepo,
+		embeddingRepo,
+		revisionRepo,
+		comparisonRepo,
+		vocabularyRepo,
+		speakerRepo,
+		webhookRepo,
+		webhook.NewDispatcher(webhookRepo),
 		suite.taskQueue,
 		suite.unifiedProcessor,
 		suite.quickTranscription,
@@ -7,6 +7,7 @@ import (
 	"scriberr/internal/database"
 	"scriberr/internal/models"
 
+	"github.com/glebarez/sqlite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
@@ -51,6 +52,44 @@ func (suite *DatabaseTestSuite) TestDatabaseInitialization() {
 	database.DB = originalDB
 }
 
+// Test that plaintext API keys from older installs are hashed with their owner's access.
+// The legacy tables mirror the DDL GORM generated for them.
+func (suite *DatabaseTestSuite) TestLegacyAPIKeyMigration() {
+	testDbPath := "test_legacy_keys.db"
+	defer os.Remove(testDbPath)
+	originalDB := database.DB
+
+	legacy, err := gorm.Open(sqlite.Open(testDbPath), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+	for _, stmt := range []string{
+		"CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`username` varchar(50) NOT NULL UNIQUE,`password` varchar(255) NOT NULL,`created_at` datetime,`updated_at` datetime)",
+		"CREATE TABLE `api_keys` (`id` integer PRIMARY KEY AUTOINCREMENT,`key` varchar(255) NOT NULL,`name` varchar(100) NOT NULL,`is_active` boolean NOT NULL,`user_id` integer,`created_at` datetime,`updated_at` datetime)",
+		"CREATE UNIQUE INDEX `idx_api_keys_key` ON `api_keys`(`key`)",
+		"INSERT INTO users (username, password) VALUES ('owner', 'x'), ('member', 'x')",
+		"INSERT INTO api_keys (key, name, is_active, user_id) VALUES ('owner-legacy-key', 'owner', true, 1), ('member-legacy-key', 'member', true, 2)",
+	} {
+		assert.NoError(suite.T(), legacy.Exec(stmt).Error)
+	}
+	sqlDB, _ := legacy.DB()
+	sqlDB.Close()
+
+	assert.NoError(suite.T(), database.Initialize(testDbPath))
+
+	var ownerKey, memberKey models.APIKey
+	assert.NoError(suite.T(), database.DB.Where("key_hash = ?", models.HashAPIKey("owner-legacy-key")).First(&ownerKey).Error)
+	assert.NoError(suite.T(), database.DB.Where("key_hash = ?", models.HashAPIKey("member-legacy-key")).First(&memberKey).Error)
+	assert.True(suite.T(), ownerKey.HasScope(models.ScopeAdmin), "the first user is promoted to admin and keeps admin access")
+	assert.False(suite.T(), memberKey.HasScope(models.ScopeAdmin))
+	assert.True(suite.T(), memberKey.HasScope(models.ScopeTranscriptionWrite))
+
+	var plaintextColumns int64
+	database.DB.Raw("SELECT COUNT(*) FROM pragma_table_info('api_keys') WHERE name = 'key'").Scan(&plaintextColumns)
+	assert.Zero(suite.T(), plaintextColumns)
+
+	database.Close()
+	database.DB = originalDB
+}
+
 // Test database initialization with invalid path
 func (suite *DatabaseTestSuite) TestDatabaseInitializationInvalidPath() {
 	// Try to initialize with an invalid path (directory doesn't exist and can't be created)
@@ -126,9 +165,11 @@ func (suite *DatabaseTestSuite) TestAPIKeyCRUD() {
 
 	// Read
 	var foundKey models.APIKey
-	result = db.Where("key = ?", "test-api-key-crud-12345").First(&foundKey)
+	result = db.Where("key_hash = ?", models.HashAPIKey("test-api-key-crud-12345")).First(&foundKey)
 	assert.NoError(suite.T(), result.Error)
-	assert.Equal(suite.T(), apiKey.Key, foundKey.Key)
+	assert.Equal(suite.T(), apiKey.KeyHash, foundKey.KeyHash)
+	assert.Equal(suite.T(), "test-api", foundKey.KeyPrefix)
+	assert.Empty(suite.T(), foundKey.Key, "plaintext key must not be persisted")
 	assert.Equal(suite.T(), apiKey.Name, foundKey.Name)
 	assert.True(suite.T(), foundKey.IsActive)
 
@@ -425,7 +466,7 @@ func (suite *DatabaseTestSuite) TestDatabaseQueries() {
 	// Should include at least our test active key
 	found := false
 	for _, key := range activeKeys {
-		if key.Key == "active-key-query-test" {
+		if key.KeyHash == models.HashAPIKey("active-key-query-test") {
 			found = true
 			break
 		}
@@ -440,7 +481,7 @@ func (suite *DatabaseTestSuite) TestDatabaseQueries() {
 	// Should include our inactive key
 	found = false
 	for _, key := range inactiveKeys {
-		if key.Key == "inactive-key-query-test" {
+		if key.KeyHash == models.HashAPIKey("inactive-key-query-test") {
 			found = true
 			break
 		}
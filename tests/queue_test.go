@@ -1,9 +1,15 @@
 package tests
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os/exec"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -11,10 +17,12 @@ import (
 	"scriberr/internal/models"
 	"scriberr/internal/queue"
 	"scriberr/internal/repository"
+	"scriberr/internal/sse"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 // MockJobProcessor for testing
@@ -78,7 +86,6 @@ func (suite *QueueTestSuite) TestNewTaskQueue() {
 	stats := tq.GetQueueStats()
 	assert.Equal(suite.T(), 2, stats["current_workers"])
 	assert.Equal(suite.T(), 0, stats["queue_size"])
-	assert.Equal(suite.T(), 200, stats["queue_capacity"])
 }
 
 // Test enqueuing jobs
@@ -86,13 +93,101 @@ func (suite *QueueTestSuite) TestEnqueueJob() {
 	mockProcessor := &MockJobProcessor{}
 	tq := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
 
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Enqueue Test Job")
+
 	// Test successful enqueue
-	err := tq.EnqueueJob("test-job-1")
+	err := tq.EnqueueJob(job.ID)
 	assert.NoError(suite.T(), err)
 
-	// Test queue stats after enqueue
+	// The job is now a queue entry in the database
 	stats := tq.GetQueueStats()
 	assert.Equal(suite.T(), 1, stats["queue_size"])
+
+	queued, err := tq.GetJobStatus(job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusPending, queued.Status)
+	assert.NotNil(suite.T(), queued.QueuedAt)
+
+	// Finished jobs are not put back in the queue
+	for _, status := range []models.JobStatus{models.StatusCompleted, models.StatusFailed} {
+		suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", job.ID).Update("status", status)
+		assert.ErrorIs(suite.T(), tq.EnqueueJob(job.ID), queue.ErrJobNotQueueable)
+		finished, err := tq.GetJobStatus(job.ID)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), status, finished.Status)
+	}
+	assert.ErrorIs(suite.T(), tq.EnqueueJob("missing"), queue.ErrJobNotQueueable)
+
+	// Requeueing only saves the job while it is still in an expected status
+	stale, err := tq.GetJobStatus(job.ID)
+	assert.NoError(suite.T(), err)
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", job.ID).Update("status", models.StatusProcessing)
+	stale.Parameters.Model = "large-v3"
+	assert.ErrorIs(suite.T(), tq.RequeueJob(stale, models.StatusCompleted, models.StatusFailed), queue.ErrJobNotQueueable)
+	current, err := tq.GetJobStatus(job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusProcessing, current.Status)
+	assert.NotEqual(suite.T(), "large-v3", current.Parameters.Model)
+
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", job.ID).Update("status", models.StatusFailed)
+	assert.NoError(suite.T(), tq.RequeueJob(stale, models.StatusCompleted, models.StatusFailed))
+	current, err = tq.GetJobStatus(job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusPending, current.Status)
+	assert.Equal(suite.T(), "large-v3", current.Parameters.Model)
+}
+
+// Test queue changes reach the owner's user-wide event stream
+func (suite *QueueTestSuite) TestEnqueueAnnouncesQueuePositions() {
+	broadcaster := sse.NewBroadcaster()
+	defer broadcaster.Shutdown()
+	tq := queue.NewTaskQueue(1, &MockJobProcessor{}, suite.jobRepo)
+	tq.SetBroadcaster(broadcaster)
+
+	first := suite.helper.CreateTestTranscriptionJob(suite.T(), "First")
+	second := suite.helper.CreateTestTranscriptionJob(suite.T(), "Second")
+
+	userID := suite.helper.TestUser.ID
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		broadcaster.ServeUser(w, r, userID)
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(suite.T(), err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	nextEvent := func() sse.Event {
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				var event sse.Event
+				assert.NoError(suite.T(), json.Unmarshal([]byte(data), &event))
+				return event
+			}
+		}
+		return sse.Event{}
+	}
+	assert.Equal(suite.T(), "connected", nextEvent().Type)
+
+	assert.NoError(suite.T(), tq.EnqueueJob(first.ID))
+	assert.NoError(suite.T(), tq.EnqueueJob(second.ID))
+
+	update := nextEvent()
+	assert.Equal(suite.T(), "job_update", update.Type)
+	assert.Equal(suite.T(), first.ID, update.Payload.(map[string]interface{})["job_id"])
+	assert.Equal(suite.T(), string(models.StatusPending), update.Payload.(map[string]interface{})["status"])
+	assert.Equal(suite.T(), "queue_update", nextEvent().Type)
+	assert.Equal(suite.T(), "job_update", nextEvent().Type)
+
+	positions := nextEvent()
+	assert.Equal(suite.T(), "queue_update", positions.Type)
+	payload, _ := json.Marshal(positions.Payload)
+	assert.JSONEq(suite.T(), fmt.Sprintf(`{"queue_length":2,"jobs":[{"job_id":%q,"position":1},{"job_id":%q,"position":2}]}`, first.ID, second.ID), string(payload))
 }
 
 // Test job processing
@@ -231,8 +326,7 @@ func (suite *QueueTestSuite) TestGetQueueStats() {
 	stats := tq.GetQueueStats()
 
 	assert.Equal(suite.T(), 3, stats["current_workers"])
-	assert.Equal(suite.T(), 0, stats["queue_size"]) // No jobs in queue buffer
-	assert.Equal(suite.T(), 200, stats["queue_capacity"])
+	assert.Equal(suite.T(), 4, stats["queue_size"]) // All four jobs are still pending in the database
 
 	// Note: The actual counts depend on what's in the database
 	assert.Contains(suite.T(), stats, "pending_jobs")
@@ -299,26 +393,171 @@ func (suite *QueueTestSuite) TestQueueShutdown() {
 	assert.Contains(suite.T(), err.Error(), "shutting down")
 }
 
-// Test queue overflow
-func (suite *QueueTestSuite) TestQueueOverflow() {
+// Test that the queue has no fixed capacity
+func (suite *QueueTestSuite) TestQueueDoesNotOverflow() {
 	mockProcessor := &MockJobProcessor{}
-	// Make jobs take a long time so they don't get processed
-	mockProcessor.processDelay = 5 * time.Second
-	mockProcessor.On("ProcessJobWithProcess", mock.Anything, mock.Anything).Return(nil)
-
 	tq := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
 
-	// Fill up the queue (capacity is 200)
-	for i := 0; i < 200; i++ {
-		err := tq.EnqueueJob(fmt.Sprintf("job-%d", i))
+	// Well beyond the old 200-slot in-memory buffer
+	for i := 0; i < 250; i++ {
+		job := suite.helper.CreateTestTranscriptionJob(suite.T(), fmt.Sprintf("Bulk Job %d", i))
+		assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+	}
+
+	stats := tq.GetQueueStats()
+	assert.Equal(suite.T(), 250, stats["queue_size"])
+}
+
+// Test that higher priority jobs are claimed first and ties go to the oldest
+func (suite *QueueTestSuite) TestPriorityOrdering() {
+	low := suite.helper.CreateTestTranscriptionJob(suite.T(), "Low Priority")
+	older := suite.helper.CreateTestTranscriptionJob(suite.T(), "Older High Priority")
+	newer := suite.helper.CreateTestTranscriptionJob(suite.T(), "Newer High Priority")
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id IN ?", []string{older.ID, newer.ID}).Update("priority", 5)
+
+	tq := queue.NewTaskQueue(1, &MockJobProcessor{}, suite.jobRepo)
+	for _, job := range []*models.TranscriptionJob{low, older, newer} {
+		assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+		time.Sleep(5 * time.Millisecond) // distinct queued_at timestamps
+	}
+
+	var order []string
+	for i := 0; i < 3; i++ {
+		job, err := suite.jobRepo.ClaimNextPending(context.Background())
 		assert.NoError(suite.T(), err)
+		if assert.NotNil(suite.T(), job) {
+			assert.Equal(suite.T(), models.StatusProcessing, job.Status)
+			order = append(order, job.ID)
+		}
 	}
+	assert.Equal(suite.T(), []string{older.ID, newer.ID, low.ID}, order)
+
+	job, err := suite.jobRepo.ClaimNextPending(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), job)
+}
+
+// Test that pending jobs left from a previous run are processed after a restart
+func (suite *QueueTestSuite) TestPendingJobsSurviveRestart() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Left Over Job")
+
+	mockProcessor := &MockJobProcessor{}
+	mockProcessor.On("ProcessJobWithProcess", mock.Anything, job.ID).Return(nil)
 
-	// The 201st job should fail
-	err := tq.EnqueueJob("overflow-job")
-	if assert.Error(suite.T(), err, "Expected error for queue overflow") {
-		assert.Contains(suite.T(), err.Error(), "queue is full")
+	// No EnqueueJob call: the pending row alone is enough
+	tq := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
+	tq.Start()
+	defer tq.Stop()
+
+	assert.Eventually(suite.T(), func() bool {
+		updated, err := tq.GetJobStatus(job.ID)
+		return err == nil && updated.Status == models.StatusCompleted
+	}, 2*time.Second, 50*time.Millisecond)
+}
+
+// Test that transient failures are retried and the attempts are counted
+func (suite *QueueTestSuite) TestTransientFailureIsRetried() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Flaky Job")
+
+	mockProcessor := &MockJobProcessor{}
+	mockProcessor.On("ProcessJobWithProcess", mock.Anything, job.ID).Return(errors.New("429 Too Many Requests")).Once()
+	mockProcessor.On("ProcessJobWithProcess", mock.Anything, job.ID).Return(nil)
+
+	tq := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
+	policy := queue.DefaultRetryPolicy()
+	policy.InitialBackoff = 0
+	tq.SetRetryPolicy(policy)
+	tq.Start()
+	defer tq.Stop()
+
+	assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+
+	assert.Eventually(suite.T(), func() bool {
+		updated, err := tq.GetJobStatus(job.ID)
+		return err == nil && updated.Status == models.StatusCompleted
+	}, 2*time.Second, 50*time.Millisecond)
+
+	updated, err := tq.GetJobStatus(job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, updated.Attempts)
+	mockProcessor.AssertNumberOfCalls(suite.T(), "ProcessJobWithProcess", 2)
+}
+
+// Test that retries stop once the attempt budget is spent
+func (suite *QueueTestSuite) TestRetriesStopAtMaxAttempts() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Always Failing Job")
+
+	mockProcessor := &MockJobProcessor{}
+	mockProcessor.On("ProcessJobWithProcess", mock.Anything, job.ID).Return(errors.New("python adapter: signal: killed"))
+
+	tq := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
+	tq.SetRetryPolicy(queue.RetryPolicy{MaxAttempts: 2, RetryableErrors: []string{"signal: killed"}})
+	tq.Start()
+	defer tq.Stop()
+
+	assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+
+	assert.Eventually(suite.T(), func() bool {
+		updated, err := tq.GetJobStatus(job.ID)
+		return err == nil && updated.Status == models.StatusFailed
+	}, 2*time.Second, 50*time.Millisecond)
+
+	updated, err := tq.GetJobStatus(job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, updated.Attempts)
+	mockProcessor.AssertNumberOfCalls(suite.T(), "ProcessJobWithProcess", 2)
+}
+
+// Test the retry policy classification and backoff schedule
+func (suite *QueueTestSuite) TestRetryPolicy() {
+	policy := queue.RetryPolicy{
+		MaxAttempts:     3,
+		InitialBackoff:  time.Second,
+		MaxBackoff:      3 * time.Second,
+		Multiplier:      2,
+		RetryableErrors: []string{"rate limit"},
 	}
+
+	assert.True(suite.T(), policy.ShouldRetry(errors.New("OpenAI Rate Limit exceeded"), 1))
+	assert.False(suite.T(), policy.ShouldRetry(errors.New("OpenAI Rate Limit exceeded"), 3))
+	assert.False(suite.T(), policy.ShouldRetry(errors.New("unsupported audio format"), 1))
+	assert.False(suite.T(), policy.ShouldRetry(context.Canceled, 1))
+
+	// The defaults match status codes only where a message reports one, and
+	// phrases only from the start of a word
+	defaults := queue.DefaultRetryPolicy()
+	for _, msg := range []string{
+		"OpenAI API error (status 503): overloaded",
+		"API error: 429 - slow down",
+		"unexpected status code: 502",
+		"embedding request rate limited",
+		"dial tcp: i/o timeout",
+		"failed to download Parakeet model: exit status 1",
+		"YouTube download failed",
+	} {
+		assert.True(suite.T(), defaults.IsRetryable(errors.New(msg)), msg)
+	}
+	for _, msg := range []string{
+		"failed to open /data/uploads/5034-429.wav: no such file or directory",
+		"API error: 400 - audio is 503 seconds too long",
+		"model checkpoint checksum mismatch",
+		"invalid option notimeout",
+	} {
+		assert.False(suite.T(), defaults.IsRetryable(errors.New(msg)), msg)
+	}
+
+	assert.Equal(suite.T(), time.Second, policy.Backoff(1))
+	assert.Equal(suite.T(), 2*time.Second, policy.Backoff(2))
+	assert.Equal(suite.T(), 3*time.Second, policy.Backoff(3))
+
+	// A job waiting out its backoff is not claimed early
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Backing Off Job")
+	err := suite.jobRepo.ScheduleRetry(context.Background(), job.ID, time.Now().Add(time.Hour), "retrying")
+	assert.NoError(suite.T(), err)
+
+	claimed, err := suite.jobRepo.ClaimNextPending(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), claimed)
 }
 
 // Test job status retrieval
@@ -390,11 +629,12 @@ func (suite *QueueTestSuite) TestConcurrentAccess() {
 		go func(goroutineID int) {
 			defer wg.Done()
 			for j := 0; j < jobsPerGoroutine; j++ {
-				jobID := fmt.Sprintf("concurrent-job-%d-%d", goroutineID, j)
-				job := suite.helper.CreateTestTranscriptionJob(suite.T(), fmt.Sprintf("Concurrent Job %d-%d", goroutineID, j))
-				job.ID = jobID
+				// Uploaded, so the running workers cannot claim the job before it is enqueued
+				title := fmt.Sprintf("Concurrent Job %d-%d", goroutineID, j)
+				job := &models.TranscriptionJob{UserID: suite.helper.TestUser.ID, Title: &title, Status: models.StatusUploaded, AudioPath: "test/path/audio.mp3"}
+				assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
 
-				err := tq.EnqueueJob(jobID)
+				err := tq.EnqueueJob(job.ID)
 				// Some enqueues might fail if queue fills up, but shouldn't panic
 				if err != nil && !assert.Contains(suite.T(), err.Error(), "queue is full") {
 					assert.NoError(suite.T(), err)
@@ -442,3 +682,390 @@ func (suite *QueueTestSuite) TestResetZombieJobs() {
 	assert.Equal(suite.T(), models.StatusFailed, updatedJob.Status)
 	assert.Contains(suite.T(), *updatedJob.ErrorMessage, "interrupted by server restart")
 }
+
+// Test ResetZombieJobs resumes jobs with chunk checkpoints
+func (suite *QueueTestSuite) TestResetZombieJobsResumesChunkedJobs() {
+	mockProcessor := &MockJobProcessor{}
+	tq := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
+
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Chunked Zombie Job")
+	err := suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", job.ID).Update("status", models.StatusProcessing).Error
+	assert.NoError(suite.T(), err)
+
+	ctx := context.Background()
+	err = suite.jobRepo.ReplaceChunks(ctx, job.ID, []models.AudioChunk{
+		{TranscriptionJobID: job.ID, Index: 0, Start: 0, End: 600, PlanDigest: "plan", Status: models.StatusPending},
+		{TranscriptionJobID: job.ID, Index: 1, Start: 600, End: 1250, PlanDigest: "plan", Status: models.StatusPending},
+	})
+	assert.NoError(suite.T(), err)
+	chunks, err := suite.jobRepo.ListChunks(ctx, job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), chunks, 2)
+	assert.NoError(suite.T(), suite.jobRepo.CompleteChunk(ctx, chunks[0].ID, `{"text":"first"}`))
+
+	tq.ResetZombieJobs()
+
+	var updatedJob models.TranscriptionJob
+	suite.helper.DB.First(&updatedJob, "id = ?", job.ID)
+	assert.Equal(suite.T(), models.StatusPending, updatedJob.Status)
+	assert.Contains(suite.T(), *updatedJob.ErrorMessage, "resuming from finished chunks")
+
+	// The checkpoints survive for the next attempt
+	chunks, err = suite.jobRepo.ListChunks(ctx, job.ID)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), chunks, 2) {
+		assert.Equal(suite.T(), models.StatusCompleted, chunks[0].Status)
+		assert.Equal(suite.T(), models.StatusPending, chunks[1].Status)
+	}
+}
+
+// Test a chunked job interrupted by stopping the queue resumes after a restart
+func (suite *QueueTestSuite) TestStoppedChunkedJobResumesAfterRestart() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Interrupted Chunked Job")
+
+	slowProcessor := &MockJobProcessor{processDelay: time.Minute}
+	slowProcessor.On("ProcessJobWithProcess", mock.Anything, job.ID).Return(nil)
+	tq := queue.NewTaskQueue(1, slowProcessor, suite.jobRepo)
+	tq.Start()
+	assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+	assert.Eventually(suite.T(), func() bool { return tq.IsJobRunning(job.ID) }, 2*time.Second, 10*time.Millisecond)
+
+	ctx := context.Background()
+	assert.NoError(suite.T(), suite.jobRepo.ReplaceChunks(ctx, job.ID, []models.AudioChunk{
+		{TranscriptionJobID: job.ID, Index: 0, Start: 0, End: 600, PlanDigest: "plan", Status: models.StatusPending},
+		{TranscriptionJobID: job.ID, Index: 1, Start: 600, End: 1250, PlanDigest: "plan", Status: models.StatusPending},
+	}))
+	chunks, err := suite.jobRepo.ListChunks(ctx, job.ID)
+	if !assert.NoError(suite.T(), err) || !assert.Len(suite.T(), chunks, 2) {
+		return
+	}
+	assert.NoError(suite.T(), suite.jobRepo.CompleteChunk(ctx, chunks[0].ID, `{"text":"first"}`))
+
+	// Stopping is not a cancellation: the job is left for the next start
+	tq.Stop()
+	stopped, err := suite.jobRepo.FindByID(ctx, job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusProcessing, stopped.Status)
+
+	processor := &MockJobProcessor{}
+	processor.On("ProcessJobWithProcess", mock.Anything, job.ID).Return(nil)
+	restarted := queue.NewTaskQueue(1, processor, suite.jobRepo)
+	restarted.Start()
+	defer restarted.Stop()
+	assert.Eventually(suite.T(), func() bool {
+		job, err := restarted.GetJobStatus(job.ID)
+		return err == nil && job.Status == models.StatusCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+
+	chunks, err = suite.jobRepo.ListChunks(ctx, job.ID)
+	if assert.NoError(suite.T(), err) && assert.Len(suite.T(), chunks, 2) {
+		assert.Equal(suite.T(), models.StatusCompleted, chunks[0].Status)
+	}
+}
+
+// estimatingProcessor is a MockJobProcessor that estimates jobs from fixed durations
+type estimatingProcessor struct {
+	MockJobProcessor
+	durations map[string]time.Duration
+}
+
+func (p *estimatingProcessor) EstimateProcessingTime(ctx context.Context, job *models.TranscriptionJob) (time.Duration, bool) {
+	duration, ok := p.durations[job.ID]
+	return duration, ok
+}
+
+// Test that the snapshot places pending jobs behind the running jobs and tasks
+func (suite *QueueTestSuite) TestSnapshotEstimatesQueue() {
+	running := suite.helper.CreateTestTranscriptionJob(suite.T(), "Running")
+	first := suite.helper.CreateTestTranscriptionJob(suite.T(), "First")
+	unknown := suite.helper.CreateTestTranscriptionJob(suite.T(), "Unknown Length")
+	last := suite.helper.CreateTestTranscriptionJob(suite.T(), "Last")
+
+	processor := &estimatingProcessor{
+		MockJobProcessor: MockJobProcessor{processDelay: 5 * time.Second},
+		durations: map[string]time.Duration{
+			running.ID: 100 * time.Second,
+			first.ID:   60 * time.Second,
+			last.ID:    30 * time.Second,
+		},
+	}
+	processor.On("ProcessJobWithProcess", mock.Anything, running.ID).Return(nil)
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id <> ?", running.ID).Update("status", models.StatusUploaded)
+
+	// A task such as a model comparison holds the second worker
+	tq := queue.NewTaskQueue(2, processor, suite.jobRepo)
+	tq.Start()
+	defer tq.Stop()
+	taskStarted, taskDone := make(chan struct{}), make(chan error, 1)
+	go func() {
+		taskDone <- tq.RunTask(context.Background(), "comparison", func(ctx context.Context) error {
+			close(taskStarted)
+			<-ctx.Done()
+			return nil
+		})
+	}()
+	<-taskStarted
+	defer func() {
+		assert.NoError(suite.T(), tq.CancelTask("comparison"))
+		assert.NoError(suite.T(), <-taskDone)
+	}()
+	assert.NoError(suite.T(), tq.EnqueueJob(running.ID))
+	assert.Eventually(suite.T(), func() bool { return tq.IsJobRunning(running.ID) }, 2*time.Second, 10*time.Millisecond)
+	for _, job := range []*models.TranscriptionJob{first, unknown, last} {
+		assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+		time.Sleep(5 * time.Millisecond) // distinct queued_at timestamps
+	}
+
+	snapshot, err := tq.Snapshot(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, snapshot.Workers)
+	if !assert.Len(suite.T(), snapshot.Running, 1) || !assert.Len(suite.T(), snapshot.Pending, 3) {
+		return
+	}
+
+	current := snapshot.Running[0]
+	assert.Equal(suite.T(), running.ID, current.JobID)
+	assert.Equal(suite.T(), current.StartedAt.Add(100*time.Second), *current.EstimatedCompletionAt)
+
+	next := snapshot.Pending[0]
+	assert.Equal(suite.T(), first.ID, next.JobID)
+	assert.Equal(suite.T(), 1, next.Position)
+	assert.Equal(suite.T(), *current.EstimatedCompletionAt, *next.EstimatedStartAt)
+	assert.Equal(suite.T(), next.EstimatedStartAt.Add(60*time.Second), *next.EstimatedCompletionAt)
+	assert.Equal(suite.T(), 60.0, *next.EstimatedDurationSeconds)
+
+	// Nothing behind a job of unknown length can be estimated
+	assert.Equal(suite.T(), []string{unknown.ID, last.ID}, []string{snapshot.Pending[1].JobID, snapshot.Pending[2].JobID})
+	assert.Nil(suite.T(), snapshot.Pending[1].EstimatedCompletionAt)
+	assert.Nil(suite.T(), snapshot.Pending[2].EstimatedCompletionAt)
+	assert.Equal(suite.T(), 3, snapshot.Pending[2].Position)
+	assert.Equal(suite.T(), last.ID, snapshot.Find(last.ID).JobID)
+	assert.Nil(suite.T(), snapshot.Find("missing"))
+}
+
+// Test a job in retry backoff lets the jobs behind it start first
+func (suite *QueueTestSuite) TestSnapshotEstimatesRetryBackoff() {
+	processor := &estimatingProcessor{durations: map[string]time.Duration{}}
+	tq := queue.NewTaskQueue(1, processor, suite.jobRepo)
+	var jobs []*models.TranscriptionJob
+	for i, duration := range []time.Duration{20 * time.Second, 60 * time.Second, 30 * time.Second, 10 * time.Second} {
+		job := suite.helper.CreateTestTranscriptionJob(suite.T(), fmt.Sprintf("Job %d", i))
+		assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+		processor.durations[job.ID] = duration
+		jobs = append(jobs, job)
+		time.Sleep(5 * time.Millisecond) // distinct queued_at timestamps
+	}
+	retryAt := time.Now().Add(45 * time.Second)
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", jobs[0].ID).Update("next_attempt_at", retryAt)
+
+	snapshot, err := tq.Snapshot(context.Background())
+	if !assert.NoError(suite.T(), err) || !assert.Len(suite.T(), snapshot.Pending, 4) {
+		return
+	}
+	backoff, second, third, last := snapshot.Pending[0], snapshot.Pending[1], snapshot.Pending[2], snapshot.Pending[3]
+	assert.Equal(suite.T(), jobs[0].ID, backoff.JobID)
+	assert.Equal(suite.T(), 1, backoff.Position)
+
+	// The second job takes the free worker; by the time it is done the
+	// backoff is over and the first job goes ahead of the third again
+	assert.WithinDuration(suite.T(), time.Now(), *second.EstimatedStartAt, time.Second)
+	assert.Equal(suite.T(), *second.EstimatedCompletionAt, *backoff.EstimatedStartAt)
+	assert.Equal(suite.T(), *backoff.EstimatedCompletionAt, *third.EstimatedStartAt)
+	assert.Equal(suite.T(), *third.EstimatedCompletionAt, *last.EstimatedStartAt)
+
+	// With every job backing off, the worker waits for the earliest retry
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id <> ?", jobs[0].ID).Update("next_attempt_at", retryAt.Add(time.Minute))
+	snapshot, err = tq.Snapshot(context.Background())
+	if assert.NoError(suite.T(), err) && assert.Len(suite.T(), snapshot.Pending, 4) {
+		assert.True(suite.T(), retryAt.Equal(*snapshot.Pending[0].EstimatedStartAt))
+		assert.True(suite.T(), retryAt.Add(time.Minute).Equal(*snapshot.Pending[1].EstimatedStartAt))
+	}
+}
+
+// Test paused jobs are skipped and stay paused across restarts
+func (suite *QueueTestSuite) TestPausedJobIsSkipped() {
+	held := suite.helper.CreateTestTranscriptionJob(suite.T(), "Held")
+	next := suite.helper.CreateTestTranscriptionJob(suite.T(), "Next")
+
+	mockProcessor := &MockJobProcessor{}
+	mockProcessor.On("ProcessJobWithProcess", mock.Anything, mock.Anything).Return(nil)
+	tq := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
+	assert.NoError(suite.T(), tq.EnqueueJob(held.ID))
+	time.Sleep(5 * time.Millisecond) // distinct queued_at timestamps
+	assert.NoError(suite.T(), tq.EnqueueJob(next.ID))
+	assert.NoError(suite.T(), tq.PauseJob(held.ID))
+	assert.Equal(suite.T(), queue.ErrJobNotPending, tq.PauseJob("missing"))
+
+	// A new queue, as after a restart, runs the other job only
+	restarted := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
+	restarted.Start()
+	defer restarted.Stop()
+	assert.Eventually(suite.T(), func() bool {
+		job, err := restarted.GetJobStatus(next.ID)
+		return err == nil && job.Status == models.StatusCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+	job, err := restarted.GetJobStatus(held.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusPending, job.Status)
+	assert.True(suite.T(), job.Paused)
+
+	assert.NoError(suite.T(), restarted.ResumeJob(held.ID))
+	assert.Eventually(suite.T(), func() bool {
+		job, err := restarted.GetJobStatus(held.ID)
+		return err == nil && job.Status == models.StatusCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+// Test a paused queue starts no jobs, also after a restart
+func (suite *QueueTestSuite) TestPausedQueueStartsNoJobs() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Waiting")
+
+	mockProcessor := &MockJobProcessor{}
+	mockProcessor.On("ProcessJobWithProcess", mock.Anything, job.ID).Return(nil)
+	tq := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
+	assert.NoError(suite.T(), tq.Pause())
+
+	restarted := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
+	restarted.Start()
+	defer restarted.Stop()
+	assert.True(suite.T(), restarted.IsPaused())
+	assert.NoError(suite.T(), restarted.EnqueueJob(job.ID))
+	time.Sleep(200 * time.Millisecond)
+	queued, err := restarted.GetJobStatus(job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusPending, queued.Status)
+
+	assert.NoError(suite.T(), restarted.Resume())
+	assert.Eventually(suite.T(), func() bool {
+		job, err := restarted.GetJobStatus(job.ID)
+		return err == nil && job.Status == models.StatusCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+// Test moving a job to the front of the queue
+func (suite *QueueTestSuite) TestMoveJobToFront() {
+	tq := queue.NewTaskQueue(1, &MockJobProcessor{}, suite.jobRepo)
+	var ids []string
+	for _, title := range []string{"First", "Second", "Urgent"} {
+		job := suite.helper.CreateTestTranscriptionJob(suite.T(), title)
+		assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+		ids = append(ids, job.ID)
+		time.Sleep(5 * time.Millisecond) // distinct queued_at timestamps
+	}
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", ids[1]).Update("priority", 5)
+
+	assert.NoError(suite.T(), tq.MoveJobToFront(ids[2]))
+	claimed, err := suite.jobRepo.ClaimNextPending(context.Background())
+	if assert.NoError(suite.T(), err) && assert.NotNil(suite.T(), claimed) {
+		assert.Equal(suite.T(), ids[2], claimed.ID)
+		assert.Equal(suite.T(), 5, claimed.Priority)
+	}
+	assert.Equal(suite.T(), queue.ErrJobNotPending, tq.MoveJobToFront(ids[2]))
+
+	// A job waiting out a retry backoff runs right away once moved
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", ids[0]).Update("next_attempt_at", time.Now().Add(time.Hour))
+	assert.NoError(suite.T(), tq.MoveJobToFront(ids[0]))
+	claimed, err = suite.jobRepo.ClaimNextPending(context.Background())
+	if assert.NoError(suite.T(), err) && assert.NotNil(suite.T(), claimed) {
+		assert.Equal(suite.T(), ids[0], claimed.ID)
+	}
+}
+
+// Test a job paused after a worker selected it is not claimed
+func (suite *QueueTestSuite) TestPauseRacingClaimWins() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Raced")
+	_, err := suite.jobRepo.MarkQueued(context.Background(), job.ID)
+	assert.NoError(suite.T(), err)
+
+	// Pause the job between the claim's select and its update
+	var once sync.Once
+	callbacks := suite.helper.DB.Callback().Query()
+	assert.NoError(suite.T(), callbacks.After("gorm:query").Register("test:pause_after_select", func(tx *gorm.DB) {
+		if tx.Statement.Table != "transcription_jobs" {
+			return
+		}
+		once.Do(func() {
+			paused, err := suite.jobRepo.SetPaused(context.Background(), job.ID, true)
+			assert.NoError(suite.T(), err)
+			assert.True(suite.T(), paused)
+		})
+	}))
+	defer callbacks.Remove("test:pause_after_select")
+
+	claimed, err := suite.jobRepo.ClaimNextPending(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), claimed)
+
+	stored, err := suite.jobRepo.FindByID(context.Background(), job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusPending, stored.Status)
+	assert.True(suite.T(), stored.Paused)
+
+	// A claimed job can no longer be paused
+	_, err = suite.jobRepo.SetPaused(context.Background(), job.ID, false)
+	assert.NoError(suite.T(), err)
+	claimed, err = suite.jobRepo.ClaimNextPending(context.Background())
+	if assert.NoError(suite.T(), err) && assert.NotNil(suite.T(), claimed) {
+		paused, err := suite.jobRepo.SetPaused(context.Background(), job.ID, true)
+		assert.NoError(suite.T(), err)
+		assert.False(suite.T(), paused)
+	}
+}
+
+// Test that tasks and jobs share the worker limit and the queue pause
+func (suite *QueueTestSuite) TestTasksShareWorkers() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Behind a task")
+
+	mockProcessor := &MockJobProcessor{}
+	mockProcessor.On("ProcessJobWithProcess", mock.Anything, job.ID).Return(nil)
+	tq := queue.NewTaskQueue(1, mockProcessor, suite.jobRepo)
+	tq.Start()
+	defer tq.Stop()
+
+	started, release := make(chan string, 3), make(chan struct{})
+	done := make(chan error, 3)
+	runTask := func(id string) {
+		done <- tq.RunTask(context.Background(), id, func(ctx context.Context) error {
+			started <- id
+			select {
+			case <-release:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+
+	go runTask("first")
+	assert.Equal(suite.T(), "first", <-started)
+
+	// The only worker slot is taken, so neither the job nor another task starts
+	assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+	go runTask("second")
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(suite.T(), started)
+	queued, err := tq.GetJobStatus(job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusPending, queued.Status)
+
+	// A waiting task can be cancelled before it runs
+	assert.NoError(suite.T(), tq.CancelTask("second"))
+	assert.ErrorIs(suite.T(), <-done, context.Canceled)
+	assert.ErrorIs(suite.T(), tq.CancelTask("second"), queue.ErrTaskNotFound)
+
+	close(release)
+	assert.NoError(suite.T(), <-done)
+	assert.Eventually(suite.T(), func() bool {
+		job, err := tq.GetJobStatus(job.ID)
+		return err == nil && job.Status == models.StatusCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Tasks wait while the queue is paused
+	assert.NoError(suite.T(), tq.Pause())
+	go runTask("third")
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(suite.T(), started)
+	assert.NoError(suite.T(), tq.Resume())
+	assert.Equal(suite.T(), "third", <-started)
+	assert.NoError(suite.T(), <-done)
+}
@@ -22,6 +22,7 @@ import (
 	"scriberr/internal/service"
 	"scriberr/internal/sse"
 	"scriberr/internal/transcription"
+	"scriberr/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -72,6 +73,13 @@ func (suite *SecurityTestSuite) SetupSuite() {
 	noteRepo := repository.NewNoteRepository(database.DB)
 	speakerMappingRepo := repository.NewSpeakerMappingRepository(database.DB)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(database.DB)
+	searchRepo := repository.NewSearchRepository(database.DB)
+	embeddingRepo := repository.NewEmbeddingRepository(database.DB)
+	revisionRepo := repository.NewRevisionRepository(database.DB)
+	comparisonRepo := repository.NewComparisonRepository(database.DB)
+	vocabularyRepo := repository.NewVocabularyRepository(database.DB)
+	speakerRepo := repository.NewSpeakerRepository(database.DB)
+	webhookRepo := repository.NewWebhookRepository(database.DB)
 
 	// Initialize services
 	userService := service.NewUserService(userRepo, suite.authService)
@@ -105,6 +113,14 @@ func (suite *SecurityTestSuite) SetupSuite() {
 		noteRepo,
 		speakerMappingRepo,
 		refreshTokenRepo,
+		searchRepo,
+		embeddingRepo,
+		revisionRepo,
+		comparisonRepo,
+		vocabularyRepo,
+		speakerRepo,
+		webhookRepo,
+		webhook.NewDispatcher(webhookRepo),
 		suite.taskQueue,
 		suite.unifiedProcessor,
 		suite.quickTranscriptionService,
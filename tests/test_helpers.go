@@ -92,10 +92,14 @@ func (h *TestHelper) Cleanup() {
 func (h *TestHelper) ResetDB(t *testing.T) {
 	// List of models to clean
 	modelsToClean := []interface{}{
+		&models.WebhookDelivery{},
+		&models.WebhookSubscription{},
 		&models.Note{},
 		&models.ChatSession{},
+		&models.AudioChunk{},
 		&models.TranscriptionJobExecution{}, // Assuming this exists based on MockJobRepository
 		&models.TranscriptionJob{},
+		&models.QueueSetting{},
 		&models.TranscriptionProfile{},
 		&models.SummaryTemplate{},
 		&models.LLMConfig{},
@@ -114,6 +118,9 @@ func (h *TestHelper) ResetDB(t *testing.T) {
 		}
 	}
 
+	// The full-text index is a virtual table without a model
+	h.DB.Exec("DELETE FROM search_index")
+
 	// Re-create test credentials as they are deleted by the cleanup
 	h.createTestCredentials(t)
 }
@@ -127,6 +134,7 @@ func (h *TestHelper) createTestCredentials(t *testing.T) {
 	user := models.User{
 		Username: "testuser",
 		Password: hashedPassword,
+		Role:     models.RoleAdmin,
 	}
 
 	result := h.DB.Create(&user)
@@ -140,8 +148,10 @@ func (h *TestHelper) createTestCredentials(t *testing.T) {
 
 	// Create test API key
 	apiKey := models.APIKey{
+		UserID:   user.ID,
 		Key:      "test-api-key-" + strings.ReplaceAll(t.Name(), "/", "_"),
 		Name:     "Test API Key for " + strings.ReplaceAll(t.Name(), "/", "_"),
+		Scopes:   models.AllAPIKeyScopes,
 		IsActive: true,
 	}
 
@@ -154,6 +164,7 @@ func (h *TestHelper) createTestCredentials(t *testing.T) {
 func (h *TestHelper) CreateTestTranscriptionJob(t *testing.T, title string) *models.TranscriptionJob {
 	// Let GORM assign a unique UUID via model hook to avoid ID collisions
 	job := &models.TranscriptionJob{
+		UserID:    h.TestUser.ID,
 		Title:     &title,
 		Status:    models.StatusPending,
 		AudioPath: "test/path/audio.mp3",
@@ -227,8 +238,8 @@ func (h *TestHelper) CreateTestSummaryTemplate(t *testing.T, name string) *model
 func (h *TestHelper) CreateTestChatSession(t *testing.T, transcriptionID string) *models.ChatSession {
 	session := &models.ChatSession{
 		ID:              "test-chat-session-" + strings.ReplaceAll(t.Name(), "/", "_"),
-		JobID:           transcriptionID,
-		TranscriptionID: transcriptionID,
+		JobID:           &transcriptionID,
+		TranscriptionID: &transcriptionID,
 		Title:           "Test Chat Session",
 		Model:           "gpt-4",
 		Provider:        "openai",
@@ -301,6 +312,14 @@ func (m *MockJobRepository) FindWithAssociations(ctx context.Context, id string)
 	return args.Get(0).(*models.TranscriptionJob), args.Error(1)
 }
 
+func (m *MockJobRepository) FindByIDForUser(ctx context.Context, id string, userID uint) (*models.TranscriptionJob, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TranscriptionJob), args.Error(1)
+}
+
 func (m *MockJobRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.TranscriptionJob, int64, error) {
 	args := m.Called(ctx, userID, offset, limit)
 	return args.Get(0).([]models.TranscriptionJob), args.Get(1).(int64), args.Error(2)
@@ -331,8 +350,36 @@ func (m *MockJobRepository) DeleteMultiTrackFilesByJobID(ctx context.Context, jo
 	return args.Error(0)
 }
 
-func (m *MockJobRepository) ListWithParams(ctx context.Context, offset, limit int, sortBy, sortOrder, searchQuery string, updatedAfter *time.Time) ([]models.TranscriptionJob, int64, error) {
-	args := m.Called(ctx, offset, limit, sortBy, sortOrder, searchQuery, updatedAfter)
+func (m *MockJobRepository) ListChunks(ctx context.Context, jobID string) ([]models.AudioChunk, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AudioChunk), args.Error(1)
+}
+
+func (m *MockJobRepository) ReplaceChunks(ctx context.Context, jobID string, chunks []models.AudioChunk) error {
+	args := m.Called(ctx, jobID, chunks)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) CompleteChunk(ctx context.Context, chunkID uint, result string) error {
+	args := m.Called(ctx, chunkID, result)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) CountCompletedChunks(ctx context.Context, jobID string) (int64, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockJobRepository) DeleteChunksByJobID(ctx context.Context, jobID string) error {
+	args := m.Called(ctx, jobID)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) ListWithParams(ctx context.Context, userID uint, offset, limit int, sortBy, sortOrder, searchQuery string, updatedAfter *time.Time) ([]models.TranscriptionJob, int64, error) {
+	args := m.Called(ctx, userID, offset, limit, sortBy, sortOrder, searchQuery, updatedAfter)
 	return args.Get(0).([]models.TranscriptionJob), args.Get(1).(int64), args.Error(2)
 }
 
@@ -352,6 +399,27 @@ func (m *MockJobRepository) FindLatestCompletedExecution(ctx context.Context, jo
 	return args.Get(0).(*models.TranscriptionJobExecution), args.Error(1)
 }
 
+func (m *MockJobRepository) FindExecution(ctx context.Context, jobID, executionID string) (*models.TranscriptionJobExecution, error) {
+	args := m.Called(ctx, jobID, executionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TranscriptionJobExecution), args.Error(1)
+}
+
+func (m *MockJobRepository) ListExecutions(ctx context.Context, jobID string) ([]models.TranscriptionJobExecution, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TranscriptionJobExecution), args.Error(1)
+}
+
+func (m *MockJobRepository) SetActiveExecution(ctx context.Context, jobID, executionID string) error {
+	args := m.Called(ctx, jobID, executionID)
+	return args.Error(0)
+}
+
 func (m *MockJobRepository) UpdateStatus(ctx context.Context, jobID string, status models.JobStatus) error {
 	args := m.Called(ctx, jobID, status)
 	return args.Error(0)
@@ -380,6 +448,80 @@ func (m *MockJobRepository) UpdateSummary(ctx context.Context, jobID string, sum
 	return args.Error(0)
 }
 
+func (m *MockJobRepository) MarkQueued(ctx context.Context, jobID string) (bool, error) {
+	args := m.Called(ctx, jobID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) RequeueJob(ctx context.Context, job *models.TranscriptionJob, from []models.JobStatus) (bool, error) {
+	args := m.Called(ctx, job, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) ClaimNextPending(ctx context.Context) (*models.TranscriptionJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TranscriptionJob), args.Error(1)
+}
+
+func (m *MockJobRepository) ListQueued(ctx context.Context) ([]models.TranscriptionJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TranscriptionJob), args.Error(1)
+}
+
+func (m *MockJobRepository) ScheduleRetry(ctx context.Context, jobID string, at time.Time, errorMsg string) error {
+	args := m.Called(ctx, jobID, at, errorMsg)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) SetWebhookDeferred(ctx context.Context, jobID string, deferred bool) error {
+	args := m.Called(ctx, jobID, deferred)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) FindWebhookDeferred(ctx context.Context) ([]models.TranscriptionJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TranscriptionJob), args.Error(1)
+}
+
+func (m *MockJobRepository) SetAudioDuration(ctx context.Context, jobID string, seconds float64) error {
+	args := m.Called(ctx, jobID, seconds)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) ProcessingSpeed(ctx context.Context, modelFamily, model, device string, limit int) (float64, int, error) {
+	args := m.Called(ctx, modelFamily, model, device, limit)
+	return args.Get(0).(float64), args.Int(1), args.Error(2)
+}
+
+func (m *MockJobRepository) SetPaused(ctx context.Context, jobID string, paused bool) (bool, error) {
+	args := m.Called(ctx, jobID, paused)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) MoveToFront(ctx context.Context, jobID string) (bool, error) {
+	args := m.Called(ctx, jobID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) QueuePaused(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) SetQueuePaused(ctx context.Context, paused bool) error {
+	args := m.Called(ctx, paused)
+	return args.Error(0)
+}
+
 // NewMockOpenAIServer creates a new mock OpenAI server for testing
 func NewMockOpenAIServer() *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {